@@ -0,0 +1,32 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/gop/langserver"
+	goppackages "golang.org/x/tools/gop/packages"
+	gopssautil "golang.org/x/tools/gop/ssa/ssautil"
+)
+
+// loadGop loads the Go/Go+ packages named by args, generating the Go code
+// of Go+ packages in-process, and creates their SSA-form representation.
+// The positions of functions and call sites in Go+ source are those of
+// the Go+ source, not of the generated gop_autogen.go files.
+func loadGop(cfg *packages.Config, args []string, mode ssa.BuilderMode) (*ssa.Program, []*ssa.Package, error) {
+	goppackages.SetGenGoMode(langserver.ModeInProcess)
+	initial, err := goppackages.Load(cfg, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if goppackages.PrintErrors(initial) > 0 {
+		return nil, nil, fmt.Errorf("packages contain errors")
+	}
+	prog, pkgs := gopssautil.AllPackages(initial, mode)
+	return prog, pkgs, nil
+}
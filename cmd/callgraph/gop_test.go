@@ -0,0 +1,98 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !android
+// +build !android
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/testenv"
+)
+
+const gopSrc = `func add = (
+	func(a, b int) int {
+		return a + b
+	}
+	func(a, b string) string {
+		return a + b
+	}
+)
+
+func sum(s []int) int {
+	n := 0
+	for x <- s {
+		n += x
+	}
+	return add(n, 1)
+}
+
+r := &Rect{W: 2, H: 3}
+println sum([1, 2]), add("a", "b"), r.Area()
+`
+
+const rectSrc = `var (
+	W, H int
+)
+
+func Area() int {
+	return add(W*H, 0)
+}
+`
+
+// TestCallgraphGop checks that -gop reports the call sites of a Go+
+// program, including calls of overloaded functions and classfile
+// methods, at their positions in the Go+ source.
+func TestCallgraphGop(t *testing.T) {
+	testenv.NeedsGOPROOT(t)
+	t.Setenv("GO111MODULE", "on") // see init in main_test.go
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":   "module example.com/a\n\ngo 1.18\n",
+		"a.gop":    gopSrc,
+		"Rect.gox": rectSrc,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const format = "{{.Caller}} --> {{.Callee}} {{.Filename}}:{{.Line}}"
+	for _, algo := range []string{"static", "rta"} {
+		stdout = new(bytes.Buffer)
+		if err := doCallgraph(dir, "", algo, format, false, true, []string{dir}); err != nil {
+			t.Error(err)
+			continue
+		}
+
+		edges := make(map[string]bool)
+		for _, line := range strings.Split(fmt.Sprint(stdout), "\n") {
+			edges[strings.ReplaceAll(line, dir+string(filepath.Separator), "")] = true
+		}
+		ok := true
+		for _, edge := range []string{
+			"example.com/a.main --> example.com/a.sum a.gop:19",
+			"example.com/a.main --> example.com/a.add__1 a.gop:19",
+			"example.com/a.main --> (*example.com/a.Rect).Area a.gop:19",
+			"example.com/a.sum --> example.com/a.add__0 a.gop:15",
+			"(*example.com/a.Rect).Area --> example.com/a.add__0 Rect.gox:6",
+		} {
+			if !edges[edge] {
+				ok = false
+				t.Errorf("callgraph(%q): missing edge: %s", algo, edge)
+			}
+		}
+		if !ok {
+			t.Log("got:\n", stdout)
+		}
+	}
+}
@@ -52,6 +52,9 @@ var (
 	formatFlag = flag.String("format",
 		"{{.Caller}}\t--{{.Dynamic}}-{{.Line}}:{{.Column}}-->\t{{.Callee}}",
 		"A template expression specifying how to format an edge")
+
+	gopFlag = flag.Bool("gop", false,
+		"Loads Go+ packages and reports positions in Go+ source")
 )
 
 func init() {
@@ -62,7 +65,7 @@ const Usage = `callgraph: display the call graph of a Go program.
 
 Usage:
 
-  callgraph [-algo=static|cha|rta|vta] [-test] [-format=...] package...
+  callgraph [-algo=static|cha|rta|vta] [-test] [-gop] [-format=...] package...
 
 Flags:
 
@@ -80,6 +83,13 @@ Flags:
 
 -test      Include the package's tests in the analysis.
 
+-gop       Load Go+ packages too, generating the Go code of their Go+
+           files (gop_autogen.go) with the gop library. Functions and
+           call sites declared in Go+ source are reported at their
+           positions in the Go+ files rather than in the generated code.
+           Go+ packages are found only by patterns that denote
+           directories, such as ./... or /abs/dir.
+
 -format    Specifies the format in which each call graph edge is displayed.
            One of:
 
@@ -151,7 +161,7 @@ func init() {
 
 func main() {
 	flag.Parse()
-	if err := doCallgraph("", "", *algoFlag, *formatFlag, *testFlag, flag.Args()); err != nil {
+	if err := doCallgraph("", "", *algoFlag, *formatFlag, *testFlag, *gopFlag, flag.Args()); err != nil {
 		fmt.Fprintf(os.Stderr, "callgraph: %s\n", err)
 		os.Exit(1)
 	}
@@ -159,7 +169,7 @@ func main() {
 
 var stdout io.Writer = os.Stdout
 
-func doCallgraph(dir, gopath, algo, format string, tests bool, args []string) error {
+func doCallgraph(dir, gopath, algo, format string, tests, gop bool, args []string) error {
 	if len(args) == 0 {
 		fmt.Fprint(os.Stderr, Usage)
 		return nil
@@ -173,17 +183,28 @@ func doCallgraph(dir, gopath, algo, format string, tests bool, args []string) er
 	if gopath != "" {
 		cfg.Env = append(os.Environ(), "GOPATH="+gopath) // to enable testing
 	}
-	initial, err := packages.Load(cfg, args...)
-	if err != nil {
-		return err
-	}
-	if packages.PrintErrors(initial) > 0 {
-		return fmt.Errorf("packages contain errors")
-	}
 
 	// Create and build SSA-form program representation.
 	mode := ssa.InstantiateGenerics // instantiate generics by default for soundness
-	prog, pkgs := ssautil.AllPackages(initial, mode)
+	var (
+		prog *ssa.Program
+		pkgs []*ssa.Package
+	)
+	if gop { // goxls: load Go+ packages
+		var err error
+		if prog, pkgs, err = loadGop(cfg, args, mode); err != nil {
+			return err
+		}
+	} else {
+		initial, err := packages.Load(cfg, args...)
+		if err != nil {
+			return err
+		}
+		if packages.PrintErrors(initial) > 0 {
+			return fmt.Errorf("packages contain errors")
+		}
+		prog, pkgs = ssautil.AllPackages(initial, mode)
+	}
 	prog.Build()
 
 	// -- call graph construction ------------------------------------------
@@ -82,7 +82,7 @@ func TestCallgraph(t *testing.T) {
 	} {
 		const format = "{{.Caller}} --> {{.Callee}}"
 		stdout = new(bytes.Buffer)
-		if err := doCallgraph("testdata/src", gopath, test.algo, format, test.tests, []string{"pkg"}); err != nil {
+		if err := doCallgraph("testdata/src", gopath, test.algo, format, test.tests, false, []string{"pkg"}); err != nil {
 			t.Error(err)
 			continue
 		}
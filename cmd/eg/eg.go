@@ -22,6 +22,7 @@ import (
 
 	exec "golang.org/x/sys/execabs"
 	"golang.org/x/tools/go/packages"
+	goppackages "golang.org/x/tools/gop/packages"
 	"golang.org/x/tools/refactor/eg"
 )
 
@@ -32,17 +33,21 @@ var (
 	transitiveFlag = flag.Bool("transitive", false, "apply refactoring to all dependencies too")
 	writeFlag      = flag.Bool("w", false, "rewrite input files in place (by default, the results are printed to standard output)")
 	verboseFlag    = flag.Bool("v", false, "show verbose matcher diagnostics")
+	gopFlag        = flag.Bool("gop", false, "load Go+ packages and apply refactoring to Go+ source files too")
 )
 
 const usage = `eg: an example-based refactoring tool.
 
-Usage: eg -t template.go [-w] [-transitive] <packages>
+Usage: eg -t template.go [-w] [-transitive] [-gop] <packages>
 
 -help            show detailed help message
 -t template.go	 specifies the template file (use -help to see explanation)
 -w          	 causes files to be re-written in place.
 -transitive 	 causes all dependencies to be refactored too.
 -v               show verbose matcher diagnostics
+-gop             causes Go+ packages to be loaded and their Go+ source
+                 files (.gop, .gox, ...) to be refactored too. The Go
+                 code generated from them is generated anew.
 -beforeedit cmd  a command to exec before each file is modified.
                  "{}" represents the name of the file.
 `
@@ -88,7 +93,17 @@ func doMain() error {
 		Tests: true,
 	}
 
-	pkgs, err := packages.Load(cfg, args...)
+	var pkgs []*packages.Package
+	var gopPkgs []*goppackages.Package
+	if *gopFlag {
+		// goxls: load Go+ packages
+		gopPkgs, err = loadGop(cfg, args)
+		for _, pkg := range gopPkgs {
+			pkgs = append(pkgs, &pkg.Package)
+		}
+	} else {
+		pkgs, err = packages.Load(cfg, args...)
+	}
 	if err != nil {
 		return err
 	}
@@ -135,6 +150,10 @@ func doMain() error {
 				// Don't rewrite the template file.
 				continue
 			}
+			if *gopFlag && isGopAutogen(filename) {
+				// goxls: generated anew from Go+ source
+				continue
+			}
 			file := pkg.Syntax[i]
 			n := xform.Transform(pkg.TypesInfo, pkg.Types, file)
 			if n == 0 {
@@ -142,23 +161,7 @@ func doMain() error {
 			}
 			fmt.Fprintf(os.Stderr, "=== %s (%d matches)\n", filename, n)
 			if *writeFlag {
-				// Run the before-edit command (e.g. "chmod +w",  "checkout") if any.
-				if *beforeeditFlag != "" {
-					args := strings.Fields(*beforeeditFlag)
-					// Replace "{}" with the filename, like find(1).
-					for i := range args {
-						if i > 0 {
-							args[i] = strings.Replace(args[i], "{}", filename, -1)
-						}
-					}
-					cmd := exec.Command(args[0], args[1:]...)
-					cmd.Stdout = os.Stdout
-					cmd.Stderr = os.Stderr
-					if err := cmd.Run(); err != nil {
-						fmt.Fprintf(os.Stderr, "Warning: edit hook %q failed (%s)\n",
-							args, err)
-					}
-				}
+				beforeEdit(filename) // goxls: shared with Go+ files
 				if err := eg.WriteAST(cfg.Fset, filename, file); err != nil {
 					fmt.Fprintf(os.Stderr, "eg: %s\n", err)
 					hadErrors = true
@@ -168,6 +171,13 @@ func doMain() error {
 			}
 		}
 	}
+	if *gopFlag {
+		// goxls: apply it to the Go+ source files too
+		if err := transformGop(cfg.Fset, gopPkgs, tAbs, template); err != nil {
+			fmt.Fprintf(os.Stderr, "eg: %s\n", err)
+			hadErrors = true
+		}
+	}
 	if hadErrors {
 		os.Exit(1)
 	}
@@ -175,6 +185,26 @@ func doMain() error {
 	return nil
 }
 
+// beforeEdit runs the before-edit command (e.g. "chmod +w",  "checkout") if any.
+func beforeEdit(filename string) {
+	if *beforeeditFlag != "" {
+		args := strings.Fields(*beforeeditFlag)
+		// Replace "{}" with the filename, like find(1).
+		for i := range args {
+			if i > 0 {
+				args[i] = strings.Replace(args[i], "{}", filename, -1)
+			}
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: edit hook %q failed (%s)\n",
+				args, err)
+		}
+	}
+}
+
 type pkgsImporter []*packages.Package
 
 func (p pkgsImporter) Import(path string) (tpkg *types.Package, err error) {
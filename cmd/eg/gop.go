@@ -0,0 +1,155 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/format"
+	"github.com/goplus/gop/parser"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/gop/langserver"
+	goppackages "golang.org/x/tools/gop/packages"
+	"golang.org/x/tools/gop/refactor/eg"
+)
+
+// loadGop loads the Go/Go+ packages named by args, generating the Go code
+// of Go+ packages in-process.
+func loadGop(cfg *packages.Config, args []string) ([]*goppackages.Package, error) {
+	goppackages.SetGenGoMode(langserver.ModeInProcess)
+	return goppackages.Load(cfg, args...)
+}
+
+// isGopAutogen reports whether filename is a Go file generated from Go+
+// files.
+func isGopAutogen(filename string) bool {
+	return strings.HasPrefix(filepath.Base(filename), "gop_autogen")
+}
+
+// transformGop applies the refactoring specified by the template file
+// tAbs to the Go+ source files of pkgs (and their dependencies, if
+// -transitive), then generates anew the Go code of the rewritten
+// packages if -w.
+//
+// The template is parsed and type-checked as Go+ source, so that it
+// matches the Go+ syntax trees.
+func transformGop(fset *token.FileSet, pkgs []*goppackages.Package, tAbs string, template []byte) error {
+	tFile, err := parser.ParseFile(fset, tAbs, template, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	// Type-check the template.
+	tInfo := &typesutil.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Overloads:  make(map[*ast.Ident][]types.Object),
+	}
+	var firstErr error
+	conf := &types.Config{
+		Importer: gopPkgsImporter(pkgs),
+		Error: func(err error) {
+			if firstErr == nil {
+				firstErr = err
+			}
+		},
+	}
+	tPkg := types.NewPackage("egtemplate", tFile.Name.Name)
+	opts := &typesutil.Config{Types: tPkg, Fset: fset}
+	if err := typesutil.NewChecker(conf, opts, nil, tInfo).Files(nil, []*ast.File{tFile}); err != nil {
+		return err
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+
+	// Analyze the template.
+	xform, err := eg.NewTransformer(fset, tPkg, tFile, tInfo, *verboseFlag)
+	if err != nil {
+		return err
+	}
+
+	// Apply it to the Go+ files of the input packages.
+	var all []*goppackages.Package
+	if *transitiveFlag {
+		goppackages.Visit(pkgs, nil, func(p *goppackages.Package) { all = append(all, p) })
+	} else {
+		all = pkgs
+	}
+	var nerrs int
+	dirs := make(map[string]bool) // directories of rewritten files
+	for _, pkg := range all {
+		if pkg.GopTypesInfo == nil {
+			continue
+		}
+		for i, filename := range pkg.CompiledGopFiles {
+			if filename == tAbs || i >= len(pkg.GopSyntax) {
+				continue
+			}
+			file := pkg.GopSyntax[i]
+			n := xform.Transform(pkg.GopTypesInfo, pkg.Types, file)
+			if n == 0 {
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "=== %s (%d matches)\n", filename, n)
+			if *writeFlag {
+				beforeEdit(filename)
+				if err := eg.WriteAST(fset, filename, file); err != nil {
+					fmt.Fprintf(os.Stderr, "eg: %s\n", err)
+					nerrs++
+					continue
+				}
+				dirs[filepath.Dir(filename)] = true
+			} else {
+				format.Node(os.Stdout, fset, file)
+			}
+		}
+	}
+
+	// Generate the Go code of the rewritten Go+ packages anew.
+	if len(dirs) > 0 {
+		var patterns []string
+		for dir := range dirs {
+			patterns = append(patterns, dir)
+		}
+		sort.Strings(patterns)
+		if _, err := goppackages.GenGo(patterns...); err != nil {
+			fmt.Fprintf(os.Stderr, "eg: %s\n", err)
+			nerrs++
+		}
+	}
+	if nerrs > 0 {
+		return fmt.Errorf("failed to rewrite %d Go+ file(s)", nerrs)
+	}
+	return nil
+}
+
+type gopPkgsImporter []*goppackages.Package
+
+func (p gopPkgsImporter) Import(path string) (tpkg *types.Package, err error) {
+	goppackages.Visit([]*goppackages.Package(p), func(pkg *goppackages.Package) bool {
+		if pkg.PkgPath == path {
+			tpkg = pkg.Types
+			return false
+		}
+		return true
+	}, nil)
+	if tpkg != nil {
+		return tpkg, nil
+	}
+	return nil, fmt.Errorf("package %q not found", path)
+}
@@ -100,6 +100,12 @@ one may run godoc as follows:
 
 	godoc -http=:6060 -zip=go.zip -goroot=$HOME/go
 
+Go+ packages are documented from their Go+ source files (.gop files and
+classfiles) rather than from the Go files generated from them. A classfile
+is presented as a type with its fields and methods, and overloads and
+operators are presented in Go+ syntax. Examples in _test.gop files are shown
+like those in _test.go files.
+
 Godoc documentation is converted to HTML or to text using the go/doc package;
 see https://golang.org/pkg/go/doc/#ToHTML for the exact rules.
 Godoc also shows example code that is runnable by the testing package;
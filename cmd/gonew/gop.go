@@ -0,0 +1,137 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goplus/gop/parser"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/mod/modfile"
+	"golang.org/x/tools/gop/goputil"
+	"golang.org/x/tools/internal/edit"
+)
+
+// gopClassExts returns the classfile extensions (such as "_yap.gox" or
+// ".tdsl") registered by the gop.mod file in the module directory dir.
+// It returns nil if the module has no gop.mod file.
+func gopClassExts(dir string) map[string]bool {
+	data, err := os.ReadFile(filepath.Join(dir, "gop.mod"))
+	if err != nil {
+		return nil
+	}
+	f, err := modfile.ParseLax("gop.mod", data, nil)
+	if err != nil {
+		log.Fatalf("parsing source module:\n%s", err)
+	}
+	exts := make(map[string]bool)
+	for _, proj := range f.Projects {
+		if proj.Ext != "" {
+			exts[proj.Ext] = true
+		}
+		for _, w := range proj.Works {
+			exts[w.Ext] = true
+		}
+	}
+	return exts
+}
+
+// isGopFile reports whether the file named file is a Go+ source file,
+// either by its extension or because it is a classfile registered in
+// classExts.
+func isGopFile(file string, classExts map[string]bool) bool {
+	return goputil.FileKind(path.Ext(file)) != goputil.FileUnknown || classExts[modfile.ClassExt(file)]
+}
+
+// isGopAutogen reports whether the file named file holds the Go code gop
+// generates from Go+ files, such as gop_autogen.go or gop_autogen_test.go.
+// Such files refer to the source module path and are not copied: gop
+// generates them anew for the destination module.
+func isGopAutogen(file string) bool {
+	name := filepath.Base(file)
+	return strings.HasPrefix(name, "gop_autogen") && strings.HasSuffix(name, ".go")
+}
+
+// fixGop rewrites the Go+ source in data to replace srcMod with dstMod.
+// isRoot indicates whether the file is in the root directory of the module,
+// in which case we also update the package name if the file declares one.
+func fixGop(data []byte, file string, srcMod, dstMod string, isRoot bool) []byte {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, data, parser.ImportsOnly)
+	if err != nil {
+		log.Fatalf("parsing source module:\n%s", err)
+	}
+
+	buf := edit.NewBuffer(data)
+	at := func(p token.Pos) int {
+		return fset.File(p).Offset(p)
+	}
+
+	srcName := path.Base(srcMod)
+	dstName := path.Base(dstMod)
+	if isRoot && !f.NoPkgDecl {
+		if name := f.Name.Name; name == srcName || name == srcName+"_test" {
+			dname := dstName + strings.TrimPrefix(name, srcName)
+			if !token.IsIdentifier(dname) {
+				log.Fatalf("%s: cannot rename package %s to package %s: invalid package name", file, name, dname)
+			}
+			buf.Replace(at(f.Name.Pos()), at(f.Name.End()), dname)
+		}
+	}
+
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if path == srcMod {
+			if srcName != dstName && spec.Name == nil {
+				// Add package rename because source code uses original name.
+				// See fixGo.
+				buf.Insert(at(spec.Path.Pos()), srcName+" ")
+			}
+			buf.Replace(at(spec.Path.Pos()), at(spec.Path.End()), strconv.Quote(dstMod))
+		}
+		if strings.HasPrefix(path, srcMod+"/") {
+			buf.Replace(at(spec.Path.Pos()), at(spec.Path.End()), strconv.Quote(strings.Replace(path, srcMod, dstMod, 1)))
+		}
+	}
+	return buf.Bytes()
+}
+
+// fixGopMod rewrites the gop.mod content in data to replace srcMod with
+// dstMod in the package paths of project and import statements, which
+// register the classfiles a template may declare in its own packages.
+func fixGopMod(data []byte, srcMod, dstMod string) []byte {
+	f, err := modfile.ParseLax("gop.mod", data, nil)
+	if err != nil {
+		log.Fatalf("parsing source module:\n%s", err)
+	}
+	fix := func(line *modfile.Line) {
+		for i, tok := range line.Token {
+			path := tok
+			if strings.HasPrefix(tok, `"`) {
+				if path, err = strconv.Unquote(tok); err != nil {
+					continue
+				}
+			}
+			if path == srcMod || strings.HasPrefix(path, srcMod+"/") {
+				line.Token[i] = modfile.AutoQuote(strings.Replace(path, srcMod, dstMod, 1))
+			}
+		}
+	}
+	for _, proj := range f.Projects {
+		fix(proj.Syntax)
+		for _, imp := range proj.Import {
+			fix(imp.Syntax)
+		}
+	}
+	return modfile.Format(f.Syntax)
+}
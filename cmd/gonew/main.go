@@ -30,6 +30,15 @@
 // into ./quote:
 //
 //	gonew rsc.io/quote
+//
+// # Go+ templates
+//
+// Gonew also retargets Go+ template modules. It rewrites the import
+// paths in Go+ source files (.gop files and classfiles, including those
+// registered in gop.mod) and the package paths of the project and import
+// statements in gop.mod. The gop_autogen*.go files that gop generates from
+// Go+ source are not copied, since they refer to the source module path;
+// run gop go (or gop build) in the new module to generate them again.
 package main
 
 import (
@@ -123,6 +132,8 @@ func main() {
 		}
 	}
 
+	classExts := gopClassExts(info.Dir) // goxls: Go+ classfiles
+
 	// Copy from module cache into new directory, making edits as needed.
 	filepath.WalkDir(info.Dir, func(src string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -140,6 +151,10 @@ func main() {
 			return nil
 		}
 
+		if isGopAutogen(rel) { // goxls: stale Go code generated from Go+ files
+			return nil
+		}
+
 		data, err := os.ReadFile(src)
 		if err != nil {
 			log.Fatal(err)
@@ -152,6 +167,13 @@ func main() {
 		if rel == "go.mod" {
 			data = fixGoMod(data, srcMod, dstMod)
 		}
+		// goxls: Go+ source and gop.mod
+		if isGopFile(rel, classExts) {
+			data = fixGop(data, rel, srcMod, dstMod, isRoot)
+		}
+		if rel == "gop.mod" {
+			data = fixGopMod(data, srcMod, dstMod)
+		}
 
 		if err := os.WriteFile(dst, data, 0666); err != nil {
 			log.Fatal(err)
@@ -18,6 +18,8 @@ import (
 	"os"
 
 	"golang.org/x/tools/go/buildutil"
+	"golang.org/x/tools/gop/langserver"
+	"golang.org/x/tools/gop/packages"
 	"golang.org/x/tools/refactor/rename"
 )
 
@@ -26,6 +28,7 @@ var (
 	fromFlag   = flag.String("from", "", "identifier to be renamed; see -help for formats")
 	toFlag     = flag.String("to", "", "new name for identifier")
 	helpFlag   = flag.Bool("help", false, "show usage message")
+	gopFlag    = flag.Bool("gop", false, "rename references in Go+ source files too")
 )
 
 func init() {
@@ -49,6 +52,11 @@ func main() {
 		return
 	}
 
+	if *gopFlag {
+		rename.Gop = true
+		packages.SetGenGoMode(langserver.ModeInProcess)
+	}
+
 	if err := rename.Main(&build.Default, *offsetFlag, *fromFlag, *toFlag); err != nil {
 		if err != rename.ConflictError {
 			log.Fatal(err)
@@ -0,0 +1,145 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/format"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/gop/langserver"
+	"golang.org/x/tools/gop/packages"
+)
+
+// parseGopPackage analyzes the single Go+ package in directory dir,
+// generating the Go code of its Go+ files in-process. The Go files
+// generated from Go+ files (gop_autogen.go) are not scanned for
+// constants: their Go+ source is.
+// parseGopPackage exits if there is an error.
+func (g *Generator) parseGopPackage(dir string, tags []string) {
+	dir, err := filepath.Abs(dir) // Go+ code is generated for directory patterns only
+	if err != nil {
+		log.Fatal(err)
+	}
+	packages.SetGenGoMode(langserver.ModeInProcess)
+	cfg := &packages.Config{
+		Mode:       packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
+		Tests:      false,
+		BuildFlags: []string{fmt.Sprintf("-tags=%s", strings.Join(tags, " "))},
+	}
+	pkgs, err := packages.Load(cfg, dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(pkgs) != 1 {
+		log.Fatalf("error: %d packages found", len(pkgs))
+	}
+	g.addGopPackage(pkgs[0])
+}
+
+// addGopPackage adds a type checked Go+ Package and its Go and Go+ syntax
+// files to the generator.
+func (g *Generator) addGopPackage(pkg *packages.Package) {
+	g.pkg = &Package{
+		name: pkg.Name,
+		defs: pkg.TypesInfo.Defs,
+	}
+	if pkg.GopTypesInfo != nil {
+		g.pkg.gopDefs = pkg.GopTypesInfo.Defs
+	}
+
+	for _, file := range pkg.NongenSyntax {
+		g.pkg.files = append(g.pkg.files, &File{
+			file:        file,
+			pkg:         g.pkg,
+			trimPrefix:  g.trimPrefix,
+			lineComment: g.lineComment,
+		})
+	}
+	for _, file := range pkg.GopSyntax {
+		g.pkg.files = append(g.pkg.files, &File{
+			gopFile:     file,
+			pkg:         g.pkg,
+			trimPrefix:  g.trimPrefix,
+			lineComment: g.lineComment,
+		})
+	}
+}
+
+// gopGenDecl processes one declaration clause of a Go+ file.
+// It is the Go+ counterpart of genDecl.
+func (f *File) gopGenDecl(node ast.Node) bool {
+	decl, ok := node.(*ast.GenDecl)
+	if !ok || decl.Tok != token.CONST {
+		// We only care about const declarations.
+		return true
+	}
+	// The name of the type of the constants we are declaring.
+	// Can change if this is a multi-element declaration.
+	typ := ""
+	for _, spec := range decl.Specs {
+		vspec := spec.(*ast.ValueSpec) // Guaranteed to succeed as this is CONST.
+		if vspec.Type == nil && len(vspec.Values) > 0 {
+			// "X = 1". With no type but a value. If the constant is untyped,
+			// skip this vspec and reset the remembered type.
+			typ = ""
+
+			// If this is a simple type conversion, remember the type.
+			ce, ok := vspec.Values[0].(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			id, ok := ce.Fun.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			typ = id.Name
+		}
+		if vspec.Type != nil {
+			// "X T". We have a type. Remember it.
+			ident, ok := vspec.Type.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			typ = ident.Name
+		}
+		if typ != f.typeName {
+			// This is not the type we're looking for.
+			continue
+		}
+		for _, name := range vspec.Names {
+			if name.Name == "_" {
+				continue
+			}
+			obj, ok := f.pkg.gopDefs[name]
+			if !ok || obj == nil {
+				log.Fatalf("no value for constant %s", name)
+			}
+			v := f.newValue(name.Name, obj, typ)
+			if c := vspec.Comment; f.lineComment && c != nil && len(c.List) == 1 {
+				v.name = strings.TrimSpace(c.Text())
+			} else {
+				v.name = strings.TrimPrefix(v.originalName, f.trimPrefix)
+			}
+			f.values = append(f.values, v)
+		}
+	}
+	return false
+}
+
+// formatGop returns the Go+ formatting of the generated Go source src.
+func formatGop(src []byte) []byte {
+	out, err := format.Source(src, false)
+	if err != nil {
+		// Should never happen, but can arise when developing this code.
+		log.Printf("warning: internal error: invalid Go+ generated: %s", err)
+		return src
+	}
+	return out
+}
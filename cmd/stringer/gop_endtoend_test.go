@@ -0,0 +1,69 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// go command is not available on android
+
+//go:build !android
+// +build !android
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gop/langserver"
+	"golang.org/x/tools/gop/packages"
+	"golang.org/x/tools/internal/testenv"
+)
+
+// TestEndToEndGop runs stringer -gop with a .gop output file on a Go+
+// package, then compiles and runs it. The binary panics if the String
+// method is incorrect.
+func TestEndToEndGop(t *testing.T) {
+	testenv.NeedsGOPROOT(t)
+	stringer := stringerPath(t)
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/test\n\ngo 1.18\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := copy(filepath.Join(dir, "day.gop"), filepath.Join("testdata", "day.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Run stringer, whose output is formatted as Go+ code.
+	stringSource := filepath.Join(dir, "day_string.gop")
+	cmd := exec.Command(stringer, "-gop", "-type", "Day", "-output", stringSource, dir)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("stringer failed: %v\n%s", err, out)
+	}
+	if len(out) > 0 { // e.g. a warning that formatGop failed
+		t.Errorf("stringer output:\n%s", out)
+	}
+	src, err := os.ReadFile(stringSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(src); !strings.Contains(got, "func (i Day) String() string {") {
+		t.Fatalf("no String method in day_string.gop:\n%s", got)
+	}
+
+	// Generate the Go code of both Go+ files, then run the binary.
+	packages.SetGenGoMode(langserver.ModeInProcess)
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedTypes, Dir: dir}
+	pkgs, err := packages.Load(cfg, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("errors in the Go+ package")
+	}
+	if err := runInDir(dir, "go", "run", "."); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/testenv"
+)
+
+// TestGoldenGop runs the golden tests with the constants declared in a
+// Go+ file of a Go+ package.
+func TestGoldenGop(t *testing.T) {
+	testenv.NeedsGOPROOT(t)
+	t.Setenv("GO111MODULE", "on")
+
+	for _, test := range golden {
+		g := Generator{
+			trimPrefix:  test.trimPrefix,
+			lineComment: test.lineComment,
+		}
+		dir := t.TempDir()
+		for name, content := range map[string]string{
+			"go.mod":           "module example.com/test\n\ngo 1.18\n",
+			test.name + ".gop": test.input, // no package clause: package main
+		} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		g.parseGopPackage(dir, nil)
+		if len(g.pkg.files) == 0 || g.pkg.files[len(g.pkg.files)-1].gopFile == nil {
+			t.Fatalf("%s: no Go+ file found", test.name)
+		}
+		// Extract the name and type of the constant from the first line.
+		tokens := strings.SplitN(test.input, " ", 3)
+		if len(tokens) != 3 {
+			t.Fatalf("%s: need type declaration on first line", test.name)
+		}
+		g.generate(tokens[1])
+		got := string(g.format())
+		if got != test.output {
+			t.Errorf("%s: got(%d)\n====\n%q====\nexpected(%d)\n====%q", test.name, len(got), got, len(test.output), test.output)
+		}
+	}
+}
@@ -65,6 +65,11 @@
 //	PillAspirin // Aspirin
 //
 // to suppress it in the output.
+//
+// The -gop flag tells stringer to load the package in the named directory
+// as a Go+ package, so that the types and constants may also be declared in
+// Go+ source files (.gop, .gox, ...). The output file is a Go file, unless
+// it is named explicitly with the -output flag as a .gop file.
 package main // import "golang.org/x/tools/cmd/stringer"
 
 import (
@@ -82,6 +87,7 @@ import (
 	"sort"
 	"strings"
 
+	gopast "github.com/goplus/gop/ast"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -91,6 +97,7 @@ var (
 	trimprefix  = flag.String("trimprefix", "", "trim the `prefix` from the generated constant names")
 	linecomment = flag.Bool("linecomment", false, "use line comment text as printed text when present")
 	buildTags   = flag.String("tags", "", "comma-separated list of build tags to apply")
+	gopFlag     = flag.Bool("gop", false, "load the directory as a Go+ package, whose constants may be declared in Go+ files")
 )
 
 // Usage is a replacement usage function for the flags package.
@@ -142,7 +149,15 @@ func main() {
 		dir = filepath.Dir(args[0])
 	}
 
-	g.parsePackage(args, tags)
+	if *gopFlag {
+		// goxls: load Go+ package
+		if len(args) != 1 || !isDirectory(args[0]) {
+			log.Fatal("-gop option applies only to a directory")
+		}
+		g.parseGopPackage(dir, tags)
+	} else {
+		g.parsePackage(args, tags)
+	}
 
 	// Print the header and package clause.
 	g.Printf("// Code generated by \"stringer %s\"; DO NOT EDIT.\n", strings.Join(os.Args[1:], " "))
@@ -165,6 +180,9 @@ func main() {
 		baseName := fmt.Sprintf("%s_string.go", types[0])
 		outputName = filepath.Join(dir, strings.ToLower(baseName))
 	}
+	if filepath.Ext(outputName) == ".gop" {
+		src = formatGop(src) // goxls: Go+ output
+	}
 	err := os.WriteFile(outputName, src, 0644)
 	if err != nil {
 		log.Fatalf("writing output: %s", err)
@@ -196,8 +214,9 @@ func (g *Generator) Printf(format string, args ...interface{}) {
 
 // File holds a single parsed file and associated data.
 type File struct {
-	pkg  *Package  // Package to which this file belongs.
-	file *ast.File // Parsed AST.
+	pkg     *Package     // Package to which this file belongs.
+	file    *ast.File    // Parsed AST.
+	gopFile *gopast.File // goxls: parsed Go+ AST; or nil
 	// These fields are reset for each type being generated.
 	typeName string  // Name of the constant type.
 	values   []Value // Accumulator for constant values of that type.
@@ -210,6 +229,8 @@ type Package struct {
 	name  string
 	defs  map[*ast.Ident]types.Object
 	files []*File
+
+	gopDefs map[*gopast.Ident]types.Object // goxls: definitions in Go+ files
 }
 
 // parsePackage analyzes the single package constructed from the patterns and tags.
@@ -261,6 +282,11 @@ func (g *Generator) generate(typeName string) {
 			ast.Inspect(file.file, file.genDecl)
 			values = append(values, file.values...)
 		}
+		if file.gopFile != nil {
+			// goxls: constants declared in Go+ files
+			gopast.Inspect(file.gopFile, file.gopGenDecl)
+			values = append(values, file.values...)
+		}
 	}
 
 	if len(values) == 0 {
@@ -437,28 +463,7 @@ func (f *File) genDecl(node ast.Node) bool {
 			if !ok {
 				log.Fatalf("no value for constant %s", name)
 			}
-			info := obj.Type().Underlying().(*types.Basic).Info()
-			if info&types.IsInteger == 0 {
-				log.Fatalf("can't handle non-integer constant type %s", typ)
-			}
-			value := obj.(*types.Const).Val() // Guaranteed to succeed as this is CONST.
-			if value.Kind() != constant.Int {
-				log.Fatalf("can't happen: constant is not an integer %s", name)
-			}
-			i64, isInt := constant.Int64Val(value)
-			u64, isUint := constant.Uint64Val(value)
-			if !isInt && !isUint {
-				log.Fatalf("internal error: value of %s is not an integer: %s", name, value.String())
-			}
-			if !isInt {
-				u64 = uint64(i64)
-			}
-			v := Value{
-				originalName: name.Name,
-				value:        u64,
-				signed:       info&types.IsUnsigned == 0,
-				str:          value.String(),
-			}
+			v := f.newValue(name.Name, obj, typ) // goxls: shared with Go+ files
 			if c := vspec.Comment; f.lineComment && c != nil && len(c.List) == 1 {
 				v.name = strings.TrimSpace(c.Text())
 			} else {
@@ -470,6 +475,33 @@ func (f *File) genDecl(node ast.Node) bool {
 	return false
 }
 
+// newValue returns the Value of the constant obj of type typ, declared
+// by the name name. Its printed name is left to the caller.
+func (f *File) newValue(name string, obj types.Object, typ string) Value {
+	info := obj.Type().Underlying().(*types.Basic).Info()
+	if info&types.IsInteger == 0 {
+		log.Fatalf("can't handle non-integer constant type %s", typ)
+	}
+	value := obj.(*types.Const).Val() // Guaranteed to succeed as this is CONST.
+	if value.Kind() != constant.Int {
+		log.Fatalf("can't happen: constant is not an integer %s", name)
+	}
+	i64, isInt := constant.Int64Val(value)
+	u64, isUint := constant.Uint64Val(value)
+	if !isInt && !isUint {
+		log.Fatalf("internal error: value of %s is not an integer: %s", name, value.String())
+	}
+	if !isInt {
+		u64 = uint64(i64)
+	}
+	return Value{
+		originalName: name,
+		value:        u64,
+		signed:       info&types.IsUnsigned == 0,
+		str:          value.String(),
+	}
+}
+
 // Helpers
 
 // usize returns the number of bits of the smallest unsigned integer
@@ -4,6 +4,7 @@ go 1.18 // tagx:compat 1.16
 
 require (
 	github.com/goplus/gop v1.2.0-pre.1.0.20240226035049-38aec77e9f12
+	github.com/goplus/gox v1.14.13-0.20240223085136-517ed22a822d
 	github.com/goplus/mod v0.13.8
 	github.com/yuin/goldmark v1.4.13
 	golang.org/x/mod v0.15.0
@@ -13,6 +14,5 @@ require (
 )
 
 require (
-	github.com/goplus/gox v1.14.13-0.20240223085136-517ed22a822d // indirect
 	github.com/qiniu/x v1.13.9 // indirect
 )
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"go/token"
 	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"golang.org/x/tools/go/expect"
@@ -42,6 +43,18 @@ func TestMarker(t *testing.T) {
 				"Bool":          {true},
 			},
 		},
+		{
+			filename:    "testdata/test.gop",
+			expectNotes: 4,
+			expectMarkers: map[string]string{
+				"sum":       "sum",
+				"IntParams": "a, b int",
+				"Lambda":    "x => x * 2",
+			},
+			expectChecks: map[string][]interface{}{
+				"Echo": {int64(3), "sum"},
+			},
+		},
 		{
 			filename:    "testdata/go.fake.mod",
 			expectNotes: 2,
@@ -69,7 +82,11 @@ func TestMarker(t *testing.T) {
 			}
 
 			fset := token.NewFileSet()
-			notes, err := expect.Parse(fset, tt.filename, content)
+			parse := expect.Parse
+			if filepath.Ext(tt.filename) == ".gop" { // goxls: Go+ source files
+				parse = expect.ParseGop
+			}
+			notes, err := parse(fset, tt.filename, content)
 			if err != nil {
 				t.Fatalf("Failed to extract notes: %v", err)
 			}
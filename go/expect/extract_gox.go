@@ -0,0 +1,47 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package expect
+
+import (
+	"go/token"
+
+	gopast "github.com/goplus/gop/ast"
+	gopparser "github.com/goplus/gop/parser"
+)
+
+// ParseGop collects all the notes present in the Go+ source file filename.
+// It is the Go+ counterpart of Parse, which handles only Go and go.mod
+// files. If content is nil, the file is read from disk.
+func ParseGop(fset *token.FileSet, filename string, content []byte) ([]*Note, error) {
+	var src interface{}
+	if content != nil {
+		src = content
+	}
+	file, err := gopparser.ParseFile(fset, filename, src, gopparser.ParseComments|gopparser.AllErrors)
+	if file == nil {
+		return nil, err
+	}
+	return ExtractGop(fset, file)
+}
+
+// ExtractGop collects all the notes present in a Go+ AST.
+// It is the Go+ counterpart of ExtractGo.
+func ExtractGop(fset *token.FileSet, file *gopast.File) ([]*Note, error) {
+	var notes []*Note
+	for _, g := range file.Comments {
+		for _, c := range g.List {
+			text, adjust := getAdjustedNote(c.Text)
+			if text == "" {
+				continue
+			}
+			parsed, err := parse(fset, token.Pos(int(c.Pos())+adjust), text)
+			if err != nil {
+				return nil, err
+			}
+			notes = append(notes, parsed...)
+		}
+	}
+	return notes, nil
+}
@@ -172,12 +172,14 @@ func (e *Exported) getNotes() error {
 	}
 	seen := make(map[token.Position]struct{})
 	for _, pkg := range pkgs {
-		for _, filename := range pkg.GoFiles {
+		// goxls: Go+ files; copy GoFiles so as not to append to it
+		filenames := append(append([]string(nil), pkg.GoFiles...), gopFiles(pkg)...)
+		for _, filename := range filenames {
 			content, err := e.FileContents(filename)
 			if err != nil {
 				return err
 			}
-			l, err := expect.Parse(e.ExpectFileSet, filename, content)
+			l, err := parseNotes(e.ExpectFileSet, filename, content) // goxls: Go+ files
 			if err != nil {
 				return fmt.Errorf("failed to extract expectations: %v", err)
 			}
@@ -0,0 +1,47 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packagestest
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/tools/go/expect"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/gop/goputil"
+)
+
+// gopFiles returns the Go+ source files in the directories of the Go files
+// of pkg, which go/packages does not report.
+func gopFiles(pkg *packages.Package) []string {
+	dirs := make(map[string]bool)
+	for _, filename := range pkg.GoFiles {
+		dirs[filepath.Dir(filename)] = true
+	}
+	var files []string
+	for dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() && goputil.FileKind(filepath.Ext(e.Name())) != goputil.FileUnknown {
+				files = append(files, filepath.Join(dir, e.Name()))
+			}
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// parseNotes collects the notes of the Go, go.mod or Go+ file filename.
+func parseNotes(fset *token.FileSet, filename string, content []byte) ([]*expect.Note, error) {
+	if goputil.FileKind(filepath.Ext(filename)) != goputil.FileUnknown {
+		return expect.ParseGop(fset, filename, content)
+	}
+	return expect.Parse(fset, filename, content)
+}
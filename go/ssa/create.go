@@ -16,6 +16,7 @@ import (
 	"sync"
 
 	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/gop/xtypes"
 	"golang.org/x/tools/internal/typeparams"
 )
 
@@ -61,6 +62,7 @@ func memberFromObject(pkg *Package, obj types.Object, syntax ast.Node) {
 		pkg.Members[name] = &Type{
 			object: obj,
 			pkg:    pkg,
+			pos:    obj.Pos(), // goxls: see SetPosMap
 		}
 
 	case *types.Const:
@@ -68,6 +70,7 @@ func memberFromObject(pkg *Package, obj types.Object, syntax ast.Node) {
 			object: obj,
 			Value:  NewConst(obj.Val(), obj.Type()),
 			pkg:    pkg,
+			pos:    obj.Pos(), // goxls: see SetPosMap
 		}
 		pkg.objects[obj] = c
 		pkg.Members[name] = c
@@ -84,6 +87,10 @@ func memberFromObject(pkg *Package, obj types.Object, syntax ast.Node) {
 		pkg.Members[name] = g
 
 	case *types.Func:
+		if _, ok := xtypes.CheckOverload(obj); ok {
+			// goxls: a Go+ overloaded function has no code; its overloads are members
+			return
+		}
 		sig := obj.Type().(*types.Signature)
 		if sig.Recv() == nil && name == "init" {
 			pkg.ninit++
@@ -326,6 +326,8 @@ func (f *Function) finishBody() {
 	f.subst = nil
 
 	numberRegisters(f) // uses f.namedRegisters
+
+	f.mapPos() // goxls: map positions of generated Go+ code
 }
 
 // After this, function is done with BUILD phase.
@@ -0,0 +1,90 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa
+
+// This file defines the mapping of source positions, used for Go code
+// generated from Go+ source (gop_autogen.go).
+
+import (
+	"go/token"
+)
+
+// SetPosMap sets the function that maps the source positions of the
+// syntax of package pkg to the positions reported by its members,
+// functions and instructions, such as positions in Go code generated
+// from Go+ source to the corresponding positions in the Go+ source.
+// The positions of members are those of the identifiers that declare
+// them. m must return positions that need no mapping unchanged.
+//
+// SetPosMap must be called before pkg is built.
+func (pkg *Package) SetPosMap(m func(token.Pos) token.Pos) {
+	pkg.posMap = m
+	for _, mem := range pkg.Members {
+		switch mem := mem.(type) {
+		case *Global:
+			mem.pos = m(mem.pos)
+		case *Type:
+			mem.pos = m(mem.pos)
+		case *NamedConst:
+			mem.pos = m(mem.pos)
+		}
+	}
+}
+
+// mapPos returns the mapped position of pos in package pkg.
+// pkg may be nil, e.g. for synthetic functions.
+func (pkg *Package) mapPos(pos token.Pos) token.Pos {
+	if pkg == nil || pkg.posMap == nil || !pos.IsValid() {
+		return pos
+	}
+	return pkg.posMap(pos)
+}
+
+// mapPos maps the positions of f, its parameters, free variables and
+// instructions by the position mapping of its package, if any.
+func (f *Function) mapPos() {
+	pkg := f.Pkg
+	if pkg == nil || pkg.posMap == nil {
+		return
+	}
+	f.pos = pkg.mapPos(f.pos)
+	for _, p := range f.Params {
+		p.pos = pkg.mapPos(p.pos)
+	}
+	for _, fv := range f.FreeVars {
+		fv.pos = pkg.mapPos(fv.pos)
+	}
+	for _, b := range f.Blocks {
+		for _, instr := range b.Instrs {
+			switch instr := instr.(type) {
+			case *Call:
+				instr.Call.pos = pkg.mapPos(instr.Call.pos)
+			case *Go:
+				instr.pos = pkg.mapPos(instr.pos)
+				instr.Call.pos = pkg.mapPos(instr.Call.pos)
+			case *Defer:
+				instr.pos = pkg.mapPos(instr.pos)
+				instr.Call.pos = pkg.mapPos(instr.Call.pos)
+			case *Select:
+				instr.pos = pkg.mapPos(instr.pos)
+				for _, st := range instr.States {
+					st.Pos = pkg.mapPos(st.Pos)
+				}
+			case *Return:
+				instr.pos = pkg.mapPos(instr.pos)
+			case *Panic:
+				instr.pos = pkg.mapPos(instr.pos)
+			case *Send:
+				instr.pos = pkg.mapPos(instr.pos)
+			case *Store:
+				instr.pos = pkg.mapPos(instr.pos)
+			case *MapUpdate:
+				instr.pos = pkg.mapPos(instr.pos)
+			case interface{ setPos(token.Pos) }: // values, e.g. *Alloc
+				instr.setPos(pkg.mapPos(instr.(Value).Pos()))
+			}
+		}
+	}
+}
@@ -544,7 +544,7 @@ func sanityCheckPackage(pkg *Package) {
 					pkg.Pkg.Path(), mem, obj.Name(), name))
 			}
 		}
-		if obj.Pos() != mem.Pos() {
+		if pkg.mapPos(obj.Pos()) != mem.Pos() { // goxls: positions may be mapped
 			panic(fmt.Sprintf("%s Pos=%d obj.Pos=%d", mem, mem.Pos(), obj.Pos()))
 		}
 	}
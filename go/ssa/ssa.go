@@ -55,6 +55,8 @@ type Package struct {
 	init    *Function               // Func("init"); the package's init function
 	debug   bool                    // include full debug info in this package
 
+	posMap func(token.Pos) token.Pos // goxls: maps source positions, e.g. to Go+ source; or nil
+
 	// The following fields are set transiently, then cleared
 	// after building.
 	buildOnce sync.Once   // ensures package building occurs once
@@ -82,6 +84,7 @@ type Member interface {
 type Type struct {
 	object *types.TypeName
 	pkg    *Package
+	pos    token.Pos // goxls: position, possibly mapped (see SetPosMap)
 }
 
 // A NamedConst is a Member of a Package representing a package-level
@@ -96,6 +99,7 @@ type NamedConst struct {
 	object *types.Const
 	Value  *Const
 	pkg    *Package
+	pos    token.Pos // goxls: position, possibly mapped (see SetPosMap)
 }
 
 // A Value is an SSA value that can be referenced by an instruction.
@@ -1583,7 +1587,7 @@ func (v *anInstruction) setBlock(block *BasicBlock) { v.block = block }
 func (v *anInstruction) Referrers() *[]Instruction  { return nil }
 
 func (t *Type) Name() string                         { return t.object.Name() }
-func (t *Type) Pos() token.Pos                       { return t.object.Pos() }
+func (t *Type) Pos() token.Pos                       { return t.pos } // goxls: was t.object.Pos()
 func (t *Type) Type() types.Type                     { return t.object.Type() }
 func (t *Type) Token() token.Token                   { return token.TYPE }
 func (t *Type) Object() types.Object                 { return t.object }
@@ -1592,7 +1596,7 @@ func (t *Type) Package() *Package                    { return t.pkg }
 func (t *Type) RelString(from *types.Package) string { return relString(t, from) }
 
 func (c *NamedConst) Name() string                         { return c.object.Name() }
-func (c *NamedConst) Pos() token.Pos                       { return c.object.Pos() }
+func (c *NamedConst) Pos() token.Pos                       { return c.pos } // goxls: was c.object.Pos()
 func (c *NamedConst) String() string                       { return c.RelString(nil) }
 func (c *NamedConst) Type() types.Type                     { return c.object.Type() }
 func (c *NamedConst) Token() token.Token                   { return token.CONST }
@@ -1641,7 +1645,7 @@ func (s *Store) Pos() token.Pos     { return s.pos }
 func (s *If) Pos() token.Pos        { return token.NoPos }
 func (s *Jump) Pos() token.Pos      { return token.NoPos }
 func (s *RunDefers) Pos() token.Pos { return token.NoPos }
-func (s *DebugRef) Pos() token.Pos  { return s.Expr.Pos() }
+func (s *DebugRef) Pos() token.Pos  { return s.Parent().Pkg.mapPos(s.Expr.Pos()) }
 
 // Operands.
 
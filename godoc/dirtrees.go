@@ -17,6 +17,8 @@ import (
 	"sort"
 	"strings"
 
+	gopparser "github.com/goplus/gop/parser"
+
 	"golang.org/x/tools/godoc/vfs"
 )
 
@@ -84,6 +86,21 @@ func (b *treeBuilder) newDirTree(fset *token.FileSet, path, name string, depth i
 	}
 
 	var synopses [3]string // prioritized package documentation (0 == highest priority)
+	addSynopsis := func(pkgName, text string) {
+		// prioritize documentation
+		i := -1
+		switch pkgName {
+		case name:
+			i = 0 // normal case: directory name matches package name
+		case "main":
+			i = 1 // directory contains a main package
+		default:
+			i = 2 // none of the above
+		}
+		if 0 <= i && i < len(synopses) && synopses[i] == "" {
+			synopses[i] = doc.Synopsis(text)
+		}
+	}
 
 	show := true // show in package listing
 	hasPkgFiles := false
@@ -151,19 +168,25 @@ func (b *treeBuilder) newDirTree(fset *token.FileSet, path, name string, depth i
 
 			hasPkgFiles = true
 			if file.Doc != nil {
-				// prioritize documentation
-				i := -1
-				switch file.Name.Name {
-				case name:
-					i = 0 // normal case: directory name matches package name
-				case "main":
-					i = 1 // directory contains a main package
-				default:
-					i = 2 // none of the above
-				}
-				if 0 <= i && i < len(synopses) && synopses[i] == "" {
-					synopses[i] = doc.Synopsis(file.Doc.Text())
+				addSynopsis(file.Name.Name, file.Doc.Text())
+			}
+			haveSummary = synopses[0] != ""
+		case !haveSummary && isGopPkgFile(d):
+			// goxls: a Go+ package file
+			ioGate <- struct{}{}
+			const flags = gopparser.ParseComments | gopparser.PackageClauseOnly
+			file, err := b.c.parseGopFile(fset, filename, flags)
+			<-ioGate
+			if err != nil {
+				if err != gopparser.ErrUnknownFileKind && b.c.Verbose {
+					log.Printf("Error parsing %v: %v", filename, err)
 				}
+				break
+			}
+
+			hasPkgFiles = true
+			if file.Doc != nil && !file.NoPkgDecl {
+				addSynopsis(file.Name.Name, file.Doc.Text())
 			}
 			haveSummary = synopses[0] != ""
 		}
@@ -201,7 +201,8 @@ func (p *Presentation) node_htmlFunc(info *PageInfo, node interface{}, linkify b
 	p.writeNode(&buf1, info, info.FSet, node)
 
 	var buf2 bytes.Buffer
-	if n, _ := node.(ast.Node); n != nil && linkify && p.DeclLinks {
+	_, gop := gopNodeOf(info, node) // goxls: Go+ syntax is not linkified
+	if n, _ := node.(ast.Node); n != nil && !gop && linkify && p.DeclLinks {
 		LinkifyText(&buf2, buf1.Bytes(), n)
 		if st, name := isStructTypeDecl(n); st != nil {
 			addStructFieldIDAttributes(&buf2, name, st)
@@ -416,6 +417,7 @@ type PageInfo struct {
 	PAst       map[string]*ast.File   // nil if no AST with package exports
 	IsMain     bool                   // true for package main
 	IsFiltered bool                   // true if results were filtered
+	gop        *gopDocs               // goxls: Go+ declarations; or nil
 
 	// analysis info
 	TypeInfoIndex  map[string]int  // index of JSON datum for type T (if -analysis=type)
@@ -856,6 +858,12 @@ func (p *Presentation) writeNode(w io.Writer, pageInfo *PageInfo, fset *token.Fi
 	//           with an another printer mode (which is more efficiently
 	//           implemented in the printer than here with another layer)
 
+	// goxls: print the nodes standing for Go+ declarations in Go+ syntax
+	if n, ok := gopNodeOf(pageInfo, x); ok {
+		p.writeGopNode(w, fset, n)
+		return
+	}
+
 	var pkgName, structName string
 	var apiInfo pkgAPIVersions
 	if gd, ok := x.(*ast.GenDecl); ok && pageInfo != nil && pageInfo.PDoc != nil &&
@@ -0,0 +1,882 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains support for documenting Go+ packages.
+//
+// The Go+ source files of a package (.gop files and classfiles) are parsed
+// from godoc's file system, so no Go+ tool chain or network access is
+// needed, and their declarations are converted into Go declarations that
+// go/doc documents along with the declarations of the Go files. The Go
+// files generated from Go+ files (gop_autogen*.go) are ignored.
+//
+// A classfile is documented as a type (the class) whose fields are the
+// variables of its first var declaration and whose methods are its
+// functions. The declarations Go has no syntax for, overloads and operator
+// methods, are printed in Go+ syntax.
+//
+// The functions and methods named Name__N, as overloads are declared in
+// Go, are documented as a single overload, and the methods named Gop_Op as
+// the operators they implement, whether they are declared in Go files or
+// Go+ files of a Go+ package or of a Go package declaring GopPackage.
+
+package godoc
+
+import (
+	"bytes"
+	"go/ast"
+	"go/doc"
+	"go/printer"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	pathpkg "path"
+	"regexp"
+	"sort"
+	"strings"
+
+	gopast "github.com/goplus/gop/ast"
+	gopparser "github.com/goplus/gop/parser"
+	gopprinter "github.com/goplus/gop/printer"
+	goptoken "github.com/goplus/gop/token"
+	"github.com/goplus/mod/modfile"
+
+	"golang.org/x/tools/godoc/vfs"
+	"golang.org/x/tools/gop/goputil"
+)
+
+// isGopFile reports whether fi is a Go+ source file.
+func isGopFile(fi os.FileInfo) bool {
+	name := fi.Name()
+	return !fi.IsDir() &&
+		len(name) > 0 && name[0] != '.' && name[0] != '_' && // ignore .files and _files
+		goputil.FileKind(pathpkg.Ext(name)) != goputil.FileUnknown
+}
+
+// isGopTestFile reports whether the Go+ source file name is a test file:
+// a _test.gop file, or a test classfile such as Case_test.gox.
+func isGopTestFile(name string) bool {
+	return strings.HasSuffix(name, "_test.gop") || strings.HasSuffix(name, "_test.gox")
+}
+
+func isGopPkgFile(fi os.FileInfo) bool {
+	return isGopFile(fi) && !isGopTestFile(fi.Name())
+}
+
+// withoutGopAutogen returns the Go file names that are not generated from
+// Go+ files (gop_autogen*.go).
+func withoutGopAutogen(names []string) []string {
+	var list []string
+	for _, name := range names {
+		if !strings.HasPrefix(name, "gop_autogen") {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+// gopFiles returns the names of the Go+ package files and Go+ test files
+// in directory abspath.
+func (c *Corpus) gopFiles(abspath string) (pkgfiles, testfiles []string) {
+	list, err := c.fs.ReadDir(abspath)
+	if err != nil {
+		return nil, nil
+	}
+	for _, fi := range list {
+		switch {
+		case isGopPkgFile(fi):
+			pkgfiles = append(pkgfiles, fi.Name())
+		case isGopFile(fi) && strings.HasSuffix(fi.Name(), "_test.gop"):
+			testfiles = append(testfiles, fi.Name())
+		}
+	}
+	return
+}
+
+func (c *Corpus) parseGopFile(fset *token.FileSet, filename string, mode gopparser.Mode) (*gopast.File, error) {
+	src, err := vfs.ReadFile(c.fs, filename)
+	if err != nil {
+		return nil, err
+	}
+	replaceLinePrefixCommentsWithBlankLine(src)
+	return gopparser.ParseEntry(fset, filename, src, gopparser.Config{Mode: mode})
+}
+
+func (c *Corpus) parseGopFiles(fset *token.FileSet, relpath string, abspath string, localnames []string) (map[string]*gopast.File, error) {
+	files := make(map[string]*gopast.File)
+	for _, f := range localnames {
+		absname := pathpkg.Join(abspath, f)
+		file, err := c.parseGopFile(fset, absname, gopparser.ParseComments)
+		if err == gopparser.ErrUnknownFileKind {
+			continue // a classfile of a framework we know nothing about
+		}
+		if err != nil {
+			return nil, err
+		}
+		files[pathpkg.Join(relpath, f)] = file
+	}
+	return files, nil
+}
+
+// gopDocs holds the Go+ declarations of a package, and the Go
+// declarations they are converted into for documentation.
+type gopDocs struct {
+	fset  *token.FileSet
+	files map[*ast.File]*gopast.File // converted files
+	nodes map[ast.Node]gopast.Node   // converted nodes printed in Go+ syntax
+	names map[*ast.FuncDecl]string   // Go+ names of operator methods
+}
+
+func newGopDocs(fset *token.FileSet) *gopDocs {
+	return &gopDocs{
+		fset:  fset,
+		files: make(map[*ast.File]*gopast.File),
+		nodes: make(map[ast.Node]gopast.Node),
+		names: make(map[*ast.FuncDecl]string),
+	}
+}
+
+// addFiles converts the Go+ files and adds them to the Go files, and
+// returns the name of their package. The files without package clause
+// belong to the package of the others, if any, or to package main.
+func (d *gopDocs) addFiles(files map[string]*ast.File, gopfiles map[string]*gopast.File) (pkgname string) {
+	var nopkg []*ast.File
+	for name, f := range gopfiles {
+		file := d.file(name, f)
+		d.files[file] = f
+		files[name] = file
+		if f.NoPkgDecl {
+			nopkg = append(nopkg, file)
+		} else if pkgname == "" {
+			pkgname = f.Name.Name
+		}
+	}
+	if pkgname == "" {
+		for _, f := range files {
+			if _, ok := d.files[f]; !ok {
+				pkgname = f.Name.Name // the package of the Go files
+				break
+			}
+		}
+	}
+	if pkgname == "" {
+		pkgname = "main"
+	}
+	for _, file := range nopkg {
+		file.Name.Name = pkgname
+	}
+	return pkgname
+}
+
+// gopNode returns the Go+ node x is printed as, if any.
+func (d *gopDocs) gopNode(x interface{}) (gopast.Node, bool) {
+	if d == nil {
+		return nil, false
+	}
+	switch x := x.(type) {
+	case *ast.File:
+		if f, ok := d.files[x]; ok {
+			return f, true
+		}
+	case ast.Node:
+		if n, ok := d.nodes[x]; ok {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// fileExports trims the Go+ files so that only exported nodes remain.
+func (d *gopDocs) fileExports() {
+	if d == nil {
+		return
+	}
+	for _, f := range d.files {
+		decls := append([]gopast.Decl(nil), f.Decls...)
+		gopast.FileExports(f)
+		// Keep exported overloads and operators, which FileExports drops.
+		kept := make(map[gopast.Decl]bool)
+		for _, decl := range f.Decls {
+			kept[decl] = true
+		}
+		f.Decls = f.Decls[:0]
+		for _, decl := range decls {
+			switch decl := decl.(type) {
+			case *gopast.OverloadFuncDecl:
+				kept[decl] = decl.Name.IsExported()
+			case *gopast.FuncDecl:
+				kept[decl] = kept[decl] || decl.Operator
+			}
+			if kept[decl] {
+				f.Decls = append(f.Decls, decl)
+			}
+		}
+	}
+}
+
+// isGopPackage reports whether the Go files declare the GopPackage
+// constant, which makes Go+ see the overloads and operators they declare.
+func isGopPackage(files map[string]*ast.File) bool {
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.CONST {
+				for _, spec := range gen.Specs {
+					for _, name := range spec.(*ast.ValueSpec).Names {
+						if name.Name == "GopPackage" {
+							return true
+						}
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// rename gives the documented operator methods their Go+ names, and
+// groups the overloads named Name__N into one function.
+func (d *gopDocs) rename(pdoc *doc.Package) {
+	if d == nil {
+		return
+	}
+	rename := func(funcs []*doc.Func) []*doc.Func {
+		for _, f := range funcs {
+			if name, ok := d.names[f.Decl]; ok {
+				f.Name = name
+			} else if name, ok := d.operator(f.Decl); ok {
+				f.Name = name
+			}
+		}
+		return d.overloads(funcs)
+	}
+	pdoc.Funcs = rename(pdoc.Funcs)
+	for _, t := range pdoc.Types {
+		t.Funcs = rename(t.Funcs)
+		t.Methods = rename(t.Methods)
+	}
+}
+
+// operator returns the Go+ operator the method decl named Gop_Op
+// implements, if any, and records the Go+ declaration it is printed as.
+func (d *gopDocs) operator(decl *ast.FuncDecl) (string, bool) {
+	if decl.Recv == nil || decl.Recv.NumFields() != 1 || !strings.HasPrefix(decl.Name.Name, "Gop_") {
+		return "", false
+	}
+	fn := &gopast.FuncDecl{
+		Type:     d.gopFuncType(decl.Type),
+		Operator: true,
+	}
+	var op string
+	switch decl.Type.Params.NumFields() {
+	case 0: // a unary operator is declared as a function of its operand
+		op = unaryGopOps[decl.Name.Name]
+		fn.Type.Params = d.gopFieldList(decl.Recv)
+	case 1:
+		op = binaryGopOps[decl.Name.Name]
+		fn.Recv = d.gopFieldList(decl.Recv)
+	}
+	if op == "" {
+		return "", false
+	}
+	fn.Name = &gopast.Ident{NamePos: decl.Name.NamePos, Name: op}
+	d.nodes[decl] = fn
+	return op, true
+}
+
+// overloads groups the functions named Name__N of funcs into functions
+// named Name, which are printed as Go+ overload declarations.
+func (d *gopDocs) overloads(funcs []*doc.Func) []*doc.Func {
+	var list []*doc.Func
+	groups := make(map[string]*doc.Func)
+	for _, f := range funcs {
+		name, ok := overloadName(f.Name)
+		if !ok {
+			list = append(list, f)
+			continue
+		}
+		g := groups[name]
+		if g == nil {
+			g = &doc.Func{
+				Name: name,
+				Decl: &ast.FuncDecl{
+					Recv: f.Decl.Recv,
+					Name: &ast.Ident{NamePos: f.Decl.Name.NamePos, Name: name},
+					Type: &ast.FuncType{Func: f.Decl.Type.Func, Params: &ast.FieldList{}},
+				},
+				Recv:  f.Recv,
+				Orig:  f.Orig,
+				Level: f.Level,
+			}
+			overload := &gopast.OverloadFuncDecl{
+				Func: f.Decl.Type.Func,
+				Name: &gopast.Ident{NamePos: f.Decl.Name.NamePos, Name: name},
+			}
+			if recv := f.Decl.Recv; recv != nil && len(recv.List) == 1 {
+				overload.Recv = &gopast.FieldList{List: []*gopast.Field{{Type: d.gopExpr(recv.List[0].Type)}}}
+			}
+			d.nodes[g.Decl] = overload
+			groups[name] = g
+			list = append(list, g)
+		}
+		if g.Doc == "" {
+			g.Doc = f.Doc // the doc of the first documented overload
+		}
+		overload := d.nodes[g.Decl].(*gopast.OverloadFuncDecl)
+		overload.Funcs = append(overload.Funcs, &gopast.FuncLit{Type: d.gopFuncType(f.Decl.Type)})
+	}
+	return list
+}
+
+// overloadName returns the name of the overload the function named name
+// is, if it is named Name__N, with N a digit or a lowercase letter.
+func overloadName(name string) (string, bool) {
+	n := len(name)
+	if n < 4 || name[n-3:n-1] != "__" {
+		return "", false
+	}
+	if c := name[n-1]; '0' <= c && c <= '9' || 'a' <= c && c <= 'z' {
+		return name[:n-3], true
+	}
+	return "", false
+}
+
+// file converts the Go+ file f named filename into a Go file declaring
+// the same API.
+func (d *gopDocs) file(filename string, f *gopast.File) *ast.File {
+	file := &ast.File{
+		Doc:     d.commentGroup(f.Doc),
+		Package: f.Package,
+		Name:    d.ident(f.Name),
+		Scope:   ast.NewScope(nil), // required by ast.NewPackage
+	}
+	for _, cg := range f.Comments {
+		file.Comments = append(file.Comments, d.commentGroup(cg))
+	}
+	if f.NoPkgDecl {
+		file.Doc = nil // the leading comment of a file without package clause
+	}
+
+	var recv *ast.FieldList // receiver of the methods of a class
+	var fields *gopast.GenDecl
+	if f.IsClass {
+		fields = classFields(f)
+		class := d.classDecl(filename, f, fields)
+		file.Decls = append(file.Decls, class)
+		recv = &ast.FieldList{List: []*ast.Field{{
+			Names: []*ast.Ident{{Name: "this"}},
+			Type:  &ast.StarExpr{X: ast.NewIdent(class.Specs[0].(*ast.TypeSpec).Name.Name)},
+		}}}
+	}
+
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *gopast.GenDecl:
+			if decl == fields {
+				continue
+			}
+			gen := d.genDecl(decl)
+			if gen.Tok == token.IMPORT {
+				for _, spec := range gen.Specs {
+					file.Imports = append(file.Imports, spec.(*ast.ImportSpec))
+				}
+			}
+			file.Decls = append(file.Decls, gen)
+		case *gopast.FuncDecl:
+			if decl.Shadow {
+				continue // the statements of the file
+			}
+			if fn := d.funcDecl(decl, recv); fn != nil {
+				file.Decls = append(file.Decls, fn)
+			}
+		case *gopast.OverloadFuncDecl:
+			file.Decls = append(file.Decls, d.overloadFuncDecl(decl, recv))
+		}
+	}
+	return file
+}
+
+// classFields returns the declaration of the fields of the class of the
+// classfile f, if any: its first var declaration, if no function
+// declaration precedes it.
+func classFields(f *gopast.File) *gopast.GenDecl {
+	for _, decl := range f.Decls {
+		g, ok := decl.(*gopast.GenDecl)
+		if !ok {
+			break
+		}
+		if g.Tok == goptoken.VAR {
+			return g
+		}
+	}
+	return nil
+}
+
+// className returns the name of the class of the classfile filename.
+func className(filename string) string {
+	name, _ := modfile.SplitFname(pathpkg.Base(filename))
+	if i := strings.Index(name, "."); i > 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// classDecl returns the declaration of the class of the classfile f,
+// whose fields are declared by fields (if not nil).
+func (d *gopDocs) classDecl(filename string, f *gopast.File, fields *gopast.GenDecl) *ast.GenDecl {
+	name := className(filename)
+	if name == "main" && f.IsNormalGox {
+		name = "_main"
+	}
+	pos := f.Pos()
+	st := &ast.StructType{Struct: pos, Fields: &ast.FieldList{Opening: pos, Closing: pos}}
+	decl := &ast.GenDecl{TokPos: pos, Tok: token.TYPE}
+	if fields != nil {
+		decl.TokPos, decl.Doc = fields.TokPos, d.commentGroup(fields.Doc)
+		st.Struct = fields.TokPos
+		st.Fields.Opening, st.Fields.Closing = fields.Lparen, fields.Rparen
+		if !fields.Lparen.IsValid() {
+			st.Fields.Opening, st.Fields.Closing = fields.TokPos, fields.End()
+		}
+		for _, spec := range fields.Specs {
+			v := spec.(*gopast.ValueSpec)
+			st.Fields.List = append(st.Fields.List, &ast.Field{
+				Doc:     d.commentGroup(v.Doc),
+				Names:   d.idents(v.Names),
+				Type:    d.expr(v.Type),
+				Tag:     d.basicLit(v.Tag),
+				Comment: d.commentGroup(v.Comment),
+			})
+		}
+	}
+	if decl.Doc == nil && f.NoPkgDecl {
+		decl.Doc = d.commentGroup(f.Doc)
+	}
+	decl.Specs = []ast.Spec{&ast.TypeSpec{
+		Name: &ast.Ident{NamePos: decl.TokPos, Name: name},
+		Type: st,
+	}}
+	return decl
+}
+
+func (d *gopDocs) genDecl(decl *gopast.GenDecl) *ast.GenDecl {
+	gen := &ast.GenDecl{
+		Doc:    d.commentGroup(decl.Doc),
+		TokPos: decl.TokPos,
+		Tok:    token.Token(decl.Tok),
+		Lparen: decl.Lparen,
+		Rparen: decl.Rparen,
+	}
+	for _, spec := range decl.Specs {
+		switch spec := spec.(type) {
+		case *gopast.ImportSpec:
+			gen.Specs = append(gen.Specs, &ast.ImportSpec{
+				Doc:     d.commentGroup(spec.Doc),
+				Name:    d.ident(spec.Name),
+				Path:    d.basicLit(spec.Path),
+				Comment: d.commentGroup(spec.Comment),
+				EndPos:  spec.EndPos,
+			})
+		case *gopast.ValueSpec:
+			gen.Specs = append(gen.Specs, &ast.ValueSpec{
+				Doc:     d.commentGroup(spec.Doc),
+				Names:   d.idents(spec.Names),
+				Type:    d.expr(spec.Type),
+				Values:  d.exprs(spec.Values),
+				Comment: d.commentGroup(spec.Comment),
+			})
+		case *gopast.TypeSpec:
+			gen.Specs = append(gen.Specs, &ast.TypeSpec{
+				Doc:        d.commentGroup(spec.Doc),
+				Name:       d.ident(spec.Name),
+				TypeParams: d.fieldList(spec.TypeParams),
+				Assign:     spec.Assign,
+				Type:       d.expr(spec.Type),
+				Comment:    d.commentGroup(spec.Comment),
+			})
+		}
+	}
+	return gen
+}
+
+// funcDecl converts the function declaration decl. recv is the receiver
+// of the functions of a classfile, or nil.
+func (d *gopDocs) funcDecl(decl *gopast.FuncDecl, recv *ast.FieldList) *ast.FuncDecl {
+	fn := &ast.FuncDecl{
+		Doc:  d.commentGroup(decl.Doc),
+		Recv: d.fieldList(decl.Recv),
+		Name: d.ident(decl.Name),
+		Type: d.funcType(decl.Type),
+	}
+	if fn.Recv == nil {
+		fn.Recv = recv
+	}
+	if !decl.Operator {
+		return fn
+	}
+
+	// An operator is a method named after it; a unary operator is a
+	// method of the type of its operand.
+	name := decl.Name.Name
+	var ok bool
+	if fn.Recv != nil {
+		fn.Name.Name, ok = binaryGopNames[name]
+	} else if params := fn.Type.Params; params != nil && len(params.List) == 1 {
+		fn.Name.Name, ok = unaryGopNames[name]
+		fn.Recv, fn.Type.Params = params, &ast.FieldList{Opening: params.Opening, Closing: params.Closing}
+	}
+	if !ok {
+		return nil
+	}
+	op := *decl
+	op.Doc, op.Body = nil, nil
+	d.nodes[fn] = &op
+	d.names[fn] = name
+	return fn
+}
+
+// overloadFuncDecl converts the overload declaration decl into a
+// declaration of a function without parameters, which is printed as decl.
+func (d *gopDocs) overloadFuncDecl(decl *gopast.OverloadFuncDecl, recv *ast.FieldList) *ast.FuncDecl {
+	fn := &ast.FuncDecl{
+		Doc:  d.commentGroup(decl.Doc),
+		Recv: d.fieldList(decl.Recv),
+		Name: d.ident(decl.Name),
+		Type: &ast.FuncType{Func: decl.Func, Params: &ast.FieldList{}},
+	}
+	if fn.Recv == nil {
+		fn.Recv = recv
+	}
+	// Print the overloads without their bodies.
+	overload := *decl
+	overload.Doc = nil
+	overload.Funcs = make([]gopast.Expr, len(decl.Funcs))
+	for i, f := range decl.Funcs {
+		if lit, ok := f.(*gopast.FuncLit); ok {
+			f = &gopast.FuncLit{Type: lit.Type}
+		}
+		overload.Funcs[i] = f
+	}
+	d.nodes[fn] = &overload
+	return fn
+}
+
+// gopFuncType converts the Go function type t into a Go+ one.
+func (d *gopDocs) gopFuncType(t *ast.FuncType) *gopast.FuncType {
+	return &gopast.FuncType{
+		Func:       t.Func,
+		TypeParams: d.gopFieldList(t.TypeParams),
+		Params:     d.gopFieldList(t.Params),
+		Results:    d.gopFieldList(t.Results),
+	}
+}
+
+func (d *gopDocs) gopFieldList(list *ast.FieldList) *gopast.FieldList {
+	if list == nil {
+		return nil
+	}
+	fields := &gopast.FieldList{Opening: list.Opening, Closing: list.Closing}
+	for _, f := range list.List {
+		field := &gopast.Field{Type: d.gopExpr(f.Type)}
+		for _, name := range f.Names {
+			field.Names = append(field.Names, &gopast.Ident{NamePos: name.NamePos, Name: name.Name})
+		}
+		fields.List = append(fields.List, field)
+	}
+	return fields
+}
+
+// gopExpr converts the Go type x into an identifier of its Go source, as
+// Go types are printed the same in Go+.
+func (d *gopDocs) gopExpr(x ast.Expr) gopast.Expr {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, d.fset, x); err != nil {
+		log.Print(err)
+	}
+	return &gopast.Ident{NamePos: x.Pos(), Name: buf.String()}
+}
+
+func (d *gopDocs) funcType(t *gopast.FuncType) *ast.FuncType {
+	if t == nil {
+		return nil
+	}
+	return &ast.FuncType{
+		Func:       t.Func,
+		TypeParams: d.fieldList(t.TypeParams),
+		Params:     d.fieldList(t.Params),
+		Results:    d.fieldList(t.Results),
+	}
+}
+
+func (d *gopDocs) fieldList(list *gopast.FieldList) *ast.FieldList {
+	if list == nil {
+		return nil
+	}
+	fields := &ast.FieldList{Opening: list.Opening, Closing: list.Closing}
+	for _, f := range list.List {
+		fields.List = append(fields.List, &ast.Field{
+			Doc:     d.commentGroup(f.Doc),
+			Names:   d.idents(f.Names),
+			Type:    d.expr(f.Type),
+			Tag:     d.basicLit(f.Tag),
+			Comment: d.commentGroup(f.Comment),
+		})
+	}
+	return fields
+}
+
+func (d *gopDocs) exprs(list []gopast.Expr) []ast.Expr {
+	var exprs []ast.Expr
+	for _, x := range list {
+		exprs = append(exprs, d.expr(x))
+	}
+	return exprs
+}
+
+// expr converts the expression x. Go+ expressions Go has no syntax for
+// are converted into literals of their Go+ source.
+func (d *gopDocs) expr(x gopast.Expr) ast.Expr {
+	switch x := x.(type) {
+	case nil:
+		return nil
+	case *gopast.Ident:
+		return d.ident(x)
+	case *gopast.BasicLit:
+		if lit := d.basicLit(x); lit != nil {
+			return lit
+		}
+	case *gopast.Ellipsis:
+		return &ast.Ellipsis{Ellipsis: x.Ellipsis, Elt: d.expr(x.Elt)}
+	case *gopast.ParenExpr:
+		return &ast.ParenExpr{Lparen: x.Lparen, X: d.expr(x.X), Rparen: x.Rparen}
+	case *gopast.SelectorExpr:
+		return &ast.SelectorExpr{X: d.expr(x.X), Sel: d.ident(x.Sel)}
+	case *gopast.IndexExpr:
+		return &ast.IndexExpr{X: d.expr(x.X), Lbrack: x.Lbrack, Index: d.expr(x.Index), Rbrack: x.Rbrack}
+	case *gopast.IndexListExpr:
+		return &ast.IndexListExpr{X: d.expr(x.X), Lbrack: x.Lbrack, Indices: d.exprs(x.Indices), Rbrack: x.Rbrack}
+	case *gopast.CallExpr:
+		if !x.NoParenEnd.IsValid() {
+			return &ast.CallExpr{Fun: d.expr(x.Fun), Lparen: x.Lparen, Args: d.exprs(x.Args), Ellipsis: x.Ellipsis, Rparen: x.Rparen}
+		}
+	case *gopast.StarExpr:
+		return &ast.StarExpr{Star: x.Star, X: d.expr(x.X)}
+	case *gopast.UnaryExpr:
+		if tok, ok := goOperator(x.Op); ok {
+			return &ast.UnaryExpr{OpPos: x.OpPos, Op: tok, X: d.expr(x.X)}
+		}
+	case *gopast.BinaryExpr:
+		if tok, ok := goOperator(x.Op); ok {
+			return &ast.BinaryExpr{X: d.expr(x.X), OpPos: x.OpPos, Op: tok, Y: d.expr(x.Y)}
+		}
+	case *gopast.ArrayType:
+		return &ast.ArrayType{Lbrack: x.Lbrack, Len: d.expr(x.Len), Elt: d.expr(x.Elt)}
+	case *gopast.StructType:
+		return &ast.StructType{Struct: x.Struct, Fields: d.fieldList(x.Fields), Incomplete: x.Incomplete}
+	case *gopast.FuncType:
+		return d.funcType(x)
+	case *gopast.InterfaceType:
+		return &ast.InterfaceType{Interface: x.Interface, Methods: d.fieldList(x.Methods), Incomplete: x.Incomplete}
+	case *gopast.MapType:
+		return &ast.MapType{Map: x.Map, Key: d.expr(x.Key), Value: d.expr(x.Value)}
+	case *gopast.ChanType:
+		return &ast.ChanType{Begin: x.Begin, Arrow: x.Arrow, Dir: ast.ChanDir(x.Dir), Value: d.expr(x.Value)}
+	}
+	var buf bytes.Buffer
+	if err := gopprinter.Fprint(&buf, d.fset, x); err != nil {
+		log.Print(err)
+	}
+	return &ast.BasicLit{ValuePos: x.Pos(), Kind: token.STRING, Value: buf.String()}
+}
+
+// goOperator returns the Go operator op, if Go has it.
+func goOperator(op goptoken.Token) (token.Token, bool) {
+	tok := token.Token(op)
+	return tok, tok.IsOperator() && tok.String() == op.String()
+}
+
+// basicLit converts the literal x, if Go has its kind.
+func (d *gopDocs) basicLit(x *gopast.BasicLit) *ast.BasicLit {
+	if x == nil {
+		return nil
+	}
+	var kind token.Token
+	switch x.Kind {
+	case goptoken.INT:
+		kind = token.INT
+	case goptoken.FLOAT:
+		kind = token.FLOAT
+	case goptoken.IMAG:
+		kind = token.IMAG
+	case goptoken.CHAR:
+		kind = token.CHAR
+	case goptoken.STRING:
+		if x.Extra != nil { // string with embedded expressions
+			return nil
+		}
+		kind = token.STRING
+	default:
+		return nil
+	}
+	return &ast.BasicLit{ValuePos: x.ValuePos, Kind: kind, Value: x.Value}
+}
+
+func (d *gopDocs) ident(x *gopast.Ident) *ast.Ident {
+	if x == nil {
+		return nil
+	}
+	return &ast.Ident{NamePos: x.NamePos, Name: x.Name}
+}
+
+func (d *gopDocs) idents(list []*gopast.Ident) []*ast.Ident {
+	var idents []*ast.Ident
+	for _, x := range list {
+		idents = append(idents, d.ident(x))
+	}
+	return idents
+}
+
+func (d *gopDocs) commentGroup(cg *gopast.CommentGroup) *ast.CommentGroup {
+	if cg == nil {
+		return nil
+	}
+	g := new(ast.CommentGroup)
+	for _, c := range cg.List {
+		g.List = append(g.List, &ast.Comment{Slash: c.Slash, Text: c.Text})
+	}
+	return g
+}
+
+// binaryGopNames and unaryGopNames map Go+ operators to the names of the
+// methods implementing them.
+var (
+	binaryGopNames = map[string]string{
+		"+": "Gop_Add", "-": "Gop_Sub", "*": "Gop_Mul", "/": "Gop_Quo", "%": "Gop_Rem",
+		"&": "Gop_And", "|": "Gop_Or", "^": "Gop_Xor", "<<": "Gop_Lsh", ">>": "Gop_Rsh", "&^": "Gop_AndNot",
+		"+=": "Gop_AddAssign", "-=": "Gop_SubAssign", "*=": "Gop_MulAssign", "/=": "Gop_QuoAssign", "%=": "Gop_RemAssign",
+		"&=": "Gop_AndAssign", "|=": "Gop_OrAssign", "^=": "Gop_XorAssign", "<<=": "Gop_LshAssign", ">>=": "Gop_RshAssign", "&^=": "Gop_AndNotAssign",
+		"==": "Gop_EQ", "!=": "Gop_NE", "<=": "Gop_LE", "<": "Gop_LT", ">=": "Gop_GE", ">": "Gop_GT",
+		"->": "Gop_PointTo", "<>": "Gop_PointBi",
+		"&&": "Gop_LAnd", "||": "Gop_LOr",
+		"<-": "Gop_Send",
+	}
+	unaryGopNames = map[string]string{
+		"++": "Gop_Inc", "--": "Gop_Dec", "-": "Gop_Neg", "+": "Gop_Dup", "^": "Gop_Not", "!": "Gop_LNot", "<-": "Gop_Recv",
+	}
+
+	// The operators implemented by the methods named Gop_Op.
+	binaryGopOps = invert(binaryGopNames)
+	unaryGopOps  = invert(unaryGopNames)
+)
+
+func invert(m map[string]string) map[string]string {
+	inv := make(map[string]string, len(m))
+	for k, v := range m {
+		inv[v] = k
+	}
+	return inv
+}
+
+// gopExampleCode is the code of an example in a Go+ test file.
+type gopExampleCode struct {
+	*gopast.BlockStmt
+	comments []*gopast.CommentGroup
+}
+
+var gopOutputPrefix = regexp.MustCompile(`(?i)^[[:space:]]*(unordered )?output:`)
+
+// collectGopExamples collects examples for pkg from Go+ testfiles.
+func collectGopExamples(c *Corpus, pkg *ast.Package, testfiles map[string]*gopast.File) []*doc.Example {
+	var examples []*doc.Example
+	globals := globalNames(pkg)
+	for _, e := range gopExamples(testfiles) {
+		name := stripExampleSuffix(e.Name)
+		if name == "" || globals[name] {
+			examples = append(examples, e)
+		} else if c.Verbose {
+			log.Printf("skipping example 'Example%s' because '%s' is not a known function or type", e.Name, e.Name)
+		}
+	}
+	return examples
+}
+
+// gopExamples returns the examples of the Go+ test files, in the manner
+// of doc.Examples.
+func gopExamples(testfiles map[string]*gopast.File) []*doc.Example {
+	var names []string
+	for name := range testfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var list []*doc.Example
+	for _, name := range names {
+		f := testfiles[name]
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*gopast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Body == nil || fn.Shadow {
+				continue
+			}
+			if !strings.HasPrefix(fn.Name.Name, "Example") {
+				continue
+			}
+			if t := fn.Type; len(t.Params.List) != 0 || t.Results != nil && len(t.Results.List) != 0 {
+				continue
+			}
+			code := &gopExampleCode{BlockStmt: fn.Body}
+			var last *gopast.CommentGroup
+			for _, cg := range f.Comments {
+				if fn.Body.Pos() < cg.Pos() && cg.End() < fn.Body.End() {
+					code.comments = append(code.comments, cg)
+					last = cg
+				}
+			}
+			eg := &doc.Example{
+				Name:  fn.Name.Name[len("Example"):],
+				Doc:   fn.Doc.Text(),
+				Code:  code,
+				Order: len(list),
+			}
+			if last != nil {
+				text := last.Text()
+				if loc := gopOutputPrefix.FindStringSubmatchIndex(text); loc != nil {
+					eg.Unordered = loc[2] != -1
+					text = strings.TrimLeft(text[loc[1]:], " ")
+					if len(text) > 0 && text[0] == '\n' {
+						text = text[1:]
+					}
+					eg.Output, eg.EmptyOutput = text, text == ""
+				}
+			}
+			list = append(list, eg)
+		}
+	}
+	return list
+}
+
+// gopNodeOf returns the Go+ node x is printed as, if any.
+func gopNodeOf(info *PageInfo, x interface{}) (interface{}, bool) {
+	switch x := x.(type) {
+	case *gopExampleCode:
+		return &gopprinter.CommentedNode{Node: x.BlockStmt, Comments: x.comments}, true
+	case *printer.CommentedNode:
+		if code, ok := x.Node.(*gopExampleCode); ok {
+			return gopNodeOf(info, code)
+		}
+	}
+	if info != nil {
+		if n, ok := info.gop.gopNode(x); ok {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// writeGopNode writes the Go+ node x to w.
+func (p *Presentation) writeGopNode(w io.Writer, fset *token.FileSet, x interface{}) {
+	mode := gopprinter.TabIndent | gopprinter.UseSpaces
+	err := (&gopprinter.Config{Mode: mode, Tabwidth: p.TabWidth}).Fprint(&tconv{p: p, output: w}, fset, x)
+	if err != nil {
+		log.Print(err)
+	}
+}
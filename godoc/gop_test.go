@@ -0,0 +1,274 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package godoc
+
+import (
+	"go/doc"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/godoc/vfs/mapfs"
+)
+
+func TestGopPackage(t *testing.T) {
+	const packagePath = "example.com/p"
+	c := NewCorpus(mapfs.New(map[string]string{
+		"src/" + packagePath + "/p.gop": `// Package p is a Go+ package.
+package p
+
+// Add adds a and b.
+func Add = (
+	func(a, b int) int {
+		return a + b
+	}
+	func(a, b string) string {
+		return a + b
+	}
+)
+
+// Vec is a vector.
+type Vec struct {
+	X, Y int
+}
+
+// + adds vectors.
+func (a Vec) + (b Vec) Vec {
+	return Vec{a.X + b.X, a.Y + b.Y}
+}
+`,
+		"src/" + packagePath + "/Rect.gox": `// Rect is a rectangle.
+var (
+	Width, Height int
+	name          string
+)
+
+// Area returns the area of the rectangle.
+func Area() int {
+	return Width * Height
+}
+`,
+		"src/" + packagePath + "/p_test.gop": `package p
+
+func ExampleAdd() {
+	println Add(1, 2)
+	// Output: 3
+}
+`,
+		"src/" + packagePath + "/gop_autogen.go": `package p
+
+func Add__0(a, b int) int { return a + b }
+`,
+	}))
+	p := &Presentation{Corpus: c, TabWidth: 4}
+	srv := &handlerServer{p: p, c: c}
+	info := srv.GetPageInfo("/src/"+packagePath, packagePath, 0, "linux", "amd64")
+	if info.Err != nil {
+		t.Fatal(info.Err)
+	}
+	pdoc := info.PDoc
+	if got, want := pdoc.Doc, "Package p is a Go+ package.\n"; got != want {
+		t.Errorf("package doc = %q, want %q", got, want)
+	}
+
+	if len(pdoc.Funcs) != 1 || pdoc.Funcs[0].Name != "Add" {
+		var names []string
+		for _, f := range pdoc.Funcs {
+			names = append(names, f.Name)
+		}
+		t.Fatalf("funcs = %v, want [Add]", names)
+	}
+	add := pdoc.Funcs[0]
+	if got, want := add.Doc, "Add adds a and b.\n"; got != want {
+		t.Errorf("Add doc = %q, want %q", got, want)
+	}
+	if got, want := p.nodeFunc(info, add.Decl), "func Add = (\n    func(a, b int) int\n    func(a, b string) string\n)"; got != want {
+		t.Errorf("Add decl = %q, want %q", got, want)
+	}
+
+	types := make(map[string]*doc.Type)
+	for _, typ := range pdoc.Types {
+		types[typ.Name] = typ
+	}
+	rect := types["Rect"]
+	if rect == nil {
+		t.Fatal("class Rect not documented")
+	}
+	if got, want := rect.Doc, "Rect is a rectangle.\n"; got != want {
+		t.Errorf("Rect doc = %q, want %q", got, want)
+	}
+	if got := p.nodeFunc(info, rect.Decl); !strings.Contains(got, "Width, Height int") || strings.Contains(got, "name") {
+		t.Errorf("Rect decl = %q, want exported fields only", got)
+	}
+	if len(rect.Methods) != 1 || rect.Methods[0].Name != "Area" {
+		t.Errorf("Rect methods = %v, want [Area]", rect.Methods)
+	} else if got, want := p.nodeFunc(info, rect.Methods[0].Decl), "func (this *Rect) Area() int"; got != want {
+		t.Errorf("Area decl = %q, want %q", got, want)
+	}
+
+	vec := types["Vec"]
+	if vec == nil || len(vec.Methods) != 1 || vec.Methods[0].Name != "+" {
+		t.Fatalf("Vec methods: want [+]")
+	}
+	if got, want := p.nodeFunc(info, vec.Methods[0].Decl), "func (a Vec) + (b Vec) Vec"; got != want {
+		t.Errorf("+ decl = %q, want %q", got, want)
+	}
+
+	if len(info.Examples) != 1 {
+		t.Fatalf("got %d examples, want 1", len(info.Examples))
+	}
+	eg := info.Examples[0]
+	if eg.Name != "Add" || eg.Output != "3\n" {
+		t.Errorf("example = %s with output %q, want Add with output %q", eg.Name, eg.Output, "3\n")
+	}
+	if got := p.nodeFunc(info, eg.Code); !strings.Contains(got, "println Add(1, 2)") {
+		t.Errorf("example code = %q", got)
+	}
+}
+
+func TestGopOverloadsAndOperators(t *testing.T) {
+	c := NewCorpus(mapfs.New(map[string]string{
+		"src/example.com/p/p.gop": `package p
+
+// Sub subtracts b from a.
+func Sub__0(a, b int) int {
+	return a - b
+}
+
+func Sub__1(a, b string) string {
+	return a
+}
+
+// Vec is a vector.
+type Vec struct {
+	X, Y int
+}
+
+// Gop_Mul multiplies vectors.
+func (a Vec) Gop_Mul(b Vec) Vec {
+	return Vec{a.X * b.X, a.Y * b.Y}
+}
+`,
+		"src/example.com/p/vec.go": `package p
+
+// Gop_Sub subtracts vectors.
+func (a Vec) Gop_Sub(b Vec) Vec { return Vec{a.X - b.X, a.Y - b.Y} }
+
+// Gop_Neg negates a vector.
+func (a Vec) Gop_Neg() Vec { return Vec{-a.X, -a.Y} }
+
+// Scale scales a vector.
+func (a *Vec) Scale__0(k int)     {}
+func (a *Vec) Scale__1(k float64) {}
+`,
+		"src/example.com/p/gop_autogen.go": `package p
+
+const GopPackage = true
+`,
+		"src/example.com/q/q.go": `package q
+
+const GopPackage = true
+
+// Max returns the larger of a and b.
+func Max__0(a, b int) int { return a }
+func Max__1(a, b float64) float64 { return a }
+
+type Big struct{}
+
+func (a *Big) Gop_Add(b *Big) *Big { return a }
+`,
+		"src/example.com/r/r.go": `package r
+
+func Max__0(a, b int) int { return a }
+
+type Big struct{}
+
+func (a *Big) Gop_Add(b *Big) *Big { return a }
+`,
+	}))
+	p := &Presentation{Corpus: c, TabWidth: 4}
+	srv := &handlerServer{p: p, c: c}
+	pageInfo := func(path string) *PageInfo {
+		t.Helper()
+		info := srv.GetPageInfo("/src/"+path, path, 0, "linux", "amd64")
+		if info.Err != nil {
+			t.Fatal(info.Err)
+		}
+		return info
+	}
+	type want struct{ name, doc, decl string }
+	check := func(info *PageInfo, what string, funcs []*doc.Func, wants ...want) {
+		t.Helper()
+		if len(funcs) != len(wants) {
+			var names []string
+			for _, f := range funcs {
+				names = append(names, f.Name)
+			}
+			t.Errorf("%s = %v, want %d funcs", what, names, len(wants))
+			return
+		}
+		for i, f := range funcs {
+			w := wants[i]
+			if f.Name != w.name || f.Doc != w.doc {
+				t.Errorf("%s[%d] = %s with doc %q, want %s with doc %q", what, i, f.Name, f.Doc, w.name, w.doc)
+			}
+			if got := p.nodeFunc(info, f.Decl); got != w.decl {
+				t.Errorf("%s decl = %q, want %q", f.Name, got, w.decl)
+			}
+		}
+	}
+	typ := func(pdoc *doc.Package, name string) *doc.Type {
+		t.Helper()
+		for _, typ := range pdoc.Types {
+			if typ.Name == name {
+				return typ
+			}
+		}
+		t.Fatalf("type %s not documented", name)
+		return nil
+	}
+
+	// A Go+ package, with overloads and operators in Go+ and Go files.
+	info := pageInfo("example.com/p")
+	check(info, "p funcs", info.PDoc.Funcs,
+		want{"Sub", "Sub subtracts b from a.\n", "func Sub = (\n    func(a, b int) int\n    func(a, b string) string\n)"})
+	check(info, "Vec methods", typ(info.PDoc, "Vec").Methods,
+		want{"*", "Gop_Mul multiplies vectors.\n", "func (a Vec) * (b Vec) Vec"},
+		want{"-", "Gop_Neg negates a vector.\n", "func -(a Vec) Vec"},
+		want{"-", "Gop_Sub subtracts vectors.\n", "func (a Vec) - (b Vec) Vec"},
+		want{"Scale", "Scale scales a vector.\n", "func (*Vec).Scale = (\n    func(k int)\n    func(k float64)\n)"})
+
+	// A Go package declaring GopPackage.
+	info = pageInfo("example.com/q")
+	check(info, "q funcs", info.PDoc.Funcs,
+		want{"Max", "Max returns the larger of a and b.\n", "func Max = (\n    func(a, b int) int\n    func(a, b float64) float64\n)"})
+	check(info, "Big methods", typ(info.PDoc, "Big").Methods,
+		want{"+", "", "func (a *Big) + (b *Big) *Big"})
+
+	// A Go package.
+	info = pageInfo("example.com/r")
+	check(info, "r funcs", info.PDoc.Funcs,
+		want{"Max__0", "", "func Max__0(a, b int) int"})
+	check(info, "Big methods", typ(info.PDoc, "Big").Methods,
+		want{"Gop_Add", "", "func (a *Big) Gop_Add(b *Big) *Big"})
+}
+
+func TestIsGopTestFile(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		want bool
+	}{
+		{"a.gop", false},
+		{"a_test.gop", true},
+		{"Rect.gox", false},
+		{"Case_test.gox", true},
+		{"latest.gox", false},
+		{"Contest.gox", false},
+		{"a_test.go", false},
+	} {
+		if got := isGopTestFile(test.name); got != test.want {
+			t.Errorf("isGopTestFile(%q) = %t, want %t", test.name, got, test.want)
+		}
+	}
+}
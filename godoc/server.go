@@ -30,6 +30,7 @@ import (
 	"golang.org/x/tools/godoc/analysis"
 	"golang.org/x/tools/godoc/util"
 	"golang.org/x/tools/godoc/vfs"
+	"golang.org/x/tools/gop/goputil"
 	"golang.org/x/tools/internal/typeparams"
 )
 
@@ -113,7 +114,13 @@ func (h *handlerServer) GetPageInfo(abspath, relpath string, mode PageInfoMode,
 	// collect package files
 	pkgname := pkginfo.Name
 	pkgfiles := append(pkginfo.GoFiles, pkginfo.CgoFiles...)
-	if len(pkgfiles) == 0 {
+	testfiles := append(pkginfo.TestGoFiles, pkginfo.XTestGoFiles...)
+	// goxls: document Go+ files rather than the Go files generated from them
+	gopfiles, gopTestfiles := h.c.gopFiles(abspath)
+	if len(gopfiles) > 0 {
+		pkgfiles, testfiles = withoutGopAutogen(pkgfiles), withoutGopAutogen(testfiles)
+	}
+	if len(pkgfiles) == 0 && len(gopfiles) == 0 {
 		// Commands written in C have no .go files in the build.
 		// Instead, documentation may be found in an ignored file.
 		// The file may be ignored via an explicit +build ignore
@@ -124,7 +131,7 @@ func (h *handlerServer) GetPageInfo(abspath, relpath string, mode PageInfoMode,
 	}
 
 	// get package information, if any
-	if len(pkgfiles) > 0 {
+	if len(pkgfiles) > 0 || len(gopfiles) > 0 {
 		// build package AST
 		fset := token.NewFileSet()
 		files, err := h.c.parseFiles(fset, relpath, abspath, pkgfiles)
@@ -132,6 +139,19 @@ func (h *handlerServer) GetPageInfo(abspath, relpath string, mode PageInfoMode,
 			info.Err = err
 			return info
 		}
+		if len(gopfiles) > 0 {
+			gfiles, err := h.c.parseGopFiles(fset, relpath, abspath, gopfiles)
+			if err != nil {
+				info.Err = err
+				return info
+			}
+			info.gop = newGopDocs(fset)
+			if name := info.gop.addFiles(files, gfiles); pkgname == "" {
+				pkgname = name
+			}
+		} else if isGopPackage(files) {
+			info.gop = newGopDocs(fset) // for its overloads and operators
+		}
 
 		// ignore any errors - they are due to unresolved identifiers
 		pkg, _ := ast.NewPackage(fset, files, poorMansImporter, nil)
@@ -148,6 +168,7 @@ func (h *handlerServer) GetPageInfo(abspath, relpath string, mode PageInfoMode,
 				m |= doc.AllMethods
 			}
 			info.PDoc = doc.New(pkg, pathpkg.Clean(relpath), m) // no trailing '/' in importpath
+			info.gop.rename(info.PDoc)
 			if mode&NoTypeAssoc != 0 {
 				for _, t := range info.PDoc.Types {
 					info.PDoc.Consts = append(info.PDoc.Consts, t.Consts...)
@@ -163,12 +184,18 @@ func (h *handlerServer) GetPageInfo(abspath, relpath string, mode PageInfoMode,
 			}
 
 			// collect examples
-			testfiles := append(pkginfo.TestGoFiles, pkginfo.XTestGoFiles...)
 			files, err = h.c.parseFiles(fset, relpath, abspath, testfiles)
 			if err != nil {
 				log.Println("parsing examples:", err)
 			}
 			info.Examples = collectExamples(h.c, pkg, files)
+			if len(gopTestfiles) > 0 {
+				gfiles, err := h.c.parseGopFiles(fset, relpath, abspath, gopTestfiles)
+				if err != nil {
+					log.Println("parsing examples:", err)
+				}
+				info.Examples = append(info.Examples, collectGopExamples(h.c, pkg, gfiles)...)
+			}
 
 			// collect any notes that we want to show
 			if info.PDoc.Notes != nil {
@@ -191,6 +218,7 @@ func (h *handlerServer) GetPageInfo(abspath, relpath string, mode PageInfoMode,
 			//           or perhaps eliminating the mode altogether.
 			if mode&NoFiltering == 0 {
 				packageExports(fset, pkg)
+				info.gop.fileExports()
 			}
 			info.PAst = files
 		}
@@ -804,6 +832,10 @@ func (p *Presentation) serveFile(w http.ResponseWriter, r *http.Request) {
 		p.serveTextFile(w, r, abspath, relpath, "Source file")
 		return
 	}
+	if goputil.FileKind(pathpkg.Ext(relpath)) != goputil.FileUnknown { // goxls: Go+ source file
+		p.serveTextFile(w, r, abspath, relpath, "Source file")
+		return
+	}
 
 	dir, err := p.Corpus.fs.Lstat(abspath)
 	if err != nil {
@@ -8,6 +8,7 @@ package analysistest
 import (
 	"bytes"
 	"fmt"
+	goformat "go/format"
 	"go/types"
 	"log"
 	"os"
@@ -24,12 +25,22 @@ import (
 	"github.com/goplus/gop/token"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/internal/checker"
+	"golang.org/x/tools/gop/goputil"
 	"golang.org/x/tools/gop/packages"
 	"golang.org/x/tools/internal/diff"
 	"golang.org/x/tools/internal/testenv"
 	"golang.org/x/tools/txtar"
 )
 
+// formatSource formats src, the content of the Go or Go+ file filename.
+func formatSource(src []byte, filename string) ([]byte, error) {
+	ext := filepath.Ext(filename)
+	if ext == ".go" {
+		return goformat.Source(src)
+	}
+	return format.Source(src, goputil.FileKind(ext) == goputil.FileGopClass, filename)
+}
+
 // WriteFiles is a helper function that creates a temporary directory
 // and populates it with a GOPATH-style project using filemap (which
 // maps file names to contents). On success it returns the name of the
@@ -195,7 +206,7 @@ func RunWithSuggestedFixes(t Testing, dir string, a analysis.IAnalyzer, patterns
 							// between files in the archive. normalize
 							// this to a single newline.
 							want := string(bytes.TrimRight(vf.Data, "\n")) + "\n"
-							formatted, err := format.Source(out, true)
+							formatted, err := formatSource(out, file.Name())
 							if err != nil {
 								t.Errorf("%s: error formatting edited source: %v\n%s", file.Name(), err, out)
 								continue
@@ -226,7 +237,7 @@ func RunWithSuggestedFixes(t Testing, dir string, a analysis.IAnalyzer, patterns
 				}
 				want := string(ar.Comment)
 
-				formatted, err := format.Source(out, true)
+				formatted, err := formatSource(out, file.Name())
 				if err != nil {
 					t.Errorf("%s: error formatting resulting source: %v\n%s", file.Name(), err, out)
 					continue
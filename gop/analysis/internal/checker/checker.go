@@ -19,6 +19,7 @@ import (
 	"go/types"
 	"log"
 	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"runtime/pprof"
@@ -28,8 +29,10 @@ import (
 	"sync"
 	"time"
 
+	gopformat "github.com/goplus/gop/format"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/internal/analysisflags"
+	"golang.org/x/tools/gop/goputil"
 	"golang.org/x/tools/gop/packages"
 	"golang.org/x/tools/internal/diff"
 	"golang.org/x/tools/internal/robustio"
@@ -78,7 +81,7 @@ func RegisterFlags() {
 	flag.BoolVar(&Fix, "fix", false, "apply all suggested fixes")
 }
 
-// Run loads the packages specified by args using go/packages,
+// Run loads the packages specified by args using gop/packages,
 // then applies the specified analyzers to them.
 // Analysis flags must already have been set.
 // Analyzers must be valid according to [analysis.Validate].
@@ -185,12 +188,12 @@ func load(patterns []string, allSyntax bool) ([]*packages.Package, error) {
 	if allSyntax {
 		mode = packages.LoadAllSyntax
 	}
-	mode |= packages.NeedModule
+	mode |= packages.NeedModule | packages.NeedNongen
 	conf := packages.Config{
 		Mode:  mode,
 		Tests: IncludeTests,
 	}
-	initial, err := packages.Load(&conf, patterns...)
+	initial, err := packages.LoadEx(nil, &conf, patterns...)
 	if err == nil {
 		if len(initial) == 0 {
 			err = fmt.Errorf("%s matched no packages", strings.Join(patterns, " "))
@@ -454,7 +457,7 @@ func applyFixes(roots []*action) error {
 		}
 
 		// Try to format the file.
-		if formatted, err := format.Source(out); err == nil {
+		if formatted, err := formatSource(path, out); err == nil {
 			out = formatted
 		}
 
@@ -465,6 +468,17 @@ func applyFixes(roots []*action) error {
 	return nil
 }
 
+// formatSource formats the contents of the Go or Go+ file path.
+func formatSource(path string, src []byte) ([]byte, error) {
+	switch goputil.FileKind(filepath.Ext(path)) {
+	case goputil.FileGopNormal:
+		return gopformat.Source(src, false, path)
+	case goputil.FileGopClass:
+		return gopformat.Source(src, true, path)
+	}
+	return format.Source(src)
+}
+
 // validateEdits returns a list of edits that is sorted and
 // contains no duplicate edits. Returns the index of some
 // overlapping adjacent edits if there is one and <0 if the
@@ -652,6 +666,25 @@ type diagnosticsRet struct {
 	diagnostics []analysis.Diagnostic
 }
 
+// uniqueDiagnostics removes the duplicates of diagnostics, which are
+// inherited from a prerequisite shared by several analyzers.
+func uniqueDiagnostics(diagnostics []analysis.Diagnostic) []analysis.Diagnostic {
+	type key struct {
+		pos, end token.Pos
+		message  string
+	}
+	seen := make(map[key]bool)
+	ret := diagnostics[:0]
+	for _, diag := range diagnostics {
+		k := key{diag.Pos, diag.End, diag.Message}
+		if !seen[k] {
+			seen[k] = true
+			ret = append(ret, diag)
+		}
+	}
+	return ret
+}
+
 // An action represents one unit of analysis work: the application of
 // one analysis to one package. Actions form a DAG, both within a
 // package (as different analyzers are applied, either in sequence or
@@ -747,6 +780,7 @@ func (act *action) execOnce() {
 	// into the inputs of this action.  Also facts.
 	inputs := make(map[*analysis.Analyzer]interface{})
 	goInputs := make(map[*analysis.GoAnalyzer]interface{})
+	var inherited []analysis.Diagnostic // goxls: Go+ packages
 	for _, dep := range act.deps {
 		if dep.pkg == act.pkg {
 			// Same package, different analysis (horizontal edge):
@@ -757,6 +791,7 @@ func (act *action) execOnce() {
 			act.objectFacts = dep.objectFacts
 			act.packageFacts = dep.packageFacts
 			act.diagnosticsRet = dep.diagnosticsRet
+			inherited = append(inherited, dep.diagnostics...)
 		} else if dep.a == act.a { // (always true)
 			// Same analysis, different package (vertical edge):
 			// serialized facts produced by prerequisite analysis
@@ -764,6 +799,11 @@ func (act *action) execOnce() {
 			inheritFacts(act, dep)
 		}
 	}
+	if len(act.pkg.GopFiles) > 0 {
+		// A Go+ analyzer also reports the diagnostics of its prerequisites,
+		// such as the Go analyzer it extends to Go+ files.
+		act.diagnosticsRet = &diagnosticsRet{diagnostics: uniqueDiagnostics(inherited)}
+	}
 	if act.diagnosticsRet == nil {
 		act.diagnosticsRet = new(diagnosticsRet)
 	}
@@ -0,0 +1,103 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package multichecker_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"golang.org/x/tools/gop/analysis/multichecker"
+	"golang.org/x/tools/gop/analysis/passes/assign"
+	"golang.org/x/tools/gop/analysis/passes/printf"
+	"golang.org/x/tools/internal/testenv"
+)
+
+func main() {
+	multichecker.Main(assign.Analyzer, printf.Analyzer)
+}
+
+const (
+	src = `x := 1
+x = x
+println x
+`
+	fixed = `x := 1
+
+println x
+`
+)
+
+// TestExitCode ensures that analysis failures are reported correctly,
+// and that -fix applies suggested fixes to Go+ files.
+// This test fork/execs the main function above.
+func TestExitCode(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skipf("skipping fork/exec test on this platform")
+	}
+
+	if os.Getenv("MULTICHECKER_CHILD") == "1" {
+		// child process
+
+		// replace [progname -test.run=TestExitCode -- ...]
+		//      by [progname ...]
+		os.Args = os.Args[2:]
+		os.Args[0] = "vet"
+		main()
+		panic("unreachable")
+	}
+
+	testenv.NeedsTool(t, "go")
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("go.mod", "module example.com/a\n\ngo 1.18\n")
+	writeFile("a.gop", src)
+
+	for _, test := range []struct {
+		args []string
+		want int
+	}{
+		{[]string{"nosuchdir/..."}, 1},         // matched no packages
+		{[]string{"-unknownflag"}, 2},          // flag error
+		{[]string{"."}, 3},                     // finds diagnostics
+		{[]string{"-gopAssign=0", "."}, 0},     // no diagnostics
+		{[]string{"-gopPrintf=false", "."}, 3}, // gopAssign only
+		{[]string{"-json", "."}, 0},            // -json: exits zero even in face of diagnostics
+		{[]string{"-fix", "."}, 3},             // fixes the diagnostics
+		{[]string{"."}, 0},                     // already fixed
+	} {
+		args := []string{"-test.run=TestExitCode", "--"}
+		args = append(args, test.args...)
+		cmd := exec.Command(os.Args[0], args...)
+		cmd.Env = append(os.Environ(), "MULTICHECKER_CHILD=1")
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if len(out) > 0 {
+			t.Logf("%s: out=<<%s>>", test.args, out)
+		}
+		var exitcode int
+		if err, ok := err.(*exec.ExitError); ok {
+			exitcode = err.ExitCode()
+		}
+		if exitcode != test.want {
+			t.Errorf("%s: exited %d, want %d", test.args, exitcode, test.want)
+		}
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "a.gop"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != fixed {
+		t.Errorf("-fix: got <<%s>>, want <<%s>>", got, fixed)
+	}
+}
@@ -10,11 +10,13 @@ package assign
 import (
 	_ "embed"
 	"fmt"
-	"go/ast"
-	"go/token"
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
 	"go/types"
 	"reflect"
 
+	"golang.org/x/tools/go/analysis/passes/assign"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
@@ -25,10 +27,10 @@ import (
 var doc string
 
 var Analyzer = &analysis.Analyzer{
-	Name:     "assign",
+	Name:     "gopAssign",
 	Doc:      analysisutil.MustExtractDoc(doc, "assign"),
 	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/assign",
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Requires: []analysis.IAnalyzer{assign.Analyzer, inspect.Analyzer},
 	Run:      run,
 }
 
@@ -49,9 +51,9 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		}
 		for i, lhs := range stmt.Lhs {
 			rhs := stmt.Rhs[i]
-			if analysisutil.HasSideEffects(pass.TypesInfo, lhs) ||
-				analysisutil.HasSideEffects(pass.TypesInfo, rhs) ||
-				isMapIndex(pass.TypesInfo, lhs) {
+			if analysisutil.HasSideEffects(pass.GopTypesInfo, lhs) ||
+				analysisutil.HasSideEffects(pass.GopTypesInfo, rhs) ||
+				isMapIndex(pass.GopTypesInfo, lhs) {
 				continue // expressions may not be equal
 			}
 			if reflect.TypeOf(lhs) != reflect.TypeOf(rhs) {
@@ -76,7 +78,7 @@ func run(pass *analysis.Pass) (interface{}, error) {
 }
 
 // isMapIndex returns true if e is a map index expression.
-func isMapIndex(info *types.Info, e ast.Expr) bool {
+func isMapIndex(info *typesutil.Info, e ast.Expr) bool {
 	if idx, ok := analysisutil.Unparen(e).(*ast.IndexExpr); ok {
 		if typ := info.Types[idx.X].Type; typ != nil {
 			_, ok := typ.Underlying().(*types.Map)
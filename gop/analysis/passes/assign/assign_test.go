@@ -7,6 +7,7 @@ package assign_test
 import (
 	"testing"
 
+	goassign "golang.org/x/tools/go/analysis/passes/assign"
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/assign"
 	"golang.org/x/tools/internal/typeparams"
@@ -18,5 +19,8 @@ func Test(t *testing.T) {
 	if typeparams.Enabled {
 		tests = append(tests, "typeparams")
 	}
-	analysistest.RunWithSuggestedFixes(t, testdata, assign.Analyzer, tests...)
+	// goxls: the Go packages are checked by the Go analyzer, which the
+	// Go+ analyzer requires.
+	analysistest.RunWithSuggestedFixes(t, testdata, goassign.Analyzer, tests...)
+	analysistest.Run(t, testdata, assign.Analyzer, "goplus/a")
 }
@@ -9,10 +9,11 @@ package copylock
 import (
 	"bytes"
 	"fmt"
-	"go/ast"
-	"go/token"
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
 	"go/types"
 
+	"golang.org/x/tools/go/analysis/passes/copylock"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
@@ -27,10 +28,10 @@ sync.WaitGroup, may cause both copies to malfunction. Generally such
 values should be referred to through a pointer.`
 
 var Analyzer = &analysis.Analyzer{
-	Name:             "copylocks",
+	Name:             "gopCopylocks",
 	Doc:              Doc,
 	URL:              "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/copylocks",
-	Requires:         []*analysis.Analyzer{inspect.Analyzer},
+	Requires:         []analysis.IAnalyzer{copylock.Analyzer, inspect.Analyzer},
 	RunDespiteErrors: true,
 	Run:              run,
 }
@@ -46,12 +47,15 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		(*ast.FuncLit)(nil),
 		(*ast.GenDecl)(nil),
 		(*ast.RangeStmt)(nil),
+		(*ast.ForPhraseStmt)(nil),
 		(*ast.ReturnStmt)(nil),
 	}
 	inspect.Preorder(nodeFilter, func(node ast.Node) {
 		switch node := node.(type) {
 		case *ast.RangeStmt:
 			checkCopyLocksRange(pass, node)
+		case *ast.ForPhraseStmt:
+			checkCopyLocksForPhrase(pass, node)
 		case *ast.FuncDecl:
 			checkCopyLocksFunc(pass, node.Name.Name, node.Recv, node.Type)
 		case *ast.FuncLit:
@@ -127,7 +131,7 @@ func checkCopyLocksCallExpr(pass *analysis.Pass, ce *ast.CallExpr) {
 	case *ast.SelectorExpr:
 		id = fun.Sel
 	}
-	if fun, ok := pass.TypesInfo.Uses[id].(*types.Builtin); ok {
+	if fun, ok := pass.GopTypesInfo.Uses[id].(*types.Builtin); ok {
 		switch fun.Name() {
 		case "new", "len", "cap", "Sizeof", "Offsetof", "Alignof":
 			return
@@ -147,7 +151,7 @@ func checkCopyLocksCallExpr(pass *analysis.Pass, ce *ast.CallExpr) {
 func checkCopyLocksFunc(pass *analysis.Pass, name string, recv *ast.FieldList, typ *ast.FuncType) {
 	if recv != nil && len(recv.List) > 0 {
 		expr := recv.List[0].Type
-		if path := lockPath(pass.Pkg, pass.TypesInfo.Types[expr].Type, nil); path != nil {
+		if path := lockPath(pass.Pkg, pass.GopTypesInfo.Types[expr].Type, nil); path != nil {
 			pass.ReportRangef(expr, "%s passes lock by value: %v", name, path)
 		}
 	}
@@ -155,7 +159,7 @@ func checkCopyLocksFunc(pass *analysis.Pass, name string, recv *ast.FieldList, t
 	if typ.Params != nil {
 		for _, field := range typ.Params.List {
 			expr := field.Type
-			if path := lockPath(pass.Pkg, pass.TypesInfo.Types[expr].Type, nil); path != nil {
+			if path := lockPath(pass.Pkg, pass.GopTypesInfo.Types[expr].Type, nil); path != nil {
 				pass.ReportRangef(expr, "%s passes lock by value: %v", name, path)
 			}
 		}
@@ -175,6 +179,17 @@ func checkCopyLocksRange(pass *analysis.Pass, r *ast.RangeStmt) {
 	checkCopyLocksRangeVar(pass, r.Tok, r.Value)
 }
 
+// checkCopyLocksForPhrase checks whether a Go+ for phrase statement
+// (for k, v <- x) might inadvertently copy a lock.
+func checkCopyLocksForPhrase(pass *analysis.Pass, r *ast.ForPhraseStmt) {
+	if r.Key != nil {
+		checkCopyLocksRangeVar(pass, token.DEFINE, r.Key)
+	}
+	if r.Value != nil {
+		checkCopyLocksRangeVar(pass, token.DEFINE, r.Value)
+	}
+}
+
 func checkCopyLocksRangeVar(pass *analysis.Pass, rtok token.Token, e ast.Expr) {
 	if e == nil {
 		return
@@ -189,13 +204,13 @@ func checkCopyLocksRangeVar(pass *analysis.Pass, rtok token.Token, e ast.Expr) {
 		if !isId {
 			return
 		}
-		obj := pass.TypesInfo.Defs[id]
+		obj := pass.GopTypesInfo.Defs[id]
 		if obj == nil {
 			return
 		}
 		typ = obj.Type()
 	} else {
-		typ = pass.TypesInfo.Types[e].Type
+		typ = pass.GopTypesInfo.Types[e].Type
 	}
 
 	if typ == nil {
@@ -236,35 +251,38 @@ func lockPathRhs(pass *analysis.Pass, x ast.Expr) typePath {
 			return nil
 		}
 	}
-	return lockPath(pass.Pkg, pass.TypesInfo.Types[x].Type, nil)
+	return lockPath(pass.Pkg, pass.GopTypesInfo.Types[x].Type, nil)
 }
 
 // lockPath returns a typePath describing the location of a lock value
 // contained in typ. If there is no contained lock, it returns nil.
 //
-// The seenTParams map is used to short-circuit infinite recursion via type
+// The seen map is used to short-circuit infinite recursion via type
 // parameters.
-func lockPath(tpkg *types.Package, typ types.Type, seenTParams map[*typeparams.TypeParam]bool) typePath {
+//
+// goxls: it is also used for named types, since the Go+ type checker
+// doesn't reject invalid recursive types (see golang/go#61678).
+func lockPath(tpkg *types.Package, typ types.Type, seen map[types.Type]bool) typePath {
 	if typ == nil {
 		return nil
 	}
 
 	if tpar, ok := typ.(*typeparams.TypeParam); ok {
-		if seenTParams == nil {
-			// Lazily allocate seenTParams, since the common case will not involve
+		if seen == nil {
+			// Lazily allocate seen, since the common case will not involve
 			// any type parameters.
-			seenTParams = make(map[*typeparams.TypeParam]bool)
+			seen = make(map[types.Type]bool)
 		}
-		if seenTParams[tpar] {
+		if seen[tpar] {
 			return nil
 		}
-		seenTParams[tpar] = true
+		seen[tpar] = true
 		terms, err := typeparams.StructuralTerms(tpar)
 		if err != nil {
 			return nil // invalid type
 		}
 		for _, term := range terms {
-			subpath := lockPath(tpkg, term.Type(), seenTParams)
+			subpath := lockPath(tpkg, term.Type(), seen)
 			if len(subpath) > 0 {
 				if term.Tilde() {
 					// Prepend a tilde to our lock path entry to clarify the resulting
@@ -298,7 +316,7 @@ func lockPath(tpkg *types.Package, typ types.Type, seenTParams map[*typeparams.T
 	ttyp, ok := typ.Underlying().(*types.Tuple)
 	if ok {
 		for i := 0; i < ttyp.Len(); i++ {
-			subpath := lockPath(tpkg, ttyp.At(i).Type(), seenTParams)
+			subpath := lockPath(tpkg, ttyp.At(i).Type(), seen)
 			if subpath != nil {
 				return append(subpath, typ.String())
 			}
@@ -329,10 +347,21 @@ func lockPath(tpkg *types.Package, typ types.Type, seenTParams map[*typeparams.T
 		return []string{typ.String()}
 	}
 
+	// goxls: Go+ - short-circuit invalid recursive types.
+	if named, ok := typ.(*types.Named); ok {
+		if seen == nil {
+			seen = make(map[types.Type]bool)
+		}
+		if seen[named] {
+			return nil
+		}
+		seen[named] = true
+	}
+
 	nfields := styp.NumFields()
 	for i := 0; i < nfields; i++ {
 		ftyp := styp.Field(i).Type()
-		subpath := lockPath(tpkg, ftyp, seenTParams)
+		subpath := lockPath(tpkg, ftyp, seen)
 		if subpath != nil {
 			return append(subpath, typ.String())
 		}
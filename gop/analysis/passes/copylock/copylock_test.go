@@ -7,6 +7,7 @@ package copylock_test
 import (
 	"testing"
 
+	gocopylock "golang.org/x/tools/go/analysis/passes/copylock"
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/copylock"
 	"golang.org/x/tools/internal/typeparams"
@@ -18,5 +19,8 @@ func Test(t *testing.T) {
 	if typeparams.Enabled {
 		pkgs = append(pkgs, "typeparams")
 	}
-	analysistest.Run(t, testdata, copylock.Analyzer, pkgs...)
+	// goxls: the Go packages are checked by the Go analyzer, which the
+	// Go+ analyzer requires.
+	analysistest.Run(t, testdata, gocopylock.Analyzer, pkgs...)
+	analysistest.Run(t, testdata, copylock.Analyzer, "goplus/a")
 }
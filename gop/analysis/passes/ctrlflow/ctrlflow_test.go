@@ -0,0 +1,35 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ctrlflow_test
+
+import (
+	"testing"
+
+	"github.com/goplus/gop/ast"
+	"golang.org/x/tools/gop/analysis/analysistest"
+	"golang.org/x/tools/gop/analysis/passes/ctrlflow"
+)
+
+func Test(t *testing.T) {
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, ctrlflow.Analyzer, "a")
+
+	// Perform a minimal smoke test on
+	// the result (CFG) computed by ctrlflow.
+	for _, result := range results {
+		cfgs := result.Result.(*ctrlflow.CFGs)
+
+		for _, f := range result.Pass.GopFiles {
+			for _, decl := range f.Decls {
+				if decl, ok := decl.(*ast.FuncDecl); ok && decl.Body != nil {
+					if cfgs.FuncDecl(decl) == nil {
+						t.Errorf("%s: no CFG for func %s",
+							result.Pass.Fset.Position(decl.Pos()), decl.Name.Name)
+					}
+				}
+			}
+		}
+	}
+}
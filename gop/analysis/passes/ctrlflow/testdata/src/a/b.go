@@ -0,0 +1,11 @@
+package a
+
+// This file tests facts produced by ctrlflow for functions declared in
+// Go files.
+
+func goNoReturn() { // want goNoReturn:"noReturn"
+	for {
+	}
+}
+
+func goCanReturn() {}
@@ -0,0 +1,13 @@
+// This file stands in for the Go code generated from a.gop: it imports
+// the packages of a.gop, so that their analysis facts are available.
+
+package a
+
+import (
+	_ "lib"
+	_ "log"
+	_ "os"
+	_ "runtime"
+	_ "syscall"
+	_ "testing"
+)
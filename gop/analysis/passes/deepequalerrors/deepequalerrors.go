@@ -7,14 +7,14 @@
 package deepequalerrors
 
 import (
-	"go/ast"
+	"github.com/goplus/gop/ast"
 	"go/types"
 
+	"golang.org/x/tools/go/analysis/passes/deepequalerrors"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
 	"golang.org/x/tools/gop/ast/inspector"
-	"golang.org/x/tools/gop/types/typeutil"
 )
 
 const Doc = `check for calls of reflect.DeepEqual on error values
@@ -27,10 +27,10 @@ where err1 and err2 are errors. Using reflect.DeepEqual to compare
 errors is discouraged.`
 
 var Analyzer = &analysis.Analyzer{
-	Name:     "deepequalerrors",
+	Name:     "gopDeepequalerrors",
 	Doc:      Doc,
 	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/deepequalerrors",
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Requires: []analysis.IAnalyzer{deepequalerrors.Analyzer, inspect.Analyzer},
 	Run:      run,
 }
 
@@ -46,7 +46,7 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	}
 	inspect.Preorder(nodeFilter, func(n ast.Node) {
 		call := n.(*ast.CallExpr)
-		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		fn, ok := analysisutil.Callee(pass.GopTypesInfo, call).(*types.Func)
 		if !ok {
 			return
 		}
@@ -62,7 +62,7 @@ var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Inter
 // hasError reports whether the type of e contains the type error.
 // See containsError, below, for the meaning of "contains".
 func hasError(pass *analysis.Pass, e ast.Expr) bool {
-	tv, ok := pass.TypesInfo.Types[e]
+	tv, ok := pass.GopTypesInfo.Types[e]
 	if !ok { // no type info, assume good
 		return false
 	}
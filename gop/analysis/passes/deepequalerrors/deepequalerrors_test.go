@@ -7,6 +7,7 @@ package deepequalerrors_test
 import (
 	"testing"
 
+	godeepequalerrors "golang.org/x/tools/go/analysis/passes/deepequalerrors"
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/deepequalerrors"
 	"golang.org/x/tools/internal/typeparams"
@@ -18,5 +19,8 @@ func Test(t *testing.T) {
 	if typeparams.Enabled {
 		tests = append(tests, "typeparams")
 	}
-	analysistest.Run(t, testdata, deepequalerrors.Analyzer, tests...)
+	// goxls: the Go packages are checked by the Go analyzer, which the
+	// Go+ analyzer requires.
+	analysistest.Run(t, testdata, godeepequalerrors.Analyzer, tests...)
+	analysistest.Run(t, testdata, deepequalerrors.Analyzer, "goplus/a")
 }
@@ -8,14 +8,14 @@ package errorsas
 
 import (
 	"errors"
-	"go/ast"
+	"github.com/goplus/gop/ast"
 	"go/types"
 
+	"golang.org/x/tools/go/analysis/passes/errorsas"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
 	"golang.org/x/tools/gop/ast/inspector"
-	"golang.org/x/tools/gop/types/typeutil"
 )
 
 const Doc = `report passing non-pointer or non-error values to errors.As
@@ -24,10 +24,10 @@ The errorsas analysis reports calls to errors.As where the type
 of the second argument is not a pointer to a type implementing error.`
 
 var Analyzer = &analysis.Analyzer{
-	Name:     "errorsas",
+	Name:     "gopErrorsas",
 	Doc:      Doc,
 	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/errorsas",
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Requires: []analysis.IAnalyzer{errorsas.Analyzer, inspect.Analyzer},
 	Run:      run,
 }
 
@@ -50,7 +50,7 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	}
 	inspect.Preorder(nodeFilter, func(n ast.Node) {
 		call := n.(*ast.CallExpr)
-		fn := typeutil.StaticCallee(pass.TypesInfo, call)
+		fn := analysisutil.StaticCallee(pass.GopTypesInfo, call)
 		if fn == nil {
 			return // not a static call
 		}
@@ -74,7 +74,7 @@ var errorType = types.Universe.Lookup("error").Type()
 
 // checkAsTarget reports an error if the second argument to errors.As is invalid.
 func checkAsTarget(pass *analysis.Pass, e ast.Expr) error {
-	t := pass.TypesInfo.Types[e].Type
+	t := pass.GopTypesInfo.Types[e].Type
 	if it, ok := t.Underlying().(*types.Interface); ok && it.NumMethods() == 0 {
 		// A target of interface{} is always allowed, since it often indicates
 		// a value forwarded from another source.
@@ -10,6 +10,7 @@ package errorsas_test
 import (
 	"testing"
 
+	goerrorsas "golang.org/x/tools/go/analysis/passes/errorsas"
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/errorsas"
 	"golang.org/x/tools/internal/typeparams"
@@ -21,5 +22,8 @@ func Test(t *testing.T) {
 	if typeparams.Enabled {
 		tests = append(tests, "typeparams")
 	}
-	analysistest.Run(t, testdata, errorsas.Analyzer, tests...)
+	// goxls: the Go packages are checked by the Go analyzer, which the
+	// Go+ analyzer requires.
+	analysistest.Run(t, testdata, goerrorsas.Analyzer, tests...)
+	analysistest.Run(t, testdata, errorsas.Analyzer, "goplus/a")
 }
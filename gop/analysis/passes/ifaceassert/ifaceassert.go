@@ -6,9 +6,10 @@ package ifaceassert
 
 import (
 	_ "embed"
-	"go/ast"
+	"github.com/goplus/gop/ast"
 	"go/types"
 
+	"golang.org/x/tools/go/analysis/passes/ifaceassert"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
@@ -19,10 +20,10 @@ import (
 var doc string
 
 var Analyzer = &analysis.Analyzer{
-	Name:     "ifaceassert",
+	Name:     "gopIfaceassert",
 	Doc:      analysisutil.MustExtractDoc(doc, "ifaceassert"),
 	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/ifaceassert",
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Requires: []analysis.IAnalyzer{ifaceassert.Analyzer, inspect.Analyzer},
 	Run:      run,
 }
 
@@ -83,9 +84,9 @@ func run(pass *analysis.Pass) (interface{}, error) {
 				targets = append(targets, c.(*ast.CaseClause).List...)
 			}
 		}
-		V := pass.TypesInfo.TypeOf(assert.X)
+		V := pass.GopTypesInfo.TypeOf(assert.X)
 		for _, target := range targets {
-			T := pass.TypesInfo.TypeOf(target)
+			T := pass.GopTypesInfo.TypeOf(target)
 			if f := assertableTo(V, T); f != nil {
 				pass.Reportf(
 					target.Pos(),
@@ -6,24 +6,25 @@ package loopclosure
 
 import (
 	_ "embed"
-	"go/ast"
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/x/typesutil"
 	"go/types"
 
+	"golang.org/x/tools/go/analysis/passes/loopclosure"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
 	"golang.org/x/tools/gop/ast/inspector"
-	"golang.org/x/tools/gop/types/typeutil"
 )
 
 //go:embed doc.go
 var doc string
 
 var Analyzer = &analysis.Analyzer{
-	Name:     "loopclosure",
+	Name:     "gopLoopclosure",
 	Doc:      analysisutil.MustExtractDoc(doc, "loopclosure"),
 	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/loopclosure",
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Requires: []analysis.IAnalyzer{loopclosure.Analyzer, inspect.Analyzer},
 	Run:      run,
 }
 
@@ -33,13 +34,14 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	nodeFilter := []ast.Node{
 		(*ast.RangeStmt)(nil),
 		(*ast.ForStmt)(nil),
+		(*ast.ForPhraseStmt)(nil),
 	}
 	inspect.Preorder(nodeFilter, func(n ast.Node) {
 		// Find the variables updated by the loop statement.
 		var vars []types.Object
 		addVar := func(expr ast.Expr) {
 			if id, _ := expr.(*ast.Ident); id != nil {
-				if obj := pass.TypesInfo.ObjectOf(id); obj != nil {
+				if obj := pass.GopTypesInfo.ObjectOf(id); obj != nil {
 					vars = append(vars, obj)
 				}
 			}
@@ -50,6 +52,11 @@ func run(pass *analysis.Pass) (interface{}, error) {
 			body = n.Body
 			addVar(n.Key)
 			addVar(n.Value)
+		case *ast.ForPhraseStmt:
+			// e.g. for i, x <- list
+			body = n.Body
+			addVar(n.Key)
+			addVar(n.Value)
 		case *ast.ForStmt:
 			body = n.Body
 			switch post := n.Post.(type) {
@@ -88,7 +95,7 @@ func run(pass *analysis.Pass) (interface{}, error) {
 				stmts = litStmts(s.Call.Fun)
 			case *ast.ExprStmt: // check for errgroup.Group.Go
 				if call, ok := s.X.(*ast.CallExpr); ok {
-					stmts = litStmts(goInvoke(pass.TypesInfo, call))
+					stmts = litStmts(goInvoke(pass.GopTypesInfo, call))
 				}
 			}
 			for _, stmt := range stmts {
@@ -106,7 +113,7 @@ func run(pass *analysis.Pass) (interface{}, error) {
 			switch s := s.(type) {
 			case *ast.ExprStmt:
 				if call, ok := s.X.(*ast.CallExpr); ok {
-					for _, stmt := range parallelSubtest(pass.TypesInfo, call) {
+					for _, stmt := range parallelSubtest(pass.GopTypesInfo, call) {
 						reportCaptured(pass, vars, stmt)
 					}
 
@@ -127,7 +134,7 @@ func reportCaptured(pass *analysis.Pass, vars []types.Object, checkStmt ast.Stmt
 		if !ok {
 			return true
 		}
-		obj := pass.TypesInfo.Uses[id]
+		obj := pass.GopTypesInfo.Uses[id]
 		if obj == nil {
 			return true
 		}
@@ -194,11 +201,13 @@ func forEachLastStmt(stmts []ast.Stmt, onLast func(last ast.Stmt)) {
 //
 // If fun is not a function literal, it returns nil.
 func litStmts(fun ast.Expr) []ast.Stmt {
-	lit, _ := fun.(*ast.FuncLit)
-	if lit == nil {
-		return nil
+	switch lit := fun.(type) {
+	case *ast.FuncLit:
+		return lit.Body.List
+	case *ast.LambdaExpr2: // => { ... }
+		return lit.Body.List
 	}
-	return lit.Body.List
+	return nil
 }
 
 // goInvoke returns a function expression that would be called asynchronously
@@ -210,7 +219,7 @@ func litStmts(fun ast.Expr) []ast.Stmt {
 //	g.Go(func() error { ... })
 //
 // Currently only "golang.org/x/sync/errgroup.Group()" is considered.
-func goInvoke(info *types.Info, call *ast.CallExpr) ast.Expr {
+func goInvoke(info *typesutil.Info, call *ast.CallExpr) ast.Expr {
 	if !isMethodCall(info, call, "golang.org/x/sync/errgroup", "Group", "Go") {
 		return nil
 	}
@@ -243,7 +252,7 @@ func goInvoke(info *types.Info, call *ast.CallExpr) ast.Expr {
 //			})
 //		}
 //	}
-func parallelSubtest(info *types.Info, call *ast.CallExpr) []ast.Stmt {
+func parallelSubtest(info *typesutil.Info, call *ast.CallExpr) []ast.Stmt {
 	if !isMethodCall(info, call, "testing", "T", "Run") {
 		return nil
 	}
@@ -337,14 +346,14 @@ func unlabel(stmt ast.Stmt) (ast.Stmt, bool) {
 
 // isMethodCall reports whether expr is a method call of
 // <pkgPath>.<typeName>.<method>.
-func isMethodCall(info *types.Info, expr ast.Expr, pkgPath, typeName, method string) bool {
+func isMethodCall(info *typesutil.Info, expr ast.Expr, pkgPath, typeName, method string) bool {
 	call, ok := expr.(*ast.CallExpr)
 	if !ok {
 		return false
 	}
 
 	// Check that we are calling a method <method>
-	f := typeutil.StaticCallee(info, call)
+	f := analysisutil.StaticCallee(info, call)
 	if f == nil || f.Name() != method {
 		return false
 	}
@@ -0,0 +1,12 @@
+// This file stands in for the Go code generated from a.gop: it imports
+// the packages of a.gop, so that their analysis facts are available.
+
+package a
+
+import (
+	_ "context"
+	_ "log"
+	_ "os"
+	_ "testing"
+	_ "time"
+)
@@ -8,25 +8,26 @@ package nilfunc
 
 import (
 	_ "embed"
-	"go/ast"
-	"go/token"
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
 	"go/types"
 
+	"golang.org/x/tools/go/analysis/passes/nilfunc"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
 	"golang.org/x/tools/gop/ast/inspector"
-	"golang.org/x/tools/internal/typeparams"
+	"golang.org/x/tools/internal/gop/typeparams"
 )
 
 //go:embed doc.go
 var doc string
 
 var Analyzer = &analysis.Analyzer{
-	Name:     "nilfunc",
+	Name:     "gopNilfunc",
 	Doc:      analysisutil.MustExtractDoc(doc, "nilfunc"),
 	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/nilfunc",
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Requires: []analysis.IAnalyzer{nilfunc.Analyzer, inspect.Analyzer},
 	Run:      run,
 }
 
@@ -47,9 +48,9 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		// Only want comparisons with a nil identifier on one side.
 		var e2 ast.Expr
 		switch {
-		case pass.TypesInfo.Types[e.X].IsNil():
+		case pass.GopTypesInfo.Types[e.X].IsNil():
 			e2 = e.Y
-		case pass.TypesInfo.Types[e.Y].IsNil():
+		case pass.GopTypesInfo.Types[e.Y].IsNil():
 			e2 = e.X
 		default:
 			return
@@ -59,14 +60,14 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		var obj types.Object
 		switch v := e2.(type) {
 		case *ast.Ident:
-			obj = pass.TypesInfo.Uses[v]
+			obj = pass.GopTypesInfo.Uses[v]
 		case *ast.SelectorExpr:
-			obj = pass.TypesInfo.Uses[v.Sel]
-		case *ast.IndexExpr, *typeparams.IndexListExpr:
+			obj = pass.GopTypesInfo.Uses[v.Sel]
+		case *ast.IndexExpr, *ast.IndexListExpr:
 			// Check generic functions such as "f[T1,T2]".
 			x, _, _, _ := typeparams.UnpackIndexExpr(v)
 			if id, ok := x.(*ast.Ident); ok {
-				obj = pass.TypesInfo.Uses[id]
+				obj = pass.GopTypesInfo.Uses[id]
 			}
 		default:
 			return
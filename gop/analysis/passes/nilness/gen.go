@@ -0,0 +1,102 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nilness
+
+import (
+	goast "go/ast"
+	"go/types"
+	"sync"
+
+	"github.com/goplus/gop"
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/cl"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/c2go"
+	"github.com/goplus/gox"
+	goxpackages "github.com/goplus/gox/packages"
+	"github.com/goplus/mod/gopmod"
+)
+
+// loadMod loads the Go+ module of dir.
+func loadMod(dir string) *gopmod.Module {
+	mod, err := gop.LoadMod(dir)
+	if err != nil {
+		return gopmod.Default
+	}
+	return mod
+}
+
+// genGo generates the Go code of the Go+ files of package pkg in module
+// mod, with //line comments.
+func genGo(pkg *types.Package, mod *gopmod.Module, fset *token.FileSet, gopFiles map[string]*ast.File, goFiles map[string]*goast.File, imp types.Importer) (*gox.Package, error) {
+	return cl.NewPackage(pkg.Path(), &ast.Package{
+		Name:    pkg.Name(),
+		Files:   gopFiles,
+		GoFiles: goFiles,
+	}, &cl.Config{
+		Fset:          fset,
+		LookupPub:     c2go.LookupPub(mod),
+		LookupClass:   mod.LookupClass,
+		Importer:      imp,
+		NoAutoGenMain: true,
+	})
+}
+
+// importer imports the packages of the generated Go code. The packages
+// imported by pkg, and their dependencies, are reused; others, such as
+// the packages of Go+ builtins, are imported by the default importer of
+// Go+ from module mod.
+type importer struct {
+	pkgs map[string]*types.Package
+	root string // root directory of the module, if it has a go.mod
+}
+
+func newImporter(pkg *types.Package, mod *gopmod.Module) *importer {
+	pkgs := make(map[string]*types.Package)
+	var addImports func(pkg *types.Package)
+	addImports = func(pkg *types.Package) {
+		for _, imp := range pkg.Imports() {
+			if _, ok := pkgs[imp.Path()]; !ok {
+				pkgs[imp.Path()] = imp
+				addImports(imp)
+			}
+		}
+	}
+	addImports(pkg)
+	root := ""
+	if mod.HasModfile() {
+		root = mod.Root()
+	}
+	return &importer{pkgs: pkgs, root: root}
+}
+
+func (p *importer) Import(path string) (*types.Package, error) {
+	if pkg := p.pkgs[path]; pkg != nil {
+		return pkg, nil
+	}
+	pkg, err := gopImport(p.root, path)
+	if err == nil {
+		p.pkgs[path] = pkg
+	}
+	return pkg, err
+}
+
+var (
+	gopImportersMu sync.Mutex
+	gopImporters   = make(map[string]*goxpackages.Importer) // by module root
+)
+
+// gopImport imports path with the default importer of Go+ for the module
+// in root, which is shared by the analyses of its packages.
+func gopImport(root, path string) (*types.Package, error) {
+	gopImportersMu.Lock()
+	defer gopImportersMu.Unlock()
+	imp := gopImporters[root]
+	if imp == nil {
+		imp = goxpackages.NewImporter(nil, root)
+		gopImporters[root] = imp
+	}
+	return imp.Import(path)
+}
@@ -0,0 +1,170 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package nilness inspects the control-flow graph of an SSA function
+// and reports errors such as nil pointer dereferences and degenerate
+// nil pointer comparisons.
+//
+// The SSA form of Go+ code is built from the Go code generated from it,
+// and the diagnostics are reported at the Go+ lines that the generated
+// code maps to, so their columns are approximate.
+package nilness
+
+import (
+	_ "embed"
+	goast "go/ast"
+	goparser "go/parser"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gox"
+	"golang.org/x/tools/go/analysis/passes/buildssa"
+	"golang.org/x/tools/go/analysis/passes/nilness"
+	"golang.org/x/tools/gop/analysis"
+	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "gopNilness",
+	Doc:      analysisutil.MustExtractDoc(doc, "nilness"),
+	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/nilness",
+	Run:      run,
+	Requires: []analysis.IAnalyzer{nilness.Analyzer},
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if len(pass.GopFiles) == 0 {
+		return nil, nil
+	}
+	goPass := genGoPass(pass)
+	if goPass == nil {
+		return nil, nil // Go code can't be generated or is ill-typed
+	}
+	ssainput, err := buildssa.Analyzer.Run(goPass)
+	if err != nil {
+		return nil, err
+	}
+	goPass.ResultOf = map[*analysis.GoAnalyzer]interface{}{buildssa.Analyzer: ssainput}
+	return nilness.Analyzer.Run(goPass)
+}
+
+// genGoPass returns a pass of the Go analyzers over the Go code generated
+// from the Go+ files of pass, together with its Go files, or nil if the
+// Go code can't be generated or type-checked. Its diagnostics are
+// reported to pass if they map to a Go+ file.
+func genGoPass(pass *analysis.Pass) *analysis.GoPass {
+	fset := pass.Fset
+	gopFiles := make(map[string]*ast.File, len(pass.GopFiles))
+	tokFiles := make(map[string]*token.File, len(pass.GopFiles))
+	for _, f := range pass.GopFiles {
+		tf := fset.File(f.Pos())
+		if tf == nil {
+			return nil
+		}
+		gopFiles[tf.Name()] = f
+		tokFiles[tf.Name()] = tf
+	}
+	goFiles := make(map[string]*goast.File, len(pass.Files))
+	for _, f := range pass.Files {
+		if tf := fset.File(f.Pos()); tf != nil {
+			goFiles[tf.Name()] = f
+		}
+	}
+	dir := filepath.Dir(fset.File(pass.GopFiles[0].Pos()).Name())
+
+	mod := loadMod(dir)
+	imp := newImporter(pass.Pkg, mod)
+	pkg, err := genGo(pass.Pkg, mod, fset, gopFiles, goFiles, imp)
+	if err != nil {
+		return nil
+	}
+
+	// Parse the generated code, whose //line comments map it to the Go+
+	// files, and type-check it with the Go files of the package.
+	var fnames []string
+	pkg.ForEachFile(func(fname string, _ *gox.File) {
+		fnames = append(fnames, fname)
+	})
+	sort.Strings(fnames)
+	files := append([]*goast.File(nil), pass.Files...)
+	for _, fname := range fnames {
+		var buf strings.Builder
+		if err := pkg.WriteTo(&buf, fname); err != nil {
+			return nil
+		}
+		filename := filepath.Join(dir, "gop_autogen"+fname+".go")
+		f, err := goparser.ParseFile(fset, filename, buf.String(), goparser.ParseComments)
+		if err != nil {
+			return nil
+		}
+		files = append(files, f)
+	}
+
+	info := &types.Info{
+		Types:      make(map[goast.Expr]types.TypeAndValue),
+		Defs:       make(map[*goast.Ident]types.Object),
+		Uses:       make(map[*goast.Ident]types.Object),
+		Implicits:  make(map[goast.Node]types.Object),
+		Scopes:     make(map[goast.Node]*types.Scope),
+		Selections: make(map[*goast.SelectorExpr]*types.Selection),
+		Instances:  make(map[*goast.Ident]types.Instance),
+	}
+	var firstErr error
+	conf := &types.Config{
+		Importer: imp,
+		Sizes:    pass.TypesSizes,
+		Error: func(err error) {
+			if firstErr == nil {
+				firstErr = err
+			}
+		},
+	}
+	tpkg := types.NewPackage(pass.Pkg.Path(), pass.Pkg.Name())
+	types.NewChecker(conf, fset, tpkg, info).Files(files)
+	if firstErr != nil {
+		return nil
+	}
+
+	return &analysis.GoPass{
+		Analyzer:   nilness.Analyzer,
+		Fset:       fset,
+		Files:      files,
+		Pkg:        tpkg,
+		TypesInfo:  info,
+		TypesSizes: pass.TypesSizes,
+		Report: func(d analysis.Diagnostic) {
+			if pos := gopPos(fset, tokFiles, d.Pos); pos.IsValid() {
+				d.Pos, d.End = pos, token.NoPos
+				pass.Report(d)
+			}
+		},
+	}
+}
+
+// gopPos returns the position in a Go+ file of pos, a position in the
+// generated Go code, or token.NoPos if it doesn't map to a Go+ file.
+// Generated code is mapped by line, so columns are approximate.
+func gopPos(fset *token.FileSet, tokFiles map[string]*token.File, pos token.Pos) token.Pos {
+	posn := fset.PositionFor(pos, true)
+	tf := tokFiles[posn.Filename]
+	if tf == nil || posn.Line < 1 || posn.Line > tf.LineCount() {
+		return token.NoPos
+	}
+	start := tf.LineStart(posn.Line)
+	end := token.Pos(tf.Base() + tf.Size())
+	if posn.Line < tf.LineCount() {
+		end = tf.LineStart(posn.Line+1) - 1 // newline
+	}
+	if col := token.Pos(posn.Column - 1); col > 0 && start+col < end {
+		return start + col
+	}
+	return start
+}
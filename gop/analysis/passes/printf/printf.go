@@ -8,9 +8,11 @@ import (
 	"bytes"
 	_ "embed"
 	"fmt"
-	"go/ast"
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	goast "go/ast"
 	"go/constant"
-	"go/token"
 	"go/types"
 	"reflect"
 	"regexp"
@@ -19,11 +21,11 @@ import (
 	"strings"
 	"unicode/utf8"
 
+	"golang.org/x/tools/go/analysis/passes/printf"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
 	"golang.org/x/tools/gop/ast/inspector"
-	"golang.org/x/tools/gop/types/typeutil"
 	"golang.org/x/tools/internal/typeparams"
 )
 
@@ -35,13 +37,13 @@ func init() {
 var doc string
 
 var Analyzer = &analysis.Analyzer{
-	Name:       "printf",
+	Name:       "gopPrintf",
 	Doc:        analysisutil.MustExtractDoc(doc, "printf"),
 	URL:        "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/printf",
-	Requires:   []*analysis.Analyzer{inspect.Analyzer},
+	Requires:   []analysis.IAnalyzer{printf.Analyzer, inspect.Analyzer},
 	Run:        run,
 	ResultType: reflect.TypeOf((*Result)(nil)),
-	FactTypes:  []analysis.Fact{new(isWrapper)},
+	FactTypes:  []analysis.Fact{new(isGopWrapper)},
 }
 
 // Kind is a kind of fmt function behavior.
@@ -90,12 +92,15 @@ func (r *Result) Kind(fn *types.Func) Kind {
 	return r.funcs[fn]
 }
 
-// isWrapper is a fact indicating that a function is a print or printf wrapper.
-type isWrapper struct{ Kind Kind }
+// isGopWrapper is a fact indicating that a function is a print or printf wrapper.
+//
+// goxls: it is named apart from the isWrapper fact of the Go printf checker,
+// as fact types are registered with encoding/gob by name.
+type isGopWrapper struct{ Kind Kind }
 
-func (f *isWrapper) AFact() {}
+func (f *isGopWrapper) AFact() {}
 
-func (f *isWrapper) String() string {
+func (f *isGopWrapper) String() string {
 	switch f.Kind {
 	case KindPrintf:
 		return "printfWrapper"
@@ -138,7 +143,7 @@ type printfCaller struct {
 // A function may be a Printf or Print wrapper if its last argument is ...interface{}.
 // If the next-to-last argument is a string, then this may be a Printf wrapper.
 // Otherwise it may be a Print wrapper.
-func maybePrintfWrapper(info *types.Info, decl ast.Decl) *printfWrapper {
+func maybePrintfWrapper(info *typesutil.Info, decl ast.Decl) *printfWrapper {
 	// Look for functions with final argument type ...interface{}.
 	fdecl, ok := decl.(*ast.FuncDecl)
 	if !ok || fdecl.Body == nil {
@@ -182,12 +187,15 @@ func maybePrintfWrapper(info *types.Info, decl ast.Decl) *printfWrapper {
 
 // findPrintfLike scans the entire package to find printf-like functions.
 func findPrintfLike(pass *analysis.Pass, res *Result) (interface{}, error) {
+	// goxls: Go+ - wrappers declared in Go files of the package.
+	exportGoWrappers(pass, res)
+
 	// Gather potential wrappers and call graph between them.
 	byObj := make(map[*types.Func]*printfWrapper)
 	var wrappers []*printfWrapper
-	for _, file := range pass.Files {
+	for _, file := range pass.GopFiles {
 		for _, decl := range file.Decls {
-			w := maybePrintfWrapper(pass.TypesInfo, decl)
+			w := maybePrintfWrapper(pass.GopTypesInfo, decl)
 			if w == nil {
 				continue
 			}
@@ -207,8 +215,8 @@ func findPrintfLike(pass *analysis.Pass, res *Result) (interface{}, error) {
 			// TODO: Relax these checks; issue 26555.
 			if assign, ok := n.(*ast.AssignStmt); ok {
 				for _, lhs := range assign.Lhs {
-					if match(pass.TypesInfo, lhs, w.format) ||
-						match(pass.TypesInfo, lhs, w.args) {
+					if match(pass.GopTypesInfo, lhs, w.format) ||
+						match(pass.GopTypesInfo, lhs, w.args) {
 						// Modifies the format
 						// string or args in
 						// some way, so not a
@@ -219,8 +227,8 @@ func findPrintfLike(pass *analysis.Pass, res *Result) (interface{}, error) {
 				}
 			}
 			if un, ok := n.(*ast.UnaryExpr); ok && un.Op == token.AND {
-				if match(pass.TypesInfo, un.X, w.format) ||
-					match(pass.TypesInfo, un.X, w.args) {
+				if match(pass.GopTypesInfo, un.X, w.format) ||
+					match(pass.GopTypesInfo, un.X, w.args) {
 					// Taking the address of the
 					// format string or args,
 					// so not a simple wrapper.
@@ -230,7 +238,7 @@ func findPrintfLike(pass *analysis.Pass, res *Result) (interface{}, error) {
 			}
 
 			call, ok := n.(*ast.CallExpr)
-			if !ok || len(call.Args) == 0 || !match(pass.TypesInfo, call.Args[len(call.Args)-1], w.args) {
+			if !ok || len(call.Args) == 0 || !match(pass.GopTypesInfo, call.Args[len(call.Args)-1], w.args) {
 				return true
 			}
 
@@ -254,7 +262,33 @@ func findPrintfLike(pass *analysis.Pass, res *Result) (interface{}, error) {
 	return nil, nil
 }
 
-func match(info *types.Info, arg ast.Expr, param *types.Var) bool {
+// exportGoWrappers exports an isGopWrapper fact for each wrapper declared
+// in the Go files of the package, as found by the Go printf checker, so
+// that calls to it from Go+ code of other packages are checked too.
+func exportGoWrappers(pass *analysis.Pass, res *Result) {
+	goRes, ok := pass.GoPass.ResultOf[printf.Analyzer].(*printf.Result)
+	if !ok {
+		return
+	}
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fdecl, ok := decl.(*goast.FuncDecl)
+			if !ok {
+				continue
+			}
+			fn, ok := pass.TypesInfo.Defs[fdecl.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			if kind := Kind(goRes.Kind(fn)); kind != KindNone {
+				pass.ExportObjectFact(fn, &isGopWrapper{Kind: kind})
+				res.funcs[fn] = kind
+			}
+		}
+	}
+}
+
+func match(info *typesutil.Info, arg ast.Expr, param *types.Var) bool {
 	id, ok := arg.(*ast.Ident)
 	return ok && info.ObjectOf(id) == param
 }
@@ -263,13 +297,13 @@ func match(info *types.Info, arg ast.Expr, param *types.Var) bool {
 // It diagnoses writing fmt.Printf(format, args) instead of fmt.Printf(format, args...).
 func checkPrintfFwd(pass *analysis.Pass, w *printfWrapper, call *ast.CallExpr, kind Kind, res *Result) {
 	matched := kind == KindPrint ||
-		kind != KindNone && len(call.Args) >= 2 && match(pass.TypesInfo, call.Args[len(call.Args)-2], w.format)
+		kind != KindNone && len(call.Args) >= 2 && match(pass.GopTypesInfo, call.Args[len(call.Args)-2], w.format)
 	if !matched {
 		return
 	}
 
 	if !call.Ellipsis.IsValid() {
-		typ, ok := pass.TypesInfo.Types[call.Fun].Type.(*types.Signature)
+		typ, ok := pass.GopTypesInfo.Types[call.Fun].Type.(*types.Signature)
 		if !ok {
 			return
 		}
@@ -291,7 +325,7 @@ func checkPrintfFwd(pass *analysis.Pass, w *printfWrapper, call *ast.CallExpr, k
 		return
 	}
 	fn := w.obj
-	var fact isWrapper
+	var fact isGopWrapper
 	if !pass.ImportObjectFact(fn, &fact) {
 		fact.Kind = kind
 		pass.ExportObjectFact(fn, &fact)
@@ -382,7 +416,7 @@ var isPrint = stringSet{
 //
 // If it cannot find any format string parameter, it returns ("", -1).
 func formatString(pass *analysis.Pass, call *ast.CallExpr) (format string, idx int) {
-	typ := pass.TypesInfo.Types[call.Fun].Type
+	typ := pass.GopTypesInfo.Types[call.Fun].Type
 	if typ != nil {
 		if sig, ok := typ.(*types.Signature); ok {
 			if !sig.Variadic() {
@@ -410,7 +444,7 @@ func formatString(pass *analysis.Pass, call *ast.CallExpr) (format string, idx i
 		if s, ok := stringConstantArg(pass, call, idx); ok {
 			return s, idx
 		}
-		if pass.TypesInfo.Types[call.Args[idx]].Type == types.Typ[types.String] {
+		if pass.GopTypesInfo.Types[call.Args[idx]].Type == types.Typ[types.String] {
 			// Skip checking a call with a non-constant format
 			// string argument, since its contents are unavailable
 			// for validation.
@@ -436,7 +470,7 @@ func stringConstantArg(pass *analysis.Pass, call *ast.CallExpr, idx int) (string
 // ("", false) is returned if expression isn't a string
 // constant.
 func stringConstantExpr(pass *analysis.Pass, expr ast.Expr) (string, bool) {
-	lit := pass.TypesInfo.Types[expr].Value
+	lit := pass.GopTypesInfo.Types[expr].Value
 	if lit != nil && lit.Kind() == constant.String {
 		return constant.StringVal(lit), true
 	}
@@ -462,7 +496,7 @@ func checkCall(pass *analysis.Pass) {
 }
 
 func printfNameAndKind(pass *analysis.Pass, call *ast.CallExpr) (fn *types.Func, kind Kind) {
-	fn, _ = typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	fn, _ = analysisutil.Callee(pass.GopTypesInfo, call).(*types.Func)
 	if fn == nil {
 		return nil, 0
 	}
@@ -483,11 +517,18 @@ func printfNameAndKind(pass *analysis.Pass, call *ast.CallExpr) (fn *types.Func,
 		return fn, kind
 	}
 
-	var fact isWrapper
+	var fact isGopWrapper
 	if pass.ImportObjectFact(fn, &fact) {
 		return fn, fact.Kind
 	}
 
+	// goxls: Go+ - wrappers declared in Go files of the package
+	if res, ok := pass.GoPass.ResultOf[printf.Analyzer].(*printf.Result); ok {
+		if kind := Kind(res.Kind(fn)); kind != KindNone {
+			return fn, kind
+		}
+	}
+
 	return fn, KindNone
 }
 
@@ -820,7 +861,7 @@ func okPrintfArg(pass *analysis.Pass, call *ast.CallExpr, state *formatState) (o
 	// Skip check for the %w verb, which requires an error.
 	formatter := false
 	if v.typ != argError && state.argNum < len(call.Args) {
-		if tv, ok := pass.TypesInfo.Types[call.Args[state.argNum]]; ok {
+		if tv, ok := pass.GopTypesInfo.Types[call.Args[state.argNum]]; ok {
 			formatter = isFormatter(tv.Type)
 		}
 	}
@@ -879,8 +920,9 @@ func okPrintfArg(pass *analysis.Pass, call *ast.CallExpr, state *formatState) (o
 	}
 	if reason, ok := matchArgType(pass, v.typ, arg); !ok {
 		typeString := ""
-		if typ := pass.TypesInfo.Types[arg].Type; typ != nil {
-			typeString = typ.String()
+		if typ := pass.GopTypesInfo.Types[arg].Type; typ != nil {
+			// goxls: Go+ records untyped constant arguments as untyped
+			typeString = types.Default(typ).String()
 		}
 		details := ""
 		if reason != "" {
@@ -905,7 +947,7 @@ func okPrintfArg(pass *analysis.Pass, call *ast.CallExpr, state *formatState) (o
 //	func (t  T) Error() string { printf("%s",  t) }
 //	func (t  T) String() string { printf("%s", &t) }
 func recursiveStringer(pass *analysis.Pass, e ast.Expr) (string, bool) {
-	typ := pass.TypesInfo.Types[e].Type
+	typ := pass.GopTypesInfo.Types[e].Type
 
 	// It's unlikely to be a recursive stringer if it has a Format method.
 	if isFormatter(typ) {
@@ -923,7 +965,12 @@ func recursiveStringer(pass *analysis.Pass, e ast.Expr) (string, bool) {
 
 	// inScope returns true if e is in the scope of f.
 	inScope := func(e ast.Expr, f *types.Func) bool {
-		return f.Scope() != nil && f.Scope().Contains(e.Pos())
+		// goxls: the Go+ type checker doesn't set the scope of functions.
+		scope := f.Scope()
+		if scope == nil {
+			scope = funcScope(pass, f)
+		}
+		return scope != nil && scope.Contains(e.Pos())
 	}
 
 	// Is the expression e within the body of that String or Error method?
@@ -946,13 +993,26 @@ func recursiveStringer(pass *analysis.Pass, e ast.Expr) (string, bool) {
 		e = u.X // strip off & from &r
 	}
 	if id, ok := e.(*ast.Ident); ok {
-		if pass.TypesInfo.Uses[id] == sig.Recv() {
+		if pass.GopTypesInfo.Uses[id] == sig.Recv() {
 			return method.FullName(), true
 		}
 	}
 	return "", false
 }
 
+// funcScope returns the scope of the function fn declared in the Go+ files
+// of the package, or nil if there is none.
+func funcScope(pass *analysis.Pass, fn *types.Func) *types.Scope {
+	for _, file := range pass.GopFiles {
+		for _, decl := range file.Decls {
+			if fdecl, ok := decl.(*ast.FuncDecl); ok && pass.GopTypesInfo.Defs[fdecl.Name] == fn {
+				return pass.GopTypesInfo.Scopes[fdecl.Type]
+			}
+		}
+	}
+	return nil
+}
+
 // isStringer reports whether the method signature matches the String() definition in fmt.Stringer.
 func isStringer(sig *types.Signature) bool {
 	return sig.Params().Len() == 0 &&
@@ -963,7 +1023,7 @@ func isStringer(sig *types.Signature) bool {
 // isFunctionValue reports whether the expression is a function as opposed to a function call.
 // It is almost always a mistake to print a function value.
 func isFunctionValue(pass *analysis.Pass, e ast.Expr) bool {
-	if typ := pass.TypesInfo.Types[e].Type; typ != nil {
+	if typ := pass.GopTypesInfo.Types[e].Type; typ != nil {
 		_, ok := typ.(*types.Signature)
 		return ok
 	}
@@ -1010,7 +1070,7 @@ const (
 // checkPrint checks a call to an unformatted print routine such as Println.
 func checkPrint(pass *analysis.Pass, call *ast.CallExpr, fn *types.Func) {
 	firstArg := 0
-	typ := pass.TypesInfo.Types[call.Fun].Type
+	typ := pass.GopTypesInfo.Types[call.Fun].Type
 	if typ == nil {
 		// Skip checking functions with unknown type.
 		return
@@ -7,6 +7,7 @@ package printf_test
 import (
 	"testing"
 
+	goprintf "golang.org/x/tools/go/analysis/passes/printf"
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/printf"
 	"golang.org/x/tools/internal/typeparams"
@@ -14,11 +15,15 @@ import (
 
 func Test(t *testing.T) {
 	testdata := analysistest.TestData()
+	goprintf.Analyzer.Flags.Set("funcs", "Warn,Warnf") // goxls: Go analyzer
 	printf.Analyzer.Flags.Set("funcs", "Warn,Warnf")
 
 	tests := []string{"a", "b", "nofmt"}
 	if typeparams.Enabled {
 		tests = append(tests, "typeparams")
 	}
-	analysistest.Run(t, testdata, printf.Analyzer, tests...)
+	// goxls: the Go packages are checked by the Go analyzer, which the
+	// Go+ analyzer requires.
+	analysistest.Run(t, testdata, goprintf.Analyzer, tests...)
+	analysistest.Run(t, testdata, printf.Analyzer, "goplus/a", "goplus/b", "goplus/nofmt")
 }
@@ -0,0 +1,14 @@
+// This file stands in for the Go code generated from the Go+ files: it
+// imports their packages, so that their analysis facts are available.
+
+package a
+
+import (
+	_ "goplus/b"
+	_ "fmt"
+	_ "log"
+	_ "math"
+	_ "os"
+	_ "testing"
+	_ "unsafe"
+)
@@ -6,7 +6,7 @@ package printf
 
 import (
 	"fmt"
-	"go/ast"
+	"github.com/goplus/gop/ast"
 	"go/types"
 
 	"golang.org/x/tools/gop/analysis"
@@ -26,7 +26,7 @@ func matchArgType(pass *analysis.Pass, t printfArgType, arg ast.Expr) (reason st
 		return "", true
 	}
 
-	typ := pass.TypesInfo.Types[arg].Type
+	typ := pass.GopTypesInfo.Types[arg].Type
 	if typ == nil {
 		return "", true // probably a type check problem
 	}
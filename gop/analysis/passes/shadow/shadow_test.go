@@ -7,11 +7,15 @@ package shadow_test
 import (
 	"testing"
 
+	goshadow "golang.org/x/tools/go/analysis/passes/shadow"
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/shadow"
 )
 
 func Test(t *testing.T) {
 	testdata := analysistest.TestData()
-	analysistest.Run(t, testdata, shadow.Analyzer, "a")
+	// goxls: the Go packages are checked by the Go analyzer, which the
+	// Go+ analyzer requires.
+	analysistest.Run(t, testdata, goshadow.Analyzer, "a")
+	analysistest.Run(t, testdata, shadow.Analyzer, "goplus/a")
 }
@@ -7,6 +7,7 @@ package stringintconv_test
 import (
 	"testing"
 
+	gostringintconv "golang.org/x/tools/go/analysis/passes/stringintconv"
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/stringintconv"
 	"golang.org/x/tools/internal/typeparams"
@@ -18,5 +19,8 @@ func Test(t *testing.T) {
 	if typeparams.Enabled {
 		pkgs = append(pkgs, "typeparams")
 	}
-	analysistest.RunWithSuggestedFixes(t, testdata, stringintconv.Analyzer, pkgs...)
+	// goxls: the Go packages are checked by the Go analyzer, which the
+	// Go+ analyzer requires.
+	analysistest.RunWithSuggestedFixes(t, testdata, gostringintconv.Analyzer, pkgs...)
+	analysistest.RunWithSuggestedFixes(t, testdata, stringintconv.Analyzer, "goplus/a")
 }
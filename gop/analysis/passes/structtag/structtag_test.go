@@ -7,11 +7,15 @@ package structtag_test
 import (
 	"testing"
 
+	gostructtag "golang.org/x/tools/go/analysis/passes/structtag"
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/structtag"
 )
 
 func Test(t *testing.T) {
 	testdata := analysistest.TestData()
-	analysistest.Run(t, testdata, structtag.Analyzer, "a")
+	// goxls: the Go packages are checked by the Go analyzer, which the
+	// Go+ analyzer requires.
+	analysistest.Run(t, testdata, gostructtag.Analyzer, "a")
+	analysistest.Run(t, testdata, structtag.Analyzer, "goplus/a")
 }
@@ -6,14 +6,14 @@ package unmarshal
 
 import (
 	_ "embed"
-	"go/ast"
+	"github.com/goplus/gop/ast"
 	"go/types"
 
+	"golang.org/x/tools/go/analysis/passes/unmarshal"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
 	"golang.org/x/tools/gop/ast/inspector"
-	"golang.org/x/tools/gop/types/typeutil"
 	"golang.org/x/tools/internal/typeparams"
 )
 
@@ -21,10 +21,10 @@ import (
 var doc string
 
 var Analyzer = &analysis.Analyzer{
-	Name:     "unmarshal",
+	Name:     "gopUnmarshal",
 	Doc:      analysisutil.MustExtractDoc(doc, "unmarshal"),
 	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/unmarshal",
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Requires: []analysis.IAnalyzer{unmarshal.Analyzer, inspect.Analyzer},
 	Run:      run,
 }
 
@@ -37,10 +37,10 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	}
 
 	// Note: (*"encoding/json".Decoder).Decode, (* "encoding/gob".Decoder).Decode
-	// and (* "encoding/xml".Decoder).Decode are methods and can be a typeutil.Callee
+	// and (* "encoding/xml".Decoder).Decode are methods and can be a analysisutil.Callee
 	// without directly importing their packages. So we cannot just skip this package
 	// when !analysisutil.Imports(pass.Pkg, "encoding/...").
-	// TODO(taking): Consider using a prepass to collect typeutil.Callees.
+	// TODO(taking): Consider using a prepass to collect analysisutil.Callees.
 
 	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 
@@ -49,7 +49,7 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	}
 	inspect.Preorder(nodeFilter, func(n ast.Node) {
 		call := n.(*ast.CallExpr)
-		fn := typeutil.StaticCallee(pass.TypesInfo, call)
+		fn := analysisutil.StaticCallee(pass.GopTypesInfo, call)
 		if fn == nil {
 			return // not a static call
 		}
@@ -90,7 +90,7 @@ func run(pass *analysis.Pass) (interface{}, error) {
 			return // not enough arguments, e.g. called with return values of another function
 		}
 
-		t := pass.TypesInfo.Types[call.Args[argidx]].Type
+		t := pass.GopTypesInfo.Types[call.Args[argidx]].Type
 		switch t.Underlying().(type) {
 		case *types.Pointer, *types.Interface, *typeparams.TypeParam:
 			return
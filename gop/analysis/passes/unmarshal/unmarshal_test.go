@@ -7,6 +7,7 @@ package unmarshal_test
 import (
 	"testing"
 
+	gounmarshal "golang.org/x/tools/go/analysis/passes/unmarshal"
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/unmarshal"
 	"golang.org/x/tools/internal/typeparams"
@@ -18,5 +19,8 @@ func Test(t *testing.T) {
 	if typeparams.Enabled {
 		tests = append(tests, "typeparams")
 	}
-	analysistest.Run(t, testdata, unmarshal.Analyzer, tests...)
+	// goxls: the Go packages are checked by the Go analyzer, which the
+	// Go+ analyzer requires.
+	analysistest.Run(t, testdata, gounmarshal.Analyzer, tests...)
+	analysistest.Run(t, testdata, unmarshal.Analyzer, "goplus/a")
 }
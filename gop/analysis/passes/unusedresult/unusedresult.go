@@ -15,27 +15,27 @@ package unusedresult
 
 import (
 	_ "embed"
-	"go/ast"
-	"go/token"
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
 	"go/types"
 	"sort"
 	"strings"
 
+	"golang.org/x/tools/go/analysis/passes/unusedresult"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
 	"golang.org/x/tools/gop/ast/inspector"
-	"golang.org/x/tools/gop/types/typeutil"
 )
 
 //go:embed doc.go
 var doc string
 
 var Analyzer = &analysis.Analyzer{
-	Name:     "unusedresult",
+	Name:     "gopUnusedresult",
 	Doc:      analysisutil.MustExtractDoc(doc, "unusedresult"),
 	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/unusedresult",
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Requires: []analysis.IAnalyzer{unusedresult.Analyzer, inspect.Analyzer},
 	Run:      run,
 }
 
@@ -88,23 +88,29 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		}
 
 		// Call to function or method?
-		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		fn, ok := analysisutil.Callee(pass.GopTypesInfo, call).(*types.Func)
 		if !ok {
 			return // e.g. var or builtin
 		}
 
+		// goxls: Go+ - a command-style call (fmt.Sprintf "%d", 1) has no "("
+		lparen := call.Lparen
+		if !lparen.IsValid() {
+			lparen = call.Fun.End()
+		}
+
 		if sig := fn.Type().(*types.Signature); sig.Recv() != nil {
 			// method (e.g. foo.String())
 			if types.Identical(sig, sigNoArgsStringResult) {
 				if stringMethods[fn.Name()] {
-					pass.Reportf(call.Lparen, "result of (%s).%s call not used",
+					pass.Reportf(lparen, "result of (%s).%s call not used",
 						sig.Recv().Type(), fn.Name())
 				}
 			}
 		} else {
 			// package-level function (e.g. fmt.Errorf)
 			if pkgFuncs[[2]string{fn.Pkg().Path(), fn.Name()}] {
-				pass.Reportf(call.Lparen, "result of %s.%s call not used",
+				pass.Reportf(lparen, "result of %s.%s call not used",
 					fn.Pkg().Path(), fn.Name())
 			}
 		}
@@ -7,6 +7,7 @@ package unusedresult_test
 import (
 	"testing"
 
+	gounusedresult "golang.org/x/tools/go/analysis/passes/unusedresult"
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/unusedresult"
 	"golang.org/x/tools/internal/typeparams"
@@ -15,10 +16,14 @@ import (
 func Test(t *testing.T) {
 	testdata := analysistest.TestData()
 	funcs := "typeparams/userdefs.MustUse,errors.New,fmt.Errorf,fmt.Sprintf,fmt.Sprint"
+	gounusedresult.Analyzer.Flags.Set("funcs", funcs) // goxls: Go analyzer
 	unusedresult.Analyzer.Flags.Set("funcs", funcs)
 	tests := []string{"a"}
 	if typeparams.Enabled {
 		tests = append(tests, "typeparams")
 	}
-	analysistest.Run(t, testdata, unusedresult.Analyzer, tests...)
+	// goxls: the Go packages are checked by the Go analyzer, which the
+	// Go+ analyzer requires.
+	analysistest.Run(t, testdata, gounusedresult.Analyzer, tests...)
+	analysistest.Run(t, testdata, unusedresult.Analyzer, "goplus/a")
 }
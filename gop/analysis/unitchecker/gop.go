@@ -0,0 +1,157 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package unitchecker
+
+import (
+	goast "go/ast"
+	"go/build"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goplus/gop"
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/parser"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"github.com/goplus/mod/gopmod"
+	"golang.org/x/tools/gop/goputil"
+)
+
+// checkGopFiles parses and type-checks the Go+ files of the package
+// described by cfg, whose Go files have been type-checked into pkg.
+// It returns nil if the package has no generated Go files.
+func checkGopFiles(fset *token.FileSet, cfg *Config, pkg *types.Package, files []*goast.File, importer types.Importer) ([]*ast.File, *typesutil.Info, error) {
+	var autogen []*goast.File
+	test := false
+	for _, f := range files {
+		fname := filepath.Base(fset.File(f.Pos()).Name())
+		if isAutogen(fname) {
+			autogen = append(autogen, f)
+		}
+		if strings.HasSuffix(fname, "_test.go") {
+			test = true
+		}
+	}
+	if len(autogen) == 0 {
+		return nil, nil, nil
+	}
+
+	mod, err := gop.LoadMod(cfg.Dir)
+	if err != nil {
+		mod = gopmod.Default
+	}
+	filenames, err := gopFileNames(cfg.Dir, pkg.Name(), test, mod)
+	if err != nil || len(filenames) == 0 {
+		return nil, nil, err
+	}
+	var gopFiles []*ast.File
+	for _, filename := range filenames {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		f, err := parser.ParseEntry(fset, filename, src, parser.Config{
+			Mode:      parser.ParseComments,
+			ClassKind: mod.ClassKind,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		gopFiles = append(gopFiles, f)
+	}
+
+	info := &typesutil.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Instances:  make(map[*ast.Ident]types.Instance),
+		Overloads:  make(map[*ast.Ident][]types.Object),
+	}
+	var firstErr error
+	conf := &types.Config{
+		Importer: importer,
+		Error: func(err error) {
+			if firstErr == nil {
+				firstErr = err
+			}
+		},
+	}
+	opts := &typesutil.Config{
+		Types: pkg,
+		Fset:  fset,
+		Mod:   mod,
+	}
+
+	// The Go+ files replace the declarations of the generated Go files.
+	scope := pkg.Scope()
+	objMap := typesutil.DeleteObjects(scope, autogen)
+	c := typesutil.NewChecker(conf, opts, nil, info)
+	c.Files(nil, gopFiles)
+	typesutil.CorrectTypesInfo(scope, objMap, info.Uses)
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+	return gopFiles, info, nil
+}
+
+// gopFileNames returns the Go+ files of package pkgName in dir, selected
+// as gop/packages does: test files are included only if test is set, and
+// build constraints are evaluated as for Go files.
+func gopFileNames(dir, pkgName string, test bool, mod *gopmod.Module) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var filenames []string
+	ctxt := gopBuildContext()
+	fsetTemp := token.NewFileSet()
+	for _, e := range entries {
+		fname := e.Name()
+		fext := filepath.Ext(fname)
+		if e.IsDir() || strings.HasPrefix(fname, "_") || goputil.FileKind(fext) == goputil.FileUnknown {
+			continue
+		}
+		if !test && strings.HasSuffix(fname[:len(fname)-len(fext)], "_test") {
+			continue
+		}
+		if !test && strings.HasSuffix(fname, "test.gox") {
+			if _, ok := mod.ClassKind(fname); ok { // classfile test
+				continue
+			}
+		}
+		filename := filepath.Join(dir, fname)
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fsetTemp, filename, src, parser.PackageClauseOnly)
+		if err != nil || f.Name.Name != pkgName || !goputil.MatchFile(ctxt, dir, fname, src) {
+			continue
+		}
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames, nil
+}
+
+// gopBuildContext returns the build context of the Go+ files of a
+// package. The vet config does not describe it: as the go command does,
+// build.Default takes GOOS, GOARCH and CGO_ENABLED from the environment,
+// and the build tags are taken from GOFLAGS.
+func gopBuildContext() *build.Context {
+	ctxt := build.Default
+	ctxt.BuildTags = goputil.BuildTags(strings.Fields(os.Getenv("GOFLAGS")))
+	return &ctxt
+}
+
+func isAutogen(fname string) bool {
+	return strings.HasPrefix(fname, "gop_autogen")
+}
@@ -0,0 +1,150 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package unitchecker_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/testenv"
+)
+
+// This is a very basic integration test of modular analysis of Go+
+// packages using unitchecker under "go vet".
+// It fork/execs minivet.
+func TestGopIntegration(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skipf("skipping fork/exec test on this platform")
+	}
+	testenv.NeedsTool(t, "go")
+
+	dir := filepath.Join(t.TempDir(), "fake")
+	for name, content := range map[string]string{
+		"go.mod": `module golang.org/fake
+
+go 1.18
+`,
+		// a has Go+ files, compiled to gop_autogen.go.
+		"a/gop_autogen.go": `package a
+
+import "fmt"
+
+func Hello(name string) {
+	fmt.Printf("hello %s\n", name)
+}
+`,
+		"a/a.gop": `package a
+
+import "fmt"
+
+func Hello(name string) {
+	fmt.Printf "hello %d\n", name
+}
+`,
+		// foo.gop is analyzed only with the build tag foo.
+		"a/foo.gop": `//go:build foo
+
+package a
+
+import "fmt"
+
+func Bye(name string) {
+	fmt.Printf "bye %d\n", name
+}
+`,
+		// a_test.gop is not analyzed: a has no gop_autogen_test.go.
+		"a/a_test.gop": `package a
+
+func _() {
+	i := 5
+	i = i
+}
+`,
+		// b has no Go+ files: it is checked by Go analyzers only.
+		"b/b.go": `package b
+
+func _() {
+	i := 5
+	i = i
+}
+`,
+	} {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const wantA = `# golang.org/fake/a
+([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?a/a.gop:6:2: fmt.Printf format %d has arg name of wrong type string
+`
+	const wantAFoo = `# golang.org/fake/a
+([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?a/a.gop:6:2: fmt.Printf format %d has arg name of wrong type string
+([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?a/foo.gop:8:2: fmt.Printf format %d has arg name of wrong type string
+`
+	const wantB = `# golang.org/fake/b
+([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?b/b.go:5:2: self-assignment of i to i
+`
+	const wantAJSON = `# golang.org/fake/a
+\{
+	"golang.org/fake/a": \{
+		"gopPrintf": \[
+			\{
+				"posn": "([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?a/a.gop:6:2",
+				"message": "fmt.Printf format %d has arg name of wrong type string"
+			\}
+		\]
+	\}
+\}
+`
+	for _, test := range []struct {
+		env           string
+		args          string
+		wantOut       string
+		wantExitError bool
+	}{
+		{args: "golang.org/fake/a", wantOut: wantA + "$", wantExitError: true},
+		{env: "GOFLAGS=-tags=foo", args: "golang.org/fake/a", wantOut: wantAFoo, wantExitError: true},
+		{args: "golang.org/fake/b", wantOut: wantB, wantExitError: true},
+		{args: "-json golang.org/fake/a", wantOut: wantAJSON, wantExitError: false},
+	} {
+		cmd := exec.Command("go", "vet", "-vettool="+os.Args[0])
+		cmd.Args = append(cmd.Args, strings.Fields(test.args)...)
+		cmd.Env = append(os.Environ(), "ENTRYPOINT=minivet", "GO111MODULE=on", "GOPROXY=off", "GOFLAGS=")
+		if test.env != "" {
+			cmd.Env = append(cmd.Env, test.env)
+		}
+		cmd.Dir = dir
+
+		out, err := cmd.CombinedOutput()
+		exitcode := 0
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitcode = exitErr.ExitCode()
+		}
+		if (exitcode != 0) != test.wantExitError {
+			want := "zero"
+			if test.wantExitError {
+				want = "nonzero"
+			}
+			t.Errorf("%s: got exit code %d, want %s", test.args, exitcode, want)
+		}
+
+		matched, err := regexp.Match(test.wantOut, out)
+		if err != nil {
+			t.Fatalf("regexp.Match(<<%s>>): %v", test.wantOut, err)
+		}
+		if !matched {
+			t.Errorf("%s: got <<%s>>, want match of regexp <<%s>>", test.args, out, test.wantOut)
+		}
+	}
+}
@@ -7,6 +7,7 @@
 
 // This file provides an example command for static checkers
 // conforming to the golang.org/x/tools/gop/analysis API.
+// It runs the Go+ versions of the analyzers of cmd/vet.
 // It serves as a model for the behavior of the cmd/vet tool in $GOROOT.
 // Being based on the unitchecker driver, it must be run by go vet:
 //
@@ -19,67 +20,33 @@ package main
 import (
 	"golang.org/x/tools/gop/analysis/unitchecker"
 
-	"golang.org/x/tools/gop/analysis/passes/asmdecl"
 	"golang.org/x/tools/gop/analysis/passes/assign"
-	"golang.org/x/tools/gop/analysis/passes/atomic"
 	"golang.org/x/tools/gop/analysis/passes/bools"
-	"golang.org/x/tools/gop/analysis/passes/buildtag"
-	"golang.org/x/tools/gop/analysis/passes/cgocall"
-	"golang.org/x/tools/gop/analysis/passes/composite"
 	"golang.org/x/tools/gop/analysis/passes/copylock"
-	"golang.org/x/tools/gop/analysis/passes/directive"
 	"golang.org/x/tools/gop/analysis/passes/errorsas"
-	"golang.org/x/tools/gop/analysis/passes/framepointer"
-	"golang.org/x/tools/gop/analysis/passes/httpresponse"
 	"golang.org/x/tools/gop/analysis/passes/ifaceassert"
 	"golang.org/x/tools/gop/analysis/passes/loopclosure"
-	"golang.org/x/tools/gop/analysis/passes/lostcancel"
 	"golang.org/x/tools/gop/analysis/passes/nilfunc"
 	"golang.org/x/tools/gop/analysis/passes/printf"
-	"golang.org/x/tools/gop/analysis/passes/shift"
-	"golang.org/x/tools/gop/analysis/passes/sigchanyzer"
-	"golang.org/x/tools/gop/analysis/passes/stdmethods"
 	"golang.org/x/tools/gop/analysis/passes/stringintconv"
 	"golang.org/x/tools/gop/analysis/passes/structtag"
-	"golang.org/x/tools/gop/analysis/passes/testinggoroutine"
-	"golang.org/x/tools/gop/analysis/passes/tests"
-	"golang.org/x/tools/gop/analysis/passes/timeformat"
 	"golang.org/x/tools/gop/analysis/passes/unmarshal"
-	"golang.org/x/tools/gop/analysis/passes/unreachable"
-	"golang.org/x/tools/gop/analysis/passes/unsafeptr"
 	"golang.org/x/tools/gop/analysis/passes/unusedresult"
 )
 
 func main() {
 	unitchecker.Main(
-		asmdecl.Analyzer,
 		assign.Analyzer,
-		atomic.Analyzer,
 		bools.Analyzer,
-		buildtag.Analyzer,
-		cgocall.Analyzer,
-		composite.Analyzer,
 		copylock.Analyzer,
-		directive.Analyzer,
 		errorsas.Analyzer,
-		framepointer.Analyzer,
-		httpresponse.Analyzer,
 		ifaceassert.Analyzer,
 		loopclosure.Analyzer,
-		lostcancel.Analyzer,
 		nilfunc.Analyzer,
 		printf.Analyzer,
-		shift.Analyzer,
-		sigchanyzer.Analyzer,
-		stdmethods.Analyzer,
 		stringintconv.Analyzer,
 		structtag.Analyzer,
-		tests.Analyzer,
-		testinggoroutine.Analyzer,
-		timeformat.Analyzer,
 		unmarshal.Analyzer,
-		unreachable.Analyzer,
-		unsafeptr.Analyzer,
 		unusedresult.Analyzer,
 	)
 }
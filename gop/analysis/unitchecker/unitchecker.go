@@ -14,10 +14,14 @@
 //	-flags          describe flags                    (to the build tool)
 //	foo.cfg         description of compilation unit (from the build tool)
 //
-// This package does not depend on go/packages.
+// The Go+ files of a package are not described by the .cfg file: if the
+// package has a generated gop_autogen*.go file, the Go+ files of its
+// directory are type-checked together with its Go files.
+//
+// This package does not depend on gop/packages.
 // If you need a standalone tool, use multichecker,
 // which supports this mode but can also load packages
-// from source using go/packages.
+// from source using gop/packages.
 package unitchecker
 
 // TODO(adonovan):
@@ -84,7 +88,7 @@ type Config struct {
 //	-V=full         describe executable for build caching
 //	foo.cfg         perform separate modular analyze on the single
 //	                unit described by a JSON config file foo.cfg.
-func Main(analyzers ...*analysis.Analyzer) {
+func Main(analyzers ...analysis.IAnalyzer) {
 	progname := filepath.Base(os.Args[0])
 	log.SetFlags(0)
 	log.SetPrefix(progname + ": ")
@@ -94,7 +98,7 @@ func Main(analyzers ...*analysis.Analyzer) {
 	}
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, `%[1]s is a tool for static analysis of Go programs.
+		fmt.Fprintf(os.Stderr, `%[1]s is a tool for static analysis of Go/Go+ programs.
 
 Usage of %[1]s:
 	%.16[1]s unit.cfg	# execute analysis specified by config file
@@ -123,7 +127,7 @@ Usage of %[1]s:
 // Run reads the *.cfg file, runs the analysis,
 // and calls os.Exit with an appropriate error code.
 // It assumes flags have already been set.
-func Run(configFile string, analyzers []*analysis.Analyzer) {
+func Run(configFile string, analyzers []analysis.IAnalyzer) {
 	cfg, err := readConfig(configFile)
 	if err != nil {
 		log.Fatal(err)
@@ -141,7 +145,7 @@ func Run(configFile string, analyzers []*analysis.Analyzer) {
 			// JSON output
 			tree := make(analysisflags.JSONTree)
 			for _, res := range results {
-				tree.Add(fset, cfg.ID, res.a.Name, res.diagnostics, res.err)
+				tree.Add(fset, cfg.ID, analysis.Name(res.a), res.diagnostics, res.err)
 			}
 			tree.Print()
 		} else {
@@ -184,9 +188,9 @@ func readConfig(filename string) (*Config, error) {
 	return cfg, nil
 }
 
-func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]result, error) {
+func run(fset *token.FileSet, cfg *Config, analyzers []analysis.IAnalyzer) ([]result, error) {
 	// Load, parse, typecheck.
-	var files []*ast.File
+	var files, nongenFiles []*ast.File
 	for _, name := range cfg.GoFiles {
 		f, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
 		if err != nil {
@@ -198,6 +202,9 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 			return nil, err
 		}
 		files = append(files, f)
+		if !isAutogen(filepath.Base(name)) {
+			nongenFiles = append(nongenFiles, f)
+		}
 	}
 	compilerImporter := importer.ForCompiler(fset, cfg.Compiler, func(path string) (io.ReadCloser, error) {
 		// path is a resolved package path, not an import path.
@@ -242,6 +249,15 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 		return nil, err
 	}
 
+	// goxls: Go+ files
+	gopFiles, gopInfo, err := checkGopFiles(fset, cfg, pkg, files, importer)
+	if err != nil {
+		if cfg.SucceedOnTypecheckFailure {
+			err = nil
+		}
+		return nil, err
+	}
+
 	// Register fact types with gob.
 	// In VetxOnly mode, analyzers are only for their facts,
 	// so we can skip any analysis that neither produces facts
@@ -258,18 +274,18 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 		usesFacts   bool // (transitively uses)
 		diagnostics []analysis.Diagnostic
 	}
-	actions := make(map[*analysis.Analyzer]*action)
-	var registerFacts func(a *analysis.Analyzer) bool
-	registerFacts = func(a *analysis.Analyzer) bool {
+	actions := make(map[analysis.IAnalyzer]*action)
+	var registerFacts func(a analysis.IAnalyzer) bool
+	registerFacts = func(a analysis.IAnalyzer) bool {
 		act, ok := actions[a]
 		if !ok {
 			act = new(action)
 			var usesFacts bool
-			for _, f := range a.FactTypes {
+			for _, f := range analysis.FactTypes(a) {
 				usesFacts = true
 				gob.Register(f)
 			}
-			for _, req := range a.Requires {
+			for _, req := range analysis.Requires(a) {
 				if registerFacts(req) {
 					usesFacts = true
 				}
@@ -279,7 +295,7 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 		}
 		return act.usesFacts
 	}
-	var filtered []*analysis.Analyzer
+	var filtered []analysis.IAnalyzer
 	for _, a := range analyzers {
 		if registerFacts(a) || !cfg.VetxOnly {
 			filtered = append(filtered, a)
@@ -300,24 +316,26 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 	}
 
 	// In parallel, execute the DAG of analyzers.
-	var exec func(a *analysis.Analyzer) *action
-	var execAll func(analyzers []*analysis.Analyzer)
-	exec = func(a *analysis.Analyzer) *action {
+	var exec func(a analysis.IAnalyzer) *action
+	var execAll func(analyzers []analysis.IAnalyzer)
+	exec = func(a analysis.IAnalyzer) *action {
 		act := actions[a]
 		act.once.Do(func() {
-			execAll(a.Requires) // prefetch dependencies in parallel
+			requires := analysis.Requires(a)
+			execAll(requires) // prefetch dependencies in parallel
 
 			// The inputs to this analysis are the
 			// results of its prerequisites.
 			inputs := make(map[*analysis.Analyzer]interface{})
+			goInputs := make(map[*analysis.GoAnalyzer]interface{})
 			var failed []string
-			for _, req := range a.Requires {
+			for _, req := range requires {
 				reqact := exec(req)
 				if reqact.err != nil {
 					failed = append(failed, req.String())
 					continue
 				}
-				inputs[req] = reqact.result
+				analysis.SetResult(inputs, goInputs, req, reqact.result)
 			}
 
 			// Report an error if any dependency failed.
@@ -328,32 +346,37 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 			}
 
 			factFilter := make(map[reflect.Type]bool)
-			for _, f := range a.FactTypes {
+			for _, f := range analysis.FactTypes(a) {
 				factFilter[reflect.TypeOf(f)] = true
 			}
 
 			pass := &analysis.Pass{
-				Analyzer:          a,
-				Fset:              fset,
-				Files:             files,
-				OtherFiles:        cfg.NonGoFiles,
-				IgnoredFiles:      cfg.IgnoredFiles,
-				Pkg:               pkg,
-				TypesInfo:         info,
-				TypesSizes:        tc.Sizes,
-				TypeErrors:        nil, // unitchecker doesn't RunDespiteErrors
-				ResultOf:          inputs,
-				Report:            func(d analysis.Diagnostic) { act.diagnostics = append(act.diagnostics, d) },
-				ImportObjectFact:  facts.ImportObjectFact,
-				ExportObjectFact:  facts.ExportObjectFact,
-				AllObjectFacts:    func() []analysis.ObjectFact { return facts.AllObjectFacts(factFilter) },
-				ImportPackageFact: facts.ImportPackageFact,
-				ExportPackageFact: facts.ExportPackageFact,
-				AllPackageFacts:   func() []analysis.PackageFact { return facts.AllPackageFacts(factFilter) },
+				GoPass: analysis.GoPass{
+					Fset:              fset,
+					Files:             nongenFiles,
+					OtherFiles:        cfg.NonGoFiles,
+					IgnoredFiles:      cfg.IgnoredFiles,
+					Pkg:               pkg,
+					TypesInfo:         info,
+					TypesSizes:        tc.Sizes,
+					TypeErrors:        nil, // unitchecker doesn't RunDespiteErrors
+					ResultOf:          goInputs,
+					Report:            func(d analysis.Diagnostic) { act.diagnostics = append(act.diagnostics, d) },
+					ImportObjectFact:  facts.ImportObjectFact,
+					ExportObjectFact:  facts.ExportObjectFact,
+					AllObjectFacts:    func() []analysis.ObjectFact { return facts.AllObjectFacts(factFilter) },
+					ImportPackageFact: facts.ImportPackageFact,
+					ExportPackageFact: facts.ExportPackageFact,
+					AllPackageFacts:   func() []analysis.PackageFact { return facts.AllPackageFacts(factFilter) },
+				},
+				ResultOf:     inputs,
+				GopFiles:     gopFiles,
+				GopTypesInfo: gopInfo,
 			}
+			pass.SetAnalyzer(a)
 
 			t0 := time.Now()
-			act.result, act.err = a.Run(pass)
+			act.result, act.err = pass.Run()
 
 			if act.err == nil { // resolve URLs on diagnostics.
 				for i := range act.diagnostics {
@@ -370,11 +393,11 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 		})
 		return act
 	}
-	execAll = func(analyzers []*analysis.Analyzer) {
+	execAll = func(analyzers []analysis.IAnalyzer) {
 		var wg sync.WaitGroup
 		for _, a := range analyzers {
 			wg.Add(1)
-			go func(a *analysis.Analyzer) {
+			go func(a analysis.IAnalyzer) {
 				_ = exec(a)
 				wg.Done()
 			}(a)
@@ -402,7 +425,7 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 }
 
 type result struct {
-	a           *analysis.Analyzer
+	a           analysis.IAnalyzer
 	diagnostics []analysis.Diagnostic
 	err         error
 }
@@ -13,11 +13,13 @@ import (
 	"strings"
 	"testing"
 
-	"golang.org/x/tools/gop/analysis/passes/assign"
-	"golang.org/x/tools/gop/analysis/passes/findcall"
-	"golang.org/x/tools/gop/analysis/passes/printf"
+	"golang.org/x/tools/go/analysis/passes/assign"
+	"golang.org/x/tools/go/analysis/passes/findcall"
+	"golang.org/x/tools/go/analysis/passes/printf"
+	"golang.org/x/tools/go/packages/packagestest"
+	gopassign "golang.org/x/tools/gop/analysis/passes/assign"
+	gopprintf "golang.org/x/tools/gop/analysis/passes/printf"
 	"golang.org/x/tools/gop/analysis/unitchecker"
-	"golang.org/x/tools/gop/packages/packagestest"
 )
 
 func TestMain(m *testing.M) {
@@ -42,6 +44,8 @@ func minivet() {
 		findcall.Analyzer,
 		printf.Analyzer,
 		assign.Analyzer,
+		gopprintf.Analyzer, // goxls: Go+ analyzers
+		gopassign.Analyzer,
 	)
 }
 
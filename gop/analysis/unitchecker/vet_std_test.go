@@ -11,33 +11,33 @@ import (
 	"strings"
 	"testing"
 
-	"golang.org/x/tools/gop/analysis/passes/asmdecl"
+	"golang.org/x/tools/go/analysis/passes/asmdecl"
+	"golang.org/x/tools/go/analysis/passes/atomic"
+	"golang.org/x/tools/go/analysis/passes/buildtag"
+	"golang.org/x/tools/go/analysis/passes/cgocall"
+	"golang.org/x/tools/go/analysis/passes/composite"
+	"golang.org/x/tools/go/analysis/passes/directive"
+	"golang.org/x/tools/go/analysis/passes/framepointer"
+	"golang.org/x/tools/go/analysis/passes/httpresponse"
+	"golang.org/x/tools/go/analysis/passes/shift"
+	"golang.org/x/tools/go/analysis/passes/sigchanyzer"
+	"golang.org/x/tools/go/analysis/passes/stdmethods"
+	"golang.org/x/tools/go/analysis/passes/testinggoroutine"
+	"golang.org/x/tools/go/analysis/passes/tests"
+	"golang.org/x/tools/go/analysis/passes/timeformat"
+	"golang.org/x/tools/go/analysis/passes/unreachable"
 	"golang.org/x/tools/gop/analysis/passes/assign"
-	"golang.org/x/tools/gop/analysis/passes/atomic"
 	"golang.org/x/tools/gop/analysis/passes/bools"
-	"golang.org/x/tools/gop/analysis/passes/buildtag"
-	"golang.org/x/tools/gop/analysis/passes/cgocall"
-	"golang.org/x/tools/gop/analysis/passes/composite"
 	"golang.org/x/tools/gop/analysis/passes/copylock"
-	"golang.org/x/tools/gop/analysis/passes/directive"
 	"golang.org/x/tools/gop/analysis/passes/errorsas"
-	"golang.org/x/tools/gop/analysis/passes/framepointer"
-	"golang.org/x/tools/gop/analysis/passes/httpresponse"
 	"golang.org/x/tools/gop/analysis/passes/ifaceassert"
 	"golang.org/x/tools/gop/analysis/passes/loopclosure"
 	"golang.org/x/tools/gop/analysis/passes/lostcancel"
 	"golang.org/x/tools/gop/analysis/passes/nilfunc"
 	"golang.org/x/tools/gop/analysis/passes/printf"
-	"golang.org/x/tools/gop/analysis/passes/shift"
-	"golang.org/x/tools/gop/analysis/passes/sigchanyzer"
-	"golang.org/x/tools/gop/analysis/passes/stdmethods"
 	"golang.org/x/tools/gop/analysis/passes/stringintconv"
 	"golang.org/x/tools/gop/analysis/passes/structtag"
-	"golang.org/x/tools/gop/analysis/passes/testinggoroutine"
-	"golang.org/x/tools/gop/analysis/passes/tests"
-	"golang.org/x/tools/gop/analysis/passes/timeformat"
 	"golang.org/x/tools/gop/analysis/passes/unmarshal"
-	"golang.org/x/tools/gop/analysis/passes/unreachable"
 	"golang.org/x/tools/gop/analysis/passes/unusedresult"
 	"golang.org/x/tools/gop/analysis/unitchecker"
 )
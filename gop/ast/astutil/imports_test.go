@@ -6,13 +6,14 @@ package astutil
 
 import (
 	"bytes"
-	"go/ast"
-	"go/format"
-	"go/parser"
-	"go/token"
 	"reflect"
 	"strconv"
 	"testing"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/format"
+	"github.com/goplus/gop/parser"
+	"github.com/goplus/gop/token"
 )
 
 var fset = token.NewFileSet()
@@ -769,6 +770,32 @@ import f "fmt"
 `,
 		unchanged: true,
 	},
+	// Go+ files without a package clause.
+	{
+		name: "no package decl",
+		pkg:  "fmt",
+		in: `println "hello"
+`,
+		out: `import "fmt"
+
+println "hello"
+`,
+	},
+	{
+		name: "no package decl, existing import",
+		pkg:  "strings",
+		in: `import "fmt"
+
+fmt.Println "hello"
+`,
+		out: `import (
+	"fmt"
+	"strings"
+)
+
+fmt.Println "hello"
+`,
+	},
 }
 
 func TestAddImport(t *testing.T) {
@@ -1680,6 +1707,24 @@ import (
 	"foo.com/surprise"
 	"foo.com/v1"
 )
+`,
+	},
+	// Go+ files without a package clause.
+	{
+		name: "no package decl",
+		pkg:  "os",
+		in: `import (
+	"fmt"
+	"os"
+)
+
+fmt.Println "hello"
+`,
+		out: `import (
+	"fmt"
+)
+
+fmt.Println "hello"
 `,
 	},
 }
@@ -6,11 +6,10 @@ package astutil
 
 import (
 	"fmt"
-	"go/ast"
 	"reflect"
 	"sort"
 
-	"golang.org/x/tools/internal/typeparams"
+	"github.com/goplus/gop/ast"
 )
 
 // An ApplyFunc is invoked by Apply for each node n, even if n is nil,
@@ -84,7 +83,8 @@ func (c *Cursor) Parent() ast.Node { return c.parent }
 
 // Name returns the name of the parent Node field that contains the current Node.
 // If the parent is a *ast.Package and the current Node is a *ast.File, Name returns
-// the filename for the current Node.
+// the filename for the current Node. If the parent is a Go+ string literal
+// *ast.BasicLit with embedded expressions, Name returns "Parts".
 func (c *Cursor) Name() string { return c.name }
 
 // Index reports the index >= 0 of the current Node in the slice of Nodes that
@@ -100,6 +100,10 @@ func (c *Cursor) Index() int {
 
 // field returns the current node's parent field value.
 func (c *Cursor) field() reflect.Value {
+	if lit, ok := c.parent.(*ast.BasicLit); ok && c.name == "Parts" {
+		// goxls: the parts of a Go+ string literal are in lit.Extra
+		return reflect.ValueOf(lit.Extra).Elem().FieldByName("Parts")
+	}
 	return reflect.Indirect(reflect.ValueOf(c.parent)).FieldByName(c.name)
 }
 
@@ -202,7 +206,7 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 	}
 
 	// walk children
-	// (the order of the cases matches the order of the corresponding node types in go/ast)
+	// (the order of the cases matches the order of the corresponding node types in gop/ast)
 	switch n := n.(type) {
 	case nil:
 		// nothing to do
@@ -227,9 +231,14 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 		a.applyList(n, "List")
 
 	// Expressions
-	case *ast.BadExpr, *ast.Ident, *ast.BasicLit:
+	case *ast.BadExpr, *ast.Ident:
 		// nothing to do
 
+	case *ast.BasicLit:
+		if n.Extra != nil {
+			a.applyParts(n)
+		}
+
 	case *ast.Ellipsis:
 		a.apply(n, "Elt", nil, n.Elt)
 
@@ -252,7 +261,7 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 		a.apply(n, "X", nil, n.X)
 		a.apply(n, "Index", nil, n.Index)
 
-	case *typeparams.IndexListExpr:
+	case *ast.IndexListExpr:
 		a.apply(n, "X", nil, n.X)
 		a.applyList(n, "Indices")
 
@@ -293,8 +302,8 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 		a.apply(n, "Fields", nil, n.Fields)
 
 	case *ast.FuncType:
-		if tparams := typeparams.ForFuncType(n); tparams != nil {
-			a.apply(n, "TypeParams", nil, tparams)
+		if n.TypeParams != nil {
+			a.apply(n, "TypeParams", nil, n.TypeParams)
 		}
 		a.apply(n, "Params", nil, n.Params)
 		a.apply(n, "Results", nil, n.Results)
@@ -408,8 +417,8 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 	case *ast.TypeSpec:
 		a.apply(n, "Doc", nil, n.Doc)
 		a.apply(n, "Name", nil, n.Name)
-		if tparams := typeparams.ForTypeSpec(n); tparams != nil {
-			a.apply(n, "TypeParams", nil, tparams)
+		if n.TypeParams != nil {
+			a.apply(n, "TypeParams", nil, n.TypeParams)
 		}
 		a.apply(n, "Type", nil, n.Type)
 		a.apply(n, "Comment", nil, n.Comment)
@@ -422,16 +431,26 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 		a.applyList(n, "Specs")
 
 	case *ast.FuncDecl:
+		if !n.Shadow { // not a shadow entry
+			a.apply(n, "Doc", nil, n.Doc)
+			a.apply(n, "Recv", nil, n.Recv)
+			a.apply(n, "Name", nil, n.Name)
+			a.apply(n, "Type", nil, n.Type)
+		}
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.OverloadFuncDecl:
 		a.apply(n, "Doc", nil, n.Doc)
 		a.apply(n, "Recv", nil, n.Recv)
 		a.apply(n, "Name", nil, n.Name)
-		a.apply(n, "Type", nil, n.Type)
-		a.apply(n, "Body", nil, n.Body)
+		a.applyList(n, "Funcs")
 
 	// Files and packages
 	case *ast.File:
 		a.apply(n, "Doc", nil, n.Doc)
-		a.apply(n, "Name", nil, n.Name)
+		if !n.NoPkgDecl {
+			a.apply(n, "Name", nil, n.Name)
+		}
 		a.applyList(n, "Decls")
 		// Don't walk n.Comments; they have either been walked already if
 		// they are Doc comments, or they can be easily walked explicitly.
@@ -447,6 +466,42 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 			a.apply(n, name, nil, n.Files[name])
 		}
 
+	// Go+ extensions
+	case *ast.SliceLit:
+		a.applyList(n, "Elts")
+
+	case *ast.LambdaExpr:
+		a.applyList(n, "Lhs")
+		a.applyList(n, "Rhs")
+
+	case *ast.LambdaExpr2:
+		a.applyList(n, "Lhs")
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.ForPhrase:
+		a.apply(n, "Key", nil, n.Key)
+		a.apply(n, "Value", nil, n.Value)
+		a.apply(n, "Init", nil, n.Init)
+		a.apply(n, "Cond", nil, n.Cond)
+		a.apply(n, "X", nil, n.X)
+
+	case *ast.ComprehensionExpr:
+		a.apply(n, "Elt", nil, n.Elt)
+		a.applyList(n, "Fors")
+
+	case *ast.ForPhraseStmt:
+		a.apply(n, "ForPhrase", nil, n.ForPhrase)
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.RangeExpr:
+		a.apply(n, "First", nil, n.First)
+		a.apply(n, "Last", nil, n.Last)
+		a.apply(n, "Expr3", nil, n.Expr3)
+
+	case *ast.ErrWrapExpr:
+		a.apply(n, "X", nil, n.X)
+		a.apply(n, "Default", nil, n.Default)
+
 	default:
 		panic(fmt.Sprintf("Apply: unexpected node type %T", n))
 	}
@@ -486,3 +541,18 @@ func (a *application) applyList(parent ast.Node, name string) {
 	}
 	a.iter = saved
 }
+
+// applyParts applies to the expression parts of a Go+ string literal,
+// such as "Hello ${name}". The string parts are skipped.
+func (a *application) applyParts(lit *ast.BasicLit) {
+	saved := a.iter
+	a.iter.index = 0
+	for a.iter.index < len(lit.Extra.Parts) {
+		a.iter.step = 1
+		if x, ok := lit.Extra.Parts[a.iter.index].(ast.Expr); ok {
+			a.apply(lit, "Parts", &a.iter, x)
+		}
+		a.iter.index += a.iter.step
+	}
+	a.iter = saved
+}
@@ -6,18 +6,19 @@ package astutil_test
 
 import (
 	"bytes"
-	"go/ast"
-	"go/format"
-	"go/parser"
-	"go/token"
+	"strings"
 	"testing"
 
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/format"
+	"github.com/goplus/gop/parser"
+	"github.com/goplus/gop/token"
 	"golang.org/x/tools/gop/ast/astutil"
-	"golang.org/x/tools/internal/typeparams"
 )
 
 type rewriteTest struct {
 	name       string
+	filename   string // default "a.gop"
 	orig, want string
 	pre, post  astutil.ApplyFunc
 }
@@ -193,44 +194,180 @@ var z int
 	},
 }
 
-func init() {
-	if typeparams.Enabled {
-		rewriteTests = append(rewriteTests, rewriteTest{
-			name: "replace",
-			orig: `package p
+// Go+ specific nodes.
+var gopRewriteTests = []rewriteTest{
+	{name: "lambda",
+		orig: `package p
+
+func f() {
+	g(x => x * 2)
+	h((a, b) => {
+		return a + b
+	})
+}
+`,
+		want: `package p
+
+func f() {
+	g(y => y * 2)
+	h((a, c) => {
+		return a + c
+	})
+}
+`,
+		post: rename(map[string]string{"x": "y", "b": "c"}),
+	},
+
+	{name: "comprehension",
+		orig: `package p
+
+func f(s []int) {
+	a := [x*x for x <- s if x > 0]
+	b := {k: v for k, v <- m if v > 0}
+	println a, b
+}
+`,
+		want: `package p
+
+func f(s []int) {
+	a := [e*e for e <- s if e > 0]
+	b := {k: u for k, u <- m if u > 0}
+	println a, b
+}
+`,
+		post: rename(map[string]string{"x": "e", "v": "u"}),
+	},
+
+	{name: "range expr",
+		orig: `package p
+
+func f() {
+	for i <- 1:10 {
+		println i
+	}
+	for i <- :5:2 {
+		println [1, 2, 3]
+	}
+}
+`,
+		want: `package p
 
-type T[P1, P2 any] int
+func f() {
+	for i <- 0:10 {
+		println i
+	}
+	for i <- :5:0 {
+		println [0, 0, 0]
+	}
+}
+`,
+		post: func(c *astutil.Cursor) bool {
+			if lit, ok := c.Node().(*ast.BasicLit); ok && lit.Kind == token.INT && lit.Value != "10" && lit.Value != "5" {
+				c.Replace(&ast.BasicLit{Kind: token.INT, Value: "0"})
+			}
+			return true
+		},
+	},
 
-type R T[int, string]
+	{name: "error wrap",
+		orig: `package p
 
-func F[Q1 any](q Q1) {}
+func f() {
+	a := g()?:0
+	b := g()!
+	println a, b
+}
 `,
-			// TODO: note how the rewrite adds a trailing comma in "func F".
-			// Is that a bug in the test, or in astutil.Apply?
-			want: `package p
+		want: `package p
 
-type S[R1, P2 any] int32
+func f() {
+	a := h()?:0
+	b := h()!
+	println a, b
+}
+`,
+		post: rename(map[string]string{"g": "h"}),
+	},
 
-type R S[int32, string]
+	{name: "overload",
+		orig: `package p
 
-func F[X1 any](q X1,) {}
+func add = (
+	func(a, b int) int {
+		return a + b
+	}
+	func(a, b string) string {
+		return a + b
+	}
+)
 `,
-			post: func(c *astutil.Cursor) bool {
-				if ident, ok := c.Node().(*ast.Ident); ok {
-					switch ident.Name {
-					case "int":
-						c.Replace(ast.NewIdent("int32"))
-					case "T":
-						c.Replace(ast.NewIdent("S"))
-					case "P1":
-						c.Replace(ast.NewIdent("R1"))
-					case "Q1":
-						c.Replace(ast.NewIdent("X1"))
-					}
+		want: `package p
+
+func plus = (
+	func(a, b int) int {
+		return a + b
+	}
+	func(a, b string) string {
+		return a + b
+	}
+)
+`,
+		post: rename(map[string]string{"add": "plus"}),
+	},
+
+	{name: "shadow entry",
+		orig: `x := 1
+println x
+`,
+		want: `y := 1
+println y
+`,
+		pre: func(c *astutil.Cursor) bool {
+			if c.Name() == "Name" {
+				if _, ok := c.Parent().(*ast.File); ok {
+					panic("Apply: unexpected package name")
 				}
-				return true
-			},
-		})
+			}
+			return true
+		},
+		post: rename(map[string]string{"x": "y"}),
+	},
+
+	{name: "classfile",
+		filename: "Rect.gox",
+		orig: `var (
+	w, h int
+)
+
+func Area() int {
+	return w * h
+}
+
+println Area()
+`,
+		want: `var (
+	width, height int
+)
+
+func Area() int {
+	return width * height
+}
+
+println Area()
+`,
+		post: rename(map[string]string{"w": "width", "h": "height"}),
+	},
+}
+
+// rename returns an ApplyFunc that renames the identifiers in names.
+func rename(names map[string]string) astutil.ApplyFunc {
+	return func(c *astutil.Cursor) bool {
+		if ident, ok := c.Node().(*ast.Ident); ok {
+			if name, ok := names[ident.Name]; ok {
+				c.Replace(ast.NewIdent(name))
+			}
+		}
+		return true
 	}
 }
 
@@ -247,14 +384,22 @@ func vardecl(name, typ string) *ast.GenDecl {
 	}
 }
 
+func parseTest(fset *token.FileSet, test rewriteTest) (*ast.File, error) {
+	filename := test.filename
+	if filename == "" {
+		filename = "a.gop"
+	}
+	return parser.ParseEntry(fset, filename, test.orig, parser.Config{Mode: parser.ParseComments})
+}
+
 func TestRewrite(t *testing.T) {
 	t.Run("*", func(t *testing.T) {
-		for _, test := range rewriteTests {
+		for _, test := range append(rewriteTests, gopRewriteTests...) {
 			test := test
 			t.Run(test.name, func(t *testing.T) {
 				t.Parallel()
 				fset := token.NewFileSet()
-				f, err := parser.ParseFile(fset, test.name, test.orig, parser.ParseComments)
+				f, err := parseTest(fset, test)
 				if err != nil {
 					t.Fatal(err)
 				}
@@ -272,6 +417,49 @@ func TestRewrite(t *testing.T) {
 	})
 }
 
+// The printer doesn't print the parts of a Go+ string literal,
+// so they are checked separately.
+func TestRewriteStringParts(t *testing.T) {
+	const src = `package p
+
+func f(name string) {
+	println "Hello ${name}, ${name}!"
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "a.gop", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lit *ast.BasicLit
+	astutil.Apply(f, nil, func(c *astutil.Cursor) bool {
+		if ident, ok := c.Node().(*ast.Ident); ok && ident.Name == "name" {
+			if l, ok := c.Parent().(*ast.BasicLit); ok && c.Name() == "Parts" {
+				lit = l
+				c.Replace(ast.NewIdent("who"))
+			}
+		}
+		return true
+	})
+	if lit == nil {
+		t.Fatal("Apply didn't visit the parts of the string literal")
+	}
+	var got []string
+	for _, part := range lit.Extra.Parts {
+		switch part := part.(type) {
+		case string:
+			got = append(got, part)
+		case *ast.Ident:
+			got = append(got, "${"+part.Name+"}")
+		default:
+			t.Fatalf("unexpected part %T", part)
+		}
+	}
+	if want := "Hello ${who}, ${who}!"; strings.Join(got, "") != want {
+		t.Errorf("got %q, want %q", strings.Join(got, ""), want)
+	}
+}
+
 var sink ast.Node
 
 func BenchmarkRewrite(b *testing.B) {
@@ -280,7 +468,7 @@ func BenchmarkRewrite(b *testing.B) {
 			for i := 0; i < b.N; i++ {
 				b.StopTimer()
 				fset := token.NewFileSet()
-				f, err := parser.ParseFile(fset, test.name, test.orig, parser.ParseComments)
+				f, err := parseTest(fset, test)
 				if err != nil {
 					b.Fatal(err)
 				}
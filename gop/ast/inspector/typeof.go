@@ -32,6 +32,7 @@ const (
 	nComment
 	nCommentGroup
 	nCompositeLit
+	nComprehensionExpr
 	nDeclStmt
 	nDeferStmt
 	nEllipsis
@@ -40,6 +41,7 @@ const (
 	nField
 	nFieldList
 	nFile
+	nForPhraseStmt
 	nForStmt
 	nFuncDecl
 	nFuncLit
@@ -55,6 +57,8 @@ const (
 	nInterfaceType
 	nKeyValueExpr
 	nLabeledStmt
+	nLambdaExpr
+	nLambdaExpr2
 	nMapType
 	nPackage
 	nParenExpr
@@ -132,6 +136,8 @@ func typeOf(n ast.Node) uint64 {
 		return 1 << nCommentGroup
 	case *ast.CompositeLit:
 		return 1 << nCompositeLit
+	case *ast.ComprehensionExpr:
+		return 1 << nComprehensionExpr
 	case *ast.DeclStmt:
 		return 1 << nDeclStmt
 	case *ast.DeferStmt:
@@ -148,6 +154,8 @@ func typeOf(n ast.Node) uint64 {
 		return 1 << nFieldList
 	case *ast.File:
 		return 1 << nFile
+	case *ast.ForPhraseStmt:
+		return 1 << nForPhraseStmt
 	case *ast.ForStmt:
 		return 1 << nForStmt
 	case *ast.FuncDecl:
@@ -178,6 +186,10 @@ func typeOf(n ast.Node) uint64 {
 		return 1 << nKeyValueExpr
 	case *ast.LabeledStmt:
 		return 1 << nLabeledStmt
+	case *ast.LambdaExpr:
+		return 1 << nLambdaExpr
+	case *ast.LambdaExpr2:
+		return 1 << nLambdaExpr2
 	case *ast.MapType:
 		return 1 << nMapType
 	case *ast.Package:
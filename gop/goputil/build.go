@@ -0,0 +1,72 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goputil
+
+import (
+	"bufio"
+	"bytes"
+	"go/build"
+	"io"
+	"path"
+	"strings"
+)
+
+// MatchFile reports whether the Go+ file fname in dir, with contents src,
+// matches the build context ctxt: its //go:build (or // +build)
+// constraints are evaluated as for a Go file, and so are the GOOS/GOARCH
+// filename suffixes of a .gop file. The name of a classfile is the name
+// of its class, such as Player_js in Player_js.spx, so it has no such
+// suffixes.
+func MatchFile(ctxt *build.Context, dir, fname string, src []byte) bool {
+	c := *ctxt
+	c.OpenFile = func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(gopHeader(src))), nil
+	}
+	name := "classfile.go"
+	if ext := path.Ext(fname); ext == ".gop" {
+		name = strings.TrimSuffix(fname, ext) + ".go"
+	}
+	match, err := c.MatchFile(dir, name)
+	return err == nil && match
+}
+
+// gopHeader returns the leading blank lines and line comments of a Go+
+// file, which may contain build constraints, followed by a package clause.
+// Unlike a Go file, a Go+ file may have no package clause at all.
+func gopHeader(src []byte) []byte {
+	var buf bytes.Buffer
+	s := bufio.NewScanner(bytes.NewReader(src))
+	for s.Scan() {
+		line := s.Bytes()
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 && !bytes.HasPrefix(trimmed, []byte("//")) {
+			break
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("package p\n")
+	return buf.Bytes()
+}
+
+// BuildTags returns the build tags of the -tags flags in flags, such as
+// the fields of GOFLAGS or the build flags of a go command.
+func BuildTags(flags []string) (tags []string) {
+	for i := 0; i < len(flags); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(flags[i], "-"), "=")
+		if name != "-tags" && name != "tags" {
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(flags) {
+				break
+			}
+			i++
+			value = flags[i]
+		}
+		// the last one wins
+		tags = strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	return
+}
@@ -0,0 +1,24 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goputil
+
+import "testing"
+
+func TestGopHeader(t *testing.T) {
+	for _, test := range []struct {
+		name, src, want string
+	}{
+		{"empty", "", "package p\n"},
+		{"package clause", "package a\n\n//go:build foo\n", "package p\n"},
+		{"constraint", "//go:build foo\n\npackage a\n", "//go:build foo\n\npackage p\n"},
+		{"plus build", "// Copyright\n\n// +build foo\n\nprintln 1\n", "// Copyright\n\n// +build foo\n\npackage p\n"},
+		{"indented", "  //go:build foo\nvar x int\n", "  //go:build foo\npackage p\n"},
+		{"block comment", "/* //go:build foo */\n", "package p\n"},
+	} {
+		if got := string(gopHeader([]byte(test.src))); got != test.want {
+			t.Errorf("%s: gopHeader(%q) = %q, want %q", test.name, test.src, got, test.want)
+		}
+	}
+}
@@ -4,6 +4,13 @@
 
 package goputil
 
+import (
+	"path"
+	"sort"
+
+	"github.com/goplus/mod/gopmod"
+)
+
 type Kind int
 
 const (
@@ -25,3 +32,38 @@ func FileKind(fext string) Kind {
 func Exts() string {
 	return "gop,spx,rdx,gox,gmx"
 }
+
+// FileKindOf returns the kind of file fname in module mod. Besides the
+// builtin extensions reported by FileKind, it recognizes the classfiles
+// registered by mod (in gop.mod or by the modules it depends on).
+// mod may be nil.
+func FileKindOf(mod *gopmod.Module, fname string) Kind {
+	if mod != nil {
+		if _, ok := mod.ClassKind(fname); ok {
+			return FileGopClass
+		}
+	}
+	return FileKind(path.Ext(fname))
+}
+
+// ImportClasses imports the classfiles registered by mod and returns the
+// sorted file extensions, without the leading dot, that they add to Exts.
+// Classfiles imported before an error occurs are still reported.
+func ImportClasses(mod *gopmod.Module) (exts []string, err error) {
+	seen := make(map[string]bool)
+	add := func(ext string) {
+		ext = path.Ext(ext) // "_yap.gox" => ".gox"
+		if ext != "" && FileKind(ext) == FileUnknown && !seen[ext] {
+			seen[ext] = true
+			exts = append(exts, ext[1:])
+		}
+	}
+	err = mod.ImportClasses(func(c *gopmod.Project) {
+		add(c.Ext)
+		for _, w := range c.Works {
+			add(w.Ext)
+		}
+	})
+	sort.Strings(exts)
+	return
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goputil_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/goplus/mod/gopmod"
+	"golang.org/x/tools/gop/goputil"
+)
+
+func TestModClassfiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.18\n",
+		"gop.mod": `gop 1.2
+
+project .tdsl Game example.com/m/tdsl
+class .tspr Sprite
+
+project _yap.gox App example.com/m/yap
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mod, err := gopmod.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exts, err := goputil.ImportClasses(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"gsh", "tdsl", "tspr"}; !reflect.DeepEqual(exts, want) {
+		t.Errorf("ImportClasses = %v, want %v", exts, want)
+	}
+
+	for _, test := range []struct {
+		fname string
+		want  goputil.Kind
+	}{
+		{"main.tdsl", goputil.FileGopClass},
+		{"hero.tspr", goputil.FileGopClass},
+		{"index_yap.gox", goputil.FileGopClass},
+		{"util.gop", goputil.FileGopNormal},
+		{"main.spx", goputil.FileGopClass},
+		{"main.go", goputil.FileUnknown},
+		{"notes.txt", goputil.FileUnknown},
+	} {
+		if got := goputil.FileKindOf(mod, test.fname); got != test.want {
+			t.Errorf("FileKindOf(%q) = %v, want %v", test.fname, got, test.want)
+		}
+	}
+	if got := goputil.FileKindOf(nil, "main.tdsl"); got != goputil.FileUnknown {
+		t.Errorf("FileKindOf(nil, main.tdsl) = %v, want FileUnknown", got)
+	}
+}
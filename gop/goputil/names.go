@@ -0,0 +1,111 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goputil
+
+// This file defines helpers for the names by which Go+ code refers to
+// objects, shared by the renaming tools.
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/gop/ast/astutil"
+)
+
+// ToStartWithLowerCase returns the lowercase form of an exported name, by
+// which Go+ may refer to it (println for Println), and reports whether
+// name is exported.
+func ToStartWithLowerCase(name string) (string, bool) {
+	if c := name[0]; c >= 'A' && c <= 'Z' {
+		return string(c+('a'-'A')) + name[1:], true
+	}
+	return name, false
+}
+
+// OverloadName returns the name of the overloaded function of which a
+// function named name, such as Foo__0 or Foo__a, is an overload, and
+// reports whether it is one.
+func OverloadName(name string) (string, bool) {
+	n := len(name) - 3
+	if n > 0 && name[n] == '_' && name[n+1] == '_' {
+		if c := name[n+2]; c >= '0' && c <= '9' || c >= 'a' && c <= 'z' {
+			return name[:n], true
+		}
+	}
+	return name, false
+}
+
+// DocComment returns the doc comment of the identifier id declared in
+// file, of token file tf.
+func DocComment(tf *token.File, file *ast.File, id *ast.Ident) *ast.CommentGroup {
+	nodes, _ := astutil.PathEnclosingInterval(file, id.Pos(), id.End())
+	for _, node := range nodes {
+		switch decl := node.(type) {
+		case *ast.FuncDecl:
+			return decl.Doc
+		case *ast.Field:
+			return decl.Doc
+		case *ast.GenDecl:
+			return decl.Doc
+		// For {Type,Value}Spec, if the doc on the spec is absent,
+		// search for the enclosing GenDecl
+		case *ast.TypeSpec:
+			if decl.Doc != nil {
+				return decl.Doc
+			}
+		case *ast.ValueSpec:
+			if decl.Doc != nil {
+				return decl.Doc
+			}
+		case *ast.Ident:
+		case *ast.AssignStmt:
+			// *ast.AssignStmt doesn't have an associated comment group.
+			// So, we try to find a comment just before the identifier.
+
+			// Try to find a comment group only for short variable declarations (:=).
+			if decl.Tok != token.DEFINE {
+				return nil
+			}
+
+			identLine := tf.Line(id.Pos())
+			for _, comment := range nodes[len(nodes)-1].(*ast.File).Comments {
+				if comment.Pos() > id.Pos() {
+					// Comment is after the identifier.
+					continue
+				}
+
+				lastCommentLine := tf.Line(comment.End())
+				if lastCommentLine+1 == identLine {
+					return comment
+				}
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// NameRefs returns the positions of the occurrences of name as a whole
+// word in comment c, of token file tf.
+func NameRefs(tf *token.File, c *ast.Comment, name string) []token.Pos {
+	// The parser strips out \r of \r\n line endings from the comment
+	// text, so the positions are computed line by line.
+	re := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	var refs []token.Pos
+	line := tf.Line(c.Pos())
+	for i, text := range strings.Split(c.Text, "\n") {
+		start := c.Pos()
+		if i > 0 {
+			start = tf.LineStart(line + i)
+		}
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			refs = append(refs, start+token.Pos(loc[0]))
+		}
+	}
+	return refs
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goputil_test
+
+import (
+	"testing"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/parser"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/gop/goputil"
+)
+
+func TestToStartWithLowerCase(t *testing.T) {
+	for _, test := range []struct {
+		name, want string
+		exported   bool
+	}{
+		{"Println", "println", true},
+		{"X", "x", true},
+		{"println", "println", false},
+		{"_Foo", "_Foo", false},
+	} {
+		got, exported := goputil.ToStartWithLowerCase(test.name)
+		if got != test.want || exported != test.exported {
+			t.Errorf("ToStartWithLowerCase(%q) = %q, %v, want %q, %v", test.name, got, exported, test.want, test.exported)
+		}
+	}
+}
+
+func TestOverloadName(t *testing.T) {
+	for _, test := range []struct {
+		name, want string
+		overload   bool
+	}{
+		{"Foo__0", "Foo", true},
+		{"foo__a", "foo", true},
+		{"Foo__A", "Foo__A", false},
+		{"Foo__", "Foo__", false},
+		{"Foo_0", "Foo_0", false},
+		{"__0", "__0", false},
+		{"Foo", "Foo", false},
+	} {
+		got, overload := goputil.OverloadName(test.name)
+		if got != test.want || overload != test.overload {
+			t.Errorf("OverloadName(%q) = %q, %v, want %q, %v", test.name, got, overload, test.want, test.overload)
+		}
+	}
+}
+
+func TestDocCommentAndNameRefs(t *testing.T) {
+	const src = `package a
+
+// foo returns foo, not foobar.
+// See also foo.
+func foo() int { return 0 }
+
+func bar() {
+	// x is foo.
+	x := foo()
+	println x
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "a.gop", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tf := fset.File(f.Pos())
+
+	var fooDecl, xDecl *ast.Ident
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Name.Name == "foo" {
+				fooDecl = n.Name
+			}
+		case *ast.AssignStmt:
+			xDecl = n.Lhs[0].(*ast.Ident)
+		}
+		return true
+	})
+
+	doc := goputil.DocComment(tf, f, fooDecl)
+	if doc == nil {
+		t.Fatal("DocComment(foo) = nil")
+	}
+	var got []string
+	for _, c := range doc.List {
+		for _, pos := range goputil.NameRefs(tf, c, "foo") {
+			got = append(got, fset.Position(pos).String())
+		}
+	}
+	want := []string{"a.gop:3:4", "a.gop:3:16", "a.gop:4:13"}
+	if len(got) != len(want) {
+		t.Fatalf("NameRefs(foo) = %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("NameRefs(foo) = %v, want %v", got, want)
+			break
+		}
+	}
+
+	if doc := goputil.DocComment(tf, f, xDecl); doc == nil || doc.Text() != "x is foo.\n" {
+		t.Errorf("DocComment(x) = %v, want the comment above x", doc)
+	}
+}
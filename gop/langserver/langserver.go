@@ -6,31 +6,82 @@ package langserver
 
 import (
 	"context"
+	"fmt"
+	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime/debug"
 	"strings"
 	"sync"
 
+	"github.com/goplus/gop"
 	"github.com/goplus/gop/x/langserver"
+	"golang.org/x/mod/semver"
 )
 
+// Mode specifies how Go+ code (gop_autogen.go) is generated.
+type Mode int
+
 const (
-	Enable = true
+	// ModeServe generates Go+ code by an external `gop serve` process.
+	ModeServe Mode = iota
+	// ModeInProcess generates Go+ code in-process, by the gop library
+	// that this module is built with.
+	ModeInProcess
 )
 
+// ParseMode parses the name of a mode: "serve" or "inprocess".
+func ParseMode(name string) (Mode, error) {
+	switch name {
+	case "", "serve":
+		return ModeServe, nil
+	case "inprocess":
+		return ModeInProcess, nil
+	}
+	return ModeServe, fmt.Errorf("invalid gengo mode %q (want serve or inprocess)", name)
+}
+
+func (m Mode) String() string {
+	switch m {
+	case ModeServe:
+		return "serve"
+	case ModeInProcess:
+		return "inprocess"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
 var (
-	ls langserver.Client
+	mode        = ModeServe
+	initialized bool
+
+	ls       langserver.Client
+	checkErr error // result of the version handshake with the external server
+
+	inproc = &inProcess{dirty: make(map[string]none)}
 )
 
+// SetMode sets how Go+ code is generated. It must be called before
+// Initialize or any other function of this package.
+func SetMode(m Mode) {
+	mode = m
+}
+
+// InProcess reports whether Go+ code is generated in-process.
+func InProcess() bool {
+	return mode == ModeInProcess
+}
+
 func Initialize() {
-	if Enable {
+	initialized = true
+	if mode == ModeServe {
 		go Get()
 	}
 }
 
 func Shutdown() {
-	if Enable {
+	if mode == ModeServe {
 		Get().Close()
 	}
 }
@@ -62,21 +113,142 @@ func lookupCmd(cmd string) string {
 func Get() langserver.Client {
 	onceInit.Do(func() {
 		cmd := lookupCmd("gop")
+		checkErr = checkVersion(cmd)
+		if checkErr != nil {
+			log.Println("langserver:", checkErr)
+		}
 		ls = langserver.ServeAndDial(nil, cmd, "serve", "-v")
 	})
 	return ls
 }
 
+// Err returns the result of the version handshake with the external
+// `gop serve` process: an error if its version doesn't match the gop
+// library this module is built with. It returns nil if Go+ code is
+// generated in-process or Initialize was not called.
+func Err() error {
+	if mode != ModeServe || !initialized {
+		return nil
+	}
+	Get()
+	return checkErr
+}
+
+// checkVersion compares the version of the gop command with the version
+// of the gop library, ignoring the patch version.
+func checkVersion(cmd string) error {
+	want := libVersion()
+	if want == "" {
+		return nil // unknown, e.g. in tests
+	}
+	out, err := exec.Command(cmd, "env", "GOPVERSION").Output()
+	if err != nil {
+		return fmt.Errorf("can't get version of %s: %v", cmd, err)
+	}
+	got := strings.TrimSpace(string(out))
+	if !semver.IsValid(got) || semver.MajorMinor(got) != semver.MajorMinor(want) {
+		return fmt.Errorf("version of %s is %s, but %s is required", cmd, got, semver.MajorMinor(want)+".x")
+	}
+	return nil
+}
+
+// libVersion returns the version of the gop library, or "" if unknown.
+func libVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "github.com/goplus/gop" {
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			if semver.IsValid(dep.Version) {
+				return dep.Version
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
 func GenGo(ctx context.Context, pattern ...string) error {
-	if Enable {
+	if mode == ModeServe {
 		return Get().GenGo(ctx, pattern...)
 	}
-	return langserver.GenGo(pattern...)
+	return genGoInProcess(pattern...)
 }
 
 func Changed(ctx context.Context, files ...string) error {
-	if Enable {
+	switch mode {
+	case ModeServe:
 		return Get().Changed(ctx, files...)
+	case ModeInProcess:
+		inproc.changed(files)
 	}
 	return nil
 }
+
+// genGoInProcess generates Go+ code of the packages in pattern by the gop
+// library. The library panics on some errors, such as a missing GOPROOT;
+// these are reported as errors.
+func genGoInProcess(pattern ...string) (err error) {
+	defer func() {
+		if x := recover(); x != nil {
+			err = fmt.Errorf("gengo %v: %v", pattern, x)
+		}
+	}()
+	return langserver.GenGo(pattern...)
+}
+
+type none = struct{}
+
+// inProcess regenerates Go+ code of the directories of changed files,
+// like the runLoop of `gop serve`.
+type inProcess struct {
+	mutex   sync.Mutex
+	dirty   map[string]none
+	running bool
+}
+
+func (p *inProcess) changed(files []string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, file := range files {
+		p.dirty[filepath.Dir(file)] = none{}
+	}
+	if !p.running {
+		p.running = true
+		go p.runLoop()
+	}
+}
+
+func (p *inProcess) runLoop() {
+	for {
+		var dir string
+		p.mutex.Lock()
+		for dir = range p.dirty {
+			delete(p.dirty, dir)
+			break
+		}
+		if dir == "" {
+			p.running = false
+			p.mutex.Unlock()
+			return
+		}
+		p.mutex.Unlock()
+		genGoDir(dir)
+	}
+}
+
+func genGoDir(dir string) {
+	defer func() {
+		if x := recover(); x != nil {
+			log.Println("langserver: gengo", dir+":", x)
+		}
+	}()
+	if _, _, err := gop.GenGoEx(dir, nil, true, gop.GenFlagPrompt); err != nil {
+		log.Println("langserver: gengo", dir+":", err)
+	}
+}
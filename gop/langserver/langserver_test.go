@@ -0,0 +1,71 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package langserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/goplus/gop/x/gopprojs"
+)
+
+func TestParseMode(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		want    Mode
+		wantErr bool
+	}{
+		{"", ModeServe, false},
+		{"serve", ModeServe, false},
+		{"inprocess", ModeInProcess, false},
+		{"InProcess", ModeServe, true},
+		{"gop", ModeServe, true},
+	} {
+		got, err := ParseMode(test.name)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseMode(%q): got error %v, want error %t", test.name, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseMode(%q) = %v, want %v", test.name, got, test.want)
+		}
+		if err == nil && test.name != "" && got.String() != test.name {
+			t.Errorf("ParseMode(%q).String() = %q", test.name, got.String())
+		}
+	}
+}
+
+// setMode sets the mode for the duration of the test.
+func setMode(t *testing.T, m Mode) {
+	old := mode
+	SetMode(m)
+	t.Cleanup(func() { SetMode(old) })
+}
+
+func TestSetMode(t *testing.T) {
+	setMode(t, ModeInProcess)
+	if !InProcess() {
+		t.Errorf("InProcess() = false after SetMode(ModeInProcess)")
+	}
+	// The version of an external gop command doesn't matter in-process.
+	if err := Err(); err != nil {
+		t.Errorf("Err() = %v in-process, want nil", err)
+	}
+
+	SetMode(ModeServe)
+	if InProcess() {
+		t.Errorf("InProcess() = true after SetMode(ModeServe)")
+	}
+}
+
+func TestGenGoInProcessError(t *testing.T) {
+	setMode(t, ModeInProcess)
+	// A pattern mixing a directory and files is invalid.
+	err := GenGo(context.Background(), "./a", "example.com/b.gop")
+	if !errors.Is(err, gopprojs.ErrMixedFilesProj) {
+		t.Errorf("GenGo: got error %v, want %v", err, gopprojs.ErrMixedFilesProj)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"go/build"
+	"os"
+	"strings"
+
+	"golang.org/x/tools/gop/goputil"
+)
+
+// buildContext returns the build context used to select Go+ files:
+// build.Default with GOOS, GOARCH, CGO_ENABLED and GOPATH taken from
+// cfg.Env, and build tags taken from GOFLAGS and cfg.BuildFlags, as the go
+// command does for Go files.
+func buildContext(cfg *Config) *build.Context {
+	ctxt := build.Default
+	env := cfg.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	var goflags string
+	for _, kv := range env { // the last one wins
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		switch k {
+		case "GOOS":
+			ctxt.GOOS = v
+		case "GOARCH":
+			ctxt.GOARCH = v
+		case "CGO_ENABLED":
+			ctxt.CgoEnabled = v == "1"
+		case "GOFLAGS":
+			goflags = v
+		case "GOPATH":
+			ctxt.GOPATH = v
+		}
+	}
+	ctxt.BuildTags = goputil.BuildTags(append(strings.Fields(goflags), cfg.BuildFlags...))
+	return &ctxt
+}
+
+// matchFile reports whether the Go+ file fname in dir, with contents src,
+// matches the build context of the loader.
+func (ld *loader) matchFile(dir, fname string, src []byte) bool {
+	return goputil.MatchFile(ld.build, dir, fname, src)
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuildContext(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		env        []string
+		buildFlags []string
+		goos       string
+		goarch     string
+		cgo        bool
+		tags       []string
+	}{
+		{"env", []string{"GOOS=windows", "GOARCH=arm64", "CGO_ENABLED=1"}, nil, "windows", "arm64", true, nil},
+		{"last wins", []string{"GOOS=windows", "GOOS=plan9", "CGO_ENABLED=1", "CGO_ENABLED=0"}, nil, "plan9", "amd64", false, nil},
+		{"GOFLAGS", []string{"GOFLAGS=-mod=mod -tags=foo,bar"}, nil, "linux", "amd64", false, []string{"foo", "bar"}},
+		{"build flags", nil, []string{"-tags", "foo bar"}, "linux", "amd64", false, []string{"foo", "bar"}},
+		{"build flags win", []string{"GOFLAGS=-tags=foo"}, []string{"--tags=bar"}, "linux", "amd64", false, []string{"bar"}},
+		{"missing tags", nil, []string{"-tags"}, "linux", "amd64", false, nil},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			env := append([]string{"GOOS=linux", "GOARCH=amd64", "CGO_ENABLED=0", "GOFLAGS="}, test.env...)
+			ctxt := buildContext(&Config{Env: env, BuildFlags: test.buildFlags})
+			if ctxt.GOOS != test.goos || ctxt.GOARCH != test.goarch || ctxt.CgoEnabled != test.cgo || !reflect.DeepEqual(ctxt.BuildTags, test.tags) {
+				t.Errorf("buildContext(%q, %q) = GOOS=%s GOARCH=%s cgo=%t tags=%q, want GOOS=%s GOARCH=%s cgo=%t tags=%q",
+					test.env, test.buildFlags, ctxt.GOOS, ctxt.GOARCH, ctxt.CgoEnabled, ctxt.BuildTags,
+					test.goos, test.goarch, test.cgo, test.tags)
+			}
+		})
+	}
+}
+
+func TestMatchFile(t *testing.T) {
+	dir := t.TempDir()
+	conf := &Config{
+		Dir:        dir,
+		Env:        []string{"GOOS=linux", "GOARCH=amd64", "CGO_ENABLED=0", "GOFLAGS="},
+		BuildFlags: []string{"-tags=foo"},
+	}
+	ld := newTestLoader(conf)
+	for _, test := range []struct {
+		fname, src string
+		want       bool
+	}{
+		{"a.gop", "package a\n", true},
+		{"a_linux.gop", "package a\n", true},
+		{"a_windows.gop", "package a\n", false},
+		{"a_linux_amd64.gop", "package a\n", true},
+		{"a_linux_arm64.gop", "package a\n", false},
+		{"Rect_windows.gox", "var x int\n", true}, // a class name
+		{"Player_js.spx", "var x int\n", true},
+		{"Map_arm.gox", "var x int\n", true},
+		{"Rect.gox", "//go:build windows\n\nvar x int\n", false},
+		{"Rect.gox", "//go:build linux\n\nvar x int\n", true},
+		{"a.gop", "//go:build foo\n\npackage a\n", true},
+		{"a.gop", "//go:build !foo\n\npackage a\n", false},
+		{"a.gop", "//go:build linux && !cgo\n\npackage a\n", true},
+		{"a.gop", "// +build windows\n\npackage a\n", false},
+		{"a.gop", "//go:build ignore\n\nprintln \"no package clause\"\n", false},
+		{"a.gop", "package a\n\n//go:build ignore\n", true}, // not a constraint
+		{"a.gop", "//go:build (\n\npackage a\n", false},     // invalid constraint
+	} {
+		if got := ld.matchFile(dir, test.fname, []byte(test.src)); got != test.want {
+			t.Errorf("matchFile(%s, %q) = %t, want %t", test.fname, test.src, got, test.want)
+		}
+	}
+}
+
+// TestNongenPkgConstraints checks that the Go+ files of a package that
+// don't match the build context are ignored, in files on disk as well as
+// in the overlay.
+func TestNongenPkgConstraints(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":          "module example.com/m\n\ngo 1.18\n",
+		"a/a.gop":         "package a\n",
+		"a/a_windows.gop": "package a\n",
+		"a/b.gop":         "//go:build !linux\n\npackage a\n",
+		"b/b.gop":         "//go:build ignore\n\npackage b\n",
+	})
+	for _, test := range []struct {
+		name    string
+		dir     string
+		overlay map[string]string
+		wantGop []string // nil for no package
+		wantIgn []string
+	}{
+		{"disk", "a", nil, []string{"a/a.gop"}, []string{"a/a_windows.gop", "a/b.gop"}},
+		{"overlay", "a", map[string]string{"a/b.gop": "//go:build linux\n\npackage a\n", "a/c.gop": "// +build ignore\n\npackage a\n"},
+			[]string{"a/a.gop", "a/b.gop"}, []string{"a/a_windows.gop", "a/c.gop"}},
+		{"all ignored", "b", nil, nil, nil},
+		{"overlay only", "c", map[string]string{"c/c.gop": "//go:build foo\n\npackage c\n", "c/d.gop": "//go:build !foo\n\npackage c\n"},
+			[]string{"c/c.gop"}, []string{"c/d.gop"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			conf := &Config{
+				Dir:        dir,
+				Mode:       NeedName | NeedFiles,
+				Env:        []string{"GOOS=linux", "GOARCH=amd64", "CGO_ENABLED=0", "GOFLAGS="},
+				BuildFlags: []string{"-tags=foo"},
+				Overlay:    overlay(dir, test.overlay),
+			}
+			ld := newTestLoader(conf)
+			pkg := ld.nongenPkg(conf, filepath.Join(dir, test.dir))
+			if pkg == nil {
+				if test.wantGop != nil {
+					t.Errorf("nongenPkg(%s) = nil, want Go+ files %q", test.dir, test.wantGop)
+				}
+				return
+			}
+			rel := func(files []string) (ret []string) {
+				for _, file := range files {
+					rel, _ := filepath.Rel(dir, file)
+					ret = append(ret, filepath.ToSlash(rel))
+				}
+				return
+			}
+			gopFiles, ignored := rel(pkg.GopFiles), rel(pkg.IgnoredFiles)
+			if !reflect.DeepEqual(gopFiles, test.wantGop) || !reflect.DeepEqual(ignored, test.wantIgn) {
+				t.Errorf("nongenPkg(%s): got Go+ files %q, ignored %q; want %q, %q", test.dir, gopFiles, ignored, test.wantGop, test.wantIgn)
+			}
+		})
+	}
+}
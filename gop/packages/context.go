@@ -57,6 +57,14 @@ func (p *Context) LoadModFrom(gomod string) (ret *gopmod.Module, err error) {
 	return
 }
 
+// ForgetMod removes the Go+ module loaded from gomod from the cache, so
+// that changes to its go.mod or gop.mod file are seen by the next load.
+func (p *Context) ForgetMod(gomod string) {
+	p.mutex.Lock()
+	delete(p.mods, gomod)
+	p.mutex.Unlock()
+}
+
 // loadModFrom loads a Go+ module from gop.mod or go.mod file.
 func loadModFrom(gomod string) (ret *gopmod.Module, err error) {
 	if ret, err = doLoadModFrom(gomod); err == nil {
@@ -18,8 +18,11 @@ var (
 	gopInstalled = env.Installed()
 )
 
+// GenGo generates gop_autogen.go of the Go+ packages in patternIn, and
+// returns the patterns to load. Without an installed gop command, Go+
+// code is only generated in-process (see SetGenGoMode).
 func GenGo(patternIn ...string) (patternOut []string, err error) {
-	if !gopInstalled {
+	if !gopInstalled && !langserver.InProcess() {
 		return patternIn, nil
 	}
 	pattern, patternOut := buildPattern(patternIn)
@@ -27,11 +30,18 @@ func GenGo(patternIn ...string) (patternOut []string, err error) {
 		log.Println("GenGo:", pattern, "in:", patternIn, "out:", patternOut)
 	}
 	if len(pattern) > 0 {
-		langserver.GenGo(context.Background(), pattern...)
+		err = langserver.GenGo(context.Background(), pattern...)
 	}
 	return
 }
 
+// SetGenGoMode sets how Go+ code is generated: by an external `gop serve`
+// process (langserver.ModeServe, the default) or in-process by the gop
+// library (langserver.ModeInProcess).
+func SetGenGoMode(mode langserver.Mode) {
+	langserver.SetMode(mode)
+}
+
 type none = struct{}
 
 func buildPattern(pattern []string) (gopPattern []string, allPattern []string) {
@@ -0,0 +1,68 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gop/langserver"
+	"golang.org/x/tools/internal/testenv"
+)
+
+// writeFiles writes the files, named by slash-separated paths, in dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadGenGoError(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+	SetGenGoMode(langserver.ModeInProcess)
+	defer SetGenGoMode(langserver.ModeServe)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":             "module example.com/m\n\ngo 1.18\n",
+		"a/gop_autogen.go":   "package a\n",
+		"a/a.gop":            "package a\n\nfunc A() {}\n",
+		"b/b.go":             "package b\n",
+		"c.x/gop_autogen.go": "package c\n",
+	})
+	// Generating Go code fails, since the pattern mixes a directory with
+	// "c.x", which gop takes as a file.
+	pkgs, err := Load(&Config{Dir: dir}, "./a", "./b", "example.com/m/c.x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gopPkgs := 0
+	for _, pkg := range pkgs {
+		if len(pkg.GopFiles) > 0 {
+			gopPkgs++
+		}
+		var genErrs []string
+		for _, err := range pkg.Errors {
+			if strings.HasPrefix(err.Msg, "gengo: ") {
+				genErrs = append(genErrs, err.Msg)
+			}
+		}
+		// Only the package with Go+ files depends on the generated code.
+		if want := len(pkg.GopFiles) > 0; (len(genErrs) > 0) != want {
+			t.Errorf("package %s (Go+ files %v): got gengo errors %q, want error: %t", pkg.ID, pkg.GopFiles, genErrs, want)
+		}
+	}
+	if len(pkgs) != 3 || gopPkgs != 1 {
+		t.Errorf("got %d packages, %d with Go+ files; want 3, 1", len(pkgs), gopPkgs)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+// This file locates the Go+ packages without generated Go files in
+// GOPATH mode, in which the go command doesn't report them.
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gopathSrcDirs returns the src directories of ctxt.GOPATH if cfg.Env
+// selects GOPATH mode (GO111MODULE=off), or nil otherwise.
+func gopathSrcDirs(cfg *Config, ctxt *build.Context) (dirs []string) {
+	env := cfg.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	var gomod string
+	for _, kv := range env { // the last one wins
+		if k, v, ok := strings.Cut(kv, "="); ok && k == "GO111MODULE" {
+			gomod = v
+		}
+	}
+	if gomod != "off" {
+		return nil
+	}
+	for _, root := range filepath.SplitList(ctxt.GOPATH) {
+		if root != "" && filepath.IsAbs(root) {
+			dirs = append(dirs, filepath.Join(root, "src"))
+		}
+	}
+	return
+}
+
+// gopathDir returns the directory of the package with import path pkgPath
+// in GOPATH mode.
+func (ld *loader) gopathDir(pkgPath string) (dir string, ok bool) {
+	if build.IsLocalImport(pkgPath) || filepath.IsAbs(pkgPath) || strings.Contains(pkgPath, "...") {
+		return
+	}
+	for _, src := range ld.gopath {
+		dir = filepath.Join(src, filepath.FromSlash(pkgPath))
+		if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// gopathPkgPath returns the import path of the package in dir in GOPATH
+// mode.
+func (ld *loader) gopathPkgPath(dir string) (pkgPath string, ok bool) {
+	for _, src := range ld.gopath {
+		if rel, err := filepath.Rel(src, dir); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel), true
+		}
+	}
+	return "", false
+}
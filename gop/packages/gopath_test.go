@@ -0,0 +1,96 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/testenv"
+)
+
+func TestGopathSrcDirs(t *testing.T) {
+	root1, root2 := t.TempDir(), t.TempDir()
+	gopath := strings.Join([]string{root1, "", "rel", root2}, string(filepath.ListSeparator))
+	for _, test := range []struct {
+		name string
+		env  []string
+		want []string
+	}{
+		{"GOPATH mode", []string{"GO111MODULE=off", "GOPATH=" + gopath}, []string{filepath.Join(root1, "src"), filepath.Join(root2, "src")}},
+		{"last wins", []string{"GO111MODULE=off", "GO111MODULE=on", "GOPATH=" + gopath}, nil},
+		{"module mode", []string{"GO111MODULE=on", "GOPATH=" + gopath}, nil},
+		{"auto", []string{"GO111MODULE=", "GOPATH=" + gopath}, nil},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			conf := &Config{Env: test.env}
+			if got := gopathSrcDirs(conf, buildContext(conf)); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("gopathSrcDirs(%q) = %q, want %q", test.env, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGopathNongenPkgs(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"src/example.com/a/a.gop": "package a\n",
+		"src/b/b.gop":             "package b\n",
+	})
+	conf := &Config{
+		Dir:  dir,
+		Mode: NeedName | NeedFiles,
+		Env:  []string{"GO111MODULE=off", "GOPATH=" + dir},
+	}
+	ld := newTestLoader(conf)
+
+	// The import path of a package is its directory relative to GOPATH/src.
+	if pkg := ld.nongenPkg(conf, filepath.Join(dir, "src", "example.com", "a")); pkg == nil || pkg.PkgPath != "example.com/a" {
+		t.Errorf("nongenPkg(src/example.com/a) = %v, want package example.com/a", pkg)
+	}
+
+	// Patterns are import paths, resolved in GOPATH.
+	placeholder := new(Package)
+	placeholder.ID = "example.com/a"
+	pkgs := ld.addNongenPkgs([]*Package{placeholder}, conf, []string{"example.com/a", "b", "missing", "example.com/..."})
+	var got []string
+	for _, pkg := range pkgs {
+		got = append(got, pkg.PkgPath)
+	}
+	if want := []string{"example.com/a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("addNongenPkgs = %q, want %q", got, want)
+	}
+}
+
+func TestLoadGopath(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"src/a/a.gop": "package a\n\nfunc A() int { return 1 }\n",
+	})
+	conf := &Config{
+		Dir:  dir,
+		Mode: NeedName | NeedFiles | NeedCompiledGoFiles | NeedSyntax | NeedTypes | NeedTypesInfo,
+		Env:  append(os.Environ(), "GO111MODULE=off", "GOPATH="+dir, "GOFLAGS="),
+	}
+	pkgs, err := Load(conf, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("got %d packages, want 1", len(pkgs))
+	}
+	pkg := pkgs[0]
+	if pkg.PkgPath != "a" || len(pkg.Errors) > 0 {
+		t.Fatalf("got package %q with errors %v, want package a", pkg.PkgPath, pkg.Errors)
+	}
+	if obj := pkg.Types.Scope().Lookup("A"); obj == nil || obj.Type().String() != "func() int" {
+		t.Errorf("got A %v, want func() int", obj)
+	}
+}
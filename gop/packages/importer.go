@@ -0,0 +1,37 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"go/types"
+
+	"github.com/goplus/gop/token"
+	goxpackages "github.com/goplus/gox/packages"
+)
+
+// importer imports the packages imported by the Go+ files of a package.
+// Packages imported by its Go files too are taken from the loaded
+// dependencies, so that the Go and Go+ type information of the package
+// share their objects; others, such as packages imported by Go+ files
+// only, are imported by the default importer of Go+.
+type importer struct {
+	pkg  *Package
+	fset *token.FileSet
+	gop  types.Importer // created on demand
+}
+
+func newImporter(pkg *Package, fset *token.FileSet) *importer {
+	return &importer{pkg: pkg, fset: fset}
+}
+
+func (p *importer) Import(path string) (*types.Package, error) {
+	if imp := p.pkg.Imports[path]; imp != nil && imp.Types != nil {
+		return imp.Types, nil
+	}
+	if p.gop == nil {
+		p.gop = goxpackages.NewImporter(p.fset)
+	}
+	return p.gop.Import(path)
+}
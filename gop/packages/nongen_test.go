@@ -0,0 +1,296 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"context"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/goplus/gop/parser"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/internal/testenv"
+)
+
+// newTestLoader returns a loader for conf, as LoadEx creates it.
+func newTestLoader(conf *Config) *loader {
+	bctx := buildContext(conf)
+	return &loader{token.NewFileSet(), Default, parser.ParseEntry, conf.Overlay, context.Background(), bctx, gopathSrcDirs(conf, bctx)}
+}
+
+// overlay returns an overlay of the files, named by slash-separated paths
+// relative to dir.
+func overlay(dir string, files map[string]string) map[string][]byte {
+	ret := make(map[string][]byte, len(files))
+	for name, content := range files {
+		ret[filepath.Join(dir, filepath.FromSlash(name))] = []byte(content)
+	}
+	return ret
+}
+
+func TestReadDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a/a.gop":   "package a\n",
+		"a/b.go":    "package a\n",
+		"a/sub/c.x": "",
+	})
+	for _, test := range []struct {
+		name    string
+		dir     string
+		overlay map[string]string
+		want    []string
+		wantErr bool
+	}{
+		{"disk", "a", nil, []string{"a.gop", "b.go"}, false},
+		{"overlay", "a", map[string]string{"a/c.gop": "", "a/a.gop": "", "b/d.gop": ""}, []string{"a.gop", "b.go", "c.gop"}, false},
+		{"overlay only", "b", map[string]string{"b/d.gop": "", "b/sub/e.gop": ""}, []string{"d.gop"}, false},
+		{"missing", "b", nil, nil, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ld := newTestLoader(&Config{Dir: dir, Overlay: overlay(dir, test.overlay)})
+			got, err := ld.readDir(filepath.Join(dir, test.dir) + string(filepath.Separator))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("readDir(%s): got error %v, want error: %t", test.dir, err, test.wantErr)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("readDir(%s) = %q, want %q", test.dir, got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.gop": "disk",
+	})
+	for _, test := range []struct {
+		name    string
+		file    string
+		overlay map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"disk", "a.gop", nil, "disk", false},
+		{"overlay", "a.gop", map[string]string{"a.gop": "overlay"}, "overlay", false},
+		{"overlay only", "b.gop", map[string]string{"b.gop": "overlay"}, "overlay", false},
+		{"missing", "b.gop", map[string]string{"a.gop": "overlay"}, "", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			ld := newTestLoader(&Config{Dir: dir, Overlay: overlay(dir, test.overlay)})
+			got, err := ld.readFile(filepath.Join(dir, test.file))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("readFile(%s): got error %v, want error: %t", test.file, err, test.wantErr)
+			}
+			if string(got) != test.want {
+				t.Errorf("readFile(%s) = %q, want %q", test.file, got, test.want)
+			}
+		})
+	}
+}
+
+func TestNongenPkg(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":         "module example.com/m\n\ngo 1.18\n",
+		"a/a.gop":        "package a\n",
+		"a/a_test.gop":   "package a\n",
+		"a/b.gop":        "package b\n", // not in the package of the first file
+		"gox/Rect.gox":   "var (\n\tW, H int\n)\n",
+		"gox/gop.go":     "package main\n",
+		"main/main.gop":  "println \"hi\"\n",
+		"nogop/nogop.go": "package nogop\n",
+		"empty/README":   "",
+	})
+	for _, test := range []struct {
+		name     string
+		dir      string
+		overlay  map[string]string
+		wantName string // "" for no package
+		wantPath string
+		wantGop  []string
+	}{
+		{"disk", "a", nil, "a", "example.com/m/a", []string{"a/a.gop"}},
+		{"classfile", "gox", nil, "main", "example.com/m/gox", []string{"gox/Rect.gox"}},
+		{"no package clause", "main", nil, "main", "example.com/m/main", []string{"main/main.gop"}},
+		{"no Go+ files", "nogop", nil, "", "", nil},
+		{"empty", "empty", nil, "", "", nil},
+		{"unsaved file", "empty", map[string]string{"empty/new.gop": "package empty\n"}, "empty", "example.com/m/empty", []string{"empty/new.gop"}},
+		{"new directory", "new", map[string]string{"new/new.gop": "package new\n"}, "new", "example.com/m/new", []string{"new/new.gop"}},
+		{"edited package clause", "a", map[string]string{"a/a.gop": "package c\n", "a/b.gop": "package c\n"}, "c", "example.com/m/a", []string{"a/a.gop", "a/b.gop"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			conf := &Config{Dir: dir, Mode: NeedName | NeedFiles, Overlay: overlay(dir, test.overlay)}
+			ld := newTestLoader(conf)
+			pkg := ld.nongenPkg(conf, filepath.Join(dir, test.dir))
+			if pkg == nil {
+				if test.wantName != "" {
+					t.Errorf("nongenPkg(%s) = nil, want package %s", test.dir, test.wantName)
+				}
+				return
+			}
+			var gopFiles []string
+			for _, file := range pkg.GopFiles {
+				rel, _ := filepath.Rel(dir, file)
+				gopFiles = append(gopFiles, filepath.ToSlash(rel))
+			}
+			if pkg.Name != test.wantName || pkg.PkgPath != test.wantPath || !reflect.DeepEqual(gopFiles, test.wantGop) {
+				t.Errorf("nongenPkg(%s) = package %s %q with Go+ files %q, want package %s %q with Go+ files %q",
+					test.dir, pkg.Name, pkg.PkgPath, gopFiles, test.wantName, test.wantPath, test.wantGop)
+			}
+		})
+	}
+}
+
+func TestAddNongenPkgs(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":             "module example.com/m\n\ngo 1.18\n",
+		"gen/a.gop":          "package gen\n",
+		"gen/gop_autogen.go": "package gen\n",
+		"nogen/a.gop":        "package nogen\n",
+		"nogop/nogop.go":     "package nogop\n",
+	})
+	abs := func(name string) string {
+		return filepath.Join(dir, filepath.FromSlash(name))
+	}
+	gen := &Package{GopFiles: []string{abs("gen/a.gop")}}
+	gen.ID = "example.com/m/gen"
+	gen.GoFiles = []string{abs("gen/gop_autogen.go")}
+	placeholder := func(id string) *Package {
+		pkg := new(Package)
+		pkg.ID = id
+		return pkg
+	}
+
+	for _, test := range []struct {
+		name     string
+		pkgs     []*Package
+		patterns []string
+		overlay  map[string]string
+		want     []string // IDs of the packages, "+" marks those added by addNongenPkgs
+	}{
+		{"generated", []*Package{gen}, []string{"./gen"}, nil, []string{"example.com/m/gen"}},
+		{"placeholder", []*Package{placeholder("./nogen")}, []string{"./nogen"}, nil, []string{"+example.com/m/nogen"}},
+		{"placeholder by path", []*Package{placeholder("example.com/m/nogen")}, []string{abs("nogen")}, nil, []string{"+example.com/m/nogen"}},
+		{"not reported", []*Package{gen}, []string{"./gen", "./nogen"}, nil, []string{"example.com/m/gen", "+example.com/m/nogen"}},
+		{"Go+ file", nil, []string{"file=" + abs("nogen/a.gop")}, nil, []string{"+example.com/m/nogen"}},
+		{"no Go+ files", []*Package{placeholder("./nogop")}, []string{"./nogop"}, nil, []string{"./nogop"}},
+		{"wildcard", nil, []string{"./..."}, nil, nil},
+		{"overlay only", []*Package{placeholder("./new")}, []string{"./new"}, map[string]string{"new/new.gop": "package new\n"}, []string{"+example.com/m/new"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			conf := &Config{Dir: dir, Mode: NeedName | NeedFiles, Overlay: overlay(dir, test.overlay)}
+			ld := newTestLoader(conf)
+			pkgs := ld.addNongenPkgs(append([]*Package(nil), test.pkgs...), conf, test.patterns)
+			var got []string
+			for _, pkg := range pkgs {
+				id := pkg.ID
+				if len(pkg.GopFiles) > 0 && pkg.GoFiles == nil {
+					id = "+" + id
+				}
+				got = append(got, id)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("addNongenPkgs(%q) = %q, want %q", test.patterns, got, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadOverlay(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":           "module example.com/m\n\ngo 1.18\n",
+		"a/a.gop":          "package a\n\nfunc A() int { return 1 }\n",
+		"a/gop_autogen.go": "package a\n",
+	})
+	conf := &Config{
+		Dir:  dir,
+		Mode: NeedName | NeedFiles | NeedCompiledGoFiles | NeedSyntax | NeedTypes | NeedTypesInfo,
+		Overlay: overlay(dir, map[string]string{
+			"a/a.gop": "package a\n\nfunc A() string { return \"\" }\n", // unsaved edit
+			"b/b.gop": "package b\n\nfunc B() {}\n",                     // unsaved new package
+		}),
+	}
+	pkgs, err := Load(conf, "./a", "./b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 2 {
+		t.Fatalf("got %d packages, want 2", len(pkgs))
+	}
+	for _, test := range []struct {
+		pkg       *Package
+		name, sig string
+	}{
+		{pkgs[0], "A", "func() string"},
+		{pkgs[1], "B", "func()"},
+	} {
+		if len(test.pkg.Errors) > 0 {
+			t.Errorf("package %s has errors: %v", test.pkg.ID, test.pkg.Errors)
+		}
+		if test.pkg.Types == nil {
+			t.Errorf("package %s has no types", test.pkg.ID)
+			continue
+		}
+		obj := test.pkg.Types.Scope().Lookup(test.name)
+		if obj == nil || obj.Type().String() != test.sig {
+			t.Errorf("package %s: got %s %v, want type %s", test.pkg.ID, test.name, obj, test.sig)
+		}
+	}
+}
+
+// TestLoadNongenTypes checks that a package without generated Go files
+// has the imports of its Go+ files and the sizes of the build context.
+func TestLoadNongenTypes(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.18\n",
+		"a/a.gop": `package a
+
+import (
+	"strings"
+	"fmt"
+)
+
+func A() string { return fmt.Sprint(strings.ToUpper("a")) }
+`,
+	})
+	conf := &Config{
+		Dir:  dir,
+		Mode: NeedName | NeedFiles | NeedCompiledGoFiles | NeedSyntax | NeedTypes | NeedTypesInfo | NeedTypesSizes,
+		Env:  append(os.Environ(), "GOARCH=386", "GOFLAGS="),
+	}
+	pkgs, err := Load(conf, "./a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 1 || pkgs[0].Types == nil {
+		t.Fatalf("got packages %v, want package a with types", pkgs)
+	}
+	pkg := pkgs[0]
+	var imports []string
+	for _, imp := range pkg.Types.Imports() {
+		imports = append(imports, imp.Path())
+	}
+	if want := []string{"fmt", "strings"}; !reflect.DeepEqual(imports, want) {
+		t.Errorf("got imports %q, want %q", imports, want)
+	}
+	if pkg.TypesSizes == nil {
+		t.Fatal("got no TypesSizes")
+	}
+	if size := pkg.TypesSizes.Sizeof(types.Typ[types.Int]); size != 4 {
+		t.Errorf("got int of size %d, want 4 (GOARCH=386)", size)
+	}
+}
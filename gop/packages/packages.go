@@ -7,11 +7,13 @@ package packages
 import (
 	"context"
 	goast "go/ast"
+	"go/build"
 	"go/types"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -248,8 +250,11 @@ func Load(cfg *Config, patterns ...string) ([]*Package, error) {
 // return an error. Clients may need to handle such errors before
 // proceeding with further analysis. The PrintErrors function is
 // provided for convenient display of all errors.
+// A failure to generate the Go code of Go+ packages is likewise recorded
+// in the Errors list of each package with Go+ files.
 func LoadEx(gop *GopConfig, cfg *Config, patterns ...string) ([]*Package, error) {
-	patterns, _ = GenGo(patterns...)
+	patternsIn := patterns
+	patterns, genErr := GenGo(patterns...)
 
 	var conf Config
 	if cfg != nil {
@@ -269,29 +274,185 @@ func LoadEx(gop *GopConfig, cfg *Config, patterns ...string) ([]*Package, error)
 	pkgMap := make(map[*packages.Package]*Package)
 	ret := make([]*Package, len(pkgs))
 
-	var ld *loader
-	if conf.Mode&(NeedSyntax|NeedTypes|NeedTypesInfo) != 0 {
-		if conf.Context == nil {
-			conf.Context = context.Background()
+	if conf.Context == nil {
+		conf.Context = context.Background()
+	}
+	if gop == nil {
+		gop = new(GopConfig)
+	}
+	ctx := gop.Context
+	if ctx == nil {
+		ctx = Default
+	}
+	parse := gop.ParseFile
+	if parse == nil {
+		parse = parser.ParseEntry
+	}
+	bctx := buildContext(&conf)
+	ld := &loader{conf.Fset, ctx, parse, conf.Overlay, conf.Context, bctx, gopathSrcDirs(&conf, bctx)}
+
+	for i, pkg := range pkgs {
+		ret[i] = pkgOf(pkgMap, pkg, ld, conf.Mode)
+	}
+	ret = ld.addNongenPkgs(ret, &conf, patternsIn)
+	if genErr != nil {
+		addGenGoError(ret, genErr)
+	}
+	return ret, nil
+}
+
+// addGenGoError records err, the failure to generate Go code of the Go+
+// packages being loaded, on each package with Go+ files: their generated
+// Go files may be missing or out of date.
+func addGenGoError(pkgs []*Package, err error) {
+	added := false
+	for _, pkg := range pkgs {
+		if len(pkg.GopFiles) > 0 {
+			pkg.Errors = append(pkg.Errors, Error{
+				Pos:  "-",
+				Msg:  "gengo: " + err.Error(),
+				Kind: ListError,
+			})
+			added = true
 		}
-		if gop == nil {
-			gop = new(GopConfig)
+	}
+	if !added {
+		log.Println("gengo:", err)
+	}
+}
+
+// addNongenPkgs adds the Go+ packages in the directories named by patterns
+// that have no generated Go files yet, such as a new directory or one
+// with unsaved Go+ files only. These packages are type-checked directly
+// from their Go+ sources. The placeholder packages that the go command
+// reports for such directories ("no Go files in dir") are replaced.
+func (ld *loader) addNongenPkgs(pkgs []*Package, conf *Config, patterns []string) []*Package {
+	covered := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, files := range [][]string{pkg.GoFiles, pkg.CompiledGoFiles, pkg.GopFiles} {
+			for _, file := range files {
+				covered[filepath.Dir(file)] = true
+			}
 		}
-		ctx := gop.Context
-		if ctx == nil {
-			ctx = Default
+	}
+	for _, pattern := range patterns {
+		dir, ok := patternDir(conf.Dir, pattern)
+		if !ok {
+			dir, ok = ld.gopathDir(pattern)
 		}
-		parse := gop.ParseFile
-		if parse == nil {
-			parse = parser.ParseEntry
+		if !ok || covered[dir] {
+			continue
+		}
+		covered[dir] = true
+		pkg := ld.nongenPkg(conf, dir)
+		if pkg == nil {
+			continue
+		}
+		replaced := false
+		for i, p := range pkgs {
+			if (p.ID == pattern || p.ID == pkg.ID) && len(p.GoFiles) == 0 && len(p.CompiledGoFiles) == 0 {
+				pkgs[i], replaced = pkg, true
+				break
+			}
+		}
+		if !replaced {
+			pkgs = append(pkgs, pkg)
 		}
-		ld = &loader{conf.Fset, ctx, parse, conf.Overlay, conf.Context}
 	}
+	return pkgs
+}
 
-	for i, pkg := range pkgs {
-		ret[i] = pkgOf(pkgMap, pkg, ld, conf.Mode)
+// patternDir returns the absolute directory of a pattern that denotes a
+// local directory, or a Go+ file (file=xxx.gop).
+func patternDir(wd, pattern string) (dir string, ok bool) {
+	const filePrefix = "file="
+	if strings.HasPrefix(pattern, filePrefix) {
+		file := pattern[len(filePrefix):]
+		if strings.HasSuffix(file, ".go") {
+			return
+		}
+		dir = filepath.Dir(file)
+	} else if build.IsLocalImport(pattern) || filepath.IsAbs(pattern) {
+		if strings.Contains(pattern, "...") {
+			return
+		}
+		dir = pattern
+	} else {
+		return
 	}
-	return ret, nil
+	if !filepath.IsAbs(dir) {
+		if wd == "" {
+			wd, _ = os.Getwd()
+		}
+		dir = filepath.Join(wd, dir)
+	}
+	return filepath.Clean(dir), true
+}
+
+// nongenPkg returns the Go+ package in dir, or nil if there is none.
+func (ld *loader) nongenPkg(conf *Config, dir string) *Package {
+	fnames, err := ld.readDir(dir)
+	if err != nil {
+		return nil
+	}
+	var name string
+	fsetTemp := token.NewFileSet()
+	gopMod := lazyGopMod(dir)
+	for _, fname := range fnames {
+		if !isGopSource(fname, false, gopMod) {
+			continue
+		}
+		file := filepath.Join(dir, fname)
+		src, err := ld.readFile(file)
+		if err != nil || !ld.matchFile(dir, fname, src) {
+			continue
+		}
+		if f, err := parser.ParseFile(fsetTemp, file, src, parser.PackageClauseOnly); err == nil {
+			name = f.Name.Name
+			break
+		}
+	}
+	if name == "" {
+		return nil
+	}
+
+	pkgPath := dir
+	var module *Module
+	if p, ok := ld.gopathPkgPath(dir); ok {
+		pkgPath = p
+	} else if mod, err := gop.LoadMod(dir); err == nil && mod.HasModfile() {
+		if rel, err := filepath.Rel(mod.Root(), dir); err == nil && !strings.HasPrefix(rel, "..") {
+			pkgPath = path.Join(mod.Path(), filepath.ToSlash(rel))
+			module = &Module{Path: mod.Path(), Dir: mod.Root(), GoMod: mod.Modfile()}
+		}
+	}
+	ret := &Package{Package: packages.Package{
+		ID:      pkgPath,
+		Name:    name,
+		PkgPath: pkgPath,
+		Fset:    conf.Fset,
+		Module:  module,
+	}}
+	if conf.Mode&NeedTypesSizes != 0 {
+		ret.TypesSizes = types.SizesFor("gc", ld.build.GOARCH)
+	}
+	if conf.Mode&(NeedTypes|NeedTypesInfo) != 0 {
+		ret.Types = types.NewPackage(pkgPath, name)
+		ret.TypesInfo = &types.Info{
+			Types:      make(map[goast.Expr]types.TypeAndValue),
+			Defs:       make(map[*goast.Ident]types.Object),
+			Uses:       make(map[*goast.Ident]types.Object),
+			Implicits:  make(map[goast.Node]types.Object),
+			Scopes:     make(map[goast.Node]*types.Scope),
+			Selections: make(map[*goast.SelectorExpr]*types.Selection),
+			Instances:  make(map[*goast.Ident]types.Instance),
+		}
+	}
+	addGopFiles(ret, ld, dir+string(filepath.Separator), conf.Mode, false)
+	if len(ret.GopFiles) == 0 {
+		return nil
+	}
+	return ret
 }
 
 func importPkgs(pkgMap map[*packages.Package]*Package, pkgs map[string]*packages.Package, ld *loader, mode LoadMode) map[string]*Package {
@@ -384,34 +545,52 @@ func autogenFiles(ret *Package, test bool) []*goast.File {
 	return files
 }
 
+// isGopSource reports whether fname is a Go+ source file, excluding
+// test files (but not classfile tests) unless test is set. Classfiles
+// registered in gop.mod are recognized through gopMod, which is only
+// called for files with an unknown extension.
+func isGopSource(fname string, test bool, gopMod func() *gopmod.Module) bool {
+	if strings.HasPrefix(fname, "_") {
+		return false
+	}
+	fext := path.Ext(fname)
+	if goputil.FileKind(fext) == goputil.FileUnknown {
+		if fext == "" || fext == ".go" || goputil.FileKindOf(gopMod(), fname) == goputil.FileUnknown {
+			return false
+		}
+	}
+	return test || !strings.HasSuffix(fname[:len(fname)-len(fext)], "_test")
+}
+
+// lazyGopMod returns a function that loads the Go+ module of dir, with its
+// classfiles imported, the first time it is called.
+func lazyGopMod(dir string) func() *gopmod.Module {
+	var mod *gopmod.Module
+	var once sync.Once
+	return func() *gopmod.Module {
+		once.Do(func() {
+			mod, _ = gop.LoadMod(dir)
+		})
+		return mod
+	}
+}
+
 func addGopFiles(ret *Package, ld *loader, dir string, mode LoadMode, test bool) {
-	entries, err := os.ReadDir(dir)
+	fnames, err := ld.readDir(dir)
 	if err != nil {
 		return
 	}
 	fsetTemp := token.NewFileSet()
 	pkgName := ret.Name
-	var mod *gopmod.Module
-	var once sync.Once
-	for _, e := range entries {
-		fname := e.Name()
-		if strings.HasPrefix(fname, "_") {
-			continue
-		}
-		fext := path.Ext(fname)
-		if goputil.FileKind(fext) == goputil.FileUnknown {
+	gopMod := lazyGopMod(dir)
+	for _, fname := range fnames {
+		if !isGopSource(fname, test, gopMod) {
 			continue
 		}
 		if !test {
-			if strings.HasSuffix(fname[:len(fname)-len(fext)], "_test") {
-				continue
-			}
 			// check gox class test
 			if strings.HasSuffix(fname, "test.gox") {
-				once.Do(func() {
-					mod, _ = gop.LoadMod(dir)
-				})
-				if mod != nil {
+				if mod := gopMod(); mod != nil {
 					if _, ok := mod.ClassKind(fname); ok {
 						continue
 					}
@@ -419,14 +598,21 @@ func addGopFiles(ret *Package, ld *loader, dir string, mode LoadMode, test bool)
 			}
 		}
 		file := dir + fname
-		f, err := parser.ParseFile(fsetTemp, file, nil, parser.PackageClauseOnly)
+		src, err := ld.readFile(file)
+		if err != nil {
+			continue
+		}
+		f, err := parser.ParseFile(fsetTemp, file, src, parser.PackageClauseOnly)
 		if err == nil && pkgName == f.Name.Name {
+			if !ld.matchFile(dir, fname, src) {
+				ret.IgnoredFiles = append(ret.IgnoredFiles, file)
+				continue
+			}
 			ret.GopFiles = append(ret.GopFiles, file)
 			ret.CompiledGopFiles = append(ret.CompiledGopFiles, file)
-			// goxls: todo - condition
 		}
 	}
-	if ld != nil && len(ret.CompiledGopFiles) > 0 {
+	if mode&(NeedSyntax|NeedTypes|NeedTypesInfo) != 0 && len(ret.CompiledGopFiles) > 0 {
 		ctx := ld.Context
 		mod := ctx.LoadMod(ret.Module)
 		ret.GopSyntax = ld.parseFiles(ret, mod, ret.CompiledGopFiles)
@@ -442,7 +628,8 @@ func addGopFiles(ret *Package, ld *loader, dir string, mode LoadMode, test bool)
 				Overloads:  make(map[*ast.Ident][]types.Object),
 			}
 			cfg := &types.Config{
-				Context: ctx.Types,
+				Context:  ctx.Types,
+				Importer: newImporter(ret, ld.Fset),
 				Error: func(err error) {
 					appendError(ret, err)
 				},
@@ -461,10 +648,40 @@ func addGopFiles(ret *Package, ld *loader, dir string, mode LoadMode, test bool)
 			if err != nil && debugVerbose {
 				log.Println("typesutil.Check:", err)
 			}
+			addGopImports(ret.Types, ret.GopTypesInfo)
 		}
 	}
 }
 
+// addGopImports adds the packages imported by Go+ files to the imports of
+// pkg, which lacks them if the package has no generated Go files.
+func addGopImports(pkg *types.Package, info *typesutil.Info) {
+	imports := pkg.Imports()
+	seen := make(map[*types.Package]bool, len(imports))
+	for _, imp := range imports {
+		seen[imp] = true
+	}
+	n := len(imports)
+	add := func(obj types.Object) {
+		if pkgName, ok := obj.(*types.PkgName); ok && !seen[pkgName.Imported()] {
+			seen[pkgName.Imported()] = true
+			imports = append(imports, pkgName.Imported())
+		}
+	}
+	for _, obj := range info.Defs {
+		add(obj)
+	}
+	for _, obj := range info.Implicits {
+		add(obj)
+	}
+	if len(imports) > n {
+		sort.Slice(imports[n:], func(i, j int) bool {
+			return imports[n+i].Path() < imports[n+j].Path()
+		})
+		pkg.SetImports(imports)
+	}
+}
+
 type loader struct {
 	Fset      *token.FileSet
 	Context   *Context
@@ -479,6 +696,13 @@ type loader struct {
 	Overlay map[string][]byte
 
 	ctx context.Context
+
+	// build is the build context used to select Go+ files.
+	build *build.Context
+
+	// gopath is the src directories of GOPATH in GOPATH mode, or nil in
+	// module mode.
+	gopath []string
 }
 
 // parseFiles reads and parses the Go+ source files and returns the ASTs
@@ -528,8 +752,34 @@ func (ld *loader) parseFiles(ret *Package, mod *gopmod.Module, filenames []strin
 // the number of parallel I/O calls per process.
 var ioLimit = make(chan bool, 20)
 
-func (ld *loader) parseFile(filename string, mod *gopmod.Module) (f *ast.File, err error) {
-	var src []byte
+// readDir returns the sorted names of the files in dir, including the
+// files of the overlay that are not on disk yet.
+func (ld *loader) readDir(dir string) ([]string, error) {
+	dir = filepath.Clean(dir)
+	entries, err := os.ReadDir(dir)
+	fnames := make([]string, 0, len(entries))
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		if !e.IsDir() {
+			fnames = append(fnames, e.Name())
+			seen[e.Name()] = true
+		}
+	}
+	for file := range ld.Overlay {
+		if fname := filepath.Base(file); filepath.Dir(file) == dir && !seen[fname] {
+			fnames = append(fnames, fname)
+			seen[fname] = true
+		}
+	}
+	if len(fnames) == 0 && err != nil {
+		return nil, err
+	}
+	sort.Strings(fnames)
+	return fnames, nil
+}
+
+// readFile returns the contents of filename, from the overlay if present.
+func (ld *loader) readFile(filename string) (src []byte, err error) {
 	for f, contents := range ld.Overlay {
 		if sameFile(f, filename) {
 			src = contents
@@ -540,6 +790,11 @@ func (ld *loader) parseFile(filename string, mod *gopmod.Module) (f *ast.File, e
 		src, err = os.ReadFile(filename)
 		<-ioLimit // signal
 	}
+	return
+}
+
+func (ld *loader) parseFile(filename string, mod *gopmod.Module) (f *ast.File, err error) {
+	src, err := ld.readFile(filename)
 	if err != nil {
 		return
 	}
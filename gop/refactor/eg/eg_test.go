@@ -0,0 +1,124 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package eg_test
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/format"
+	"github.com/goplus/gop/parser"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/gop/langserver"
+	"golang.org/x/tools/gop/packages"
+	"golang.org/x/tools/gop/refactor/eg"
+	"golang.org/x/tools/internal/testenv"
+)
+
+func TestTransform(t *testing.T) {
+	testenv.NeedsGOPROOT(t)
+	t.Setenv("GO111MODULE", "on")
+
+	const (
+		template = `package template
+
+import "strings"
+
+func before(s string) string { return strings.ToUpper(s) }
+func after(s string) string  { return strings.ToLower(s) }
+`
+		input = `import "strings"
+
+func shout(s string) string {
+	return strings.toUpper(s) + "!"
+}
+
+echo shout("hi"), strings.ToUpper("x"), strings.toUpper(strings.toUpper("y"))
+`
+		want = `import "strings"
+
+func shout(s string) string {
+	return strings.ToLower(s) + "!"
+}
+
+echo shout("hi"), strings.ToLower("x"), strings.ToLower(strings.ToLower("y"))
+`
+	)
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":    "module example.com/app\n\ngo 1.18\n",
+		"app.gop":   input,
+		"tmpl.go.t": template,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	packages.SetGenGoMode(langserver.ModeInProcess)
+	cfg := &packages.Config{Mode: packages.LoadAllSyntax, Dir: dir}
+	pkgs, err := packages.Load(cfg, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("packages contain errors")
+	}
+	pkg := pkgs[0]
+	if len(pkg.GopSyntax) != 1 {
+		t.Fatalf("got %d Go+ files, want 1", len(pkg.GopSyntax))
+	}
+
+	// Parse and type-check the template as Go+ source.
+	tFile, err := parser.ParseFile(pkg.Fset, filepath.Join(dir, "tmpl.go.t"), template, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tInfo := &typesutil.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	conf := &types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if imp := pkg.Imports[path]; imp != nil {
+				return imp.Types, nil
+			}
+			return nil, fmt.Errorf("package %q not found", path)
+		}),
+		Error: func(err error) { t.Error(err) },
+	}
+	tPkg := types.NewPackage("egtemplate", tFile.Name.Name)
+	opts := &typesutil.Config{Types: tPkg, Fset: pkg.Fset}
+	if err := typesutil.NewChecker(conf, opts, nil, tInfo).Files(nil, []*ast.File{tFile}); err != nil {
+		t.Fatal(err)
+	}
+
+	xform, err := eg.NewTransformer(pkg.Fset, tPkg, tFile, tInfo, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := pkg.GopSyntax[0]
+	if n := xform.Transform(pkg.GopTypesInfo, pkg.Types, file); n != 4 {
+		t.Errorf("Transform: got %d matches, want 4", n)
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, pkg.Fset, file); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("got <<%s>>, want <<%s>>", got, want)
+	}
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
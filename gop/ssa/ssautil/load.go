@@ -0,0 +1,319 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ssautil defines utility functions for constructing programs
+// in SSA form from Go+ packages.
+package ssautil
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/gop/packages"
+	"golang.org/x/tools/gop/xtypes"
+)
+
+// Packages creates an SSA program for a set of Go/Go+ packages.
+//
+// The packages must have been loaded from source syntax using the
+// golang.org/x/tools/gop/packages.Load function in LoadSyntax or
+// LoadAllSyntax mode.
+//
+// The SSA code of a Go+ package is built from the Go code generated from
+// its Go+ files (gop_autogen.go), but the positions of its members,
+// functions and instructions are those of the Go+ source. As generated
+// code is mapped to Go+ source by line, columns are approximate.
+//
+// Packages creates an SSA package for each well-typed package in the
+// initial list, plus all their dependencies. The resulting list of
+// packages corresponds to the list of initial packages, and may contain
+// a nil if SSA code could not be constructed for the corresponding initial
+// package due to type errors.
+//
+// Code for bodies of functions is not built until Build is called on
+// the resulting Program. SSA code is constructed only for the initial
+// packages with well-typed syntax trees.
+//
+// The mode parameter controls diagnostics and checking during SSA construction.
+func Packages(initial []*packages.Package, mode ssa.BuilderMode) (*ssa.Program, []*ssa.Package) {
+	return doPackages(initial, mode, false)
+}
+
+// AllPackages creates an SSA program for a set of Go/Go+ packages plus
+// all their dependencies.
+//
+// The packages must have been loaded from source syntax using the
+// golang.org/x/tools/gop/packages.Load function in LoadAllSyntax mode.
+// Positions of Go+ packages are mapped as by Packages.
+//
+// AllPackages creates an SSA package for each well-typed package in the
+// initial list, plus all their dependencies. The resulting list of
+// packages corresponds to the list of initial packages, and may contain
+// a nil if SSA code could not be constructed for the corresponding
+// initial package due to type errors.
+//
+// Code for bodies of functions is not built until Build is called on
+// the resulting Program. SSA code is constructed for all packages with
+// well-typed syntax trees.
+//
+// The mode parameter controls diagnostics and checking during SSA construction.
+func AllPackages(initial []*packages.Package, mode ssa.BuilderMode) (*ssa.Program, []*ssa.Package) {
+	return doPackages(initial, mode, true)
+}
+
+func doPackages(initial []*packages.Package, mode ssa.BuilderMode, deps bool) (*ssa.Program, []*ssa.Package) {
+
+	var fset *token.FileSet
+	if len(initial) > 0 {
+		fset = initial[0].Fset
+	}
+
+	prog := ssa.NewProgram(fset, mode)
+
+	isInitial := make(map[*packages.Package]bool, len(initial))
+	for _, p := range initial {
+		isInitial[p] = true
+	}
+
+	ssamap := make(map[*packages.Package]*ssa.Package)
+	packages.Visit(initial, nil, func(p *packages.Package) {
+		if p.Types != nil && !p.IllTyped {
+			var files []*ast.File
+			var info *types.Info
+			if deps || isInitial[p] {
+				files = p.Syntax
+				info = goTypesInfo(p)
+			}
+			ssapkg := prog.CreatePackage(p.Types, files, info, true)
+			if files != nil && p.GopSyntax != nil {
+				ssapkg.SetPosMap(gopPosMap(p))
+			}
+			ssamap[p] = ssapkg
+		}
+	})
+
+	var ssapkgs []*ssa.Package
+	for _, p := range initial {
+		ssapkgs = append(ssapkgs, ssamap[p]) // may be nil
+	}
+	return prog, ssapkgs
+}
+
+// FuncValue returns the SSA function for obj, a function or method from
+// the Go+ type information of a package (GopTypesInfo), or nil if there
+// is none. For a call of an overloaded Go+ function, obj is the selected
+// overload, recorded in GopTypesInfo.Uses; the overloaded function itself
+// (see xtypes.CheckOverload) has no code, so FuncValue returns nil for it.
+func FuncValue(prog *ssa.Program, obj *types.Func) *ssa.Function {
+	if _, ok := xtypes.CheckOverload(obj); ok {
+		return nil
+	}
+	if fn := prog.FuncValue(obj); fn != nil {
+		return fn
+	}
+
+	// obj is declared by the Go+ type checker, not by the generated Go
+	// code that the SSA package is built from: look up the function of
+	// the same name.
+	pkg := prog.Package(obj.Pkg())
+	if pkg == nil {
+		return nil
+	}
+	recv := obj.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return pkg.Func(obj.Name())
+	}
+	t := recv.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return nil
+	}
+	typ := pkg.Type(named.Obj().Name())
+	if typ == nil {
+		return nil
+	}
+	if named, ok := typ.Type().(*types.Named); ok {
+		for i, n := 0, named.NumMethods(); i < n; i++ {
+			if m := named.Method(i); m.Name() == obj.Name() {
+				return prog.FuncValue(m)
+			}
+		}
+	}
+	return nil
+}
+
+// goTypesInfo returns the type information of the Go syntax of package
+// p. For a Go+ package, gop/packages redirects the uses of package-level
+// objects in p.TypesInfo to the objects declared by the Go+ type checker;
+// goTypesInfo returns a copy whose uses refer to the objects declared by
+// the Go syntax again, as the SSA builder requires.
+func goTypesInfo(p *packages.Package) *types.Info {
+	if p.GopTypesInfo == nil || p.TypesInfo == nil {
+		return p.TypesInfo
+	}
+	decls := make(map[string]types.Object)
+	addDecl := func(id *ast.Ident) {
+		if obj := p.TypesInfo.Defs[id]; obj != nil {
+			decls[id.Name] = obj
+		}
+	}
+	for _, f := range p.Syntax {
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					addDecl(decl.Name)
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.ValueSpec:
+						for _, id := range spec.Names {
+							addDecl(id)
+						}
+					case *ast.TypeSpec:
+						addDecl(spec.Name)
+					}
+				}
+			}
+		}
+	}
+
+	scope := p.Types.Scope()
+	info := *p.TypesInfo
+	info.Uses = make(map[*ast.Ident]types.Object, len(p.TypesInfo.Uses))
+	for id, obj := range p.TypesInfo.Uses {
+		if obj != nil && obj.Pkg() == p.Types && scope.Lookup(obj.Name()) == obj {
+			if decl := decls[obj.Name()]; decl != nil {
+				obj = decl
+			}
+		}
+		info.Uses[id] = obj
+	}
+	return &info
+}
+
+// gopPosMap returns a function that maps positions in the Go files of
+// package p generated from its Go+ files to the corresponding positions
+// in the Go+ files. Other positions are returned unchanged.
+//
+// The identifier declaring a package-level object or a method is mapped
+// to that of the Go+ object of the same name. Code of function bodies,
+// which has //line directives, is mapped by line, so columns are
+// approximate.
+func gopPosMap(p *packages.Package) func(token.Pos) token.Pos {
+	fset := p.Fset
+	autogen := make(map[*token.File]bool)
+	for _, f := range p.Syntax {
+		if tf := fset.File(f.Pos()); tf != nil && strings.HasPrefix(filepath.Base(tf.Name()), "gop_autogen") {
+			autogen[tf] = true
+		}
+	}
+	gopFiles := make(map[string]*token.File)
+	for _, f := range p.GopSyntax {
+		if tf := fset.File(f.Pos()); tf != nil {
+			gopFiles[tf.Name()] = tf
+		}
+	}
+	decls := gopDeclPos(p, func(pos token.Pos) bool {
+		tf := fset.File(pos)
+		return tf != nil && gopFiles[tf.Name()] == tf
+	})
+	return func(pos token.Pos) token.Pos {
+		tf := fset.File(pos)
+		if tf == nil || !autogen[tf] {
+			return pos
+		}
+		if gpos, ok := decls[pos]; ok {
+			return gpos
+		}
+		posn := tf.PositionFor(pos, true)
+		gf := gopFiles[posn.Filename]
+		if gf == nil || posn.Line < 1 || posn.Line > gf.LineCount() {
+			return pos
+		}
+		start := gf.LineStart(posn.Line)
+		end := token.Pos(gf.Base() + gf.Size())
+		if posn.Line < gf.LineCount() {
+			end = gf.LineStart(posn.Line+1) - 1 // newline
+		}
+		if col := token.Pos(posn.Column - 1); col > 0 && start+col < end {
+			return start + col
+		}
+		return start
+	}
+}
+
+// gopDeclPos returns the positions, in Go+ files as reported by inGop,
+// of the Go+ objects of package p, by the positions of the identifiers
+// of the Go syntax that declare the package-level objects and methods of
+// the same names.
+func gopDeclPos(p *packages.Package, inGop func(token.Pos) bool) map[token.Pos]token.Pos {
+	decls := make(map[token.Pos]token.Pos)
+	scope := p.Types.Scope()
+	add := func(id *ast.Ident, obj types.Object) {
+		if obj != nil && inGop(obj.Pos()) {
+			decls[id.Pos()] = obj.Pos()
+		}
+	}
+	for _, f := range p.Syntax {
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					add(decl.Name, scope.Lookup(decl.Name.Name))
+				} else if fn, ok := p.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+					add(decl.Name, lookupMethod(scope, fn))
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.ValueSpec:
+						for _, id := range spec.Names {
+							add(id, scope.Lookup(id.Name))
+						}
+					case *ast.TypeSpec:
+						add(spec.Name, scope.Lookup(spec.Name.Name))
+					}
+				}
+			}
+		}
+	}
+	return decls
+}
+
+// lookupMethod returns the method of the same name and receiver type
+// name as method fn, in the package of scope, or nil if there is none.
+func lookupMethod(scope *types.Scope, fn *types.Func) *types.Func {
+	named, ok := deref(fn.Type().(*types.Signature).Recv().Type()).(*types.Named)
+	if !ok {
+		return nil
+	}
+	tn, ok := scope.Lookup(named.Obj().Name()).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	if named, ok := tn.Type().(*types.Named); ok {
+		for i, n := 0, named.NumMethods(); i < n; i++ {
+			if m := named.Method(i); m.Name() == fn.Name() {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+func deref(t types.Type) types.Type {
+	if ptr, ok := t.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
@@ -0,0 +1,190 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssautil_test
+
+import (
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/gop/langserver"
+	"golang.org/x/tools/gop/packages"
+	"golang.org/x/tools/gop/ssa/ssautil"
+	"golang.org/x/tools/internal/testenv"
+)
+
+const src = `func add = (
+	func(a, b int) int {
+		return a + b
+	}
+	func(a, b string) string {
+		return a + b
+	}
+)
+
+func sum(s []int) int {
+	n := 0
+	for x <- s {
+		n += x
+	}
+	return add(n, 1)
+}
+
+var count = 2
+
+type point struct {
+	x, y int
+}
+
+const limit = 10
+
+println sum([1, 2]), add("a", "b"), count, point{}, limit
+`
+
+// load generates the Go code of a Go+ main package with source src and
+// loads it.
+func load(t *testing.T) *packages.Package {
+	testenv.NeedsGoBuild(t)
+	testenv.NeedsGOPROOT(t)
+	packages.SetGenGoMode(langserver.ModeInProcess)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod": "module example.com/a\n\ngo 1.18\n",
+		"a.gop":  src,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &packages.Config{Mode: packages.LoadAllSyntax, Dir: dir}
+	pkgs, err := packages.Load(cfg, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 || len(pkgs) != 1 {
+		t.Fatal("there were errors")
+	}
+	if len(pkgs[0].GopSyntax) == 0 || len(pkgs[0].Syntax) == 0 {
+		t.Fatalf("package has Go+ files %v, Go files %v; want both", pkgs[0].GopFiles, pkgs[0].CompiledGoFiles)
+	}
+	return pkgs[0]
+}
+
+func TestPackages(t *testing.T) {
+	pkg := load(t)
+	prog, ssapkgs := ssautil.Packages([]*packages.Package{pkg}, ssa.SanityCheckFunctions)
+	ssapkg := ssapkgs[0]
+	if ssapkg == nil {
+		t.Fatal("no SSA package")
+	}
+	prog.Build()
+
+	// All positions are in the Go+ source.
+	for _, name := range []string{"add__0", "add__1", "sum", "main"} {
+		fn := ssapkg.Func(name)
+		if fn == nil {
+			t.Fatalf("no function %s", name)
+		}
+		if posn := prog.Fset.Position(fn.Pos()); filepath.Base(posn.Filename) != "a.gop" {
+			t.Errorf("%s.Pos() = %s, want a.gop", name, posn)
+		}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if !instr.Pos().IsValid() {
+					continue
+				}
+				if posn := prog.Fset.Position(instr.Pos()); filepath.Base(posn.Filename) != "a.gop" {
+					t.Errorf("%s: %s.Pos() = %s, want a.gop", name, instr, posn)
+				}
+			}
+		}
+	}
+
+	// Package-level members are at the identifiers declaring them.
+	for name, want := range map[string]string{
+		"sum":   "a.gop:10:6",
+		"count": "a.gop:18:5",
+		"point": "a.gop:20:6",
+		"limit": "a.gop:24:7",
+	} {
+		mem := ssapkg.Members[name]
+		if mem == nil {
+			t.Errorf("no member %s", name)
+			continue
+		}
+		posn := prog.Fset.Position(mem.Pos())
+		if got := fmt.Sprintf("%s:%d:%d", filepath.Base(posn.Filename), posn.Line, posn.Column); got != want {
+			t.Errorf("%s.Pos() = %s, want %s", name, got, want)
+		}
+	}
+
+	// The call of the selected overload is on the line of the Go+ call.
+	var found bool
+	for _, b := range ssapkg.Func("sum").Blocks {
+		for _, instr := range b.Instrs {
+			if call, ok := instr.(*ssa.Call); ok && call.Call.StaticCallee() == ssapkg.Func("add__0") {
+				found = true
+				if line := prog.Fset.Position(call.Pos()).Line; line != 15 {
+					t.Errorf("call of add__0 is on line %d, want 15", line)
+				}
+			}
+		}
+	}
+	if !found {
+		t.Error("no call of add__0 in sum")
+	}
+}
+
+func TestFuncValue(t *testing.T) {
+	pkg := load(t)
+	prog, ssapkgs := ssautil.Packages([]*packages.Package{pkg}, 0)
+
+	got := make(map[string]bool)
+	for id, obj := range pkg.GopTypesInfo.Uses {
+		if obj, ok := obj.(*types.Func); ok && obj.Pkg() == pkg.Types {
+			fn := ssautil.FuncValue(prog, obj)
+			if fn == nil || fn.Pkg != ssapkgs[0] {
+				t.Errorf("FuncValue(%s) = %v", id.Name, fn)
+				continue
+			}
+			got[id.Name+" "+fn.Name()] = true
+		}
+	}
+	for _, want := range []string{"add add__0", "add add__1", "sum sum"} {
+		if !got[want] {
+			t.Errorf("no use %s", want)
+		}
+	}
+
+	// The overloaded function has no code.
+	add, _ := pkg.Types.Scope().Lookup("add").(*types.Func)
+	if add == nil {
+		t.Fatal("no overloaded function add")
+	}
+	if fn := ssautil.FuncValue(prog, add); fn != nil {
+		t.Errorf("FuncValue(add) = %v, want nil", fn)
+	}
+}
+
+// TestCreateFromScope checks that a package created without syntax, from
+// the scope of a Go+ package, has no member for an overloaded function.
+func TestCreateFromScope(t *testing.T) {
+	pkg := load(t)
+	prog := ssa.NewProgram(pkg.Fset, 0)
+	ssapkg := prog.CreatePackage(pkg.Types, nil, nil, true)
+	for name := range ssapkg.Members {
+		if name == "add" || strings.HasPrefix(name, "add#") {
+			t.Errorf("unexpected member %s", name)
+		}
+	}
+	if ssapkg.Func("add__0") == nil {
+		t.Errorf("no member add__0")
+	}
+}
@@ -22,3 +22,31 @@ type SubstType interface {
 }
 
 // ----------------------------------------------------------------------------
+
+// CheckOverload reports whether obj is a Go+ overloaded function or method,
+// and returns its overload type if so. The type of such an object is either
+// an OverloadType, or a signature that wraps it:
+//
+//	func(__gop_overload_args__ interface{_(OverloadType)})
+//
+// An overloaded function has no code of its own: each of its overloads is
+// a separate function.
+func CheckOverload(obj types.Object) (OverloadType, bool) {
+	switch t := obj.Type().(type) {
+	case OverloadType:
+		return t, true
+	case *types.Signature:
+		if t.Params().Len() != 1 {
+			break
+		}
+		if it, ok := t.Params().At(0).Type().(*types.Interface); ok && it.NumExplicitMethods() == 1 {
+			if sig, ok := it.ExplicitMethod(0).Type().(*types.Signature); ok && sig.Recv() != nil {
+				ot, ok := sig.Recv().Type().(OverloadType)
+				return ot, ok
+			}
+		}
+	}
+	return nil, false
+}
+
+// ----------------------------------------------------------------------------
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/goplus/gop/ast"
+	"golang.org/x/tools/gop/goputil"
 )
 
 // gopExports export Go+ style func, startLower and overload (GopPackage)
@@ -21,13 +22,13 @@ func gopExports(scopes []*ast.Scope) (exports []string) {
 				exports = append(exports, name)
 				switch obj.Kind {
 				case ast.Fun:
-					if v, ok := toStartWithLowerCase(name); ok {
+					if v, ok := goputil.ToStartWithLowerCase(name); ok {
 						exports = append(exports, v)
 					}
 					if gopPackage && strings.HasSuffix(name, "__0") {
 						name = name[:len(name)-3]
 						exports = append(exports, name)
-						if v, ok := toStartWithLowerCase(name); ok {
+						if v, ok := goputil.ToStartWithLowerCase(name); ok {
 							exports = append(exports, v)
 						}
 					}
@@ -37,10 +38,3 @@ func gopExports(scopes []*ast.Scope) (exports []string) {
 	}
 	return exports
 }
-
-func toStartWithLowerCase(name string) (string, bool) {
-	if c := name[0]; c >= 'A' && c <= 'Z' {
-		return string(c+('a'-'A')) + name[1:], true
-	}
-	return name, false
-}
@@ -16,6 +16,7 @@ import (
 	"github.com/goplus/gop/token"
 	"github.com/goplus/gop/x/typesutil"
 	"golang.org/x/tools/gop/analysis"
+	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/ast/astutil"
 	"golang.org/x/tools/gop/ast/inspector"
 	"golang.org/x/tools/gopls/internal/lsp/safetoken"
@@ -23,6 +24,25 @@ import (
 	"golang.org/x/tools/internal/gop/analysisinternal"
 )
 
+var GopAnalyzer = &analysis.Analyzer{
+	Name:             "gopFillstruct",
+	Doc:              Doc,
+	Requires:         []analysis.IAnalyzer{Analyzer, inspect.Analyzer},
+	Run:              gopRun,
+	RunDespiteErrors: true,
+}
+
+func gopRun(pass *analysis.Pass) (interface{}, error) {
+	if len(pass.GopFiles) == 0 {
+		return nil, nil
+	}
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	for _, d := range GopDiagnoseFillableStructs(inspect, token.NoPos, token.NoPos, pass.Pkg, pass.GopTypesInfo) {
+		pass.Report(d)
+	}
+	return nil, nil
+}
+
 // GopDiagnoseFillableStructs computes diagnostics for fillable struct composite
 // literals overlapping with the provided start and end position.
 //
@@ -0,0 +1,17 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fillstruct_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/gop/analysis/analysistest"
+	"golang.org/x/tools/gopls/internal/lsp/analysis/fillstruct"
+)
+
+func TestGop(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, fillstruct.GopAnalyzer, "gop")
+}
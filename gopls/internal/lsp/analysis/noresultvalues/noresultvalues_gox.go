@@ -0,0 +1,87 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package noresultvalues
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/format"
+	"golang.org/x/tools/gop/analysis"
+	"golang.org/x/tools/gop/analysis/passes/inspect"
+	"golang.org/x/tools/gop/ast/inspector"
+	"golang.org/x/tools/internal/gop/analysisinternal"
+)
+
+var GopAnalyzer = &analysis.Analyzer{
+	Name:             "gopNoresultvalues",
+	Doc:              Doc,
+	Requires:         []analysis.IAnalyzer{Analyzer, inspect.Analyzer},
+	Run:              gopRun,
+	RunDespiteErrors: true,
+}
+
+func gopRun(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	if len(pass.GopFiles) == 0 || len(pass.TypeErrors) == 0 {
+		return nil, nil
+	}
+
+	nodeFilter := []ast.Node{(*ast.ReturnStmt)(nil)}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		retStmt, _ := n.(*ast.ReturnStmt)
+		if len(retStmt.Results) == 0 {
+			return
+		}
+
+		var file *ast.File
+		for _, f := range pass.GopFiles {
+			if f.Pos() <= retStmt.Pos() && retStmt.Pos() < f.End() {
+				file = f
+				break
+			}
+		}
+		if file == nil {
+			return
+		}
+
+		for _, err := range pass.TypeErrors {
+			if !GopFixesError(err.Msg) {
+				continue
+			}
+			// The Go+ type checker reports the error at the return
+			// statement itself.
+			if retStmt.Pos() > err.Pos || err.Pos >= retStmt.End() {
+				continue
+			}
+			var buf bytes.Buffer
+			if err := format.Node(&buf, pass.Fset, file); err != nil {
+				continue
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:     err.Pos,
+				End:     analysisinternal.TypeErrorEndPos(pass.Fset, buf.Bytes(), err.Pos),
+				Message: err.Msg,
+				SuggestedFixes: []analysis.SuggestedFix{{
+					Message: "Delete return values",
+					TextEdits: []analysis.TextEdit{{
+						Pos:     retStmt.Pos(),
+						End:     retStmt.End(),
+						NewText: []byte("return"),
+					}},
+				}},
+			})
+		}
+	})
+	return nil, nil
+}
+
+// GopFixesError is like FixesError, but also accepts the message of the
+// Go+ type checker: "too many arguments to return ... want ()".
+func GopFixesError(msg string) bool {
+	return FixesError(msg) ||
+		strings.HasPrefix(msg, "too many arguments to return") && strings.HasSuffix(msg, "want ()")
+}
@@ -0,0 +1,181 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simplifycompositelit
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/printer"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/gop/analysis"
+	"golang.org/x/tools/gop/analysis/passes/inspect"
+	"golang.org/x/tools/gop/ast/inspector"
+)
+
+var GopAnalyzer = &analysis.Analyzer{
+	Name:     "gopSimplifycompositelit",
+	Doc:      Doc,
+	Requires: []analysis.IAnalyzer{Analyzer, inspect.Analyzer},
+	Run:      gopRun,
+}
+
+func gopRun(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{(*ast.CompositeLit)(nil)}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		expr := n.(*ast.CompositeLit)
+
+		outer := expr
+		var keyType, eltType ast.Expr
+		switch typ := outer.Type.(type) {
+		case *ast.ArrayType:
+			eltType = typ.Elt
+		case *ast.MapType:
+			keyType = typ.Key
+			eltType = typ.Value
+		}
+
+		if eltType == nil {
+			return
+		}
+		var ktyp reflect.Value
+		if keyType != nil {
+			ktyp = reflect.ValueOf(keyType)
+		}
+		typ := reflect.ValueOf(eltType)
+		for _, x := range outer.Elts {
+			// look at value of indexed/named elements
+			if t, ok := x.(*ast.KeyValueExpr); ok {
+				if keyType != nil {
+					gopSimplifyLiteral(pass, ktyp, keyType, t.Key)
+				}
+				x = t.Value
+			}
+			gopSimplifyLiteral(pass, typ, eltType, x)
+		}
+	})
+	return nil, nil
+}
+
+func gopSimplifyLiteral(pass *analysis.Pass, typ reflect.Value, astType, x ast.Expr) {
+	// if the element is a composite literal and its literal type
+	// matches the outer literal's element type exactly, the inner
+	// literal type may be omitted
+	if inner, ok := x.(*ast.CompositeLit); ok && inner.Type != nil && gopMatch(typ, reflect.ValueOf(inner.Type)) {
+		var b bytes.Buffer
+		printer.Fprint(&b, pass.Fset, inner.Type)
+		gopCreateDiagnostic(pass, inner.Type.Pos(), inner.Type.End(), b.String())
+	}
+	// if the outer literal's element type is a pointer type *T
+	// and the element is & of a composite literal of type T,
+	// the inner &T may be omitted.
+	if ptr, ok := astType.(*ast.StarExpr); ok {
+		if addr, ok := x.(*ast.UnaryExpr); ok && addr.Op == token.AND {
+			if inner, ok := addr.X.(*ast.CompositeLit); ok && inner.Type != nil {
+				if gopMatch(reflect.ValueOf(ptr.X), reflect.ValueOf(inner.Type)) {
+					var b bytes.Buffer
+					printer.Fprint(&b, pass.Fset, inner.Type)
+					// Account for the & by subtracting 1 from typ.Pos().
+					gopCreateDiagnostic(pass, inner.Type.Pos()-1, inner.Type.End(), "&"+b.String())
+				}
+			}
+		}
+	}
+}
+
+func gopCreateDiagnostic(pass *analysis.Pass, start, end token.Pos, typ string) {
+	pass.Report(analysis.Diagnostic{
+		Pos:     start,
+		End:     end,
+		Message: "redundant type from array, slice, or map composite literal",
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: fmt.Sprintf("Remove '%s'", typ),
+			TextEdits: []analysis.TextEdit{{
+				Pos:     start,
+				End:     end,
+				NewText: []byte{},
+			}},
+		}},
+	})
+}
+
+// gopMatch is like match, for Go+ syntax trees.
+func gopMatch(pattern, val reflect.Value) bool {
+	// Otherwise, pattern and val must match recursively.
+	if !pattern.IsValid() || !val.IsValid() {
+		return !pattern.IsValid() && !val.IsValid()
+	}
+	if pattern.Type() != val.Type() {
+		return false
+	}
+
+	// Special cases.
+	switch pattern.Type() {
+	case gopIdentType:
+		// For identifiers, only the names need to match
+		// (and none of the other *ast.Object information).
+		// This is a common case, handle it all here instead
+		// of recursing down any further via reflection.
+		p := pattern.Interface().(*ast.Ident)
+		v := val.Interface().(*ast.Ident)
+		return p == nil && v == nil || p != nil && v != nil && p.Name == v.Name
+	case gopObjectPtrType, gopPositionType:
+		// object pointers and token positions always match
+		return true
+	case gopCallExprType:
+		// For calls, the Ellipsis fields (token.Position) must
+		// match since that is how f(x) and f(x...) are different.
+		// Check them here but fall through for the remaining fields.
+		p := pattern.Interface().(*ast.CallExpr)
+		v := val.Interface().(*ast.CallExpr)
+		if p.Ellipsis.IsValid() != v.Ellipsis.IsValid() {
+			return false
+		}
+	}
+
+	p := reflect.Indirect(pattern)
+	v := reflect.Indirect(val)
+	if !p.IsValid() || !v.IsValid() {
+		return !p.IsValid() && !v.IsValid()
+	}
+
+	switch p.Kind() {
+	case reflect.Slice:
+		if p.Len() != v.Len() {
+			return false
+		}
+		for i := 0; i < p.Len(); i++ {
+			if !gopMatch(p.Index(i), v.Index(i)) {
+				return false
+			}
+		}
+		return true
+
+	case reflect.Struct:
+		for i := 0; i < p.NumField(); i++ {
+			if !gopMatch(p.Field(i), v.Field(i)) {
+				return false
+			}
+		}
+		return true
+
+	case reflect.Interface:
+		return gopMatch(p.Elem(), v.Elem())
+	}
+
+	// Handle token integers, etc.
+	return p.Interface() == v.Interface()
+}
+
+// Values/types for special cases of Go+ syntax trees.
+var (
+	gopIdentType     = reflect.TypeOf((*ast.Ident)(nil))
+	gopObjectPtrType = reflect.TypeOf((*ast.Object)(nil))
+	gopPositionType  = reflect.TypeOf(token.NoPos)
+	gopCallExprType  = reflect.TypeOf((*ast.CallExpr)(nil))
+)
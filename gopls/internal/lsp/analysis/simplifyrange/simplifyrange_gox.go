@@ -0,0 +1,128 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simplifyrange
+
+import (
+	"bytes"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/printer"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/gop/analysis"
+	"golang.org/x/tools/gop/analysis/passes/inspect"
+	"golang.org/x/tools/gop/ast/inspector"
+)
+
+var GopAnalyzer = &analysis.Analyzer{
+	Name:     "gopSimplifyrange",
+	Doc:      Doc,
+	Requires: []analysis.IAnalyzer{Analyzer, inspect.Analyzer},
+	Run:      gopRun,
+}
+
+func gopRun(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{
+		(*ast.RangeStmt)(nil),
+		(*ast.ForPhraseStmt)(nil),
+		(*ast.ComprehensionExpr)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.RangeStmt:
+			gopSimplifyRangeStmt(pass, n)
+		case *ast.ForPhraseStmt:
+			gopSimplifyForPhrase(pass, n.ForPhrase)
+		case *ast.ComprehensionExpr:
+			for _, fp := range n.Fors {
+				gopSimplifyForPhrase(pass, fp)
+			}
+		}
+	})
+	return nil, nil
+}
+
+func gopSimplifyRangeStmt(pass *analysis.Pass, stmt *ast.RangeStmt) {
+	x := *stmt
+	copy := &x
+	end := gopNewlineIndex(pass.Fset, copy)
+
+	// Range statements of the form: for i, _ := range x {}
+	var old ast.Expr
+	if gopIsBlank(copy.Value) {
+		old = copy.Value
+		copy.Value = nil
+	}
+	// Range statements of the form: for _ := range x {}
+	if gopIsBlank(copy.Key) && copy.Value == nil {
+		old = copy.Key
+		copy.Key = nil
+	}
+	// Return early if neither if condition is met.
+	if old == nil {
+		return
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:            old.Pos(),
+		End:            old.End(),
+		Message:        "simplify range expression",
+		SuggestedFixes: gopSuggestedFixes(pass.Fset, copy, end),
+	})
+}
+
+// gopSimplifyForPhrase simplifies for phrases of the form: for _, v <- x {}
+// A single variable of a for phrase is the value, so the key can be removed.
+func gopSimplifyForPhrase(pass *analysis.Pass, fp *ast.ForPhrase) {
+	if fp.Key == nil || fp.Key.Name != "_" || fp.Value == nil {
+		return
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:     fp.Key.Pos(),
+		End:     fp.Key.End(),
+		Message: "simplify range expression",
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: "Remove empty key",
+			TextEdits: []analysis.TextEdit{{
+				Pos: fp.Key.Pos(),
+				End: fp.Value.Pos(),
+			}},
+		}},
+	})
+}
+
+func gopSuggestedFixes(fset *token.FileSet, rng *ast.RangeStmt, end token.Pos) []analysis.SuggestedFix {
+	var b bytes.Buffer
+	printer.Fprint(&b, fset, rng)
+	stmt := b.Bytes()
+	index := bytes.Index(stmt, []byte("\n"))
+	// If there is a new line character, then don't replace the body.
+	if index != -1 {
+		stmt = stmt[:index]
+	}
+	return []analysis.SuggestedFix{{
+		Message: "Remove empty value",
+		TextEdits: []analysis.TextEdit{{
+			Pos:     rng.Pos(),
+			End:     end,
+			NewText: stmt,
+		}},
+	}}
+}
+
+func gopNewlineIndex(fset *token.FileSet, rng *ast.RangeStmt) token.Pos {
+	var b bytes.Buffer
+	printer.Fprint(&b, fset, rng)
+	contents := b.Bytes()
+	index := bytes.Index(contents, []byte("\n"))
+	if index == -1 {
+		return rng.End()
+	}
+	return rng.Pos() + token.Pos(index)
+}
+
+func gopIsBlank(x ast.Expr) bool {
+	ident, ok := x.(*ast.Ident)
+	return ok && ident.Name == "_"
+}
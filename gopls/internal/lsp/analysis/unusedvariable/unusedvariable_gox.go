@@ -0,0 +1,318 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package unusedvariable
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/format"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/gop/analysis"
+	"golang.org/x/tools/gop/ast/astutil"
+)
+
+var GopAnalyzer = &analysis.Analyzer{
+	Name:             "gopUnusedvariable",
+	Doc:              Doc,
+	Requires:         []analysis.IAnalyzer{Analyzer},
+	Run:              gopRun,
+	RunDespiteErrors: true,
+}
+
+// gopRun reports unused local variables of Go+ files. Unlike the Go type
+// checker, the Go+ one doesn't report them as errors, so they are found
+// here from the Defs and Uses of the type information.
+func gopRun(pass *analysis.Pass) (interface{}, error) {
+	info := pass.GopTypesInfo
+	if len(pass.GopFiles) == 0 || info == nil {
+		return nil, nil
+	}
+
+	used := make(map[types.Object]bool)
+	for _, obj := range info.Uses {
+		used[obj] = true
+	}
+	for _, file := range pass.GopFiles {
+		var unused []*ast.Ident
+		ast.Inspect(file, func(n ast.Node) bool {
+			var idents []*ast.Ident
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				return n.Body != nil
+			case *ast.DeclStmt:
+				if decl, ok := n.Decl.(*ast.GenDecl); ok && decl.Tok == token.VAR {
+					for _, spec := range decl.Specs {
+						idents = append(idents, spec.(*ast.ValueSpec).Names...)
+					}
+				}
+			case *ast.AssignStmt:
+				if n.Tok == token.DEFINE {
+					for _, expr := range n.Lhs {
+						if ident, ok := expr.(*ast.Ident); ok {
+							idents = append(idents, ident)
+						}
+					}
+				}
+			}
+			for _, ident := range idents {
+				if obj, ok := info.Defs[ident].(*types.Var); ok && ident.Name != "_" && !used[obj] {
+					unused = append(unused, ident)
+				}
+			}
+			return true
+		})
+		for _, ident := range unused {
+			gopRunForIdent(pass, file, ident)
+		}
+	}
+
+	return nil, nil
+}
+
+func gopRunForIdent(pass *analysis.Pass, file *ast.File, ident *ast.Ident) {
+	path, _ := astutil.PathEnclosingInterval(file, ident.Pos(), ident.Pos())
+	if len(path) < 2 || path[0] != ident {
+		return
+	}
+
+	diag := analysis.Diagnostic{
+		Pos:     ident.Pos(),
+		End:     ident.End(),
+		Message: fmt.Sprintf("%s declared and not used", ident.Name),
+	}
+
+	for i := range path {
+		switch stmt := path[i].(type) {
+		case *ast.ValueSpec:
+			// Find GenDecl to which offending ValueSpec belongs.
+			if decl, ok := path[i+1].(*ast.GenDecl); ok {
+				fixes := gopRemoveVariableFromSpec(pass, path, stmt, decl, ident)
+				// fixes may be nil
+				if len(fixes) > 0 {
+					diag.SuggestedFixes = fixes
+					pass.Report(diag)
+				}
+			}
+
+		case *ast.AssignStmt:
+			if stmt.Tok != token.DEFINE {
+				continue
+			}
+
+			containsIdent := false
+			for _, expr := range stmt.Lhs {
+				if expr == ident {
+					containsIdent = true
+				}
+			}
+			if !containsIdent {
+				continue
+			}
+
+			fixes := gopRemoveVariableFromAssignment(path, stmt, ident)
+			// fixes may be nil
+			if len(fixes) > 0 {
+				diag.SuggestedFixes = fixes
+				pass.Report(diag)
+			}
+		}
+	}
+}
+
+func gopRemoveVariableFromSpec(pass *analysis.Pass, path []ast.Node, stmt *ast.ValueSpec, decl *ast.GenDecl, ident *ast.Ident) []analysis.SuggestedFix {
+	newDecl := new(ast.GenDecl)
+	*newDecl = *decl
+	newDecl.Specs = nil
+
+	for _, spec := range decl.Specs {
+		if spec != stmt {
+			newDecl.Specs = append(newDecl.Specs, spec)
+			continue
+		}
+
+		newSpec := new(ast.ValueSpec)
+		*newSpec = *stmt
+		newSpec.Names = nil
+
+		for _, n := range stmt.Names {
+			if n != ident {
+				newSpec.Names = append(newSpec.Names, n)
+			}
+		}
+
+		if len(newSpec.Names) > 0 {
+			newDecl.Specs = append(newDecl.Specs, newSpec)
+		}
+	}
+
+	// decl.End() does not include any comments, so if a comment is present we
+	// need to account for it when we delete the statement
+	end := decl.End()
+	if stmt.Comment != nil && stmt.Comment.End() > end {
+		end = stmt.Comment.End()
+	}
+
+	// There are no other specs left in the declaration, the whole statement can
+	// be deleted
+	if len(newDecl.Specs) == 0 {
+		// Find parent DeclStmt and delete it
+		for _, node := range path {
+			if declStmt, ok := node.(*ast.DeclStmt); ok {
+				return []analysis.SuggestedFix{
+					{
+						Message:   suggestedFixMessage(ident.Name),
+						TextEdits: gopDeleteStmtFromBlock(path, declStmt),
+					},
+				}
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	if err := format.Node(&b, pass.Fset, newDecl); err != nil {
+		return nil
+	}
+
+	return []analysis.SuggestedFix{
+		{
+			Message: suggestedFixMessage(ident.Name),
+			TextEdits: []analysis.TextEdit{
+				{
+					Pos: decl.Pos(),
+					// Avoid adding a new empty line
+					End:     end + 1,
+					NewText: b.Bytes(),
+				},
+			},
+		},
+	}
+}
+
+func gopRemoveVariableFromAssignment(path []ast.Node, stmt *ast.AssignStmt, ident *ast.Ident) []analysis.SuggestedFix {
+	// The only variable in the assignment is unused
+	if len(stmt.Lhs) == 1 {
+		// If LHS has only one expression to be valid it has to have 1 expression
+		// on RHS
+		//
+		// RHS may have side effects, preserve RHS
+		if gopExprMayHaveSideEffects(stmt.Rhs[0]) {
+			// Delete until RHS
+			return []analysis.SuggestedFix{
+				{
+					Message: suggestedFixMessage(ident.Name),
+					TextEdits: []analysis.TextEdit{
+						{
+							Pos: ident.Pos(),
+							End: stmt.Rhs[0].Pos(),
+						},
+					},
+				},
+			}
+		}
+
+		// RHS does not have any side effects, delete the whole statement
+		return []analysis.SuggestedFix{
+			{
+				Message:   suggestedFixMessage(ident.Name),
+				TextEdits: gopDeleteStmtFromBlock(path, stmt),
+			},
+		}
+	}
+
+	// Otherwise replace ident with `_`
+	return []analysis.SuggestedFix{
+		{
+			Message: suggestedFixMessage(ident.Name),
+			TextEdits: []analysis.TextEdit{
+				{
+					Pos:     ident.Pos(),
+					End:     ident.End(),
+					NewText: []byte("_"),
+				},
+			},
+		},
+	}
+}
+
+func gopDeleteStmtFromBlock(path []ast.Node, stmt ast.Stmt) []analysis.TextEdit {
+	block := gopEnclosingBlock(path)
+	if block == nil {
+		return nil
+	}
+
+	nodeIndex := -1
+	for i, blockStmt := range block.List {
+		if blockStmt == stmt {
+			nodeIndex = i
+			break
+		}
+	}
+
+	// The statement we need to delete was not found in BlockStmt
+	if nodeIndex == -1 {
+		return nil
+	}
+
+	// Delete until the end of the block unless there is another statement after
+	// the one we are trying to delete. A body without braces ends with its
+	// last statement.
+	end := block.Rbrace
+	if nodeIndex < len(block.List)-1 {
+		end = block.List[nodeIndex+1].Pos()
+	} else if !end.IsValid() {
+		end = stmt.End()
+	}
+
+	return []analysis.TextEdit{
+		{
+			Pos: stmt.Pos(),
+			End: end,
+		},
+	}
+}
+
+// gopEnclosingBlock returns the innermost BlockStmt of path. The body of
+// the main function of a Go+ file without one has no braces, so it is not
+// on the path: then the body of the enclosing shadow FuncDecl is returned.
+func gopEnclosingBlock(path []ast.Node) *ast.BlockStmt {
+	for i := range path {
+		switch n := path[i].(type) {
+		case *ast.BlockStmt:
+			return n
+		case *ast.FuncDecl:
+			if n.Shadow {
+				return n.Body
+			}
+		}
+	}
+	return nil
+}
+
+// gopExprMayHaveSideEffects reports whether the expression may have side
+// effects (because it contains a function call or channel receive). We
+// disregard runtime panics as well written programs should not encounter them.
+func gopExprMayHaveSideEffects(expr ast.Expr) bool {
+	var mayHaveSideEffects bool
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr: // possible function call
+			mayHaveSideEffects = true
+			return false
+		case *ast.UnaryExpr:
+			if n.Op == token.ARROW { // channel receive
+				mayHaveSideEffects = true
+				return false
+			}
+		case *ast.FuncLit, *ast.LambdaExpr, *ast.LambdaExpr2:
+			return false // evaluating what's inside a FuncLit has no effect
+		}
+		return true
+	})
+
+	return mayHaveSideEffects
+}
@@ -749,6 +749,12 @@ func (an *analysisNode) cacheKey() [sha256.Size]byte {
 		fmt.Fprintln(hasher, fh.FileIdentity())
 	}
 
+	// goxls: Go+ file names and contents
+	fmt.Fprintf(hasher, "gop files: %d\n", len(an.gopFiles))
+	for _, fh := range an.gopFiles {
+		fmt.Fprintln(hasher, fh.FileIdentity())
+	}
+
 	// vdeps, in PackageID order
 	depIDs := make([]string, 0, len(an.succs))
 	for depID := range an.succs {
@@ -1686,6 +1686,7 @@ func doTypeCheck(ctx context.Context, b *typeCheckBatch, ph *packageHandle) (*sy
 			gopFiles = append(gopFiles, cgf.File)
 		}
 		cfg.Importer = newGopImporter(cfg.Importer, ph.m.GopImporter(pkg.fset))
+		cfg.Error = dedupErrors(onError)
 		opts := &typesutil.Config{Types: pkg.types, Fset: pkg.fset, Mod: ph.m.GopMod_()}
 		check := typesutil.NewChecker(cfg, opts, pkg.typesInfo, pkg.gopTypesInfo)
 		_ = check.Files(files, gopFiles)
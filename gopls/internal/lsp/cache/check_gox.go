@@ -5,6 +5,7 @@
 package cache
 
 import (
+	"go/token"
 	"go/types"
 
 	"github.com/goplus/gop/ast"
@@ -39,3 +40,22 @@ func (p *gopImporter) Import(path string) (*types.Package, error) {
 func newGopImporter(imp, gop types.Importer) types.Importer {
 	return &gopImporter{imp, gop}
 }
+
+// dedupErrors returns an error handler that calls onError once for each
+// distinct type error, as the Go+ type checker may report an error more
+// than once.
+func dedupErrors(onError func(e error)) func(e error) {
+	type errorKey struct {
+		pos token.Pos
+		msg string
+	}
+	seen := make(map[errorKey]bool)
+	return func(e error) {
+		err := e.(types.Error)
+		key := errorKey{err.Pos, err.Msg}
+		if !seen[key] {
+			seen[key] = true
+			onError(e)
+		}
+	}
+}
@@ -446,6 +446,9 @@ func buildMetadata(updates map[PackageID]*source.Metadata, pkg *packages.Package
 	}
 	if len(pkg.GopFiles) > 0 {
 		m.LoadGopMod()
+		// goxls: unless gop/packages loads its Go+ files instead, a package
+		// without Go files is reported by go list with an error only.
+		m.ListFailed = len(pkg.GoFiles) == 0 && len(pkg.Errors) > 0
 	}
 
 	updates[id] = m
@@ -773,7 +776,7 @@ func isTestMain(pkg *packages.Package, gocache string) bool {
 		return false
 	}
 	// Test mains always have exactly one GoFile that is in the build cache.
-	if len(pkg.GoFiles) > 1 {
+	if len(pkg.GoFiles) != 1 { // goxls: the test main of Go+ tests may have no GoFiles
 		return false
 	}
 	if !source.InDir(gocache, pkg.GoFiles[0]) {
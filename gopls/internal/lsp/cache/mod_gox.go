@@ -0,0 +1,113 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/goplus/mod/modfile"
+	"github.com/qiniu/x/errors"
+	xmodfile "golang.org/x/mod/modfile"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/event/tag"
+	"golang.org/x/tools/internal/memoize"
+)
+
+// ParseGopMod parses a gop.mod file, using a cache. It may return partial
+// results and an error.
+func (s *snapshot) ParseGopMod(ctx context.Context, fh source.FileHandle) (*source.ParsedGopModFile, error) {
+	uri := fh.URI()
+
+	s.mu.Lock()
+	entry, hit := s.parseGopModHandles.Get(uri)
+	s.mu.Unlock()
+
+	type parseGopModKey source.FileIdentity
+	type parseGopModResult struct {
+		parsed *source.ParsedGopModFile
+		err    error
+	}
+
+	// cache miss?
+	if !hit {
+		handle, release := s.store.Promise(parseGopModKey(fh.FileIdentity()), func(ctx context.Context, _ interface{}) interface{} {
+			parsed, err := parseGopModImpl(ctx, fh)
+			return parseGopModResult{parsed, err}
+		})
+
+		entry = handle
+		s.mu.Lock()
+		s.parseGopModHandles.Set(uri, entry, func(_, _ interface{}) { release() })
+		s.mu.Unlock()
+	}
+
+	// Await result.
+	v, err := s.awaitPromise(ctx, entry.(*memoize.Promise))
+	if err != nil {
+		return nil, err
+	}
+	res := v.(parseGopModResult)
+	return res.parsed, res.err
+}
+
+// parseGopModImpl parses a gop.mod file. It may return partial results and
+// an error.
+func parseGopModImpl(ctx context.Context, fh source.FileHandle) (*source.ParsedGopModFile, error) {
+	_, done := event.Start(ctx, "cache.ParseGopMod", tag.URI.Of(fh.URI()))
+	defer done()
+
+	content, err := fh.Content()
+	if err != nil {
+		return nil, err
+	}
+	m := protocol.NewMapper(fh.URI(), content)
+	file, parseErr := modfile.Parse(fh.URI().Filename(), content, nil)
+	// Attempt to convert the error to a standardized parse error.
+	var parseErrors []*source.Diagnostic
+	if parseErr != nil {
+		var pos []xmodfile.Position
+		var msgs []string
+		switch errs := errors.Err(parseErr).(type) {
+		case xmodfile.ErrorList: // syntax errors
+			for _, e := range errs {
+				pos = append(pos, e.Pos)
+				msgs = append(msgs, e.Err.Error())
+			}
+		case errors.List: // errors in gop.mod directives
+			for _, e := range errs {
+				e, ok := e.(*modfile.Error)
+				if !ok {
+					return nil, fmt.Errorf("unexpected parse error type %v", e)
+				}
+				pos = append(pos, e.Pos)
+				msgs = append(msgs, errors.Summary(e.Err))
+			}
+		default:
+			return nil, fmt.Errorf("unexpected parse error type %v", parseErr)
+		}
+		for i, msg := range msgs {
+			rng, err := m.OffsetRange(pos[i].Byte, pos[i].Byte)
+			if err != nil {
+				return nil, err
+			}
+			parseErrors = append(parseErrors, &source.Diagnostic{
+				URI:      fh.URI(),
+				Range:    rng,
+				Severity: protocol.SeverityError,
+				Source:   source.ParseError,
+				Message:  msg,
+			})
+		}
+	}
+	return &source.ParsedGopModFile{
+		URI:         fh.URI(),
+		Mapper:      m,
+		File:        file,
+		ParseErrors: parseErrors,
+	}, parseErr
+}
@@ -197,6 +197,7 @@ func (s *Session) createView(ctx context.Context, name string, folder span.URI,
 		unloadableFiles:      make(map[span.URI]struct{}),
 		parseModHandles:      persistent.NewMap(uriLessInterface),
 		parseWorkHandles:     persistent.NewMap(uriLessInterface),
+		parseGopModHandles:   persistent.NewMap(uriLessInterface), // goxls: Go+
 		modTidyHandles:       persistent.NewMap(uriLessInterface),
 		modVulnHandles:       persistent.NewMap(uriLessInterface),
 		modWhyHandles:        persistent.NewMap(uriLessInterface),
@@ -31,7 +31,6 @@ import (
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/types/objectpath"
-	"golang.org/x/tools/gop/goputil"
 	"golang.org/x/tools/gopls/internal/bug"
 	"golang.org/x/tools/gopls/internal/goxls"
 	"golang.org/x/tools/gopls/internal/lsp/command"
@@ -147,6 +146,9 @@ type snapshot struct {
 	// The handles need not refer to only the view's go.work file.
 	parseWorkHandles *persistent.Map // from span.URI to *memoize.Promise[parseWorkResult]
 
+	// goxls: parseGopModHandles keeps track of any parseGopModHandles for the snapshot.
+	parseGopModHandles *persistent.Map // from span.URI to *memoize.Promise[parseGopModResult]
+
 	// Preserve go.mod-related handles to avoid garbage-collecting the results
 	// of various calls to the go command. The handles need not refer to only
 	// the view's go.mod file.
@@ -258,6 +260,7 @@ func (s *snapshot) destroy(destroyedBy string) {
 	s.symbolizeHandles.Destroy()
 	s.parseModHandles.Destroy()
 	s.parseWorkHandles.Destroy()
+	s.parseGopModHandles.Destroy() // goxls: Go+
 	s.modTidyHandles.Destroy()
 	s.modVulnHandles.Destroy()
 	s.modWhyHandles.Destroy()
@@ -890,7 +893,7 @@ func (s *snapshot) resetActivePackagesLocked() {
 const fileExtensions = "go,mod,sum,work"
 
 func (s *snapshot) fileWatchingGlobPatterns(ctx context.Context) map[string]struct{} {
-	extensions := fileExtensions + "," + gopExtensions() // goxls: Go+
+	extensions := fileExtensions + "," + s.gopExtensions() // goxls: Go+
 	for _, ext := range s.View().Options().TemplateExtensions {
 		extensions += "," + ext
 	}
@@ -1892,6 +1895,9 @@ func (s *snapshot) clone(ctx, bgCtx context.Context, changes map[span.URI]*fileC
 	reinit := false
 	wsModFiles, wsModFilesErr := s.workspaceModFiles, s.workspaceModFilesErr
 
+	// goxls: classfiles registered in gop.mod may have changed
+	s.view.invalidateGopClassfiles(changes)
+
 	if workURI, _ := s.view.GOWORK(); workURI != "" {
 		if change, ok := changes[workURI]; ok {
 			wsModFiles, wsModFilesErr = computeWorkspaceModFiles(ctx, s.view.gomod, workURI, s.view.effectiveGO111MODULE(), &unappliedChanges{
@@ -1909,6 +1915,10 @@ func (s *snapshot) clone(ctx, bgCtx context.Context, changes map[span.URI]*fileC
 			reinit = true
 		}
 	}
+	// goxls: so has gop.mod, which may register classfiles
+	if gopModChanged(wsModFiles, changes) {
+		reinit = true
+	}
 
 	// Finally, process sumfile changes that may affect loading.
 	for uri, change := range changes {
@@ -1964,6 +1974,7 @@ func (s *snapshot) clone(ctx, bgCtx context.Context, changes map[span.URI]*fileC
 		unloadableFiles:      make(map[span.URI]struct{}, len(s.unloadableFiles)),
 		parseModHandles:      s.parseModHandles.Clone(),
 		parseWorkHandles:     s.parseWorkHandles.Clone(),
+		parseGopModHandles:   s.parseGopModHandles.Clone(), // goxls: Go+
 		modTidyHandles:       s.modTidyHandles.Clone(),
 		modWhyHandles:        s.modWhyHandles.Clone(),
 		modVulnHandles:       s.modVulnHandles.Clone(),
@@ -2045,7 +2056,7 @@ func (s *snapshot) clone(ctx, bgCtx context.Context, changes map[span.URI]*fileC
 		var invalidateMetadata, pkgFileChanged, importDeleted bool
 		if strings.HasSuffix(uri.Filename(), ".go") {
 			invalidateMetadata, pkgFileChanged, importDeleted = metadataChanges(ctx, s, originalFH, change.fileHandle)
-		} else if goputil.FileKind(path.Ext(uri.Filename())) != goputil.FileUnknown { // goxls: Support Go+
+		} else if s.view.checkGopFile(uri, path.Ext(uri.Filename())) { // goxls: Support Go+
 			invalidateMetadata, pkgFileChanged, importDeleted = gopMetadataChanges(ctx, s, originalFH, change.fileHandle)
 		}
 
@@ -2097,6 +2108,7 @@ func (s *snapshot) clone(ctx, bgCtx context.Context, changes map[span.URI]*fileC
 
 		result.parseModHandles.Delete(uri)
 		result.parseWorkHandles.Delete(uri)
+		result.parseGopModHandles.Delete(uri) // goxls: Go+
 		// Handle the invalidated file; it may have new contents or not exist.
 		if !change.exists {
 			result.files.Delete(uri)
@@ -5,23 +5,162 @@
 package cache
 
 import (
+	"bytes"
 	"context"
 	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/goplus/gop/ast"
+	"github.com/goplus/mod"
 	"github.com/goplus/mod/gopmod"
+	"github.com/goplus/mod/modload"
 	"golang.org/x/tools/gop/goputil"
+	"golang.org/x/tools/gop/packages"
 	"golang.org/x/tools/gopls/internal/bug"
 	"golang.org/x/tools/gopls/internal/goxls/parserutil"
 	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/gopls/internal/span"
 )
 
-func checkGopFile(fh source.FileHandle, fext string) bool {
-	return goputil.FileKind(fext) != goputil.FileUnknown
+// gopClassfiles holds the classfiles registered by a Go+ module.
+type gopClassfiles struct {
+	mod  *gopmod.Module // nil if the directory is not inside a module
+	exts []string       // extensions not known to goputil.Exts
 }
 
-func gopExtensions() string {
-	return goputil.Exts()
+var (
+	gopBuiltinClassesOnce sync.Once
+	gopBuiltinClasses     *gopClassfiles
+)
+
+// gopBuiltinClassfiles returns the classfiles of a module without a
+// gop.mod file: those builtin to Go+, such as .gsh.
+func gopBuiltinClassfiles() *gopClassfiles {
+	gopBuiltinClassesOnce.Do(func() {
+		c := &gopClassfiles{mod: gopmod.New(modload.Default)}
+		c.exts, _ = goputil.ImportClasses(c.mod) // no I/O: no classfile modules
+		gopBuiltinClasses = c
+	})
+	return gopBuiltinClasses
+}
+
+// gopClassfilesOf returns the classfiles registered by the Go+ module that
+// contains dir. Results are cached until the next gop.mod or go.mod change.
+func (v *View) gopClassfilesOf(dir string) *gopClassfiles {
+	v.gopClassesMu.Lock()
+	c, ok := v.gopClasses[dir]
+	gen := v.gopClassesGen
+	v.gopClassesMu.Unlock()
+	if ok {
+		return c
+	}
+
+	// Only a gop.mod file, or a //gop:class require of go.mod, registers
+	// classfiles besides the builtin ones, so gopmod.Load and the imports
+	// of the classfile modules are skipped for other modules.
+	c = new(gopClassfiles)
+	if root, gomod, err := mod.FindGoMod(dir); err == nil {
+		if !gopRegistersClasses(root, gomod) {
+			c = gopBuiltinClassfiles()
+		} else if mod, err := gopmod.Load(root); err == nil {
+			c.mod = mod
+			c.exts, _ = goputil.ImportClasses(mod)
+		}
+	}
+
+	v.gopClassesMu.Lock()
+	defer v.gopClassesMu.Unlock()
+	if v.gopClassesGen == gen { // not invalidated meanwhile
+		if v.gopClasses == nil {
+			v.gopClasses = make(map[string]*gopClassfiles)
+		}
+		v.gopClasses[dir] = c
+	}
+	return c
+}
+
+// gopRegistersClasses reports whether the module in directory root, with
+// go.mod file gomod, may register classfiles: whether it has a gop.mod
+// file, or a go.mod file that mentions gop:class.
+func gopRegistersClasses(root, gomod string) bool {
+	if _, err := os.Stat(filepath.Join(root, "gop.mod")); err == nil {
+		return true
+	}
+	data, err := os.ReadFile(gomod)
+	return err == nil && bytes.Contains(data, []byte("gop:class"))
+}
+
+// invalidateGopClassfiles forgets the cached Go+ modules, and with them the
+// registered classfiles, if any of changes modifies a gop.mod or go.mod file.
+func (v *View) invalidateGopClassfiles(changes map[span.URI]*fileChange) {
+	for uri, change := range changes {
+		if change.isUnchanged {
+			continue
+		}
+		if base := filepath.Base(uri.Filename()); base == "gop.mod" || base == "go.mod" {
+			packages.Default.ForgetMod(filepath.Join(filepath.Dir(uri.Filename()), "go.mod"))
+			v.gopClassesMu.Lock()
+			v.gopClasses = nil
+			v.gopClassesGen++
+			v.gopClassesMu.Unlock()
+		}
+	}
+}
+
+// gopModChanged reports whether changes modify the gop.mod file of a module
+// in wsModFiles. Like go.mod, gop.mod only matters once saved.
+func gopModChanged(wsModFiles map[span.URI]struct{}, changes map[span.URI]*fileChange) bool {
+	for uri, change := range changes {
+		if filepath.Base(uri.Filename()) != "gop.mod" || !change.fileHandle.Saved() || change.isUnchanged {
+			continue
+		}
+		modURI := span.URIFromPath(filepath.Join(filepath.Dir(uri.Filename()), "go.mod"))
+		if _, ok := wsModFiles[modURI]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkGopFile reports whether uri, with extension fext, is a Go+ file.
+func (v *View) checkGopFile(uri span.URI, fext string) bool {
+	if goputil.FileKind(fext) != goputil.FileUnknown {
+		return true
+	}
+	if fext == "" {
+		return false
+	}
+	fname := uri.Filename()
+	c := v.gopClassfilesOf(filepath.Dir(fname))
+	return goputil.FileKindOf(c.mod, filepath.Base(fname)) != goputil.FileUnknown
+}
+
+// gopExtensions returns the extensions of Go+ files in the workspace,
+// including classfiles registered by the workspace modules.
+func (s *snapshot) gopExtensions() string {
+	dirs := []string{s.view.folder.Filename()}
+	for modURI := range s.workspaceModFiles {
+		dirs = append(dirs, filepath.Dir(modURI.Filename()))
+	}
+	seen := make(map[string]bool)
+	var exts []string
+	for _, dir := range dirs {
+		for _, ext := range s.view.gopClassfilesOf(dir).exts {
+			if !seen[ext] {
+				seen[ext] = true
+				exts = append(exts, ext)
+			}
+		}
+	}
+	if len(exts) == 0 {
+		return goputil.Exts()
+	}
+	sort.Strings(exts)
+	return goputil.Exts() + "," + strings.Join(exts, ",")
 }
 
 // gopMetadataChanges detects features of the change from oldFH->newFH that may
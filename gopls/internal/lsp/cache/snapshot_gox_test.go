@@ -0,0 +1,62 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/gop/goputil"
+	"golang.org/x/tools/gopls/internal/span"
+)
+
+func TestGopClassfilesOf(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("nomod/a.gsh", "")
+	write("plain/go.mod", "module example.com/plain\n\ngo 1.18\n")
+	write("gop/go.mod", "module example.com/gop\n\ngo 1.18\n")
+	write("gop/gop.mod", "gop 1.2\n")
+
+	v := new(View)
+
+	// Outside of a module, only the builtin extensions are known.
+	if c := v.gopClassfilesOf(filepath.Join(dir, "nomod")); c.mod != nil {
+		t.Errorf("outside of a module: got a module")
+	}
+
+	// A module without gop.mod has the builtin classfiles, such as .gsh.
+	plain := v.gopClassfilesOf(filepath.Join(dir, "plain"))
+	if plain != gopBuiltinClassfiles() {
+		t.Errorf("module without gop.mod: got classfiles %v, want the builtin ones", plain)
+	}
+	if kind := goputil.FileKindOf(plain.mod, "a.gsh"); kind != goputil.FileGopClass {
+		t.Errorf("module without gop.mod: FileKindOf(a.gsh) = %v, want FileGopClass", kind)
+	}
+
+	// A module with gop.mod is loaded, and cached until it changes.
+	gop := v.gopClassfilesOf(filepath.Join(dir, "gop"))
+	if gop.mod == nil || gop == gopBuiltinClassfiles() {
+		t.Fatalf("module with gop.mod: not loaded")
+	}
+	if c := v.gopClassfilesOf(filepath.Join(dir, "gop")); c != gop {
+		t.Errorf("module with gop.mod: not cached")
+	}
+	v.invalidateGopClassfiles(map[span.URI]*fileChange{
+		span.URIFromPath(filepath.Join(dir, "gop", "gop.mod")): {},
+	})
+	if c := v.gopClassfilesOf(filepath.Join(dir, "gop")); c == gop {
+		t.Errorf("module with gop.mod: cached after a change of gop.mod")
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"context"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/gopls/internal/goxls/parserutil"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/gopls/internal/span"
+)
+
+// gopSymbolHandleKey is the cache key of the symbols of a Go+ file.
+//
+// Unlike Go files, the symbols of a Go+ classfile depend on its name, so the
+// URI is part of the key.
+type gopSymbolHandleKey struct {
+	uri  span.URI
+	hash source.Hash
+}
+
+// gopSymbolizeImpl reads and parses a Go+ file and extracts symbols from it.
+func gopSymbolizeImpl(ctx context.Context, snapshot *snapshot, fh source.FileHandle) ([]source.Symbol, error) {
+	pgf, err := snapshot.ParseGop(ctx, fh, parserutil.ParseFull)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &gopSymbolWalker{
+		tokFile: pgf.Tok,
+		mapper:  pgf.Mapper,
+	}
+	if classType, ok := parserutil.GetClassType(pgf.File, fh.URI().Filename()); ok {
+		w.classFile(pgf.File, classType)
+	} else {
+		w.fileDecls(pgf.File.Decls)
+	}
+
+	return w.symbols, w.firstError
+}
+
+type gopSymbolWalker struct {
+	// for computing positions
+	tokFile *token.File
+	mapper  *protocol.Mapper
+
+	symbols    []source.Symbol
+	firstError error
+}
+
+func (w *gopSymbolWalker) atNode(node ast.Node, name string, kind protocol.SymbolKind, path ...string) {
+	rng, err := w.mapper.NodeRange(w.tokFile, node)
+	if err != nil {
+		w.error(err)
+		return
+	}
+	w.atRange(rng, name, kind, path...)
+}
+
+func (w *gopSymbolWalker) atRange(rng protocol.Range, name string, kind protocol.SymbolKind, path ...string) {
+	var b strings.Builder
+	for _, elem := range path {
+		if elem != "" {
+			b.WriteString(elem)
+			b.WriteString(".")
+		}
+	}
+	b.WriteString(name)
+
+	sym := source.Symbol{
+		Name:  b.String(),
+		Kind:  kind,
+		Range: rng,
+	}
+	w.symbols = append(w.symbols, sym)
+}
+
+func (w *gopSymbolWalker) error(err error) {
+	if err != nil && w.firstError == nil {
+		w.firstError = err
+	}
+}
+
+// classFile processes the symbols of a classfile: the class itself (named
+// after the file), its fields and its methods.
+func (w *gopSymbolWalker) classFile(f *ast.File, classType string) {
+	// The class has no declaration of its own, so report it at the start of
+	// the file.
+	w.atRange(protocol.Range{}, classType, protocol.Class)
+
+	decls := f.Decls
+	if fields := gopClassFields(f); fields != nil {
+		for _, spec := range fields.Specs {
+			spec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			if len(spec.Names) == 0 { // embedded field
+				w.atNode(spec.Type, typesutil.ExprString(spec.Type), protocol.Field, classType)
+				continue
+			}
+			for _, name := range spec.Names {
+				w.atNode(name, name.Name, protocol.Field, classType)
+			}
+		}
+		rest := make([]ast.Decl, 0, len(decls)-1)
+		for _, decl := range decls {
+			if decl != fields {
+				rest = append(rest, decl)
+			}
+		}
+		decls = rest
+	}
+
+	for _, decl := range decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Shadow {
+				// The shadow entry of a classfile (its MainEntry or Main
+				// method) is the body of the class, with no name in the
+				// source.
+				continue
+			}
+			recv := classType
+			if !decl.IsClass && decl.Recv != nil && len(decl.Recv.List) > 0 {
+				recv = gopRecvName(decl.Recv.List[0].Type)
+			}
+			w.atNode(decl.Name, decl.Name.Name, protocol.Method, recv)
+		case *ast.OverloadFuncDecl:
+			recv := classType
+			if !decl.IsClass && decl.Recv != nil && len(decl.Recv.List) > 0 {
+				recv = gopRecvName(decl.Recv.List[0].Type)
+			}
+			w.atNode(decl.Name, decl.Name.Name, protocol.Method, recv)
+		default:
+			w.fileDecls([]ast.Decl{decl})
+		}
+	}
+}
+
+// gopClassFields returns the declaration of the fields of a classfile, which
+// is the first var declaration preceding any other kind of declaration.
+func gopClassFields(f *ast.File) *ast.GenDecl {
+	for _, decl := range f.Decls {
+		g, ok := decl.(*ast.GenDecl)
+		if !ok {
+			break
+		}
+		if g.Tok == token.VAR {
+			return g
+		}
+	}
+	return nil
+}
+
+func (w *gopSymbolWalker) fileDecls(decls []ast.Decl) {
+	for _, decl := range decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Shadow {
+				// The shadow entry of a .gop file is the main function, but
+				// it has no name in the source.
+				continue
+			}
+			kind := protocol.Function
+			var recv string
+			if decl.Recv.NumFields() > 0 {
+				kind = protocol.Method
+				recv = gopRecvName(decl.Recv.List[0].Type)
+			}
+			w.atNode(decl.Name, decl.Name.Name, kind, recv)
+		case *ast.OverloadFuncDecl:
+			kind := protocol.Function
+			var recv string
+			if decl.Recv.NumFields() > 0 {
+				kind = protocol.Method
+				recv = gopRecvName(decl.Recv.List[0].Type)
+			}
+			w.atNode(decl.Name, decl.Name.Name, kind, recv)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					kind := gopGuessKind(spec)
+					w.atNode(spec.Name, spec.Name.Name, kind)
+					w.walkType(spec.Type, spec.Name.Name)
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						kind := protocol.Variable
+						if decl.Tok == token.CONST {
+							kind = protocol.Constant
+						}
+						w.atNode(name, name.Name, kind)
+					}
+				}
+			}
+		}
+	}
+}
+
+// gopRecvName returns the name of the receiver type expression rtyp,
+// or "" if it is not a valid receiver type.
+func gopRecvName(rtyp ast.Expr) string {
+	for {
+		switch t := rtyp.(type) {
+		case *ast.ParenExpr:
+			rtyp = t.X
+		case *ast.StarExpr:
+			rtyp = t.X
+		case *ast.IndexExpr:
+			rtyp = t.X
+		case *ast.IndexListExpr:
+			rtyp = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func gopGuessKind(spec *ast.TypeSpec) protocol.SymbolKind {
+	switch spec.Type.(type) {
+	case *ast.InterfaceType:
+		return protocol.Interface
+	case *ast.StructType:
+		return protocol.Struct
+	case *ast.FuncType:
+		return protocol.Function
+	}
+	return protocol.Class
+}
+
+// walkType processes symbols related to a type expression. path is path of
+// nested type identifiers to the type expression.
+func (w *gopSymbolWalker) walkType(typ ast.Expr, path ...string) {
+	switch st := typ.(type) {
+	case *ast.StructType:
+		for _, field := range st.Fields.List {
+			w.walkField(field, protocol.Field, protocol.Field, path...)
+		}
+	case *ast.InterfaceType:
+		for _, field := range st.Methods.List {
+			w.walkField(field, protocol.Interface, protocol.Method, path...)
+		}
+	}
+}
+
+// walkField processes symbols related to the struct field or interface method.
+//
+// unnamedKind and namedKind are the symbol kinds if the field is resp. unnamed
+// or named. path is the path of nested identifiers containing the field.
+func (w *gopSymbolWalker) walkField(field *ast.Field, unnamedKind, namedKind protocol.SymbolKind, path ...string) {
+	if len(field.Names) == 0 {
+		switch typ := field.Type.(type) {
+		case *ast.SelectorExpr:
+			// embedded qualified type
+			w.atNode(field, typ.Sel.Name, unnamedKind, path...)
+		default:
+			w.atNode(field, typesutil.ExprString(field.Type), unnamedKind, path...)
+		}
+	}
+	for _, name := range field.Names {
+		w.atNode(name, name.Name, namedKind, path...)
+		w.walkType(field.Type, append(path, name.Name)...)
+	}
+}
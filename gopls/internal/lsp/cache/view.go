@@ -59,6 +59,14 @@ type View struct {
 	importsState    *importsState
 	gopImportsState *gopImportsState // goxls: Go+
 
+	// goxls: gopClasses caches the classfiles registered by Go+ modules,
+	// keyed by directory. It is reset when a gop.mod or go.mod file changes,
+	// which increments gopClassesGen. No I/O is done while holding
+	// gopClassesMu.
+	gopClassesMu  sync.Mutex
+	gopClasses    map[string]*gopClassfiles
+	gopClassesGen int
+
 	// moduleUpgrades tracks known upgrades for module paths in each modfile.
 	// Each modfile has a map of module name to upgrade version.
 	moduleUpgradesMu sync.Mutex
@@ -426,13 +434,16 @@ func (v *View) FileKind(fh source.FileHandle) source.FileKind {
 	case ".go":
 		return source.Go
 	case ".mod":
+		if filepath.Base(fh.URI().Filename()) == "gop.mod" { // goxls: gop.mod
+			return source.GopMod
+		}
 		return source.Mod
 	case ".sum":
 		return source.Sum
 	case ".work":
 		return source.Work
 	default:
-		if checkGopFile(fh, fext) { // goxls: check Go+ file
+		if v.checkGopFile(fh.URI(), fext) { // goxls: check Go+ file
 			return source.Gop
 		}
 	}
@@ -644,7 +644,7 @@ func (c *connection) openFile(ctx context.Context, uri span.URI) (*cmdFile, erro
 	p := &protocol.DidOpenTextDocumentParams{
 		TextDocument: protocol.TextDocumentItem{
 			URI:        protocol.URIFromSpanURI(uri),
-			LanguageID: "go",
+			LanguageID: languageID(uri), // goxls: Go+ files
 			Version:    1,
 			Text:       string(file.mapper.Content),
 		},
@@ -8,14 +8,19 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strings"
 	"text/tabwriter"
 
+	"github.com/goplus/gop"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/gopls/internal/goxls/parserutil"
 	"golang.org/x/tools/gopls/internal/lsp/debug"
 	"golang.org/x/tools/gopls/internal/lsp/filecache"
 	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/gopls/internal/span"
 	"golang.org/x/tools/internal/tool"
 )
 
@@ -190,3 +195,22 @@ func (app *GopApplication) featureCommands() []tool.Application {
 		&vulncheck{app: goApp},
 	}
 }
+
+// languageID returns the language identifier used to open uri. Only Go
+// files are identified explicitly: the kind of other files, such as Go+
+// files and classfiles registered in gop.mod, is left to the server,
+// which determines it from the file name.
+func languageID(uri span.URI) string {
+	if filepath.Ext(uri.Filename()) == ".go" {
+		return "go"
+	}
+	return ""
+}
+
+// parseGopFile parses the Go+ file filename, recognizing the classfiles
+// of its module, and reports any syntax error.
+func parseGopFile(filename string, src []byte) error {
+	mod, _ := gop.LoadMod(filepath.Dir(filename))
+	_, err := parserutil.ParseFileEx(mod, token.NewFileSet(), filename, src, 0)
+	return err
+}
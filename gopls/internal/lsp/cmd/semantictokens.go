@@ -106,16 +106,23 @@ func (c *semtok) Run(ctx context.Context, args ...string) error {
 	if err != nil {
 		return err
 	}
-	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, args[0], buf, 0)
-	if err != nil {
-		log.Printf("parsing %s failed %v", args[0], err)
-		return err
-	}
-	tok := fset.File(f.Pos())
-	if tok == nil {
-		// can't happen; just parsed this file
-		return fmt.Errorf("can't find %s in fset", args[0])
+	if languageID(uri) != "go" { // goxls: parse Go+ files with the Go+ parser
+		if err := parseGopFile(args[0], buf); err != nil {
+			log.Printf("parsing %s failed %v", args[0], err)
+			return err
+		}
+	} else {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, args[0], buf, 0)
+		if err != nil {
+			log.Printf("parsing %s failed %v", args[0], err)
+			return err
+		}
+		tok := fset.File(f.Pos())
+		if tok == nil {
+			// can't happen; just parsed this file
+			return fmt.Errorf("can't find %s in fset", args[0])
+		}
 	}
 	colmap = protocol.NewMapper(uri, buf)
 	err = decorate(file.uri.Filename(), resp.Data)
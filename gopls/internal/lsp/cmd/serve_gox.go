@@ -14,16 +14,19 @@ import (
 
 	qlog "github.com/qiniu/x/log"
 	"golang.org/x/tools/gop/langserver"
+	"golang.org/x/tools/internal/tool"
 )
 
 // gopServe is a struct that exposes the configurable parts of the LSP server as
 // flags, in the right form for tool.Main to consume.
 type gopServe struct {
 	*Serve
+
+	GenGo string `flag:"gengo" help:"how to generate Go+ code: serve (by an external 'gop serve' process) or inprocess; defaults to $GOXLS_GENGO"`
 }
 
 func newGopServe(app *Application) gopServe {
-	return gopServe{&app.Serve}
+	return gopServe{Serve: &app.Serve}
 }
 
 func (s *gopServe) ShortHelp() string {
@@ -56,8 +59,24 @@ func (s *gopServe) Run(ctx context.Context, args ...string) error {
 		}
 	}
 
+	mode, err := s.genGoMode()
+	if err != nil {
+		return tool.CommandLineErrorf("%v", err)
+	}
+	langserver.SetMode(mode)
+
 	langserver.Initialize()
 	defer langserver.Shutdown()
 
 	return s.Serve.Run(ctx, args...)
 }
+
+// genGoMode returns how Go+ code is generated, as set by the -gengo flag,
+// or else by the GOXLS_GENGO environment variable.
+func (s *gopServe) genGoMode() (langserver.Mode, error) {
+	gengo := s.GenGo
+	if gengo == "" {
+		gengo = os.Getenv("GOXLS_GENGO")
+	}
+	return langserver.ParseMode(gengo)
+}
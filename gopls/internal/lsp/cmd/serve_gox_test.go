@@ -0,0 +1,40 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"testing"
+
+	"golang.org/x/tools/gop/langserver"
+)
+
+func TestGenGoMode(t *testing.T) {
+	for _, test := range []struct {
+		flag, env string
+		want      langserver.Mode
+		wantErr   bool
+	}{
+		{"", "", langserver.ModeServe, false},
+		{"serve", "", langserver.ModeServe, false},
+		{"inprocess", "", langserver.ModeInProcess, false},
+		{"", "inprocess", langserver.ModeInProcess, false},
+		{"", "serve", langserver.ModeServe, false},
+		{"serve", "inprocess", langserver.ModeServe, false}, // the flag wins
+		{"inprocess", "bogus", langserver.ModeInProcess, false},
+		{"bogus", "", 0, true},
+		{"", "bogus", 0, true},
+	} {
+		t.Setenv("GOXLS_GENGO", test.env)
+		s := &gopServe{GenGo: test.flag}
+		got, err := s.genGoMode()
+		if (err != nil) != test.wantErr {
+			t.Errorf("-gengo=%q GOXLS_GENGO=%q: got error %v, want error %t", test.flag, test.env, err, test.wantErr)
+			continue
+		}
+		if err == nil && got != test.want {
+			t.Errorf("-gengo=%q GOXLS_GENGO=%q: got mode %v, want %v", test.flag, test.env, got, test.want)
+		}
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/goplus/gop"
+	"golang.org/x/tools/gop/goputil"
 	goplsbug "golang.org/x/tools/gopls/internal/bug"
 	"golang.org/x/tools/gopls/internal/lsp"
 	"golang.org/x/tools/gopls/internal/lsp/command"
@@ -239,6 +241,7 @@ type dirStats struct {
 	Files         int
 	TestdataFiles int
 	GoFiles       int
+	GopFiles      int // goxls: Go+ files
 	ModFiles      int
 	Dirs          int
 }
@@ -247,6 +250,7 @@ type dirStats struct {
 // subdirectories.
 func findDirStats(ctx context.Context) (dirStats, error) {
 	var ds dirStats
+	mod, _ := gop.LoadMod(".") // goxls: recognize the classfiles of the module
 	filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -261,6 +265,8 @@ func findDirStats(ctx context.Context) (dirStats, error) {
 				ds.TestdataFiles++
 			case strings.HasSuffix(path, ".go"):
 				ds.GoFiles++
+			case goputil.FileKindOf(mod, d.Name()) != goputil.FileUnknown:
+				ds.GopFiles++
 			case strings.HasSuffix(path, ".mod"):
 				ds.ModFiles++
 			}
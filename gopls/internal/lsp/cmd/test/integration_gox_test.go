@@ -0,0 +1,443 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmdtest
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/bug"
+	"golang.org/x/tools/gopls/internal/hooks"
+	"golang.org/x/tools/gopls/internal/lsp/cmd"
+	"golang.org/x/tools/internal/testenv"
+	"golang.org/x/tools/internal/tool"
+)
+
+// This function is a stand-in for goxls.Main in ../../../../goxls/goxls.go.
+func goxlsMain() {
+	if os.Getenv("TEST_GOPLS_BUG") == "" {
+		bug.PanicOnBugs = true
+	}
+
+	tool.Main(context.Background(), cmd.GopNew("goxls", "", nil, hooks.Options), os.Args[1:])
+}
+
+// goxls executes goxls in a child process.
+func goxls(t *testing.T, dir string, args ...string) *result {
+	testenv.NeedsGOPROOT(t)
+	res := goplsWithEnv(t, dir, []string{"ENTRYPOINT=goxlsMain"}, args...)
+	res.command = "goxls" + res.command[len("gopls"):]
+	return res
+}
+
+// gopTree is a Go+ module with a normal Go+ file, a classfile and a
+// main file. The gop_autogen.go file lets the go command find the package.
+const gopTree = `
+-- go.mod --
+module example.com
+go 1.18
+
+-- gop_autogen.go --
+package main
+
+import _ "strings"
+
+-- a.gop --
+import "strings"
+
+func f(x int) int {
+	return x * 2
+}
+
+func g() {
+	echo f(1)
+	echo f(2)
+	echo strings.ToUpper("a")
+}
+
+-- Rect.gox --
+var (
+	Width, Height int
+)
+
+func Area() int {
+	return Width * Height
+}
+
+-- b.gop --
+type Shape interface {
+	Area() int
+}
+
+r := &Rect{Width: 2, Height: 3}
+echo f(r.Area())
+`
+
+// TestGopCheck tests the 'check' subcommand on Go+ files.
+func TestGopCheck(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, `
+-- go.mod --
+module example.com
+go 1.18
+
+-- gop_autogen.go --
+package main
+
+-- a.gop --
+echo undefinedName
+
+-- b.gop --
+func f() {}
+`)
+	res := goxls(t, tree, "check", "a.gop", "b.gop")
+	res.checkExit(true)
+	res.checkStdout(`a.gop:1:6-19: undefined: undefinedName`)
+	res.checkStdout(`^[^b]*$`) // no diagnostics in b.gop
+}
+
+// TestGopCallHierarchy tests the 'call_hierarchy' subcommand on Go+ files.
+func TestGopCallHierarchy(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, gopTree)
+	res := goxls(t, tree, "call_hierarchy", "a.gop:3:6")
+	res.checkExit(true)
+	res.checkStdout("ranges 8:7-8, 9:7-8 in ..a.gop from/to function g in ..a.gop:7:6-7")
+	res.checkStdout("ranges 6:6-7 in ..b.gop from/to function main in ..b.gop")
+	res.checkStdout("identifier: function f in ..a.gop:3:6-7")
+}
+
+// TestGopDefinition tests the 'definition' subcommand on Go+ files.
+func TestGopDefinition(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, gopTree)
+	// Go+ function
+	{
+		res := goxls(t, tree, "definition", "a.gop:8:7") // "f(1)"
+		res.checkExit(true)
+		res.checkStdout("a.gop:3:6-7: defined here as func f")
+	}
+	// classfile method
+	{
+		res := goxls(t, tree, "definition", "b.gop:6:10") // "Area()"
+		res.checkExit(true)
+		res.checkStdout(`Rect.gox:5:6-10: defined here as func \(\*Rect\).Area\(\) int`)
+	}
+	// Go package
+	{
+		res := goxls(t, tree, "definition", "a.gop:10:15") // "ToUpper"
+		res.checkExit(true)
+		res.checkStdout("strings.go.* defined here as func strings.ToUpper")
+	}
+}
+
+// TestGopFoldingRanges tests the 'folding_ranges' subcommand on Go+ files.
+func TestGopFoldingRanges(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, gopTree)
+	res := goxls(t, tree, "folding_ranges", "Rect.gox")
+	res.checkExit(true)
+	res.checkStdout("1:6-3:1")  // var ( ... )
+	res.checkStdout("5:18-7:1") // body { ... }
+}
+
+// TestGopFormat tests the 'format' subcommand on Go+ files.
+func TestGopFormat(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, `
+-- a.gop --
+func f ( x int )  int { return x*2 }
+echo   f(1)
+-- Rect.gox --
+var (
+	Width   int
+)
+func Area ( ) int { return Width }
+`)
+	// default => print formatted result
+	{
+		res := goxls(t, tree, "format", "a.gop")
+		res.checkExit(true)
+		const want = `func f(x int) int { return x * 2 }
+
+echo f(1)
+`
+		if res.stdout != want {
+			t.Errorf("format: got <<%s>>, want <<%s>>", res.stdout, want)
+		}
+	}
+	// -diff prints a unified diff
+	{
+		res := goxls(t, tree, "format", "-diff", "a.gop")
+		res.checkExit(true)
+		res.checkStdout(regexp.QuoteMeta("+func f(x int) int { return x * 2 }"))
+	}
+	// -write updates the classfile
+	{
+		res := goxls(t, tree, "format", "-write", "Rect.gox")
+		res.checkExit(true)
+		res.checkStdout("^$") // empty
+		checkContent(t, filepath.Join(tree, "Rect.gox"), `var (
+	Width int
+)
+
+func Area() int { return Width }
+`)
+	}
+}
+
+// TestGopHighlight tests the 'highlight' subcommand on Go+ files.
+func TestGopHighlight(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, gopTree)
+	res := goxls(t, tree, "highlight", "a.gop:3:6")
+	res.checkExit(true)
+	res.checkStdout("a.gop:3:6-7")
+	res.checkStdout("a.gop:8:7-8")
+	res.checkStdout("a.gop:9:7-8")
+}
+
+// TestGopImplementations tests the 'implementation' subcommand on Go+
+// files, including the implicit type of a classfile.
+func TestGopImplementations(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, gopTree)
+	// Shape.Area
+	{
+		res := goxls(t, tree, "implementation", "b.gop:2:2")
+		res.checkExit(true)
+		res.checkStdout("Rect.gox:5:6-10")
+	}
+	// (*Rect).Area
+	{
+		res := goxls(t, tree, "implementation", "Rect.gox:5:6")
+		res.checkExit(true)
+		res.checkStdout("b.gop:2:2-6")
+	}
+}
+
+// TestGopImports tests the 'imports' subcommand on Go+ files.
+func TestGopImports(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, `
+-- go.mod --
+module example.com
+go 1.18
+
+-- gop_autogen.go --
+package main
+
+-- a.gop --
+func f() {
+	strings.TrimSpace("x")
+}
+`)
+	want := `import "strings"
+func f() {
+	strings.TrimSpace("x")
+}
+`
+	// default: print with imports
+	{
+		res := goxls(t, tree, "imports", "a.gop")
+		res.checkExit(true)
+		if res.stdout != want {
+			t.Errorf("imports: got <<%s>>, want <<%s>>", res.stdout, want)
+		}
+	}
+	// -write: update file
+	{
+		res := goxls(t, tree, "imports", "-write", "a.gop")
+		res.checkExit(true)
+		checkContent(t, filepath.Join(tree, "a.gop"), want)
+	}
+}
+
+// TestGopReferences tests the 'references' subcommand on Go+ files.
+func TestGopReferences(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, gopTree)
+	// references to a Go+ function
+	{
+		res := goxls(t, tree, "references", "-d", "a.gop:3:6")
+		res.checkExit(true)
+		res.checkStdout("a.gop:3:6-7")
+		res.checkStdout("a.gop:8:7-8")
+		res.checkStdout("a.gop:9:7-8")
+		res.checkStdout("b.gop:6:6-7")
+	}
+	// references to a classfile field
+	{
+		res := goxls(t, tree, "references", "Rect.gox:2:2")
+		res.checkExit(true)
+		res.checkStdout("Rect.gox:6:9-14")
+		res.checkStdout("b.gop:5:12-17")
+	}
+}
+
+// TestGopSignature tests the 'signature' subcommand on Go+ files.
+func TestGopSignature(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, gopTree)
+	res := goxls(t, tree, "signature", "a.gop:8:9")
+	res.checkExit(true)
+	res.checkStdout("f\\(x int\\) int")
+}
+
+// TestGopPrepareRename tests the 'prepare_rename' subcommand on Go+ files.
+func TestGopPrepareRename(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, gopTree)
+	res := goxls(t, tree, "prepare_rename", "Rect.gox:5:6")
+	res.checkExit(true)
+	res.checkStdout(`Rect.gox:5:6-10`)
+}
+
+// TestGopRename tests the 'rename' subcommand on Go+ files, across a
+// classfile and the files using it.
+func TestGopRename(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, gopTree)
+	// default: print the new content of each file
+	{
+		res := goxls(t, tree, "rename", "Rect.gox:5:6", "Size")
+		res.checkExit(true)
+		res.checkStdout(`func Size\(\) int`)
+		res.checkStdout(`echo f\(r.Size\(\)\)`)
+	}
+	// -write: update files
+	{
+		res := goxls(t, tree, "rename", "-write", "a.gop:3:6", "double")
+		res.checkExit(true)
+		res.checkStderr(`a.gop`)
+		res.checkStderr(`b.gop`)
+		checkContent(t, filepath.Join(tree, "b.gop"), `type Shape interface {
+	Area() int
+}
+
+r := &Rect{Width: 2, Height: 3}
+echo double(r.Area())
+`)
+	}
+}
+
+// TestGopSymbols tests the 'symbols' subcommand on Go+ files.
+func TestGopSymbols(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, gopTree)
+	res := goxls(t, tree, "symbols", "Rect.gox")
+	res.checkExit(true)
+	res.checkStdout("Width Variable 2:2-2:7")
+	res.checkStdout(`\(\*Rect\).Area Method 5:6-5:10`)
+}
+
+// TestGopSemtok tests the 'semtok' subcommand on Go+ files.
+func TestGopSemtok(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, gopTree)
+	// normal Go+ file
+	{
+		res := goxls(t, tree, "semtok", "a.gop")
+		res.checkExit(true)
+		got := res.stdout
+		want := `/*⇒4,keyword,[]*/func /*⇒1,function,[definition]*/f(`
+		if !strings.Contains(got, want) {
+			t.Errorf("semtok: got <<%s>>, want substring <<%s>>", got, want)
+		}
+	}
+	// classfile
+	{
+		res := goxls(t, tree, "semtok", "Rect.gox")
+		res.checkExit(true)
+		got := res.stdout
+		want := `/*⇒4,keyword,[]*/func /*⇒4,method,[definition]*/Area()`
+		if !strings.Contains(got, want) {
+			t.Errorf("semtok: got <<%s>>, want substring <<%s>>", got, want)
+		}
+	}
+	// syntax error
+	{
+		tree := writeTree(t, `
+-- a.gop --
+func f( {
+`)
+		res := goxls(t, tree, "semtok", "a.gop")
+		res.checkExit(false)
+	}
+}
+
+// TestGopFix tests the 'fix' subcommand on Go+ files.
+func TestGopFix(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, `
+-- go.mod --
+module example.com
+go 1.18
+
+-- gop_autogen.go --
+package main
+
+-- a.gop --
+func f() (int, string) { return }
+`)
+	want := `func f() (int, string) { return 0, "" }
+`
+	res := goxls(t, tree, "fix", "-a", "a.gop")
+	res.checkExit(true)
+	if got := res.stdout; got != want {
+		t.Errorf("fix: got <<%s>>, want <<%s>>\nstderr:\n%s", got, want, res.stderr)
+	}
+}
+
+// TestGopStats tests that the 'stats' subcommand counts Go+ files.
+func TestGopStats(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, gopTree)
+	res := goxls(t, tree, "stats", "-anon")
+	res.checkExit(true)
+
+	var stats cmd.GoplsStats
+	if err := json.Unmarshal([]byte(res.stdout), &stats); err != nil {
+		t.Fatalf("failed to unmarshal JSON output of stats command: %v", err)
+	}
+	if got, want := stats.DirStats.GopFiles, 3; got != want {
+		t.Errorf("stats.DirStats.GopFiles = %d, want %d", got, want)
+	}
+	if got, want := stats.WorkspaceStats.Views[0].WorkspacePackages.CompiledGopFiles, 3; got != want {
+		t.Errorf("stats.WorkspaceStats.Views[0].WorkspacePackages.CompiledGopFiles = %d, want %d", got, want)
+	}
+}
+
+// TestGopWorkspaceSymbol tests the 'workspace_symbol' subcommand on Go+ files.
+func TestGopWorkspaceSymbol(t *testing.T) {
+	t.Parallel()
+
+	tree := writeTree(t, gopTree)
+	res := goxls(t, tree, "workspace_symbol", "Area")
+	res.checkExit(true)
+	res.checkStdout("Rect.gox:5:6-10 Rect.Area Method")
+	res.checkStdout("b.gop:2:2-6 Shape.Area Method")
+}
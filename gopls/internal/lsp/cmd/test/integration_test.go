@@ -845,6 +845,8 @@ func TestMain(m *testing.M) {
 	switch os.Getenv("ENTRYPOINT") {
 	case "goplsMain":
 		goplsMain()
+	case "goxlsMain": // goxls: Go+
+		goxlsMain()
 	default:
 		os.Exit(m.Run())
 	}
@@ -117,16 +117,16 @@ func (s *Server) gopCodeAction(
 	}
 
 	var stubMethodsDiagnostics []protocol.Diagnostic
-	if wantQuickFixes && snapshot.View().Options().IsAnalyzerEnabled(stubmethods.Analyzer.Name) {
+	if wantQuickFixes && snapshot.View().Options().IsAnalyzerEnabled(stubmethods.GopAnalyzer.Name) {
 		for _, pd := range diagnostics {
-			if stubmethods.MatchesMessage(pd.Message) {
+			if stubmethods.GopMatchesMessage(pd.Message) {
 				stubMethodsDiagnostics = append(stubMethodsDiagnostics, pd)
 			}
 		}
 	}
 
 	// Code actions requiring type information.
-	if len(stubMethodsDiagnostics) > 0 || want[protocol.RefactorRewrite] || want[protocol.GoTest] {
+	if len(stubMethodsDiagnostics) > 0 || want[protocol.RefactorRewrite] || want[protocol.RefactorInline] || want[protocol.GoTest] {
 		pkg, pgf, err := source.NarrowestPackageForGopFile(ctx, snapshot, fh.URI())
 		if err != nil {
 			return nil, err
@@ -182,6 +182,14 @@ func (s *Server) gopCodeAction(
 			actions = append(actions, rewrites...)
 		}
 
+		if want[protocol.RefactorInline] {
+			rewrites, err := gopRefactorInline(pkg, pgf, params.Range)
+			if err != nil {
+				return nil, err
+			}
+			actions = append(actions, rewrites...)
+		}
+
 		if want[protocol.GoTest] {
 			fixes, err := gopTest(ctx, snapshot, pkg, pgf, params.Range)
 			if err != nil {
@@ -283,7 +291,7 @@ func gopRefactorRewrite(ctx context.Context, snapshot source.Snapshot, pkg sourc
 	//
 	// TODO: Consider removing the inspection after convenienceAnalyzers are removed.
 	inspect := inspector.New([]*ast.File{pgf.File})
-	if snapshot.View().Options().IsAnalyzerEnabled(fillstruct.Analyzer.Name) {
+	if snapshot.View().Options().IsAnalyzerEnabled(fillstruct.GopAnalyzer.Name) {
 		for _, d := range fillstruct.GopDiagnoseFillableStructs(inspect, start, end, pkg.GetTypes(), pkg.GopTypesInfo()) {
 			rng, err := pgf.Mapper.PosRange(pgf.Tok, d.Pos, d.End)
 			if err != nil {
@@ -340,6 +348,27 @@ func gopRefactorRewrite(ctx context.Context, snapshot source.Snapshot, pkg sourc
 	return actions, nil
 }
 
+// gopRefactorInline returns inline actions for selections beginning with a call.
+func gopRefactorInline(pkg source.Package, pgf *source.ParsedGopFile, rng protocol.Range) ([]protocol.CodeAction, error) {
+	// If range is within call expression, offer inline action.
+	if _, fn, err := source.GopEnclosingStaticCall(pkg, pgf, rng); err == nil {
+		cmd, err := command.NewApplyFixCommand(fmt.Sprintf("Inline call to %s", fn.Name()), command.ApplyFixArgs{
+			URI:   protocol.URIFromSpanURI(pgf.URI),
+			Fix:   source.InlineCall,
+			Range: rng,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []protocol.CodeAction{{
+			Title:   cmd.Title,
+			Kind:    protocol.RefactorInline,
+			Command: &cmd,
+		}}, nil
+	}
+	return nil, nil
+}
+
 func gopTest(ctx context.Context, snapshot source.Snapshot, pkg source.Package, pgf *source.ParsedGopFile, rng protocol.Range) ([]protocol.CodeAction, error) {
 	fns, err := source.GopTestsAndBenchmarks(ctx, snapshot, pkg, pgf)
 	if err != nil {
@@ -448,6 +448,12 @@ func (c *commandHandler) RunTests(ctx context.Context, args command.RunTestsArgs
 		requireSave: true,
 		forURI:      args.URI,
 	}, func(ctx context.Context, deps commandDeps) error {
+		if deps.snapshot.View().FileKind(deps.fh) == source.Gop { // goxls: run Go+ tests with `gop test`
+			if err := c.runGopTests(ctx, deps.snapshot, deps.work, args.URI, args.Tests, args.Benchmarks); err != nil {
+				return fmt.Errorf("running tests failed: %w", err)
+			}
+			return nil
+		}
 		if err := c.runTests(ctx, deps.snapshot, deps.work, args.URI, args.Tests, args.Benchmarks); err != nil {
 			return fmt.Errorf("running tests failed: %w", err)
 		}
@@ -1108,7 +1114,9 @@ func collectPackageStats(md []*source.Metadata) command.PackageStats {
 
 	for _, m := range md {
 		n := len(m.CompiledNongenGoFiles)
-		stats.CompiledGoFiles += n // goxls: TODO - how about Go+ files?
+		stats.CompiledGoFiles += n
+		stats.CompiledGopFiles += len(m.CompiledGopFiles) // goxls: Go+ files
+		n += len(m.CompiledGopFiles)
 		if n > stats.LargestPackage {
 			stats.LargestPackage = n
 		}
@@ -23,11 +23,13 @@ const (
 	AddImport             Command = "add_import"
 	ApplyFix              Command = "apply_fix"
 	CheckUpgrades         Command = "check_upgrades"
+	DebugGopTest          Command = "debug_gop_test"
 	EditGoDirective       Command = "edit_go_directive"
 	FetchVulncheckResult  Command = "fetch_vulncheck_result"
 	GCDetails             Command = "gc_details"
 	Generate              Command = "generate"
 	GoGetPackage          Command = "go_get_package"
+	ListGopTests          Command = "list_gop_tests"
 	ListImports           Command = "list_imports"
 	ListKnownPackages     Command = "list_known_packages"
 	MemStats              Command = "mem_stats"
@@ -55,11 +57,13 @@ var Commands = []Command{
 	AddImport,
 	ApplyFix,
 	CheckUpgrades,
+	DebugGopTest,
 	EditGoDirective,
 	FetchVulncheckResult,
 	GCDetails,
 	Generate,
 	GoGetPackage,
+	ListGopTests,
 	ListImports,
 	ListKnownPackages,
 	MemStats,
@@ -108,6 +112,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.CheckUpgrades(ctx, a0)
+	case "gopls.debug_gop_test":
+		var a0 RunTestsArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.DebugGopTest(ctx, a0)
 	case "gopls.edit_go_directive":
 		var a0 EditGoDirectiveArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -138,6 +148,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.GoGetPackage(ctx, a0)
+	case "gopls.list_gop_tests":
+		var a0 URIArg
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return s.ListGopTests(ctx, a0)
 	case "gopls.list_imports":
 		var a0 URIArg
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -304,6 +320,18 @@ func NewCheckUpgradesCommand(title string, a0 CheckUpgradesArgs) (protocol.Comma
 	}, nil
 }
 
+func NewDebugGopTestCommand(title string, a0 RunTestsArgs) (protocol.Command, error) {
+	args, err := MarshalArgs(a0)
+	if err != nil {
+		return protocol.Command{}, err
+	}
+	return protocol.Command{
+		Title:     title,
+		Command:   "gopls.debug_gop_test",
+		Arguments: args,
+	}, nil
+}
+
 func NewEditGoDirectiveCommand(title string, a0 EditGoDirectiveArgs) (protocol.Command, error) {
 	args, err := MarshalArgs(a0)
 	if err != nil {
@@ -364,6 +392,18 @@ func NewGoGetPackageCommand(title string, a0 GoGetPackageArgs) (protocol.Command
 	}, nil
 }
 
+func NewListGopTestsCommand(title string, a0 URIArg) (protocol.Command, error) {
+	args, err := MarshalArgs(a0)
+	if err != nil {
+		return protocol.Command{}, err
+	}
+	return protocol.Command{
+		Title:     title,
+		Command:   "gopls.list_gop_tests",
+		Arguments: args,
+	}, nil
+}
+
 func NewListImportsCommand(title string, a0 URIArg) (protocol.Command, error) {
 	args, err := MarshalArgs(a0)
 	if err != nil {
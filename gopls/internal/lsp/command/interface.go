@@ -191,7 +191,24 @@ type Interface interface {
 	RunGoWorkCommand(context.Context, RunGoWorkArgs) error
 
 	// RunGopCommand: run `gop <command> [args...]`
+	//
+	// Runs `gop run`, `gop test`, `gop build`, `gop go` or `gop mod` in the
+	// view's directory, for the package in the given directory, and reports
+	// compiler errors in Go+ files as diagnostics.
 	RunGopCommand(context.Context, RunGopCommandArgs) error
+
+	// ListGopTests: list the tests of a Go+ package
+	//
+	// Lists the tests and benchmarks of the package containing the given
+	// Go+ file, including the tests generated for test classfiles.
+	ListGopTests(context.Context, URIArg) (ListGopTestsResult, error)
+
+	// DebugGopTest: build a Go+ test binary for debugging
+	//
+	// Builds the test binary of the package containing the given Go+ file
+	// with `gop test -c`, with optimizations disabled, and returns the
+	// program and arguments a debugger should launch to run the tests.
+	DebugGopTest(context.Context, RunTestsArgs) (DebugGopTestResult, error)
 }
 
 type RunTestsArgs struct {
@@ -491,11 +508,11 @@ type ViewStats struct {
 
 // PackageStats holds information about a collection of packages.
 type PackageStats struct {
-	Packages        int // total number of packages
-	LargestPackage  int // number of files in the largest package
-	CompiledGoFiles int // total number of compiled Go files across all packages
-	Modules         int // total number of unique modules
-	// goxls: TODO - how about Go+ files?
+	Packages         int // total number of packages
+	LargestPackage   int // number of files in the largest package
+	CompiledGoFiles  int // total number of compiled Go files across all packages
+	CompiledGopFiles int // goxls: total number of compiled Go+ files across all packages
+	Modules          int // total number of unique modules
 }
 
 type RunGoWorkArgs struct {
@@ -505,10 +522,34 @@ type RunGoWorkArgs struct {
 }
 
 type RunGopCommandArgs struct {
-	// URI for the directory to gop command
+	// URI for the package directory of the gop command
 	URI protocol.DocumentURI
 	// Command for gop command
 	Command string
 	// Args for gop command arguments
 	Args []string
 }
+
+type ListGopTestsResult struct {
+	// Tests of the package, e.g. TestFoo.
+	Tests []GopTestFunc
+	// Benchmarks of the package, e.g. BenchmarkFoo.
+	Benchmarks []GopTestFunc
+}
+
+type GopTestFunc struct {
+	// Name of the test function.
+	Name string
+	// Location of the test function, or the start of its test classfile.
+	Location protocol.Location
+}
+
+type DebugGopTestResult struct {
+	// Program is the path of the test binary, in the user's cache
+	// directory. Each build of the package replaces it.
+	Program string
+	// Args are the arguments selecting the tests to run, e.g. -test.run.
+	Args []string
+	// Dir is the directory to run the test binary in.
+	Dir string
+}
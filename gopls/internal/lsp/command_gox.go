@@ -5,12 +5,23 @@
 package lsp
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 
 	"golang.org/x/tools/gop/ast/astutil"
 	"golang.org/x/tools/gopls/internal/goxls/parserutil"
 	"golang.org/x/tools/gopls/internal/lsp/command"
+	"golang.org/x/tools/gopls/internal/lsp/progress"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
 	"golang.org/x/tools/gopls/internal/lsp/source"
 	"golang.org/x/tools/internal/tokeninternal"
 )
@@ -52,5 +63,181 @@ func gopListImportsCmd(result *command.ListImportsResult, ctx context.Context, a
 }
 
 func (c *commandHandler) RunGopCommand(ctx context.Context, args command.RunGopCommandArgs) error {
-	return nil
+	gopArgs, err := source.GopCommandArgs(args.Command, args.Args)
+	if err != nil {
+		return err
+	}
+	return c.run(ctx, commandConfig{
+		async:       true,
+		requireSave: true,
+		progress:    "Running gop " + strings.Join(gopArgs, " "),
+		forURI:      args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		// Run gop in the view's directory, passing the package directory
+		// named by the URI to the commands that take one.
+		dir := deps.snapshot.View().Folder().Filename()
+		pkgDir := args.URI.SpanURI().Filename()
+		if fi, err := os.Stat(pkgDir); err == nil && !fi.IsDir() {
+			pkgDir = filepath.Dir(pkgDir)
+		}
+		gopArgs := source.GopCommandPackageArgs(gopArgs, dir, pkgDir)
+
+		buf := &bytes.Buffer{}
+		ew := progress.NewEventWriter(ctx, "gop")
+		out := io.MultiWriter(ew, progress.NewWorkDoneWriter(ctx, deps.work), buf)
+		runErr := source.RunGopCommandPiped(ctx, deps.snapshot, dir, gopArgs, out, out)
+		if errors.Is(runErr, context.Canceled) {
+			return runErr
+		}
+
+		// Report the compiler errors of the command as diagnostics,
+		// replacing those of the previous run.
+		c.s.clearDiagnosticSource(gopCommandSource)
+		for uri, diags := range source.GopCommandDiagnostics(ctx, deps.snapshot, dir, buf.Bytes()) {
+			c.s.storeDiagnostics(deps.snapshot, uri, gopCommandSource, diags, true)
+		}
+		c.s.publishDiagnostics(ctx, true, deps.snapshot)
+		return runErr
+	})
+}
+
+func (c *commandHandler) ListGopTests(ctx context.Context, args command.URIArg) (command.ListGopTestsResult, error) {
+	var result command.ListGopTestsResult
+	err := c.run(ctx, commandConfig{
+		forURI: args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		var err error
+		result, err = source.GopPackageTests(ctx, deps.snapshot, args.URI.SpanURI())
+		return err
+	})
+	return result, err
+}
+
+func (c *commandHandler) DebugGopTest(ctx context.Context, args command.RunTestsArgs) (command.DebugGopTestResult, error) {
+	var result command.DebugGopTestResult
+	err := c.run(ctx, commandConfig{
+		requireSave: true,
+		progress:    "Building gop test binary",
+		forURI:      args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		dir := filepath.Dir(args.URI.SpanURI().Filename())
+		bin, err := gopTestBinDir(dir)
+		if err != nil {
+			return err
+		}
+		program := filepath.Join(bin, filepath.Base(dir)+".test")
+		gopArgs := []string{"test", "-c", "-o", program, "-gcflags=all=-N -l", "."}
+		out := io.MultiWriter(progress.NewEventWriter(ctx, "gop"), progress.NewWorkDoneWriter(ctx, deps.work))
+		if err := source.RunGopCommandPiped(ctx, deps.snapshot, dir, gopArgs, out, out); err != nil {
+			return err
+		}
+		result = command.DebugGopTestResult{
+			Program: program,
+			Args:    gopTestRunArgs(args.Tests, args.Benchmarks),
+			Dir:     dir,
+		}
+		return nil
+	})
+	return result, err
+}
+
+// gopTestBinDir returns the directory, in the user's cache directory, in
+// which DebugGopTest builds the test binary of the package in dir. It is
+// the same directory for each build of the package, so that a new binary
+// replaces the previous one rather than piling up in temporary directories.
+func gopTestBinDir(dir string) (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(dir))
+	bin := filepath.Join(cache, "goxls", "test", hex.EncodeToString(sum[:8]))
+	if err := os.MkdirAll(bin, 0777); err != nil {
+		return "", err
+	}
+	return bin, nil
+}
+
+// gopTestRunArgs returns the test binary flags that run exactly the given
+// tests and benchmarks.
+func gopTestRunArgs(tests, benchmarks []string) []string {
+	args := []string{"-test.v"}
+	if len(tests) > 0 {
+		args = append(args, "-test.run", "^("+strings.Join(tests, "|")+")$")
+	} else {
+		args = append(args, "-test.run", "^$")
+	}
+	if len(benchmarks) > 0 {
+		args = append(args, "-test.bench", "^("+strings.Join(benchmarks, "|")+")$")
+	}
+	return args
+}
+
+// runGopTests is the Go+ counterpart of runTests: it runs the tests and
+// benchmarks with `gop test` and reports the failures in Go+ files, which
+// gop maps back through //line directives, as diagnostics.
+func (c *commandHandler) runGopTests(ctx context.Context, snapshot source.Snapshot, work *progress.WorkDone, uri protocol.DocumentURI, tests, benchmarks []string) error {
+	dir := filepath.Dir(uri.SpanURI().Filename())
+
+	// create output
+	buf := &bytes.Buffer{}
+	ew := progress.NewEventWriter(ctx, "test")
+	out := io.MultiWriter(ew, progress.NewWorkDoneWriter(ctx, work), buf)
+
+	// Run `gop test -run Func` on each test.
+	var failedTests int
+	for _, funcName := range tests {
+		args := []string{"test", "-v", "-count=1", "-run", fmt.Sprintf("^%s$", funcName), "."}
+		if err := source.RunGopCommandPiped(ctx, snapshot, dir, args, out, out); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			failedTests++
+		}
+	}
+
+	// Run `gop test -run=^$ -bench Func` on each benchmark.
+	var failedBenchmarks int
+	for _, funcName := range benchmarks {
+		args := []string{"test", "-v", "-run=^$", "-bench", fmt.Sprintf("^%s$", funcName), "."}
+		if err := source.RunGopCommandPiped(ctx, snapshot, dir, args, out, out); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			failedBenchmarks++
+		}
+	}
+
+	c.s.clearDiagnosticSource(gopCommandSource)
+	for uri, diags := range source.GopCommandDiagnostics(ctx, snapshot, dir, buf.Bytes()) {
+		c.s.storeDiagnostics(snapshot, uri, gopCommandSource, diags, true)
+	}
+	c.s.publishDiagnostics(ctx, true, snapshot)
+
+	var title string
+	if len(tests) > 0 && len(benchmarks) > 0 {
+		title = "tests and benchmarks"
+	} else if len(tests) > 0 {
+		title = "tests"
+	} else if len(benchmarks) > 0 {
+		title = "benchmarks"
+	} else {
+		return errors.New("No functions were provided")
+	}
+	message := fmt.Sprintf("all %s passed", title)
+	if failedTests > 0 && failedBenchmarks > 0 {
+		message = fmt.Sprintf("%d / %d tests failed and %d / %d benchmarks failed", failedTests, len(tests), failedBenchmarks, len(benchmarks))
+	} else if failedTests > 0 {
+		message = fmt.Sprintf("%d / %d tests failed", failedTests, len(tests))
+	} else if failedBenchmarks > 0 {
+		message = fmt.Sprintf("%d / %d benchmarks failed", failedBenchmarks, len(benchmarks))
+	}
+	if failedTests > 0 || failedBenchmarks > 0 {
+		message += "\n" + buf.String()
+	}
+
+	return c.s.client.ShowMessage(ctx, &protocol.ShowMessageParams{
+		Type:    protocol.Info,
+		Message: message,
+	})
 }
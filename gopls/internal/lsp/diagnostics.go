@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"golang.org/x/tools/gopls/internal/bug"
+	"golang.org/x/tools/gopls/internal/lsp/gopmod"
 	"golang.org/x/tools/gopls/internal/lsp/mod"
 	"golang.org/x/tools/gopls/internal/lsp/protocol"
 	"golang.org/x/tools/gopls/internal/lsp/source"
@@ -49,6 +50,8 @@ const (
 	workSource
 	modCheckUpgradesSource
 	modVulncheckSource // source.Govulncheck + source.Vulncheck
+	gopCommandSource   // goxls: source.GopCommandError
+	gopModSource       // goxls: source.GopModFileError
 )
 
 // A diagnosticReport holds results for a single diagnostic source.
@@ -111,6 +114,10 @@ func (d diagnosticSource) String() string {
 		return "FromCheckForUpgrades"
 	case modVulncheckSource:
 		return "FromModVulncheck"
+	case gopCommandSource: // goxls: Go+
+		return "FromGopCommand"
+	case gopModSource: // goxls: Go+
+		return "FromGopMod"
 	default:
 		return fmt.Sprintf("From?%d?", d)
 	}
@@ -305,6 +312,13 @@ func (s *Server) diagnose(ctx context.Context, snapshot source.Snapshot, analyze
 	}
 	store(modParseSource, "diagnosing go.mod file", modReports, modErr, true)
 
+	// goxls: Diagnose gop.mod file.
+	gopModReports, gopModErr := gopmod.Diagnostics(ctx, snapshot)
+	if ctx.Err() != nil {
+		return
+	}
+	store(gopModSource, "diagnosing gop.mod file", gopModReports, gopModErr, true)
+
 	// Diagnose go.mod upgrades.
 	upgradeReports, upgradeErr := mod.UpgradeDiagnostics(ctx, snapshot)
 	if ctx.Err() != nil {
@@ -366,14 +380,20 @@ func (s *Server) diagnose(ctx context.Context, snapshot source.Snapshot, analyze
 	)
 	for _, m := range workspace {
 		var hasNonIgnored, hasOpenFile bool
-		// goxls: TODO - how about Go+ files?
-		for _, uri := range m.CompiledNongenGoFiles {
-			seen[uri] = struct{}{}
-			if !hasNonIgnored && !snapshot.IgnoredFile(uri) {
-				hasNonIgnored = true
-			}
-			if !hasOpenFile && snapshot.IsOpen(uri) {
-				hasOpenFile = true
+		// goxls: add Go+ files & use NongenGoFiles
+		fileLists := [][]span.URI{m.CompiledNongenGoFiles}
+		if !m.ListFailed { // its Go+ files can be type-checked
+			fileLists = append(fileLists, m.CompiledGopFiles)
+		}
+		for _, files := range fileLists {
+			for _, uri := range files {
+				seen[uri] = struct{}{}
+				if !hasNonIgnored && !snapshot.IgnoredFile(uri) {
+					hasNonIgnored = true
+				}
+				if !hasOpenFile && snapshot.IsOpen(uri) {
+					hasOpenFile = true
+				}
 			}
 		}
 		if hasNonIgnored {
@@ -17,12 +17,27 @@ func (s *Server) foldingRange(ctx context.Context, params *protocol.FoldingRange
 	ctx, done := event.Start(ctx, "lsp.Server.foldingRange", tag.URI.Of(params.TextDocument.URI))
 	defer done()
 
-	snapshot, fh, ok, release, err := s.beginFileRequest(ctx, params.TextDocument.URI, source.Go)
+	// goxls: Go+
+	// snapshot, fh, ok, release, err := s.beginFileRequest(ctx, params.TextDocument.URI, source.Go)
+	snapshot, fh, ok, release, err := s.beginFileRequest(ctx, params.TextDocument.URI, source.UnknownKind)
 	defer release()
 	if !ok {
 		return nil, err
 	}
 
+	// goxls: Go+
+	switch snapshot.View().FileKind(fh) {
+	case source.Gop:
+		ranges, err := source.GopFoldingRange(ctx, snapshot, fh, snapshot.View().Options().LineFoldingOnly)
+		if err != nil {
+			return nil, err
+		}
+		return toProtocolFoldingRanges(ranges)
+	case source.Go:
+	default:
+		return nil, nil
+	}
+
 	ranges, err := source.FoldingRange(ctx, snapshot, fh, snapshot.View().Options().LineFoldingOnly)
 	if err != nil {
 		return nil, err
@@ -7,6 +7,7 @@ package lsp
 import (
 	"context"
 
+	"golang.org/x/tools/gopls/internal/lsp/gopmod"
 	"golang.org/x/tools/gopls/internal/lsp/mod"
 	"golang.org/x/tools/gopls/internal/lsp/protocol"
 	"golang.org/x/tools/gopls/internal/lsp/source"
@@ -31,6 +32,8 @@ func (s *Server) formatting(ctx context.Context, params *protocol.DocumentFormat
 		return source.Format(ctx, snapshot, fh)
 	case source.Work:
 		return work.Format(ctx, snapshot, fh)
+	case source.GopMod: // goxls: format gop.mod files
+		return gopmod.Format(ctx, snapshot, fh)
 	case source.Gop: // goxls: format Go+ files
 		return source.FormatGop(ctx, snapshot, fh)
 	}
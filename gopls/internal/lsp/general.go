@@ -220,6 +220,7 @@ func (s *Server) initialized(ctx context.Context, params *protocol.InitializedPa
 	}
 	s.pendingFolders = nil
 	s.checkViewGoVersions()
+	go s.checkGopVersion() // goxls: Go+
 
 	var registrations []protocol.Registration
 	if options.ConfigurationSupported && options.DynamicConfigurationSupported {
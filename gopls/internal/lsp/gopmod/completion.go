@@ -0,0 +1,84 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopmod
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/internal/event"
+)
+
+// directives are the statements of a gop.mod file.
+var directives = []struct {
+	name, usage string
+	inProject   bool // only valid after a project statement
+}{
+	{"gop", "gop 1.x", false},
+	{"project", "project [.projExt ProjClass] classFilePkgPath ...", false},
+	{"class", "class .workExt WorkClass", true},
+	{"import", "import [name] pkgPath", true},
+}
+
+// Completion completes the directive at the beginning of a line.
+func Completion(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle, position protocol.Position) (*protocol.CompletionList, error) {
+	ctx, done := event.Start(ctx, "gopmod.Completion")
+	defer done()
+
+	// The file usually doesn't parse while a directive is being typed,
+	// so completion works on the content directly.
+	pgm, err := snapshot.ParseGopMod(ctx, fh)
+	if pgm == nil {
+		return nil, fmt.Errorf("getting gop.mod file handle: %w", err)
+	}
+	content := pgm.Mapper.Content
+	cursor, err := pgm.Mapper.PositionOffset(position)
+	if err != nil {
+		return nil, fmt.Errorf("computing cursor offset: %w", err)
+	}
+
+	lineStart := bytes.LastIndexByte(content[:cursor], '\n') + 1
+	prefix := strings.TrimLeft(string(content[lineStart:cursor]), " \t")
+	if strings.IndexFunc(prefix, func(r rune) bool { return r < 'a' || r > 'z' }) >= 0 {
+		return &protocol.CompletionList{}, nil // not in a directive name
+	}
+	rng, err := pgm.Mapper.OffsetRange(cursor-len(prefix), cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	before := string(content[:lineStart])
+	hasGop := hasDirective(before, "gop") || hasDirective(string(content[cursor:]), "gop")
+	inProject := hasDirective(before, "project")
+
+	items := []protocol.CompletionItem{} // must be a slice
+	for _, d := range directives {
+		if !strings.HasPrefix(d.name, prefix) ||
+			(d.name == "gop" && hasGop) || (d.inProject && !inProject) {
+			continue
+		}
+		items = append(items, protocol.CompletionItem{
+			Label:    d.name,
+			Kind:     protocol.KeywordCompletion,
+			Detail:   d.usage,
+			TextEdit: &protocol.TextEdit{Range: rng, NewText: d.name + " "},
+		})
+	}
+	return &protocol.CompletionList{Items: items}, nil
+}
+
+// hasDirective reports whether a line of text starts with directive name.
+func hasDirective(text, name string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == name {
+			return true
+		}
+	}
+	return false
+}
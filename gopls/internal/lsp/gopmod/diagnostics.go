@@ -0,0 +1,189 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gopmod provides core features related to gop.mod file
+// handling for use by Go+ editors and tools.
+package gopmod
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goplus/mod/modfile"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/gopls/internal/span"
+	"golang.org/x/tools/internal/event"
+)
+
+// Diagnostics returns diagnostics for the gop.mod files next to the go.mod
+// files of the snapshot.
+func Diagnostics(ctx context.Context, snapshot source.Snapshot) (map[span.URI][]*source.Diagnostic, error) {
+	ctx, done := event.Start(ctx, "gopmod.Diagnostics", source.SnapshotLabels(snapshot)...)
+	defer done()
+
+	reports := map[span.URI][]*source.Diagnostic{}
+	for _, modURI := range snapshot.ModFiles() {
+		fh, err := snapshot.ReadFile(ctx, gopModURI(modURI))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fh.Content(); err != nil && os.IsNotExist(err) {
+			continue
+		}
+		reports[fh.URI()] = []*source.Diagnostic{}
+		diagnostics, err := DiagnosticsForGopMod(ctx, snapshot, fh)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range diagnostics {
+			reports[d.URI] = append(reports[d.URI], d)
+		}
+	}
+	return reports, nil
+}
+
+// DiagnosticsForGopMod returns the parse errors of a gop.mod file, and
+// reports classfile projects whose packages can't be resolved.
+func DiagnosticsForGopMod(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle) ([]*source.Diagnostic, error) {
+	pgm, err := snapshot.ParseGopMod(ctx, fh)
+	if err != nil {
+		if pgm == nil || len(pgm.ParseErrors) == 0 {
+			return nil, err
+		}
+		return pgm.ParseErrors, nil
+	}
+
+	var diagnostics []*source.Diagnostic
+	report := func(line *modfile.Line, tok, format string, args ...interface{}) error {
+		start, end := tokenOffsets(pgm, line, tok)
+		rng, err := pgm.Mapper.OffsetRange(start, end)
+		if err != nil {
+			return err
+		}
+		diagnostics = append(diagnostics, &source.Diagnostic{
+			URI:      fh.URI(),
+			Range:    rng,
+			Severity: protocol.SeverityError,
+			Source:   source.GopModFileError,
+			Message:  fmt.Sprintf(format, args...),
+		})
+		return nil
+	}
+
+	// Report classfile extensions registered more than once.
+	exts := make(map[string]bool)
+	checkExt := func(line *modfile.Line, ext string) error {
+		if ext == "" {
+			return nil
+		}
+		if exts[ext] {
+			return report(line, ext, "classfile extension %s is already registered", ext)
+		}
+		exts[ext] = true
+		return nil
+	}
+
+	// Report packages of classfile projects that can't be found.
+	resolve, err := pkgResolver(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	checkPkg := func(line *modfile.Line, pkgPath string) error {
+		if msg := resolve(pkgPath); msg != "" {
+			return report(line, pkgPath, "%s", msg)
+		}
+		return nil
+	}
+
+	for _, proj := range pgm.File.Projects {
+		if err := checkExt(proj.Syntax, proj.Ext); err != nil {
+			return nil, err
+		}
+		for _, pkgPath := range proj.PkgPaths {
+			if err := checkPkg(proj.Syntax, pkgPath); err != nil {
+				return nil, err
+			}
+		}
+		for _, w := range proj.Works {
+			if w.Ext == proj.Ext { // the project file may also be a work class
+				continue
+			}
+			if err := checkExt(w.Syntax, w.Ext); err != nil {
+				return nil, err
+			}
+		}
+		for _, imp := range proj.Import {
+			if err := checkPkg(imp.Syntax, imp.Path); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return diagnostics, nil
+}
+
+// pkgResolver returns a function that reports why a package referenced by
+// the gop.mod file at uri can't be found, or "" if it can. Packages are
+// looked up in the module of the go.mod file next to it.
+func pkgResolver(ctx context.Context, snapshot source.Snapshot, uri span.URI) (func(pkgPath string) string, error) {
+	dir := filepath.Dir(uri.Filename())
+	modfh, err := snapshot.ReadFile(ctx, span.URIFromPath(filepath.Join(dir, "go.mod")))
+	if err != nil {
+		return nil, err
+	}
+	pm, err := snapshot.ParseMod(ctx, modfh)
+	if err != nil || pm.File.Module == nil {
+		// Nothing can be resolved without a valid go.mod file, which is
+		// diagnosed on its own.
+		return func(string) string { return "" }, nil
+	}
+	modPath := pm.File.Module.Mod.Path
+	return func(pkgPath string) string {
+		if inModule(pkgPath, modPath) {
+			pkgDir := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(pkgPath[len(modPath):], "/")))
+			if fi, err := os.Stat(pkgDir); err != nil || !fi.IsDir() {
+				return fmt.Sprintf("cannot find package %s in module %s", pkgPath, modPath)
+			}
+			return ""
+		}
+		if elem, _, _ := strings.Cut(pkgPath, "/"); !strings.Contains(elem, ".") {
+			return "" // standard package
+		}
+		for _, req := range pm.File.Require {
+			if inModule(pkgPath, req.Mod.Path) {
+				return ""
+			}
+		}
+		return fmt.Sprintf("no required module provides package %s", pkgPath)
+	}, nil
+}
+
+// inModule reports whether pkgPath is a package of module modPath.
+func inModule(pkgPath, modPath string) bool {
+	if modPath != "" && strings.HasPrefix(pkgPath, modPath) {
+		suffix := pkgPath[len(modPath):]
+		return suffix == "" || suffix[0] == '/'
+	}
+	return false
+}
+
+// tokenOffsets returns the offsets of tok within line, or of the whole line
+// if tok isn't found.
+func tokenOffsets(pgm *source.ParsedGopModFile, line *modfile.Line, tok string) (start, end int) {
+	s, e := line.Start.Byte, line.End.Byte
+	if i := bytes.Index(pgm.Mapper.Content[s:e], []byte(tok)); tok != "" && i >= 0 {
+		return s + i, s + i + len(tok)
+	}
+	return s, e
+}
+
+// gopModURI returns the URI of the gop.mod file next to the go.mod file at
+// modURI.
+func gopModURI(modURI span.URI) span.URI {
+	return span.URIFromPath(filepath.Join(filepath.Dir(modURI.Filename()), "gop.mod"))
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopmod
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/goplus/mod/modfile"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/internal/event"
+)
+
+// Hover describes the classfile project of the project, class or import
+// statement at position.
+func Hover(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle, position protocol.Position) (*protocol.Hover, error) {
+	ctx, done := event.Start(ctx, "gopmod.Hover")
+	defer done()
+
+	pgm, err := snapshot.ParseGopMod(ctx, fh)
+	if err != nil {
+		if pgm == nil || len(pgm.ParseErrors) == 0 {
+			return nil, fmt.Errorf("getting gop.mod file handle: %w", err)
+		}
+		return nil, nil // no hover in a gop.mod file with errors
+	}
+	offset, err := pgm.Mapper.PositionOffset(position)
+	if err != nil {
+		return nil, fmt.Errorf("computing cursor offset: %w", err)
+	}
+
+	proj, line := projectAt(pgm.File, offset)
+	if proj == nil {
+		return nil, nil
+	}
+	rng, err := pgm.Mapper.OffsetRange(line.Start.Byte, line.End.Byte)
+	if err != nil {
+		return nil, err
+	}
+	options := snapshot.View().Options()
+	return &protocol.Hover{
+		Contents: protocol.MarkupContent{
+			Kind:  options.PreferredContentFormat,
+			Value: formatProject(proj, options.PreferredContentFormat == protocol.Markdown),
+		},
+		Range: rng,
+	}, nil
+}
+
+// projectAt returns the project declaring the statement at offset, and the
+// syntax of that statement.
+func projectAt(f *modfile.File, offset int) (*modfile.Project, *modfile.Line) {
+	in := func(line *modfile.Line) bool {
+		return line != nil && line.Start.Byte <= offset && offset <= line.End.Byte
+	}
+	for _, proj := range f.Projects {
+		if in(proj.Syntax) {
+			return proj, proj.Syntax
+		}
+		for _, w := range proj.Works {
+			if in(w.Syntax) {
+				return proj, w.Syntax
+			}
+		}
+		for _, imp := range proj.Import {
+			if in(imp.Syntax) {
+				return proj, imp.Syntax
+			}
+		}
+	}
+	return nil, nil
+}
+
+// formatProject describes the classes of proj.
+func formatProject(proj *modfile.Project, markdown bool) string {
+	code := func(s string) string {
+		if markdown {
+			return "`" + s + "`"
+		}
+		return s
+	}
+	files := func(ext string) string {
+		return code("*" + ext)
+	}
+
+	var b strings.Builder
+	if len(proj.PkgPaths) > 0 {
+		if markdown {
+			b.WriteString("#### ")
+		}
+		fmt.Fprintf(&b, "classfile %s\n\n", proj.PkgPaths[0])
+	}
+	if proj.Ext != "" {
+		fmt.Fprintf(&b, "Project class %s for %s files.\n", code(proj.Class), files(proj.Ext))
+	}
+	if len(proj.Works) > 0 {
+		b.WriteString("\nWork classes:\n")
+		for _, w := range proj.Works {
+			fmt.Fprintf(&b, "- %s for %s files\n", code(w.Class), files(w.Ext))
+		}
+	}
+	if len(proj.PkgPaths) > 1 {
+		b.WriteString("\nInline-imported packages:\n")
+		for _, pkgPath := range proj.PkgPaths[1:] {
+			fmt.Fprintf(&b, "- %s\n", code(pkgPath))
+		}
+	}
+	if len(proj.Import) > 0 {
+		b.WriteString("\nAuto-imported packages:\n")
+		for _, imp := range proj.Import {
+			if imp.Name != "" {
+				fmt.Fprintf(&b, "- %s %s\n", imp.Name, code(imp.Path))
+			} else {
+				fmt.Fprintf(&b, "- %s\n", code(imp.Path))
+			}
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
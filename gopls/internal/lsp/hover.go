@@ -7,6 +7,7 @@ package lsp
 import (
 	"context"
 
+	"golang.org/x/tools/gopls/internal/lsp/gopmod"
 	"golang.org/x/tools/gopls/internal/lsp/mod"
 	"golang.org/x/tools/gopls/internal/lsp/protocol"
 	"golang.org/x/tools/gopls/internal/lsp/source"
@@ -36,6 +37,8 @@ func (s *Server) hover(ctx context.Context, params *protocol.HoverParams) (*prot
 		return template.Hover(ctx, snapshot, fh, params.Position)
 	case source.Work:
 		return work.Hover(ctx, snapshot, fh, params.Position)
+	case source.GopMod: // goxls: gop.mod
+		return gopmod.Hover(ctx, snapshot, fh, params.Position)
 	}
 	return nil, nil
 }
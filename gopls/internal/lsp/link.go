@@ -37,6 +37,8 @@ func (s *Server) documentLink(ctx context.Context, params *protocol.DocumentLink
 		links, err = modLinks(ctx, snapshot, fh)
 	case source.Go:
 		links, err = goLinks(ctx, snapshot, fh)
+	case source.Gop: // goxls: Go+
+		links, err = gopLinks(ctx, snapshot, fh)
 	}
 	// Don't return errors for document links.
 	if err != nil {
@@ -0,0 +1,248 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"context"
+	"go/types"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/gopls/internal/goxls/parserutil"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/safetoken"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+)
+
+// gopLinks returns the set of hyperlink annotations for the specified Go+ file.
+func gopLinks(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle) ([]protocol.DocumentLink, error) {
+	view := snapshot.View()
+
+	pgf, err := snapshot.ParseGop(ctx, fh, parserutil.ParseFull)
+	if err != nil {
+		return nil, err
+	}
+
+	// The import map from the package metadata is used to append module
+	// version suffixes to pkg.go.dev links, and to tell Go+ packages from Go
+	// packages. Ignore errors.
+	meta, _ := source.NarrowestMetadataForFile(ctx, snapshot, fh.URI())
+	linker := &gopLinker{snapshot: snapshot, meta: meta}
+
+	var links []protocol.DocumentLink
+
+	// Create links for import specs.
+	if view.Options().ImportShortcut.ShowLinks() {
+		for _, imp := range pgf.File.Imports {
+			importPath := source.GopUnquoteImportPath(imp)
+			if importPath == "" {
+				continue // bad import
+			}
+			targetURL := linker.link(importPath, "")
+			if targetURL == "" {
+				continue
+			}
+			start, end, err := safetoken.Offsets(pgf.Tok, imp.Path.Pos(), imp.Path.End())
+			if err != nil {
+				return nil, err
+			}
+			// Account for the quotation marks in the positions.
+			l, err := toProtocolLink(pgf.Mapper, targetURL, start+len(`"`), end-len(`"`))
+			if err != nil {
+				return nil, err
+			}
+			links = append(links, l)
+		}
+	}
+
+	urlRegexp := snapshot.View().Options().URLRegexp
+
+	// Gather links found in string literals.
+	var str []*ast.BasicLit
+	ast.Inspect(pgf.File, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.ImportSpec:
+			return false // don't process import strings again
+		case *ast.BasicLit:
+			if n.Kind == token.STRING {
+				str = append(str, n)
+			}
+		}
+		return true
+	})
+	for _, s := range str {
+		strOffset, err := safetoken.Offset(pgf.Tok, s.Pos())
+		if err != nil {
+			return nil, err
+		}
+		l, err := findLinksInString(urlRegexp, s.Value, strOffset, pgf.Mapper)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, l...)
+	}
+
+	// Gather links found in comments.
+	for _, commentGroup := range pgf.File.Comments {
+		for _, comment := range commentGroup.List {
+			commentOffset, err := safetoken.Offset(pgf.Tok, comment.Pos())
+			if err != nil {
+				return nil, err
+			}
+			l, err := findLinksInString(urlRegexp, comment.Text, commentOffset, pgf.Mapper)
+			if err != nil {
+				return nil, err
+			}
+			links = append(links, l...)
+
+			l, err = linker.docLinks(ctx, pgf, comment.Text, commentOffset)
+			if err != nil {
+				return nil, err
+			}
+			links = append(links, l...)
+		}
+	}
+
+	return links, nil
+}
+
+// gopDocLinkRegexp matches doc links such as [Name], [Name.Method],
+// [pkg], [pkg.Name] and [pkg.Name.Method] in comments.
+// See https://go.dev/doc/comment#doclinks.
+var gopDocLinkRegexp = regexp.MustCompile(`\[(\*?)([\pL_][\pL_0-9]*(?:\.[\pL_][\pL_0-9]*){0,2})\]`)
+
+// A gopLinker builds documentation links for the packages referenced by a
+// Go+ file.
+type gopLinker struct {
+	snapshot source.Snapshot
+	meta     *source.Metadata // may be nil
+
+	pkg     *types.Package // lazily type-checked package of the file
+	pkgDone bool
+}
+
+// link returns the documentation link for the package importPath, with the
+// optional anchor, or "" if the package must not be linked.
+func (l *gopLinker) link(importPath source.ImportPath, anchor string) string {
+	view := l.snapshot.View()
+	// See golang/go#36998: don't link to modules matching GOPRIVATE.
+	if view.IsGoPrivatePath(string(importPath)) {
+		return ""
+	}
+	opts := view.Options()
+	target := opts.LinkTarget
+	urlPath := string(importPath)
+
+	var m *source.Metadata
+	if l.meta != nil {
+		if l.meta.PkgPath == source.PackagePath(importPath) {
+			m = l.meta
+		} else {
+			m = l.snapshot.Metadata(l.meta.DepsByImpPath[importPath])
+		}
+	}
+	if m != nil && len(m.GopFiles) > 0 && opts.GopLinkTarget != "" {
+		// Documentation of Go+ packages is served by GopLinkTarget.
+		target = opts.GopLinkTarget
+	} else if strings.ToLower(target) == "pkg.go.dev" {
+		// For pkg.go.dev, append module version suffix to package import path.
+		if m != nil && m.Module != nil && m.Module.Path != "" && m.Module.Version != "" {
+			urlPath = strings.Replace(urlPath, m.Module.Path, m.Module.Path+"@"+m.Module.Version, 1)
+		}
+	}
+	return gopBuildLink(target, urlPath, anchor)
+}
+
+// gopBuildLink is like source.BuildLink, but target may have a scheme, such
+// as http://localhost:8080 for a local documentation server.
+func gopBuildLink(target, path, anchor string) string {
+	if !strings.Contains(target, "://") {
+		return source.BuildLink(target, path, anchor)
+	}
+	link := strings.TrimSuffix(target, "/") + "/" + path
+	if anchor == "" {
+		return link
+	}
+	return link + "#" + anchor
+}
+
+// docLinks returns the links for the doc links, such as [pkg.Name], found in
+// the comment text, which starts at srcOffset within pgf.
+func (l *gopLinker) docLinks(ctx context.Context, pgf *source.ParsedGopFile, text string, srcOffset int) ([]protocol.DocumentLink, error) {
+	var links []protocol.DocumentLink
+	for _, index := range gopDocLinkRegexp.FindAllStringSubmatchIndex(text, -1) {
+		start, end := index[0], index[1]
+		// "[text]: URL" is a link definition, not a doc link.
+		if strings.HasPrefix(text[end:], ":") {
+			continue
+		}
+		name := text[index[4]:index[5]]
+		importPath, anchor, ok := l.resolveDocLink(ctx, pgf, name)
+		if !ok {
+			continue
+		}
+		targetURL := l.link(importPath, anchor)
+		if targetURL == "" {
+			continue
+		}
+		// Exclude the brackets from the link.
+		lnk, err := toProtocolLink(pgf.Mapper, targetURL, srcOffset+start+len("["), srcOffset+end-len("]"))
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, lnk)
+	}
+	return links, nil
+}
+
+// resolveDocLink resolves the doc link name, such as "pkg.Name.Method", to
+// a package and an anchor within its documentation.
+func (l *gopLinker) resolveDocLink(ctx context.Context, pgf *source.ParsedGopFile, name string) (importPath source.ImportPath, anchor string, ok bool) {
+	first, rest, _ := strings.Cut(name, ".")
+	// [pkg], [pkg.Name] or [pkg.Name.Method]
+	for _, imp := range pgf.File.Imports {
+		impPath := source.GopUnquoteImportPath(imp)
+		if impPath != "" && l.importName(imp, impPath) == first {
+			return impPath, rest, true
+		}
+	}
+	// [Name] or [Name.Method] of the current package
+	if l.meta == nil || strings.Count(name, ".") > 1 {
+		return "", "", false
+	}
+	if pkg := l.typesPackage(ctx); pkg != nil && pkg.Scope().Lookup(first) != nil {
+		return source.ImportPath(l.meta.PkgPath), name, true
+	}
+	return "", "", false
+}
+
+// importName returns the name by which the import imp of impPath is
+// referenced in the file.
+func (l *gopLinker) importName(imp *ast.ImportSpec, impPath source.ImportPath) string {
+	if imp.Name != nil {
+		return imp.Name.Name
+	}
+	if l.meta != nil {
+		if m := l.snapshot.Metadata(l.meta.DepsByImpPath[impPath]); m != nil {
+			return string(m.Name)
+		}
+	}
+	return path.Base(string(impPath))
+}
+
+// typesPackage returns the type-checked package of the file, or nil. It is
+// only computed once, as doc links to the current package are rare.
+func (l *gopLinker) typesPackage(ctx context.Context) *types.Package {
+	if !l.pkgDone {
+		l.pkgDone = true
+		if pkgs, err := l.snapshot.TypeCheck(ctx, l.meta.ID); err == nil {
+			l.pkg = pkgs[0].GetTypes()
+		}
+	}
+	return l.pkg
+}
@@ -34,6 +34,15 @@ func (s *Server) selectionRange(ctx context.Context, params *protocol.SelectionR
 		return nil, err
 	}
 
+	// goxls: Go+
+	switch snapshot.View().FileKind(fh) {
+	case source.Gop:
+		return s.gopSelectionRange(ctx, snapshot, fh, params)
+	case source.Go:
+	default:
+		return nil, nil
+	}
+
 	pgf, err := snapshot.ParseGo(ctx, fh, source.ParseFull)
 	if err != nil {
 		return nil, err
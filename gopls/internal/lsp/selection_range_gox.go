@@ -0,0 +1,57 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"context"
+
+	"golang.org/x/tools/gop/ast/astutil"
+	"golang.org/x/tools/gopls/internal/goxls/parserutil"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+)
+
+// gopSelectionRange is the Go+ version of selectionRange.
+func (s *Server) gopSelectionRange(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle, params *protocol.SelectionRangeParams) ([]protocol.SelectionRange, error) {
+	pgf, err := snapshot.ParseGop(ctx, fh, parserutil.ParseFull)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]protocol.SelectionRange, len(params.Positions))
+	for i, protocolPos := range params.Positions {
+		pos, err := pgf.PositionPos(protocolPos)
+		if err != nil {
+			return nil, err
+		}
+
+		path, _ := astutil.PathEnclosingInterval(pgf.File, pos, pos)
+
+		tail := &result[i] // tail of the Parent linked list, built head first
+
+		added := false
+		for _, node := range path {
+			// Skip nodes without a position, such as the synthesized
+			// parts of a classfile.
+			if !node.Pos().IsValid() || !node.End().IsValid() {
+				continue
+			}
+			rng, err := pgf.NodeRange(node)
+			if err != nil {
+				return nil, err
+			}
+
+			// Add node to tail.
+			if added {
+				tail.Parent = &protocol.SelectionRange{}
+				tail = tail.Parent
+			}
+			tail.Range = rng
+			added = true
+		}
+	}
+
+	return result, nil
+}
@@ -3,3 +3,23 @@
 // license that can be found in the LICENSE file.
 
 package lsp
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/tools/gop/langserver"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+)
+
+// checkGopVersion checks whether the external `gop serve` process used to
+// generate Go+ code matches the gop library goxls is built with, raising a
+// showMessage notification if not.
+func (s *Server) checkGopVersion() {
+	if err := langserver.Err(); err != nil {
+		s.eventuallyShowMessage(context.Background(), &protocol.ShowMessageParams{
+			Type:    protocol.Warning,
+			Message: fmt.Sprintf("Go+ code generation may fail: %v. Install a matching gop, or run goxls with -gengo=inprocess.", err),
+		})
+	}
+}
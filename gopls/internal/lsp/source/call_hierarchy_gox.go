@@ -79,7 +79,11 @@ func GopIncomingCalls(ctx context.Context, snapshot Snapshot, fh FileHandle, pos
 	// Group references by their enclosing function declaration.
 	incomingCalls := make(map[protocol.Location]*protocol.CallHierarchyIncomingCall)
 	for _, ref := range refs {
-		callItem, err := enclosingNodeCallItem(ctx, snapshot, ref.pkgPath, ref.location)
+		enclosing := gopEnclosingNodeCallItem
+		if filepath.Ext(ref.location.URI.SpanURI().Filename()) == ".go" {
+			enclosing = enclosingNodeCallItem
+		}
+		callItem, err := enclosing(ctx, snapshot, ref.pkgPath, ref.location)
 		if err != nil {
 			event.Error(ctx, "error getting enclosing node", err, tag.Method.Of(string(ref.pkgPath)))
 			continue
@@ -53,6 +53,12 @@ func gopRunTestCodeLens(ctx context.Context, snapshot Snapshot, fh FileHandle) (
 		}
 		rng := protocol.Range{Start: fn.Rng.Start, End: fn.Rng.Start}
 		codeLens = append(codeLens, protocol.CodeLens{Range: rng, Command: &cmd})
+
+		debug, err := command.NewDebugGopTestCommand("debug test", command.RunTestsArgs{URI: puri, Tests: []string{fn.Name}})
+		if err != nil {
+			return nil, err
+		}
+		codeLens = append(codeLens, protocol.CodeLens{Range: rng, Command: &debug})
 	}
 
 	for _, fn := range fns.Benchmarks {
@@ -70,7 +76,11 @@ func gopRunTestCodeLens(ctx context.Context, snapshot Snapshot, fh FileHandle) (
 			return nil, err
 		}
 		// add a code lens to the top of the file which runs all benchmarks in the file
-		rng, err := pgf.PosRange(pgf.File.Package, pgf.File.Package)
+		pos := pgf.File.Package
+		if !pos.IsValid() { // goxls: the package clause of a Go+ file is optional
+			pos = pgf.File.Pos()
+		}
+		rng, err := pgf.PosRange(pos, pos)
 		if err != nil {
 			return nil, err
 		}
@@ -90,7 +100,19 @@ func gopRunTestCodeLens(ctx context.Context, snapshot Snapshot, fh FileHandle) (
 func GopTestsAndBenchmarks(ctx context.Context, snapshot Snapshot, pkg Package, pgf *ParsedGopFile) (testFns, error) {
 	var out testFns
 
-	if !strings.HasSuffix(pgf.URI.Filename(), "_test.go") {
+	// goxls: a test classfile (*test.gox) holds the body of a single
+	// generated test function, so its lens goes to the top of the file.
+	if name, ok := GopClassTestName(pkg.Metadata().GopMod_(), pgf.URI.Filename()); ok {
+		if name != "" {
+			rng, err := pgf.PosRange(pgf.File.Pos(), pgf.File.Pos())
+			if err != nil {
+				return out, err
+			}
+			out.Tests = append(out.Tests, testFn{name, rng})
+		}
+		return out, nil
+	}
+	if !IsGopTestFile(pgf.URI.Filename()) {
 		return out, nil
 	}
 
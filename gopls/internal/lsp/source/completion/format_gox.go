@@ -14,6 +14,7 @@ import (
 
 	"github.com/goplus/gop/ast"
 	"github.com/goplus/gox"
+	"golang.org/x/tools/gop/goputil"
 	"golang.org/x/tools/gopls/internal/lsp/protocol"
 	"golang.org/x/tools/gopls/internal/lsp/safetoken"
 	"golang.org/x/tools/gopls/internal/lsp/snippet"
@@ -143,7 +144,7 @@ Suffixes:
 					var buf bytes.Buffer
 					buf.WriteString("Go+ overload funcs\n")
 					for _, o := range objs {
-						if isIndexOverload(o.Name(), obj.Name()) {
+						if name, ok := goputil.OverloadName(o.Name()); ok && name == obj.Name() {
 							c.seen[o] = true
 						}
 						s, err := source.NewSignature(ctx, c.snapshot, c.pkg, o.Type().(*types.Signature), nil, c.qf, c.mq)
@@ -282,17 +283,6 @@ Suffixes:
 	return item, nil
 }
 
-func isIndexOverload(fn string, name string) bool {
-	n := len(name)
-	if (len(fn) == n+3) && (fn[:n] == name) && (fn[n] == '_') && (fn[n+1] == '_') {
-		c := fn[n+2]
-		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') {
-			return true
-		}
-	}
-	return false
-}
-
 func (c *gopCompleter) formatBuiltin(ctx context.Context, cand candidate) (CompletionItem, error) {
 	obj := cand.obj
 	item := CompletionItem{
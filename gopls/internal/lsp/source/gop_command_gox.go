@@ -0,0 +1,180 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/span"
+	"golang.org/x/tools/internal/event"
+)
+
+// gopCommands are the gop commands that may be run by RunGopCommand.
+var gopCommands = map[string]bool{
+	"run":   true,
+	"test":  true,
+	"build": true,
+	"go":    true,
+	"mod":   true,
+}
+
+// GopCommandArgs returns the arguments of the gop command cmd, which may
+// include arguments such as "mod tidy", followed by args.
+func GopCommandArgs(cmd string, args []string) ([]string, error) {
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 || !gopCommands[fields[0]] {
+		return nil, fmt.Errorf("unsupported gop command %q", cmd)
+	}
+	return append(fields, args...), nil
+}
+
+// gopPackageCommands are the gop commands that take a package directory.
+var gopPackageCommands = map[string]bool{
+	"run":   true,
+	"test":  true,
+	"build": true,
+	"go":    true,
+}
+
+// GopCommandPackageArgs returns the arguments args of a gop command run in
+// the directory folder, with the package directory dir inserted after the
+// command name as a path relative to folder, such as "./cmd/hello".
+// args are returned unchanged if the command takes no package, or if dir
+// is folder or outside of it.
+func GopCommandPackageArgs(args []string, folder, dir string) []string {
+	if len(args) == 0 || !gopPackageCommands[args[0]] {
+		return args
+	}
+	rel, err := filepath.Rel(folder, dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return args
+	}
+	pkg := "./" + filepath.ToSlash(rel)
+	return append([]string{args[0], pkg}, args[1:]...)
+}
+
+// RunGopCommandPiped runs `gop args...` in the directory dir, using the
+// environment of the snapshot's view, and writes its output to stdout
+// and stderr.
+func RunGopCommandPiped(ctx context.Context, snapshot Snapshot, dir string, args []string, stdout, stderr io.Writer) error {
+	env := append(os.Environ(), snapshot.View().Options().EnvSlice()...)
+	cmd := exec.CommandContext(ctx, lookupGop(env), args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	event.Log(ctx, fmt.Sprintf("running gop %s in %s", strings.Join(args, " "), dir))
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("gop %s: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// lookupGop returns the path of the gop executable, searching the PATH of
+// env before the PATH of the gopls process.
+func lookupGop(env []string) string {
+	var path string
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "PATH=") {
+			path = kv[len("PATH="):] // the last one wins
+		}
+	}
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			continue
+		}
+		if bin, err := exec.LookPath(filepath.Join(dir, "gop")); err == nil {
+			return bin
+		}
+	}
+	if bin, err := exec.LookPath("gop"); err == nil {
+		return bin
+	}
+	return "gop"
+}
+
+// gopErrorRe matches the errors reported by gop commands, such as
+// "./main.gop:3:5: undefined: foo".
+var gopErrorRe = regexp.MustCompile(`^(.+?):(\d+)(?::(\d+))?: (.+)$`)
+
+// GopCommandDiagnostics converts the errors in the output of a gop command
+// run in the directory dir into diagnostics for the Go+ files of the
+// snapshot. Errors in other files are ignored.
+func GopCommandDiagnostics(ctx context.Context, snapshot Snapshot, dir string, output []byte) map[span.URI][]*Diagnostic {
+	reports := make(map[span.URI][]*Diagnostic)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		m := gopErrorRe.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if m == nil {
+			continue
+		}
+		filename := m[1]
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(dir, filename)
+		}
+		uri := span.URIFromPath(filename)
+		fh := snapshot.FindFile(uri)
+		if fh == nil && !filepath.IsAbs(m[1]) {
+			// The //line directives of gop test failures are relative to
+			// the module root rather than to dir.
+			uri = span.URIFromPath(filepath.Join(dir, filepath.Base(m[1])))
+			fh = snapshot.FindFile(uri)
+		}
+		if fh == nil || snapshot.View().FileKind(fh) != Gop {
+			continue
+		}
+		content, err := fh.Content()
+		if err != nil {
+			continue
+		}
+		line, _ := strconv.Atoi(m[2])
+		col := 1
+		if m[3] != "" {
+			col, _ = strconv.Atoi(m[3])
+		} else {
+			// Test failures have no column: point at the statement
+			// rather than at its indentation.
+			col += indentOf(content, line)
+		}
+		rng, err := protocol.NewMapper(uri, content).SpanRange(span.New(uri, span.NewPoint(line, col, -1), span.Point{}))
+		if err != nil {
+			event.Error(ctx, "gop command error position", err)
+			continue
+		}
+		reports[uri] = append(reports[uri], &Diagnostic{
+			URI:      uri,
+			Range:    rng,
+			Severity: protocol.SeverityError,
+			Source:   GopCommandError,
+			Message:  m[4],
+		})
+	}
+	return reports
+}
+
+// indentOf returns the length in bytes of the indentation of the 1-based
+// line of content.
+func indentOf(content []byte, line int) int {
+	lines := bytes.SplitN(content, []byte("\n"), line+1)
+	if line < 1 || line > len(lines) {
+		return 0
+	}
+	l := lines[line-1]
+	return len(l) - len(bytes.TrimLeft(l, " \t"))
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goplus/mod/gopmod"
+	"github.com/goplus/mod/modfile"
+	"golang.org/x/tools/gopls/internal/lsp/command"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/span"
+)
+
+// IsGopTestFile reports whether filename is a Go+ test source file, such
+// as foo_test.gop.
+func IsGopTestFile(filename string) bool {
+	base := filepath.Base(filename)
+	ext := filepath.Ext(base)
+	if ext == ".go" {
+		return false
+	}
+	return strings.HasSuffix(strings.TrimSuffix(base, ext), "_test")
+}
+
+// GopClassTestName reports whether filename is a test classfile (a
+// classfile whose extension ends with "test.gox") of the module mod, and
+// returns the name of the test function gop generates for it: "Test"
+// followed by the class name, which is prefixed with "_" unless it is
+// exported. The name is empty for the project file of the classfile,
+// which becomes TestMain.
+func GopClassTestName(mod *gopmod.Module, filename string) (name string, ok bool) {
+	if mod == nil {
+		return "", false
+	}
+	base := filepath.Base(filename)
+	isProj, ok := mod.ClassKind(base)
+	if !ok {
+		return "", false
+	}
+	tname, ext := modfile.SplitFname(base)
+	if !strings.HasSuffix(ext, "test.gox") {
+		return "", false
+	}
+	if isProj {
+		return "", true
+	}
+	if idx := strings.Index(tname, "."); idx > 0 {
+		tname = tname[:idx]
+	}
+	if c := tname[0]; c < 'A' || c > 'Z' {
+		tname = "_" + tname
+	}
+	return "Test" + tname, true
+}
+
+// GopPackageTests returns the tests and benchmarks declared in the Go+
+// files of the package containing uri, including its test classfiles,
+// _test.gop files and external test package.
+func GopPackageTests(ctx context.Context, snapshot Snapshot, uri span.URI) (command.ListGopTestsResult, error) {
+	var result command.ListGopTestsResult
+	narrowest, err := NarrowestMetadataForFile(ctx, snapshot, uri)
+	if err != nil {
+		return result, err
+	}
+	pkgPath := narrowest.PkgPath
+	if narrowest.ForTest != "" {
+		pkgPath = narrowest.ForTest
+	}
+
+	// The test variants of a package are a superset of the package itself.
+	all, err := snapshot.AllMetadata(ctx)
+	if err != nil {
+		return result, err
+	}
+	var ids []PackageID
+	for _, m := range all {
+		if m.ForTest == pkgPath && !m.IsIntermediateTestVariant() {
+			ids = append(ids, m.ID)
+		}
+	}
+	if len(ids) == 0 {
+		ids = append(ids, narrowest.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	pkgs, err := snapshot.TypeCheck(ctx, ids...)
+	if err != nil {
+		return result, err
+	}
+
+	seen := make(map[span.URI]bool)
+	for _, pkg := range pkgs {
+		for _, pgf := range pkg.CompiledGopFiles() {
+			if seen[pgf.URI] {
+				continue
+			}
+			seen[pgf.URI] = true
+			fns, err := GopTestsAndBenchmarks(ctx, snapshot, pkg, pgf)
+			if err != nil {
+				return result, err
+			}
+			loc := func(fn testFn) command.GopTestFunc {
+				return command.GopTestFunc{
+					Name:     fn.Name,
+					Location: protocol.Location{URI: protocol.URIFromSpanURI(pgf.URI), Range: fn.Rng},
+				}
+			}
+			for _, fn := range fns.Tests {
+				result.Tests = append(result.Tests, loc(fn))
+			}
+			for _, fn := range fns.Benchmarks {
+				result.Benchmarks = append(result.Benchmarks, loc(fn))
+			}
+		}
+	}
+	return result, nil
+}
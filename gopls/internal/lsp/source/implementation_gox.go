@@ -18,6 +18,7 @@ import (
 	"github.com/goplus/gop/token"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/tools/gopls/internal/goxls"
+	"golang.org/x/tools/gopls/internal/goxls/parserutil"
 	"golang.org/x/tools/gopls/internal/lsp/protocol"
 	"golang.org/x/tools/gopls/internal/lsp/safetoken"
 	"golang.org/x/tools/gopls/internal/lsp/source/methodsets"
@@ -305,7 +306,70 @@ func gopLocalImplementations(ctx context.Context, snapshot Snapshot, pkg Package
 	// Scan through all type declarations in the syntax.
 	locs, methodLocs := goLocalImplementations(pkg, queryType, methodID)
 
+	// visit reports the type def, declared at loc, if it implements queryType.
+	visit := func(def *types.TypeName, loc func() protocol.Location) {
+		if def.IsAlias() {
+			return // skip type aliases to avoid duplicate reporting
+		}
+		candidateType := methodsets.EnsurePointer(def.Type())
+
+		// The historical behavior enshrined by this
+		// function rejects cases where both are
+		// (nontrivial) interface types?
+		// That seems like useful information.
+		// TODO(adonovan): UX: report I/I pairs too?
+		// The same question appears in the global algorithm (methodsets).
+		if !concreteImplementsIntf(candidateType, queryType) {
+			return // not assignable
+		}
+
+		// Ignore types with empty method sets.
+		// (No point reporting that every type satisfies 'any'.)
+		mset := types.NewMethodSet(candidateType)
+		if mset.Len() == 0 {
+			return
+		}
+
+		if methodID == "" {
+			// Found matching type.
+			locs = append(locs, loc())
+			return
+		}
+
+		// Find corresponding method.
+		//
+		// We can't use LookupFieldOrMethod because it requires
+		// the methodID's types.Package, which we don't know.
+		// We could recursively search pkg.Imports for it,
+		// but it's easier to walk the method set.
+		for i := 0; i < mset.Len(); i++ {
+			method := mset.At(i).Obj()
+			if method.Id() == methodID {
+				posn := safetoken.StartPosition(pkg.FileSet(), method.Pos())
+				methodLocs = append(methodLocs, methodsets.Location{
+					Filename: posn.Filename,
+					Start:    posn.Offset,
+					End:      posn.Offset + len(method.Name()),
+				})
+				break
+			}
+		}
+	}
+
 	for _, pgf := range pkg.CompiledGopFiles() {
+		pgf := pgf
+		// The type of a classfile has no declaration: it is the file itself.
+		if classType, ok := parserutil.GetClassType(pgf.File, pgf.URI.Filename()); ok {
+			if def, ok := pkg.GetTypes().Scope().Lookup(classType).(*types.TypeName); ok {
+				visit(def, func() protocol.Location {
+					loc, err := pgf.PosLocation(pgf.File.Pos(), pgf.File.Pos())
+					if err != nil {
+						panic(err) // can't happen in implementations
+					}
+					return loc
+				})
+			}
+		}
 		ast.Inspect(pgf.File, func(n ast.Node) bool {
 			spec, ok := n.(*ast.TypeSpec)
 			if !ok {
@@ -315,52 +379,7 @@ func gopLocalImplementations(ctx context.Context, snapshot Snapshot, pkg Package
 			if def == nil {
 				return true // "can't happen" for types
 			}
-			if def.(*types.TypeName).IsAlias() {
-				return true // skip type aliases to avoid duplicate reporting
-			}
-			candidateType := methodsets.EnsurePointer(def.Type())
-
-			// The historical behavior enshrined by this
-			// function rejects cases where both are
-			// (nontrivial) interface types?
-			// That seems like useful information.
-			// TODO(adonovan): UX: report I/I pairs too?
-			// The same question appears in the global algorithm (methodsets).
-			if !concreteImplementsIntf(candidateType, queryType) {
-				return true // not assignable
-			}
-
-			// Ignore types with empty method sets.
-			// (No point reporting that every type satisfies 'any'.)
-			mset := types.NewMethodSet(candidateType)
-			if mset.Len() == 0 {
-				return true
-			}
-
-			if methodID == "" {
-				// Found matching type.
-				locs = append(locs, gopMustLocation(pgf, spec.Name))
-				return true
-			}
-
-			// Find corresponding method.
-			//
-			// We can't use LookupFieldOrMethod because it requires
-			// the methodID's types.Package, which we don't know.
-			// We could recursively search pkg.Imports for it,
-			// but it's easier to walk the method set.
-			for i := 0; i < mset.Len(); i++ {
-				method := mset.At(i).Obj()
-				if method.Id() == methodID {
-					posn := safetoken.StartPosition(pkg.FileSet(), method.Pos())
-					methodLocs = append(methodLocs, methodsets.Location{
-						Filename: posn.Filename,
-						Start:    posn.Offset,
-						End:      posn.Offset + len(method.Name()),
-					})
-					break
-				}
-			}
+			visit(def.(*types.TypeName), func() protocol.Location { return gopMustLocation(pgf, spec.Name) })
 			return true
 		})
 	}
@@ -76,6 +76,16 @@ var AllInlayHints = map[string]*Hint{
 		Doc:  "Enable/disable inlay hints for implicit type parameters on generic functions:\n```go\n\tmyFoo/*[int, string]*/(1, \"hello\")\n```",
 		Run:  funcTypeParams,
 	},
+
+	// goxls: Go+ specific hints, which have no Run for Go source files.
+	OverloadFunctions: {
+		Name: OverloadFunctions,
+		Doc:  "Enable/disable inlay hints for the selected overload of overloaded functions:\n```gop\n\tadd/*#1 (a string, b string) string*/ \"a\", \"b\"\n```",
+	},
+	ImplicitReceivers: {
+		Name: ImplicitReceivers,
+		Doc:  "Enable/disable inlay hints for the implicit `this` receiver in classfiles:\n```gop\n\t/*this.*/play \"music\"\n```",
+	},
 }
 
 func InlayHint(ctx context.Context, snapshot Snapshot, fh FileHandle, pRng protocol.Range) ([]protocol.InlayHint, error) {
@@ -94,7 +104,7 @@ func InlayHint(ctx context.Context, snapshot Snapshot, fh FileHandle, pRng proto
 		if !enabled {
 			continue
 		}
-		if h, ok := AllInlayHints[hint]; ok {
+		if h, ok := AllInlayHints[hint]; ok && h.Run != nil { // goxls: Go+ hints have no Run
 			enabledHints = append(enabledHints, h.Run)
 		}
 	}
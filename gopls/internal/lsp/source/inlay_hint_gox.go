@@ -0,0 +1,533 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/internal/event"
+)
+
+// Go+ specific inlay hints.
+const (
+	OverloadFunctions = "overloadFunctions"
+	ImplicitReceivers = "implicitReceivers"
+)
+
+type GopInlayHintFunc func(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) []protocol.InlayHint
+
+// GopHint is the Go+ counterpart of Hint.
+type GopHint struct {
+	Name string
+	Doc  string
+	Run  GopInlayHintFunc
+}
+
+// AllGopInlayHints contains the Go+ versions of AllInlayHints, including
+// the hints that only make sense for Go+ source files. They are enabled by
+// the same "hints" setting as the Go hints.
+var AllGopInlayHints = map[string]*GopHint{
+	AssignVariableTypes: {
+		Name: AssignVariableTypes,
+		Doc:  AllInlayHints[AssignVariableTypes].Doc,
+		Run:  gopAssignVariableTypes,
+	},
+	ParameterNames: {
+		Name: ParameterNames,
+		Doc:  AllInlayHints[ParameterNames].Doc,
+		Run:  gopParameterNames,
+	},
+	ConstantValues: {
+		Name: ConstantValues,
+		Doc:  AllInlayHints[ConstantValues].Doc,
+		Run:  gopConstantValues,
+	},
+	RangeVariableTypes: {
+		Name: RangeVariableTypes,
+		Doc:  AllInlayHints[RangeVariableTypes].Doc,
+		Run:  gopRangeVariableTypes,
+	},
+	CompositeLiteralTypes: {
+		Name: CompositeLiteralTypes,
+		Doc:  AllInlayHints[CompositeLiteralTypes].Doc,
+		Run:  gopCompositeLiteralTypes,
+	},
+	CompositeLiteralFieldNames: {
+		Name: CompositeLiteralFieldNames,
+		Doc:  AllInlayHints[CompositeLiteralFieldNames].Doc,
+		Run:  gopCompositeLiteralFields,
+	},
+	FunctionTypeParameters: {
+		Name: FunctionTypeParameters,
+		Doc:  AllInlayHints[FunctionTypeParameters].Doc,
+		Run:  gopFuncTypeParams,
+	},
+	OverloadFunctions: {
+		Name: OverloadFunctions,
+		Doc:  AllInlayHints[OverloadFunctions].Doc,
+		Run:  gopOverloadFunctions,
+	},
+	ImplicitReceivers: {
+		Name: ImplicitReceivers,
+		Doc:  AllInlayHints[ImplicitReceivers].Doc,
+		Run:  gopImplicitReceivers,
+	},
+}
+
+func GopInlayHint(ctx context.Context, snapshot Snapshot, fh FileHandle, pRng protocol.Range) ([]protocol.InlayHint, error) {
+	ctx, done := event.Start(ctx, "source.GopInlayHint")
+	defer done()
+
+	pkg, pgf, err := NarrowestPackageForGopFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, fmt.Errorf("getting file for GopInlayHint: %w", err)
+	}
+
+	// Collect a list of the inlay hints that are enabled.
+	inlayHintOptions := snapshot.View().Options().InlayHintOptions
+	var enabledHints []GopInlayHintFunc
+	for hint, enabled := range inlayHintOptions.Hints {
+		if !enabled {
+			continue
+		}
+		if h, ok := AllGopInlayHints[hint]; ok {
+			enabledHints = append(enabledHints, h.Run)
+		}
+	}
+	if len(enabledHints) == 0 {
+		return nil, nil
+	}
+
+	info := pkg.GopTypesInfo()
+	q := GopQualifier(pgf.File, pkg.GetTypes(), info)
+
+	// Set the range to the full file if the range is not valid.
+	start, end := pgf.File.Pos(), pgf.File.End()
+	if pRng.Start.Line < pRng.End.Line || pRng.Start.Character < pRng.End.Character {
+		// Adjust start and end for the specified range.
+		var err error
+		start, end, err = pgf.RangePos(pRng)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var hints []protocol.InlayHint
+	ast.Inspect(pgf.File, func(node ast.Node) bool {
+		// If not in range, we can stop looking.
+		if node == nil || node.End() < start || node.Pos() > end {
+			return false
+		}
+		for _, fn := range enabledHints {
+			hints = append(hints, fn(node, pgf.Mapper, pgf.Tok, info, &q)...)
+		}
+		return true
+	})
+	return hints, nil
+}
+
+func gopParameterNames(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, _ *types.Qualifier) []protocol.InlayHint {
+	callExpr, ok := node.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	signature, ok := info.TypeOf(callExpr.Fun).(*types.Signature)
+	if !ok {
+		return nil
+	}
+
+	var hints []protocol.InlayHint
+	for i, v := range callExpr.Args {
+		start, err := m.PosPosition(tf, v.Pos())
+		if err != nil {
+			continue
+		}
+		params := signature.Params()
+		// When a function has variadic params, we skip args after
+		// params.Len().
+		if i > params.Len()-1 {
+			break
+		}
+		param := params.At(i)
+		// param.Name is empty for built-ins like append
+		if param.Name() == "" {
+			continue
+		}
+		// Skip the parameter name hint if the arg matches
+		// the parameter name.
+		if i, ok := v.(*ast.Ident); ok && i.Name == param.Name() {
+			continue
+		}
+
+		label := param.Name()
+		if signature.Variadic() && i == params.Len()-1 {
+			label = label + "..."
+		}
+		hints = append(hints, protocol.InlayHint{
+			Position:     start,
+			Label:        buildLabel(label + ":"),
+			Kind:         protocol.Parameter,
+			PaddingRight: true,
+		})
+	}
+	return hints
+}
+
+func gopFuncTypeParams(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, _ *types.Qualifier) []protocol.InlayHint {
+	ce, ok := node.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	id, ok := ce.Fun.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	inst := info.Instances[id]
+	if inst.TypeArgs == nil {
+		return nil
+	}
+	start, err := m.PosPosition(tf, id.End())
+	if err != nil {
+		return nil
+	}
+	var args []string
+	for i := 0; i < inst.TypeArgs.Len(); i++ {
+		args = append(args, inst.TypeArgs.At(i).String())
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return []protocol.InlayHint{{
+		Position: start,
+		Label:    buildLabel("[" + strings.Join(args, ", ") + "]"),
+		Kind:     protocol.Type,
+	}}
+}
+
+func gopAssignVariableTypes(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) []protocol.InlayHint {
+	stmt, ok := node.(*ast.AssignStmt)
+	if !ok || stmt.Tok != token.DEFINE {
+		return nil
+	}
+
+	var hints []protocol.InlayHint
+	for _, v := range stmt.Lhs {
+		if h := gopVariableType(v, m, tf, info, q); h != nil {
+			hints = append(hints, *h)
+		}
+	}
+	return hints
+}
+
+func gopRangeVariableTypes(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) []protocol.InlayHint {
+	var key, value ast.Expr
+	switch n := node.(type) {
+	case *ast.RangeStmt:
+		key, value = n.Key, n.Value
+	case *ast.ForPhrase: // for k, v <- container { ... } or [expr for k, v <- container]
+		key, value = gopForPhraseVars(n)
+	default:
+		return nil
+	}
+	var hints []protocol.InlayHint
+	if h := gopVariableType(key, m, tf, info, q); h != nil {
+		hints = append(hints, *h)
+	}
+	if h := gopVariableType(value, m, tf, info, q); h != nil {
+		hints = append(hints, *h)
+	}
+	return hints
+}
+
+// gopForPhraseVars returns the key and value variables of a for phrase as
+// expressions, taking care not to turn a nil *ast.Ident into a non-nil
+// ast.Expr.
+func gopForPhraseVars(p *ast.ForPhrase) (key, value ast.Expr) {
+	if p.Key != nil {
+		key = p.Key
+	}
+	if p.Value != nil {
+		value = p.Value
+	}
+	return
+}
+
+func gopVariableType(e ast.Expr, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) *protocol.InlayHint {
+	if e == nil {
+		return nil
+	}
+	typ := info.TypeOf(e)
+	if typ == nil {
+		return nil
+	}
+	end, err := m.PosPosition(tf, e.End())
+	if err != nil {
+		return nil
+	}
+	return &protocol.InlayHint{
+		Position:    end,
+		Label:       buildLabel(types.TypeString(typ, *q)),
+		Kind:        protocol.Type,
+		PaddingLeft: true,
+	}
+}
+
+func gopConstantValues(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, _ *types.Qualifier) []protocol.InlayHint {
+	genDecl, ok := node.(*ast.GenDecl)
+	if !ok || genDecl.Tok != token.CONST {
+		return nil
+	}
+
+	var hints []protocol.InlayHint
+	for _, v := range genDecl.Specs {
+		spec, ok := v.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		end, err := m.PosPosition(tf, v.End())
+		if err != nil {
+			continue
+		}
+		// Show hints when values are missing or at least one value is not
+		// a basic literal.
+		showHints := len(spec.Values) == 0
+		checkValues := len(spec.Names) == len(spec.Values)
+		var values []string
+		for i, w := range spec.Names {
+			obj, ok := info.ObjectOf(w).(*types.Const)
+			if !ok || obj.Val().Kind() == constant.Unknown {
+				return nil
+			}
+			if checkValues {
+				switch spec.Values[i].(type) {
+				case *ast.BadExpr:
+					return nil
+				case *ast.BasicLit:
+				default:
+					if obj.Val().Kind() != constant.Bool {
+						showHints = true
+					}
+				}
+			}
+			values = append(values, fmt.Sprintf("%v", obj.Val()))
+		}
+		if !showHints || len(values) == 0 {
+			continue
+		}
+		hints = append(hints, protocol.InlayHint{
+			Position:    end,
+			Label:       buildLabel("= " + strings.Join(values, ", ")),
+			PaddingLeft: true,
+		})
+	}
+	return hints
+}
+
+func gopCompositeLiteralFields(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) []protocol.InlayHint {
+	compLit, ok := node.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	typ := info.TypeOf(compLit)
+	if typ == nil {
+		return nil
+	}
+	if t, ok := typ.(*types.Pointer); ok {
+		typ = t.Elem()
+	}
+	strct, ok := typ.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+
+	var hints []protocol.InlayHint
+	var allEdits []protocol.TextEdit
+	for i, v := range compLit.Elts {
+		if _, ok := v.(*ast.KeyValueExpr); !ok {
+			start, err := m.PosPosition(tf, v.Pos())
+			if err != nil {
+				continue
+			}
+			if i > strct.NumFields()-1 {
+				break
+			}
+			hints = append(hints, protocol.InlayHint{
+				Position:     start,
+				Label:        buildLabel(strct.Field(i).Name() + ":"),
+				Kind:         protocol.Parameter,
+				PaddingRight: true,
+			})
+			allEdits = append(allEdits, protocol.TextEdit{
+				Range:   protocol.Range{Start: start, End: start},
+				NewText: strct.Field(i).Name() + ": ",
+			})
+		}
+	}
+	// It is not allowed to have a mix of keyed and unkeyed fields, so
+	// have the text edits add keys to all fields.
+	for i := range hints {
+		hints[i].TextEdits = allEdits
+	}
+	return hints
+}
+
+func gopCompositeLiteralTypes(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) []protocol.InlayHint {
+	compLit, ok := node.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	typ := info.TypeOf(compLit)
+	if typ == nil {
+		return nil
+	}
+	if compLit.Type != nil {
+		return nil
+	}
+	prefix := ""
+	if t, ok := typ.(*types.Pointer); ok {
+		typ = t.Elem()
+		prefix = "&"
+	}
+	// The type for this composite literal is implicit, add an inlay hint.
+	start, err := m.PosPosition(tf, compLit.Lbrace)
+	if err != nil {
+		return nil
+	}
+	return []protocol.InlayHint{{
+		Position: start,
+		Label:    buildLabel(fmt.Sprintf("%s%s", prefix, types.TypeString(typ, *q))),
+		Kind:     protocol.Type,
+	}}
+}
+
+// gopOverloadFunctions shows which overload of an overloaded function was
+// selected by the type checker, such as:
+//
+//	add/*#1 (a string, b string) string*/ "a", "b"
+func gopOverloadFunctions(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) []protocol.InlayHint {
+	ce, ok := node.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	var id *ast.Ident
+	switch fun := ce.Fun.(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	default:
+		return nil
+	}
+	objs := info.Overloads[id]
+	if len(objs) == 0 {
+		return nil
+	}
+	// Uses records the overload selected for this call.
+	selected, ok := info.Uses[id].(*types.Func)
+	if !ok {
+		return nil
+	}
+	idx := -1
+	for i, obj := range objs {
+		if obj == selected {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil
+	}
+	end, err := m.PosPosition(tf, id.End())
+	if err != nil {
+		return nil
+	}
+	sig := strings.TrimPrefix(types.TypeString(selected.Type(), *q), "func")
+	return []protocol.InlayHint{{
+		Position:    end,
+		Label:       buildLabel(fmt.Sprintf("#%d %s", idx, sig)),
+		Kind:        protocol.Type,
+		PaddingLeft: ce.NoParenEnd != token.NoPos,
+	}}
+}
+
+// gopImplicitReceivers shows the implicit `this` receiver of fields and
+// methods that are referenced by their bare names in classfile methods.
+func gopImplicitReceivers(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, _ *types.Qualifier) []protocol.InlayHint {
+	fd, ok := node.(*ast.FuncDecl)
+	if !ok || fd.Body == nil || fd.Recv == nil || len(fd.Recv.List) != 1 {
+		return nil
+	}
+	// In classfiles, the receiver of methods is synthesized by the compiler.
+	recv := fd.Recv.List[0]
+	if recv.Type.Pos() != token.NoPos {
+		return nil
+	}
+
+	var hints []protocol.InlayHint
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			// n.Sel is qualified by n.X.
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.CompositeLit:
+			// Keys of struct literals name fields, not receivers.
+			if typ := info.TypeOf(n); typ != nil && gopIsStruct(Deref(typ)) {
+				if n.Type != nil {
+					ast.Inspect(n.Type, visit)
+				}
+				for _, elt := range n.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						elt = kv.Value
+					}
+					ast.Inspect(elt, visit)
+				}
+				return false
+			}
+		case *ast.Ident:
+			gopImplicitReceiver(n, m, tf, info, &hints)
+		}
+		return true
+	}
+	ast.Inspect(fd.Body, visit)
+	return hints
+}
+
+func gopImplicitReceiver(id *ast.Ident, m *protocol.Mapper, tf *token.File, info *typesutil.Info, hints *[]protocol.InlayHint) {
+	switch obj := info.Uses[id].(type) {
+	case *types.Var:
+		if !obj.IsField() {
+			return
+		}
+	case *types.Func:
+		if sig, ok := obj.Type().(*types.Signature); !ok || sig.Recv() == nil {
+			return
+		}
+	default:
+		return
+	}
+	start, err := m.PosPosition(tf, id.Pos())
+	if err != nil {
+		return
+	}
+	*hints = append(*hints, protocol.InlayHint{
+		Position: start,
+		Label:    buildLabel("this."),
+	})
+}
+
+func gopIsStruct(typ types.Type) bool {
+	_, ok := typ.Underlying().(*types.Struct)
+	return ok
+}
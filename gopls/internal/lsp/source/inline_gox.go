@@ -0,0 +1,256 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+// This file defines the refactor.inline code action for Go+ files.
+
+import (
+	"context"
+	"fmt"
+	"go/types"
+	"runtime/debug"
+	"strings"
+
+	goast "go/ast"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gop/ast/astutil"
+	"golang.org/x/tools/gopls/internal/bug"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/safetoken"
+	"golang.org/x/tools/gopls/internal/span"
+	"golang.org/x/tools/internal/diff"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/refactor/inline"
+)
+
+// GopEnclosingStaticCall returns the innermost function call enclosing
+// the selected range, along with the callee.
+//
+// The call is an *ast.CallExpr, including a command-style call such as
+// "echo x", or the *ast.Ident or *ast.SelectorExpr of an auto-property
+// such as "x.len", which calls a method without arguments.
+func GopEnclosingStaticCall(pkg Package, pgf *ParsedGopFile, rng protocol.Range) (ast.Expr, *types.Func, error) {
+	start, end, err := pgf.RangePos(rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, start, end)
+	info := pkg.GopTypesInfo()
+
+	var call ast.Expr
+loop:
+	for i, n := range path {
+		switch n := n.(type) {
+		case *ast.FuncLit, *ast.LambdaExpr, *ast.LambdaExpr2:
+			break loop
+		case *ast.CallExpr:
+			call = n
+			break loop
+		case *ast.Ident:
+			if i+1 < len(path) {
+				if sel, ok := path[i+1].(*ast.SelectorExpr); ok && sel.Sel == n {
+					continue // see SelectorExpr
+				}
+			}
+			if gopIsAutoProperty(info, n, n) && !gopIsCallee(path[i+1:], n) {
+				call = n
+				break loop
+			}
+		case *ast.SelectorExpr:
+			if gopIsAutoProperty(info, n, n.Sel) && !gopIsCallee(path[i+1:], n) {
+				call = n
+				break loop
+			}
+		}
+	}
+	if call == nil {
+		return nil, nil, fmt.Errorf("no enclosing call")
+	}
+	var fun ast.Expr
+	switch c := call.(type) {
+	case *ast.CallExpr:
+		fun = astutil.Unparen(c.Fun)
+		lparen := c.Lparen
+		if c.IsCommand() {
+			lparen = c.Fun.End() // echo x
+		}
+		if safetoken.Line(pgf.Tok, lparen) != safetoken.Line(pgf.Tok, start) {
+			return nil, nil, fmt.Errorf("enclosing call is not on this line")
+		}
+	default:
+		fun = call
+	}
+	fn := gopStaticCallee(info, fun)
+	if fn == nil {
+		return nil, nil, fmt.Errorf("not a static call to a Go+ or Go function")
+	}
+	return call, fn, nil
+}
+
+// gopIsAutoProperty reports whether e, whose selected name is id, is an
+// auto-property, that is, a call of a method without parens.
+func gopIsAutoProperty(info *typesutil.Info, e ast.Expr, id *ast.Ident) bool {
+	if _, ok := info.Uses[id].(*types.Func); !ok {
+		return false
+	}
+	_, isSig := info.TypeOf(e).(*types.Signature)
+	return !isSig
+}
+
+// gopIsCallee reports whether e is the function of the call path[0].
+func gopIsCallee(path []ast.Node, e ast.Expr) bool {
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.ParenExpr:
+			continue
+		case *ast.CallExpr:
+			return astutil.Unparen(n.Fun) == e
+		}
+		break
+	}
+	return false
+}
+
+// gopStaticCallee returns the function called by fun, or nil if the
+// call is dynamic. For overloaded functions it is the selected overload.
+func gopStaticCallee(info *typesutil.Info, fun ast.Expr) *types.Func {
+	var id *ast.Ident
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[fun]; ok && sel.Kind() == types.FieldVal {
+			return nil // call of a field of func type
+		}
+		id = fun.Sel
+	default:
+		return nil
+	}
+	fn, _ := info.Uses[id].(*types.Func)
+	if fn == nil {
+		return nil
+	}
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil && types.IsInterface(recv.Type()) {
+		return nil // interface method
+	}
+	return fn
+}
+
+func gopInlineCall(ctx context.Context, snapshot Snapshot, fh FileHandle, rng protocol.Range) (_ *token.FileSet, _ *analysis.SuggestedFix, err error) {
+	// Find enclosing static call.
+	callerPkg, callerPGF, err := NarrowestPackageForGopFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, nil, err
+	}
+	call, fn, err := GopEnclosingStaticCall(callerPkg, callerPGF, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The inliner assumes that input is well-typed,
+	// but that is frequently not the case within gopls.
+	// Until we are able to harden the inliner,
+	// report panics as errors to avoid crashing the server.
+	defer func() {
+		if x := recover(); x != nil {
+			err = bug.Errorf("inlining failed unexpectedly: %v\nstack: %v",
+				x, debug.Stack())
+		}
+	}()
+
+	// Users can consult the gopls event log to see
+	// why a particular inlining strategy was chosen.
+	logf := func(format string, args ...any) {
+		event.Log(ctx, "inliner: "+fmt.Sprintf(format, args...))
+	}
+
+	// Locate callee by file/line and analyze it.
+	calleePosn := safetoken.StartPosition(callerPkg.FileSet(), fn.Pos())
+	calleeURI := span.URIFromPath(calleePosn.Filename)
+	var callee *inline.Callee
+	if strings.HasSuffix(calleePosn.Filename, ".go") {
+		calleePkg, calleePGF, err := NarrowestPackageForFile(ctx, snapshot, calleeURI)
+		if err != nil {
+			return nil, nil, err
+		}
+		var calleeDecl *goast.FuncDecl
+		for _, decl := range calleePGF.File.Decls {
+			if decl, ok := decl.(*goast.FuncDecl); ok {
+				posn := safetoken.StartPosition(calleePkg.FileSet(), decl.Name.Pos())
+				if posn.Line == calleePosn.Line && posn.Column == calleePosn.Column {
+					calleeDecl = decl
+					break
+				}
+			}
+		}
+		if calleeDecl == nil {
+			return nil, nil, fmt.Errorf("can't find callee")
+		}
+		callee, err = inline.AnalyzeCallee(logf, calleePkg.FileSet(), calleePkg.GetTypes(), calleePkg.GetTypesInfo(), calleeDecl, calleePGF.Src)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		calleePkg, calleePGF, err := NarrowestPackageForGopFile(ctx, snapshot, calleeURI)
+		if err != nil {
+			return nil, nil, err
+		}
+		var calleeDecl *ast.FuncDecl
+		for _, decl := range calleePGF.File.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok {
+				posn := safetoken.StartPosition(calleePkg.FileSet(), decl.Name.Pos())
+				if posn.Line == calleePosn.Line && posn.Column == calleePosn.Column {
+					calleeDecl = decl
+					break
+				}
+			}
+		}
+		if calleeDecl == nil {
+			return nil, nil, fmt.Errorf("can't find callee")
+		}
+		callee, err = inline.GopAnalyzeCallee(logf, calleePkg.FileSet(), calleePkg.GetTypes(), calleePkg.GopTypesInfo(), calleeDecl, calleePGF.Src)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Inline the call.
+	caller := &inline.GopCaller{
+		Fset:    callerPkg.FileSet(),
+		Types:   callerPkg.GetTypes(),
+		Info:    callerPkg.GopTypesInfo(),
+		File:    callerPGF.File,
+		Call:    call,
+		Content: callerPGF.Src,
+	}
+
+	got, err := inline.GopInline(logf, caller, callee)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Suggest the fix.
+	return callerPkg.FileSet(), &analysis.SuggestedFix{
+		Message:   fmt.Sprintf("inline call of %v", callee),
+		TextEdits: gopDiffToTextEdits(callerPGF.Tok, diff.Bytes(callerPGF.Src, got)),
+	}, nil
+}
+
+// gopDiffToTextEdits converts diff edits of the file tok to text edits.
+func gopDiffToTextEdits(tok *token.File, diffs []diff.Edit) []analysis.TextEdit {
+	edits := make([]analysis.TextEdit, 0, len(diffs))
+	for _, edit := range diffs {
+		edits = append(edits, analysis.TextEdit{
+			Pos:     tok.Pos(edit.Start),
+			End:     tok.Pos(edit.End),
+			NewText: []byte(edit.New),
+		})
+	}
+	return edits
+}
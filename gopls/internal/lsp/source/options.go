@@ -52,6 +52,22 @@ import (
 	"golang.org/x/tools/go/analysis/passes/unsafeptr"
 	"golang.org/x/tools/go/analysis/passes/unusedresult"
 	"golang.org/x/tools/go/analysis/passes/unusedwrite"
+	gopassign "golang.org/x/tools/gop/analysis/passes/assign"
+	gopbools "golang.org/x/tools/gop/analysis/passes/bools"
+	gopcopylock "golang.org/x/tools/gop/analysis/passes/copylock"
+	gopdeepequalerrors "golang.org/x/tools/gop/analysis/passes/deepequalerrors"
+	goperrorsas "golang.org/x/tools/gop/analysis/passes/errorsas"
+	gopifaceassert "golang.org/x/tools/gop/analysis/passes/ifaceassert"
+	goploopclosure "golang.org/x/tools/gop/analysis/passes/loopclosure"
+	goplostcancel "golang.org/x/tools/gop/analysis/passes/lostcancel"
+	gopnilfunc "golang.org/x/tools/gop/analysis/passes/nilfunc"
+	gopnilness "golang.org/x/tools/gop/analysis/passes/nilness"
+	gopprintf "golang.org/x/tools/gop/analysis/passes/printf"
+	gopshadow "golang.org/x/tools/gop/analysis/passes/shadow"
+	gopstringintconv "golang.org/x/tools/gop/analysis/passes/stringintconv"
+	gopstructtag "golang.org/x/tools/gop/analysis/passes/structtag"
+	gopunmarshal "golang.org/x/tools/gop/analysis/passes/unmarshal"
+	gopunusedresult "golang.org/x/tools/gop/analysis/passes/unusedresult"
 	"golang.org/x/tools/gopls/internal/lsp/analysis/deprecated"
 	"golang.org/x/tools/gopls/internal/lsp/analysis/embeddirective"
 	"golang.org/x/tools/gopls/internal/lsp/analysis/fillreturns"
@@ -113,6 +129,7 @@ func DefaultOptions() *Options {
 						protocol.QuickFix:              true,
 						protocol.RefactorRewrite:       true,
 						protocol.RefactorExtract:       true,
+						protocol.RefactorInline:        true,
 					},
 					Mod: {
 						protocol.SourceOrganizeImports: true,
@@ -405,6 +422,12 @@ type DocumentationOptions struct {
 	// documentation links in hover.
 	LinkTarget string
 
+	// GopLinkTarget controls where documentation links of Go+ packages go,
+	// such as a local Go+ documentation server. Unlike LinkTarget, it may
+	// have a scheme, as in "http://localhost:8080"; it is https otherwise.
+	// If empty, LinkTarget is used.
+	GopLinkTarget string // goxls: Go+
+
 	// LinksInHover toggles the presence of links to documentation in hover.
 	LinksInHover bool
 }
@@ -1099,6 +1122,9 @@ func (o *Options) set(name string, value interface{}, seen map[string]struct{})
 	case "linkTarget":
 		result.setString(&o.LinkTarget)
 
+	case "gopLinkTarget": // goxls: Go+
+		result.setString(&o.GopLinkTarget)
+
 	case "linksInHover":
 		result.setBool(&o.LinksInHover)
 
@@ -1509,19 +1535,36 @@ func typeErrorAnalyzers() map[string]*Analyzer {
 			Analyzer: nonewvars.Analyzer,
 			Enabled:  true,
 		},
+		nonewvars.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer: nonewvars.GopAnalyzer,
+			Enabled:  true,
+		},
 		noresultvalues.Analyzer.Name: {
 			Analyzer: noresultvalues.Analyzer,
 			Enabled:  true,
 		},
+		noresultvalues.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer: noresultvalues.GopAnalyzer,
+			Enabled:  true,
+		},
 		undeclaredname.Analyzer.Name: {
 			Analyzer: undeclaredname.Analyzer,
 			Fix:      UndeclaredName,
 			Enabled:  true,
 		},
+		undeclaredname.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer: undeclaredname.GopAnalyzer,
+			Fix:      UndeclaredName,
+			Enabled:  true,
+		},
 		unusedvariable.Analyzer.Name: {
 			Analyzer: unusedvariable.Analyzer,
 			Enabled:  false,
 		},
+		unusedvariable.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer: unusedvariable.GopAnalyzer,
+			Enabled:  false,
+		},
 	}
 }
 
@@ -1535,11 +1578,22 @@ func convenienceAnalyzers() map[string]*Analyzer {
 			Enabled:    true,
 			ActionKind: []protocol.CodeActionKind{protocol.RefactorRewrite},
 		},
+		fillstruct.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer:   fillstruct.GopAnalyzer,
+			Fix:        FillStruct,
+			Enabled:    true,
+			ActionKind: []protocol.CodeActionKind{protocol.RefactorRewrite},
+		},
 		stubmethods.Analyzer.Name: {
 			Analyzer: stubmethods.Analyzer,
 			Fix:      StubMethods,
 			Enabled:  true,
 		},
+		stubmethods.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer: stubmethods.GopAnalyzer,
+			Fix:      StubMethods,
+			Enabled:  true,
+		},
 		infertypeargs.Analyzer.Name: {
 			Analyzer:   infertypeargs.Analyzer,
 			Enabled:    true,
@@ -1587,9 +1641,29 @@ func defaultAnalyzers() map[string]*Analyzer {
 		sortslice.Analyzer.Name:        {Analyzer: sortslice.Analyzer, Enabled: true},
 		testinggoroutine.Analyzer.Name: {Analyzer: testinggoroutine.Analyzer, Enabled: true},
 		unusedparams.Analyzer.Name:     {Analyzer: unusedparams.Analyzer, Enabled: false},
+		unusedparams.GopAnalyzer.Name:  {Analyzer: unusedparams.GopAnalyzer, Enabled: false}, // goxls: use Go+ Analyzer
 		unusedwrite.Analyzer.Name:      {Analyzer: unusedwrite.Analyzer, Enabled: false},
 		useany.Analyzer.Name:           {Analyzer: useany.Analyzer, Enabled: false},
 		timeformat.Analyzer.Name:       {Analyzer: timeformat.Analyzer, Enabled: true},
+
+		// goxls: use Go+ Analyzer
+		gopassign.Analyzer.Name:          {Analyzer: gopassign.Analyzer, Enabled: true},
+		gopbools.Analyzer.Name:           {Analyzer: gopbools.Analyzer, Enabled: true},
+		gopcopylock.Analyzer.Name:        {Analyzer: gopcopylock.Analyzer, Enabled: true},
+		gopdeepequalerrors.Analyzer.Name: {Analyzer: gopdeepequalerrors.Analyzer, Enabled: true},
+		goperrorsas.Analyzer.Name:        {Analyzer: goperrorsas.Analyzer, Enabled: true},
+		gopifaceassert.Analyzer.Name:     {Analyzer: gopifaceassert.Analyzer, Enabled: true},
+		goploopclosure.Analyzer.Name:     {Analyzer: goploopclosure.Analyzer, Enabled: true},
+		goplostcancel.Analyzer.Name:      {Analyzer: goplostcancel.Analyzer, Enabled: true},
+		gopnilfunc.Analyzer.Name:         {Analyzer: gopnilfunc.Analyzer, Enabled: true},
+		gopnilness.Analyzer.Name:         {Analyzer: gopnilness.Analyzer, Enabled: false},
+		gopprintf.Analyzer.Name:          {Analyzer: gopprintf.Analyzer, Enabled: true},
+		gopshadow.Analyzer.Name:          {Analyzer: gopshadow.Analyzer, Enabled: false},
+		gopstringintconv.Analyzer.Name:   {Analyzer: gopstringintconv.Analyzer, Enabled: true},
+		gopstructtag.Analyzer.Name:       {Analyzer: gopstructtag.Analyzer, Enabled: true},
+		gopunmarshal.Analyzer.Name:       {Analyzer: gopunmarshal.Analyzer, Enabled: true},
+		gopunusedresult.Analyzer.Name:    {Analyzer: gopunusedresult.Analyzer, Enabled: true},
+
 		embeddirective.Analyzer.Name: {
 			Analyzer:        embeddirective.Analyzer,
 			Enabled:         true,
@@ -1608,6 +1682,16 @@ func defaultAnalyzers() map[string]*Analyzer {
 			Enabled:    true,
 			ActionKind: []protocol.CodeActionKind{protocol.SourceFixAll, protocol.QuickFix},
 		},
+		simplifycompositelit.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer:   simplifycompositelit.GopAnalyzer,
+			Enabled:    true,
+			ActionKind: []protocol.CodeActionKind{protocol.SourceFixAll, protocol.QuickFix},
+		},
+		simplifyrange.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer:   simplifyrange.GopAnalyzer,
+			Enabled:    true,
+			ActionKind: []protocol.CodeActionKind{protocol.SourceFixAll, protocol.QuickFix},
+		},
 		simplifyslice.Analyzer.Name: {
 			Analyzer:   simplifyslice.Analyzer,
 			Enabled:    true,
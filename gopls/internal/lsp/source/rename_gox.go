@@ -10,7 +10,6 @@ import (
 	"fmt"
 	"go/types"
 	"log"
-	"regexp"
 	"sort"
 	"strings"
 
@@ -18,10 +17,10 @@ import (
 	"github.com/goplus/gop/token"
 	"golang.org/x/tools/go/types/objectpath"
 	"golang.org/x/tools/gop/ast/astutil"
+	"golang.org/x/tools/gop/goputil"
 	"golang.org/x/tools/gopls/internal/bug"
 	"golang.org/x/tools/gopls/internal/goxls/parserutil"
 	"golang.org/x/tools/gopls/internal/lsp/protocol"
-	"golang.org/x/tools/gopls/internal/lsp/safetoken"
 	"golang.org/x/tools/gopls/internal/span"
 	"golang.org/x/tools/internal/diff"
 	"golang.org/x/tools/internal/event"
@@ -468,31 +467,17 @@ func (r *renamer) gopUpdate(result map[span.URI][]diff.Edit) (map[span.URI][]dif
 		}
 
 		// Perform the rename in doc comments declared in the original package.
-		// go/parser strips out \r\n returns from the comment text, so go
-		// line-by-line through the comment text to get the correct positions.
-		docRegexp := regexp.MustCompile(`\b` + r.from + `\b`) // valid identifier => valid regexp
+		uri := span.URIFromPath(pgf.Tok.Name())
 		for _, comment := range doc.List {
 			if isDirective(comment.Text) {
 				continue
 			}
-			// TODO(adonovan): why are we looping over lines?
-			// Just run the loop body once over the entire multiline comment.
-			lines := strings.Split(comment.Text, "\n")
-			tokFile := pgf.Tok
-			commentLine := safetoken.Line(tokFile, comment.Pos())
-			uri := span.URIFromPath(tokFile.Name())
-			for i, line := range lines {
-				lineStart := comment.Pos()
-				if i > 0 {
-					lineStart = tokFile.LineStart(commentLine + i)
-				}
-				for _, locs := range docRegexp.FindAllIndex([]byte(line), -1) {
-					edit, err := posEdit(tokFile, lineStart+token.Pos(locs[0]), lineStart+token.Pos(locs[1]), r.to)
-					if err != nil {
-						return nil, err // can't happen
-					}
-					result[uri] = append(result[uri], edit)
+			for _, pos := range goputil.NameRefs(pgf.Tok, comment, r.from) {
+				edit, err := posEdit(pgf.Tok, pos, pos+token.Pos(len(r.from)), r.to)
+				if err != nil {
+					return nil, err // can't happen
 				}
+				result[uri] = append(result[uri], edit)
 			}
 		}
 	}
@@ -502,52 +487,7 @@ func (r *renamer) gopUpdate(result map[span.URI][]diff.Edit) (map[span.URI][]dif
 
 // gopDocComment returns the doc for an identifier within the specified file.
 func gopDocComment(pgf *ParsedGopFile, id *ast.Ident) *ast.CommentGroup {
-	nodes, _ := astutil.PathEnclosingInterval(pgf.File, id.Pos(), id.End())
-	for _, node := range nodes {
-		switch decl := node.(type) {
-		case *ast.FuncDecl:
-			return decl.Doc
-		case *ast.Field:
-			return decl.Doc
-		case *ast.GenDecl:
-			return decl.Doc
-		// For {Type,Value}Spec, if the doc on the spec is absent,
-		// search for the enclosing GenDecl
-		case *ast.TypeSpec:
-			if decl.Doc != nil {
-				return decl.Doc
-			}
-		case *ast.ValueSpec:
-			if decl.Doc != nil {
-				return decl.Doc
-			}
-		case *ast.Ident:
-		case *ast.AssignStmt:
-			// *ast.AssignStmt doesn't have an associated comment group.
-			// So, we try to find a comment just before the identifier.
-
-			// Try to find a comment group only for short variable declarations (:=).
-			if decl.Tok != token.DEFINE {
-				return nil
-			}
-
-			identLine := safetoken.Line(pgf.Tok, id.Pos())
-			for _, comment := range nodes[len(nodes)-1].(*ast.File).Comments {
-				if comment.Pos() > id.Pos() {
-					// Comment is after the identifier.
-					continue
-				}
-
-				lastCommentLine := safetoken.Line(pgf.Tok, comment.End())
-				if lastCommentLine+1 == identLine {
-					return comment
-				}
-			}
-		default:
-			return nil
-		}
-	}
-	return nil
+	return goputil.DocComment(pgf.Tok, pgf.File, id)
 }
 
 // gopUpdatePkgName returns the updates to rename a pkgName in the import spec by
@@ -160,6 +160,10 @@ type Snapshot interface {
 	// ParseWork is used to parse go.work files.
 	ParseWork(ctx context.Context, fh FileHandle) (*ParsedWorkFile, error)
 
+	// ParseGopMod is used to parse gop.mod files.
+	// goxls: Go+
+	ParseGopMod(ctx context.Context, fh FileHandle) (*ParsedGopModFile, error)
+
 	// BuiltinFile returns information about the special builtin package.
 	BuiltinFile(ctx context.Context) (*ParsedGoFile, error)
 
@@ -571,6 +575,7 @@ type Metadata struct {
 	CompiledGopFiles []span.URI
 	gopMod_          *gopmod.Module // see GopMod_()
 	gopImporter      types.Importer
+	ListFailed       bool // go list failed to load the package, which has no Go files
 
 	ForTest       PackagePath // q in a "p [q.test]" package, else ""
 	TypesSizes    types.Sizes
@@ -867,6 +872,8 @@ const (
 	Work
 	// goxls: Gop is a Go+ file.
 	Gop
+	// goxls: GopMod is a gop.mod file.
+	GopMod
 )
 
 func (k FileKind) String() string {
@@ -883,6 +890,8 @@ func (k FileKind) String() string {
 		return "go.work"
 	case Gop: // goxls: Gop is a Go+ file
 		return "gop"
+	case GopMod: // goxls: GopMod is a gop.mod file
+		return "gop.mod"
 	default:
 		return fmt.Sprintf("internal error: unknown file kind %d", k)
 	}
@@ -1069,6 +1078,8 @@ const (
 	TemplateError            DiagnosticSource = "template"
 	WorkFileError            DiagnosticSource = "go.work file"
 	ConsistencyInfo          DiagnosticSource = "consistency"
+	GopCommandError          DiagnosticSource = "gop command"  // goxls: Go+
+	GopModFileError          DiagnosticSource = "gop.mod file" // goxls: Go+
 )
 
 func AnalyzerErrorKind(name string) DiagnosticSource {
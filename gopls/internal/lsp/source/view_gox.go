@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"go/types"
 	"path/filepath"
+	"strings"
 
 	"github.com/goplus/gop"
 	"github.com/goplus/gop/ast"
@@ -16,7 +17,9 @@ import (
 	"github.com/goplus/gop/scanner"
 	"github.com/goplus/gop/token"
 	"github.com/goplus/gop/x/gopenv"
+	modenv "github.com/goplus/mod/env"
 	"github.com/goplus/mod/gopmod"
+	"github.com/goplus/mod/modfile"
 	"golang.org/x/tools/gop/packages"
 	"golang.org/x/tools/gopls/internal/lsp/protocol"
 	"golang.org/x/tools/gopls/internal/lsp/safetoken"
@@ -86,6 +89,15 @@ func (p ParsedGopFile) Fixed() bool {
 	return p.FixedSrc || p.FixedAST
 }
 
+// A ParsedGopModFile contains the results of parsing a gop.mod file.
+// File is nil if the file has errors.
+type ParsedGopModFile struct {
+	URI         span.URI
+	File        *modfile.File
+	Mapper      *protocol.Mapper
+	ParseErrors []*Diagnostic
+}
+
 // HasPkgDecl checks if `package xxx` exists or not.
 func (pgf *ParsedGopFile) HasPkgDecl() bool {
 	return pgf.File.Package != token.NoPos
@@ -124,6 +136,12 @@ func (pgf *ParsedGopFile) PosRange(start, end token.Pos) (protocol.Range, error)
 	return pgf.Mapper.PosRange(pgf.Tok, start, end)
 }
 
+// PosMappedRange returns a MappedRange for the token.Pos interval in this file.
+// A MappedRange can be converted to any other form.
+func (pgf *ParsedGopFile) PosMappedRange(start, end token.Pos) (protocol.MappedRange, error) {
+	return pgf.Mapper.PosMappedRange(pgf.Tok, start, end)
+}
+
 // PosLocation returns a protocol Location for the token.Pos interval in this file.
 func (pgf *ParsedGopFile) PosLocation(start, end token.Pos) (protocol.Location, error) {
 	return pgf.Mapper.PosLocation(pgf.Tok, start, end)
@@ -140,13 +158,38 @@ func (m *Metadata) GopMod_() *gopmod.Module {
 	return m.gopMod_
 }
 
+// GopImporter returns the importer of the packages imported by the Go+
+// files of the package. If the Go+ environment can't be found, it fails
+// with the reason, which is reported on the imports that need it.
 func (m *Metadata) GopImporter(fset *token.FileSet) types.Importer {
 	if m.gopImporter == nil {
-		m.gopImporter = gop.NewImporter(m.GopMod_(), gopenv.Get(), fset)
+		if env, err := gopEnv(); err != nil {
+			m.gopImporter = errImporter{err}
+		} else {
+			m.gopImporter = gop.NewImporter(m.GopMod_(), env, fset)
+		}
 	}
 	return m.gopImporter
 }
 
+// gopEnv returns the Go+ environment. gopenv.Get panics if GOPROOT can't
+// be found.
+func gopEnv() (env *modenv.Gop, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("cannot find the Go+ environment: %s", strings.TrimSpace(fmt.Sprint(e)))
+		}
+	}()
+	return gopenv.Get(), nil
+}
+
+// An errImporter fails to import any package with err.
+type errImporter struct{ err error }
+
+func (imp errImporter) Import(path string) (*types.Package, error) {
+	return nil, imp.err
+}
+
 // NarrowestPackageForGopFile is a convenience function that selects the
 // narrowest non-ITV package to which this file belongs, type-checks
 // it in the requested mode (full or workspace), and returns it, along
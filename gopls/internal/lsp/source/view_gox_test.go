@@ -0,0 +1,22 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goplus/gop/token"
+)
+
+func TestGopImporterWithoutGOPROOT(t *testing.T) {
+	t.Setenv("GOPROOT", t.TempDir()) // not a valid GOPROOT
+
+	imp := new(Metadata).GopImporter(token.NewFileSet())
+	_, err := imp.Import("github.com/goplus/gop/builtin")
+	if err == nil || !strings.Contains(err.Error(), "cannot find the Go+ environment") || !strings.Contains(err.Error(), "GOPROOT") {
+		t.Errorf("Import without GOPROOT: got error %v, want the Go+ environment error", err)
+	}
+}
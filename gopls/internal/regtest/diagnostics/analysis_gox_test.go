@@ -0,0 +1,72 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diagnostics
+
+import (
+	"testing"
+
+	. "golang.org/x/tools/gopls/internal/lsp/regtest"
+	"golang.org/x/tools/internal/testenv"
+)
+
+// TestGopVetAnalyzers checks that the vet analyzers ported to Go+ report
+// their findings in Go+ files.
+func TestGopVetAnalyzers(t *testing.T) {
+	testenv.NeedsGOPROOT(t)
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.18
+-- gop_autogen.go --
+package main
+-- main.gop --
+import "sync"
+
+var mu sync.Mutex
+
+func lock(m sync.Mutex) {
+}
+
+x := 1
+x = x
+printf "%s\n", x
+for i <- [1, 2, 3] {
+	go func() {
+		println i
+	}()
+}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("main.gop")
+		env.AfterChange(
+			Diagnostics(env.AtRegexp("main.gop", `m (sync.Mutex)`), WithMessage("passes lock by value")),
+			Diagnostics(env.AtRegexp("main.gop", `x = x`), WithMessage("self-assignment of x to x")),
+			Diagnostics(env.AtRegexp("main.gop", `printf`), WithMessage("fmt.Printf format %s has arg x of wrong type int")),
+			Diagnostics(env.AtRegexp("main.gop", `println (i)`), WithMessage("loop variable i captured by func literal")),
+		)
+	})
+}
+
+// TestGopVetAnalyzersNongen checks that the Go+ files of a package without
+// generated Go files, which go list fails to load, are analyzed too.
+func TestGopVetAnalyzersNongen(t *testing.T) {
+	testenv.NeedsGOPROOT(t)
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.18
+-- main.gop --
+x := 1
+x = x
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("main.gop")
+		env.AfterChange(
+			Diagnostics(env.AtRegexp("main.gop", `x = x`), WithMessage("self-assignment of x to x")),
+		)
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diagnostics
+
+import (
+	"testing"
+
+	. "golang.org/x/tools/gopls/internal/lsp/regtest"
+	"golang.org/x/tools/internal/testenv"
+)
+
+func TestGopBuildConstraints(t *testing.T) {
+	testenv.NeedsGOPROOT(t)
+	const files = `
+-- go.mod --
+module mod.test
+
+go 1.18
+-- gop_autogen.go --
+package main
+-- main.gop --
+println name, tagged
+-- name_linux.gop --
+package main
+
+var name = "linux"
+-- name_windows.gop --
+package main
+
+var name = "windows"
+-- name_other.gop --
+//go:build !linux && !windows
+
+package main
+
+var name = "other"
+-- tagged.gop --
+//go:build foo
+
+package main
+
+var tagged = true
+-- untagged.gop --
+//go:build !foo
+
+package main
+
+var tagged = false
+`
+	for _, flags := range [][]string{nil, {"-tags=foo"}} {
+		WithOptions(
+			Settings{"buildFlags": flags},
+		).Run(t, files, func(t *testing.T, env *Env) {
+			env.OpenFile("main.gop")
+			env.AfterChange(
+				NoDiagnostics(ForFile("main.gop")),
+				NoDiagnostics(ForFile("name_linux.gop")),
+				NoDiagnostics(ForFile("name_windows.gop")),
+				NoDiagnostics(ForFile("tagged.gop")),
+			)
+		})
+	}
+}
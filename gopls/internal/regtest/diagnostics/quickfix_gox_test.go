@@ -0,0 +1,58 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package diagnostics
+
+import (
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	. "golang.org/x/tools/gopls/internal/lsp/regtest"
+	"golang.org/x/tools/internal/testenv"
+)
+
+// TestGopQuickFixes checks that the quick fixes of type errors are
+// offered and applied in Go+ files.
+func TestGopQuickFixes(t *testing.T) {
+	testenv.NeedsGOPROOT(t)
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.18
+-- gop_autogen.go --
+package main
+-- main.gop --
+func f() {
+	return 1
+}
+
+x := 1
+x := 2
+println x
+`
+	const want = `func f() {
+	return
+}
+
+x := 1
+x = 2
+println x
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("main.gop")
+		for _, re := range []string{`return 1`, `x := 2`} {
+			var d protocol.PublishDiagnosticsParams
+			env.AfterChange(
+				Diagnostics(env.AtRegexp("main.gop", re)),
+				ReadDiagnostics("main.gop", &d),
+			)
+			env.ApplyQuickFixes("main.gop", d.Diagnostics)
+		}
+		env.AfterChange(NoDiagnostics(ForFile("main.gop")))
+		if got := env.BufferText("main.gop"); got != want {
+			t.Errorf("after quick fixes:\n%s\nwant:\n%s", got, want)
+		}
+	})
+}
@@ -4,12 +4,15 @@
 package inlayhint
 
 import (
+	"fmt"
+	"sort"
 	"testing"
 
 	"golang.org/x/tools/gopls/internal/bug"
 	"golang.org/x/tools/gopls/internal/hooks"
 	. "golang.org/x/tools/gopls/internal/lsp/regtest"
 	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/internal/testenv"
 )
 
 func TestMain(m *testing.M) {
@@ -67,3 +70,72 @@ const (
 		})
 	}
 }
+
+func TestGopInlayHints(t *testing.T) {
+	testenv.NeedsGOPROOT(t)
+	const workspace = `
+-- go.mod --
+module inlayHint.test
+go 1.18
+-- gop_autogen.go --
+package main
+-- main.gop --
+func add = (
+	func(a, b int) int {
+		return a + b
+	}
+	func(a, b string) string {
+		return a + b
+	}
+)
+
+s := add("a", "b")
+for i <- [1, 2] {
+	println i, s
+}
+-- Counter.gox --
+var (
+	n int
+)
+
+func Inc() {
+	n++
+}
+
+func Reset() {
+	n = 0
+	Inc()
+}
+`
+	WithOptions(
+		Settings{
+			"hints": map[string]bool{
+				source.OverloadFunctions:  true,
+				source.RangeVariableTypes: true,
+				source.ParameterNames:     true,
+				source.ImplicitReceivers:  true,
+			},
+		},
+	).Run(t, workspace, func(t *testing.T, env *Env) {
+		env.OpenFile("main.gop")
+		var got []string
+		for _, hint := range env.InlayHints("main.gop") {
+			got = append(got, hint.Label[0].Value)
+		}
+		sort.Strings(got)
+		want := []string{"#1 (a string, b string) string", "a...:", "a:", "b:", "int"}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("got inlay hints %q, want %q", got, want)
+		}
+
+		env.OpenFile("Counter.gox")
+		got = nil
+		for _, hint := range env.InlayHints("Counter.gox") {
+			got = append(got, hint.Label[0].Value)
+		}
+		want = []string{"this.", "this.", "this."}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("got inlay hints %q, want %q", got, want)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"strings"
+	"testing"
+
+	. "golang.org/x/tools/gopls/internal/lsp/regtest"
+)
+
+// TestGopModClassfile checks that classfile extensions registered in gop.mod
+// are recognized as Go+ files, and that changes to gop.mod are picked up.
+func TestGopModClassfile(t *testing.T) {
+	const files = `
+-- go.mod --
+module example.com
+
+go 1.18
+-- gop.mod --
+gop 1.2
+-- tdsl/tdsl.go --
+package tdsl
+
+type Game struct{}
+
+func (p *Game) Main() {}
+
+func (p *Game) Title() string { return "tdsl" }
+-- main.tdsl --
+func double(x int) int {
+	return x * 2
+}
+
+echo double(21), title
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("main.tdsl")
+		env.WriteWorkspaceFile("gop.mod", "gop 1.2\n\nproject .tdsl Game example.com/tdsl\n")
+		env.AfterChange()
+		content, _ := env.Hover(env.RegexpSearch("main.tdsl", `double\(21`))
+		if content == nil || !strings.Contains(content.Value, "func (*Game).double(x int) int") {
+			t.Errorf("hover over double: got %v, want func (*Game).double(x int) int", content)
+		}
+		env.AfterChange(NoDiagnostics(ForFile("main.tdsl")))
+	})
+}
@@ -0,0 +1,232 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	. "golang.org/x/tools/gopls/internal/lsp/regtest"
+)
+
+const gopFoldingSrc = `
+-- go.mod --
+module example.com
+go 1.18
+-- main.gop --
+import (
+	"fmt"
+	"strings"
+)
+
+// double returns
+// the doubled values.
+func double(a []int) []int {
+	return [x * 2 for x <- a]
+}
+
+squares := [x * x for x <- [
+	1, 2, 3,
+]]
+fmt.Println strings.ToUpper("a"), squares
+`
+
+type gopFold struct {
+	start, end uint32
+	kind       string
+}
+
+// gopFoldingRanges returns the folding ranges of the open file name.
+func gopFoldingRanges(t *testing.T, env *Env, name string) []gopFold {
+	t.Helper()
+	ranges, err := env.Editor.Server.FoldingRange(env.Ctx, &protocol.FoldingRangeParams{
+		TextDocument: env.Editor.TextDocumentIdentifier(name),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []gopFold
+	for _, rng := range ranges {
+		got = append(got, gopFold{rng.StartLine, rng.EndLine, rng.Kind})
+	}
+	return got
+}
+
+func checkGopFoldingRanges(t *testing.T, got, want []gopFold) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got folding ranges %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("folding range #%d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGopFoldingRange(t *testing.T) {
+	Run(t, gopFoldingSrc, func(t *testing.T, env *Env) {
+		env.OpenFile("main.gop")
+		checkGopFoldingRanges(t, gopFoldingRanges(t, env, "main.gop"), []gopFold{
+			{0, 3, "imports"}, // import block
+			{5, 6, "comment"}, // doc comment
+			{7, 7, ""},        // func params
+			{7, 9, ""},        // func body
+			{8, 8, ""},        // list comprehension
+			{11, 13, ""},      // list comprehension
+			{11, 13, ""},      // slice literal
+			{11, 14, ""},      // shadow entry
+			{14, 14, ""},      // call args
+		})
+	})
+}
+
+const gopFoldingClassfileSrc = `
+-- go.mod --
+module example.com
+go 1.18
+-- Rect.gox --
+var (
+	W, H int
+)
+
+func Area() int {
+	return W * H
+}
+
+echo Area()
+echo "done"
+-- lambda.gop --
+add := (x, y) => x +
+	y
+mul := (x, y) => {
+	return x * y
+}
+squares := {x: x * x for x <- [
+	1, 2, 3,
+], x%2 == 1}
+echo add(1, 2), mul(2, 3), squares
+`
+
+func TestGopFoldingRangeClassfile(t *testing.T) {
+	Run(t, gopFoldingClassfileSrc, func(t *testing.T, env *Env) {
+		env.OpenFile("Rect.gox")
+		checkGopFoldingRanges(t, gopFoldingRanges(t, env, "Rect.gox"), []gopFold{
+			{0, 2, ""}, // class fields
+			{4, 4, ""}, // method params
+			{4, 6, ""}, // method body
+			{8, 8, ""}, // call args
+			{8, 9, ""}, // class body
+		})
+	})
+}
+
+func TestGopFoldingRangeLambda(t *testing.T) {
+	Run(t, gopFoldingClassfileSrc, func(t *testing.T, env *Env) {
+		env.OpenFile("lambda.gop")
+		checkGopFoldingRanges(t, gopFoldingRanges(t, env, "lambda.gop"), []gopFold{
+			{0, 1, ""}, // lambda expression
+			{0, 8, ""}, // shadow entry
+			{2, 4, ""}, // lambda body
+			{5, 7, ""}, // map comprehension
+			{5, 7, ""}, // slice literal
+			{8, 8, ""}, // call args
+			{8, 8, ""}, // call args
+		})
+	})
+}
+
+// gopSelectionRanges returns the text of the first n selection ranges
+// at the first match of re in the open file name, innermost first.
+func gopSelectionRanges(t *testing.T, env *Env, name, re string, n int) []string {
+	t.Helper()
+	loc := env.RegexpSearch(name, re)
+	ranges, err := env.Editor.Server.SelectionRange(env.Ctx, &protocol.SelectionRangeParams{
+		TextDocument: env.Editor.TextDocumentIdentifier(name),
+		Positions:    []protocol.Position{loc.Range.Start},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 1 {
+		t.Fatalf("got %d selection ranges, want 1", len(ranges))
+	}
+	mapper, err := env.Editor.Mapper(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for sel := &ranges[0]; sel != nil && len(got) < n; sel = sel.Parent {
+		start, end, err := mapper.RangeOffsets(sel.Range)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(mapper.Content[start:end]))
+	}
+	return got
+}
+
+func checkGopSelectionRanges(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got selection ranges %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("selection range #%d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGopSelectionRange(t *testing.T) {
+	Run(t, gopFoldingSrc, func(t *testing.T, env *Env) {
+		env.OpenFile("main.gop")
+		got := gopSelectionRanges(t, env, "main.gop", `x \* 2`, 3)
+		checkGopSelectionRanges(t, got, []string{"x", "x * 2", "[x * 2 for x <- a]"})
+	})
+}
+
+func TestGopSelectionRangeClassfile(t *testing.T) {
+	Run(t, gopFoldingClassfileSrc, func(t *testing.T, env *Env) {
+		env.OpenFile("Rect.gox")
+		got := gopSelectionRanges(t, env, "Rect.gox", `W \* H`, 5)
+		checkGopSelectionRanges(t, got, []string{
+			"W",
+			"W * H",
+			"return W * H",
+			"{\n\treturn W * H\n}",
+			"func Area() int {\n\treturn W * H\n}",
+		})
+		// The class body has no braces.
+		got = gopSelectionRanges(t, env, "Rect.gox", `"done"`, 4)
+		checkGopSelectionRanges(t, got, []string{
+			`"done"`,
+			`echo "done"`, // call
+			`echo "done"`, // statement
+			"echo Area()\necho \"done\"",
+		})
+	})
+}
+
+func TestGopSelectionRangeLambda(t *testing.T) {
+	Run(t, gopFoldingClassfileSrc, func(t *testing.T, env *Env) {
+		env.OpenFile("lambda.gop")
+		got := gopSelectionRanges(t, env, "lambda.gop", `x \+`, 4)
+		checkGopSelectionRanges(t, got, []string{
+			"x",
+			"x +\n\ty",
+			"(x, y) => x +\n\ty",
+			"add := (x, y) => x +\n\ty",
+		})
+		got = gopSelectionRanges(t, env, "lambda.gop", `x \* y`, 5)
+		checkGopSelectionRanges(t, got, []string{
+			"x",
+			"x * y",
+			"return x * y",
+			"{\n\treturn x * y\n}",
+			"(x, y) => {\n\treturn x * y\n}",
+		})
+	})
+}
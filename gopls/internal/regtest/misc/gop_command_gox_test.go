@@ -0,0 +1,119 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/lsp/command"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	. "golang.org/x/tools/gopls/internal/lsp/regtest"
+)
+
+// fakeGop installs a fake gop executable running the given shell script,
+// and returns the PATH to find it.
+func fakeGop(t *testing.T, script string) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake gop executable is a shell script")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "gop"), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir + string(os.PathListSeparator) + os.Getenv("PATH")
+}
+
+func TestRunGopCommand(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.test
+
+go 1.18
+-- gop_autogen.go --
+package main
+-- main.gop --
+println "hello"
+
+foo
+`
+	path := fakeGop(t, `
+echo "gop $@"
+echo "./main.gop:3:1: undefined: foo" >&2
+exit 1
+`)
+	WithOptions(
+		EnvVars{"PATH": path},
+	).Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("main.gop")
+		env.ExecuteCodeLensCommand("main.gop", command.RunGopCommand, nil)
+		env.Await(
+			CompletedWork("Running gop run", 1, true),
+			Diagnostics(env.AtRegexp("main.gop", "foo"), WithMessage("undefined: foo")),
+		)
+	})
+}
+
+// TestRunGopCommandPackage checks that gop runs in the view's directory,
+// with the directory of a main package in a subdirectory as argument.
+func TestRunGopCommandPackage(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.test
+
+go 1.18
+-- hello/gop_autogen.go --
+package main
+-- hello/main.gop --
+println "hello"
+
+foo
+`
+	path := fakeGop(t, `
+echo "gop $@"
+if [ "$1 $2" = "run ./hello" ] && [ -f go.mod ]; then
+	echo "./hello/main.gop:3:1: undefined: foo" >&2
+fi
+exit 1
+`)
+	WithOptions(
+		EnvVars{"PATH": path},
+	).Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("hello/main.gop")
+		env.ExecuteCodeLensCommand("hello/main.gop", command.RunGopCommand, nil)
+		env.Await(
+			CompletedWork("Running gop run", 1, true),
+			Diagnostics(env.AtRegexp("hello/main.gop", "foo"), WithMessage("undefined: foo")),
+		)
+	})
+}
+
+func TestRunGopCommandUnsupported(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.test
+
+go 1.18
+-- main.gop --
+println "hello"
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		cmd, err := command.NewRunGopCommandCommand("", command.RunGopCommandArgs{
+			URI:     env.Sandbox.Workdir.URI("main.gop"),
+			Command: "install",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := env.Editor.ExecuteCommand(env.Ctx, &protocol.ExecuteCommandParams{
+			Command:   cmd.Command,
+			Arguments: cmd.Arguments,
+		}); err == nil {
+			t.Error("RunGopCommand(install) succeeded unexpectedly")
+		}
+	})
+}
@@ -0,0 +1,183 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/lsp/command"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	. "golang.org/x/tools/gopls/internal/lsp/regtest"
+)
+
+const gopTestFiles = `
+-- go.mod --
+module mod.test
+
+go 1.18
+-- gop.mod --
+gop 1.2
+
+project _ytest.gox App mod.test/ytest
+class _ytest.gox Case
+-- ytest/ytest.go --
+package ytest
+
+import "testing"
+
+const GopPackage = true
+
+type App struct{ m *testing.M }
+
+type Case struct{ t *testing.T }
+
+func (p *Case) initCase(t *testing.T) { p.t = t }
+
+func (p Case) T() *testing.T { return p.t }
+
+func Gopt_Case_TestMain(c interface{ initCase(t *testing.T) }, t *testing.T) {
+	c.initCase(t)
+	c.(interface{ Main() }).Main()
+}
+-- gop_autogen.go --
+package main
+-- gop_autogen_test.go --
+package main
+
+import (
+	_ "testing"
+
+	_ "mod.test/ytest"
+)
+-- foo.gop --
+func foo() int {
+	return 1
+}
+-- foo_test.gop --
+import "testing"
+
+func TestFoo(t *testing.T) {
+	if foo() != 2 {
+		t.Fatal("foo() != 2")
+	}
+}
+
+func BenchmarkFoo(b *testing.B) {
+}
+
+func helper(t *testing.T) {
+}
+-- bar_ytest.gox --
+println "bar"
+`
+
+func TestListGopTests(t *testing.T) {
+	WithOptions(
+		Settings{"codelenses": map[string]bool{string(command.Test): true}},
+	).Run(t, gopTestFiles, func(t *testing.T, env *Env) {
+		env.OpenFile("foo.gop")
+		cmd, err := command.NewListGopTestsCommand("", command.URIArg{URI: env.Sandbox.Workdir.URI("foo.gop")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result command.ListGopTestsResult
+		env.ExecuteCommand(&protocol.ExecuteCommandParams{
+			Command:   cmd.Command,
+			Arguments: cmd.Arguments,
+		}, &result)
+		var got []string
+		for _, fn := range result.Tests {
+			got = append(got, fn.Name)
+		}
+		sort.Strings(got)
+		if want := "TestFoo Test_bar"; strings.Join(got, " ") != want {
+			t.Errorf("ListGopTests: got tests %v, want %s", got, want)
+		}
+		if len(result.Benchmarks) != 1 || result.Benchmarks[0].Name != "BenchmarkFoo" {
+			t.Errorf("ListGopTests: got benchmarks %v, want [BenchmarkFoo]", result.Benchmarks)
+		}
+
+		env.OpenFile("foo_test.gop")
+		var titles []string
+		for _, lens := range env.CodeLens("foo_test.gop") {
+			titles = append(titles, lens.Command.Title)
+		}
+		sort.Strings(titles)
+		if want := "debug test,run benchmark,run file benchmarks,run test"; strings.Join(titles, ",") != want {
+			t.Errorf("CodeLens(foo_test.gop): got %v, want %s", titles, want)
+		}
+	})
+}
+
+func TestRunGopTests(t *testing.T) {
+	path := fakeGop(t, `
+echo "gop $@"
+echo "=== RUN   TestFoo"
+echo "    foo_test.gop:5: foo() != 2"
+echo "--- FAIL: TestFoo (0.00s)"
+exit 1
+`)
+	WithOptions(
+		EnvVars{"PATH": path},
+		Settings{"codelenses": map[string]bool{string(command.Test): true}},
+	).Run(t, gopTestFiles, func(t *testing.T, env *Env) {
+		env.OpenFile("foo_test.gop")
+		env.ExecuteCodeLensCommand("foo_test.gop", command.Test, nil)
+		env.Await(
+			CompletedWork("Running go test", 1, true),
+			Diagnostics(env.AtRegexp("foo_test.gop", `t.Fatal`), WithMessage("foo() != 2")),
+		)
+	})
+}
+
+func TestDebugGopTest(t *testing.T) {
+	// The test binaries are built in the user's cache directory. Keep the
+	// build cache of the go command where it is, for speed.
+	gocache, err := exec.Command("go", "env", "GOCACHE").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOCACHE", strings.TrimSpace(string(gocache)))
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	path := fakeGop(t, `
+echo "gop $@"
+# gop test -c -o program ...
+echo binary > "$4"
+`)
+	WithOptions(
+		EnvVars{"PATH": path},
+	).Run(t, gopTestFiles, func(t *testing.T, env *Env) {
+		env.OpenFile("foo_test.gop")
+		debug := func() command.DebugGopTestResult {
+			cmd, err := command.NewDebugGopTestCommand("", command.RunTestsArgs{
+				URI:   env.Sandbox.Workdir.URI("foo_test.gop"),
+				Tests: []string{"TestFoo"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var result command.DebugGopTestResult
+			env.ExecuteCommand(&protocol.ExecuteCommandParams{
+				Command:   cmd.Command,
+				Arguments: cmd.Arguments,
+			}, &result)
+			return result
+		}
+		first, second := debug(), debug()
+		if first.Program != second.Program {
+			t.Errorf("DebugGopTest: built %s, then %s; want the same binary", first.Program, second.Program)
+		}
+		if _, err := os.Stat(second.Program); err != nil {
+			t.Errorf("DebugGopTest: %v", err)
+		}
+		if want := "-test.run ^(TestFoo)$"; !strings.Contains(strings.Join(second.Args, " "), want) {
+			t.Errorf("DebugGopTest: got args %v, want %s", second.Args, want)
+		}
+	})
+}
@@ -0,0 +1,160 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	. "golang.org/x/tools/gopls/internal/lsp/regtest"
+	"golang.org/x/tools/gopls/internal/lsp/tests/compare"
+	"golang.org/x/tools/internal/testenv"
+)
+
+func TestGopInlineCall(t *testing.T) {
+	testenv.NeedsGOPROOT(t)
+	const files = `
+-- go.mod --
+module mod.test
+
+go 1.18
+-- gop_autogen.go --
+package main
+-- main.gop --
+type Counter struct {
+	n int
+}
+
+func (c *Counter) Value() int {
+	return c.n
+}
+
+func add(a, b int) int {
+	return a + b
+}
+
+func show(v int) {
+	println v
+}
+
+func mulInt(a, b int) int {
+	return a * b
+}
+
+func mulFloat(a, b float64) float64 {
+	return a * b
+}
+
+func mul = (
+	mulInt
+	mulFloat
+)
+
+c := &Counter{n: 1}
+x := add(1, 2)
+show x
+y := c.value
+z := mul(2, 3)
+println x, y, z
+`
+	tests := []struct {
+		re, title, before, after string
+	}{
+		{`add\(1`, "Inline call to add", "x := add(1, 2)", "x := 1 + 2"},
+		{`show x`, "Inline call to show", "show x", "println x"},
+		{`c\.value`, "Inline call to Value", "y := c.value", "y := c.n"},
+		{`mul\(2`, "Inline call to mulInt", "z := mul(2, 3)", "z := 2 * 3"},
+	}
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			Run(t, files, func(t *testing.T, env *Env) {
+				env.OpenFile("main.gop")
+				before := env.BufferText("main.gop")
+				loc := env.RegexpSearch("main.gop", test.re)
+				actions, err := env.Editor.CodeAction(env.Ctx, loc, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var inline *protocol.CodeAction
+				for _, action := range actions {
+					if action.Kind == protocol.RefactorInline && action.Title == test.title {
+						inline = &action
+						break
+					}
+				}
+				if inline == nil {
+					t.Fatalf("could not find %q action", test.title)
+				}
+				env.ApplyCodeAction(*inline)
+				want := strings.Replace(before, test.before, test.after, 1)
+				if got := env.BufferText("main.gop"); got != want {
+					t.Errorf("inline call failed:\n%s", compare.Text(want, got))
+				}
+			})
+		})
+	}
+}
+
+func TestGopInlineClassMethod(t *testing.T) {
+	testenv.NeedsGOPROOT(t)
+	const files = `
+-- go.mod --
+module mod.test
+
+go 1.18
+-- gop_autogen.go --
+package main
+-- Counter.gox --
+var (
+	n int
+)
+
+func Incr() {
+	n++
+}
+
+func Twice() {
+	Incr()
+	Incr()
+}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("Counter.gox")
+		loc := env.RegexpSearch("Counter.gox", `(Incr)\(\)\n\tIncr`)
+		actions, err := env.Editor.CodeAction(env.Ctx, loc, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var inline *protocol.CodeAction
+		for _, action := range actions {
+			if action.Kind == protocol.RefactorInline {
+				inline = &action
+				break
+			}
+		}
+		if inline == nil {
+			t.Fatal("could not find inline action")
+		}
+		env.ApplyCodeAction(*inline)
+		want := `
+var (
+	n int
+)
+
+func Incr() {
+	n++
+}
+
+func Twice() {
+	this.n++
+	Incr()
+}
+`[1:]
+		if got := env.BufferText("Counter.gox"); got != want {
+			t.Errorf("inline call failed:\n%s", compare.Text(want, got))
+		}
+	})
+}
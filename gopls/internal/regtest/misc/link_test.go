@@ -94,3 +94,59 @@ const Hello = "Hello"
 		}
 	})
 }
+
+func TestGopDocumentLink(t *testing.T) {
+	const program = `
+-- go.mod --
+module mod.test
+
+go 1.12
+-- lib/gop_autogen.go --
+package lib
+-- lib/lib.gop --
+package lib
+
+const Hello = "Hello"
+-- gop_autogen.go --
+package main
+
+import _ "mod.test/lib"
+-- main.gop --
+import "mod.test/lib"
+
+// See [lib.Hello], [Greet] and https://goplus.org/docs.
+func Greet() {
+	println lib.Hello
+}
+
+Greet
+`
+	for _, test := range []struct {
+		name, target, prefix string
+	}{
+		{"no scheme", "localhost:8080", "https://localhost:8080/"},
+		{"scheme", "http://localhost:8080", "http://localhost:8080/"},
+		{"scheme and path", "http://localhost:8080/pkg/", "http://localhost:8080/pkg/"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			WithOptions(
+				Settings{"gopLinkTarget": test.target},
+			).Run(t, program, func(t *testing.T, env *Env) {
+				env.OpenFile("main.gop")
+				var got []string
+				for _, link := range env.DocumentLink("main.gop") {
+					got = append(got, *link.Target)
+				}
+				want := []string{
+					test.prefix + "mod.test/lib",
+					"https://goplus.org/docs",
+					test.prefix + "mod.test/lib#Hello",
+					test.prefix + "mod.test#Greet",
+				}
+				if strings.Join(got, "\n") != strings.Join(want, "\n") {
+					t.Errorf("documentLink: got targets %q for main.gop, want %q", got, want)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	. "golang.org/x/tools/gopls/internal/lsp/regtest"
+	"golang.org/x/tools/internal/testenv"
+)
+
+// TestGopSemantic checks the semantic tokens of the implicit receivers of
+// a classfile and of range expressions.
+func TestGopSemantic(t *testing.T) {
+	testenv.NeedsGOPROOT(t)
+	const src = `
+-- go.mod --
+module example.com
+
+go 1.18
+-- gop_autogen.go --
+package main
+-- Rect.gox --
+var (
+	W, H int
+)
+
+func Area() int {
+	return W * H
+}
+
+func Scale(k int) {
+	W, H = W*k, H*k
+	echo Area()
+}
+-- main.gop --
+for i <- 1:10:2 {
+	echo i
+}
+`
+	tests := []struct {
+		file string
+		want []result
+	}{
+		{"Rect.gox", []result{
+			{"var", "keyword", ""},
+			{"W", "variable", "definition"},
+			{"H", "variable", "definition"},
+			{"int", "type", "defaultLibrary"},
+			{"func", "keyword", ""},
+			{"Area", "method", "definition"},
+			{"int", "type", "defaultLibrary"},
+			{"return", "keyword", ""},
+			{"W", "variable", ""},
+			{"*", "operator", ""},
+			{"H", "variable", ""},
+			{"func", "keyword", ""},
+			{"Scale", "method", "definition"},
+			{"k", "parameter", "definition"},
+			{"int", "type", "defaultLibrary"},
+			{"W", "variable", ""},
+			{"H", "variable", ""},
+			{"=", "operator", ""},
+			{"W", "variable", ""},
+			{"*", "operator", ""},
+			{"k", "parameter", ""},
+			{"H", "variable", ""},
+			{"*", "operator", ""},
+			{"k", "parameter", ""},
+			{"echo", "function", ""},
+			{"Area", "method", ""}, // called through the implicit receiver
+		}},
+		{"main.gop", []result{
+			{"for", "keyword", ""},
+			{"i", "variable", "definition"},
+			{"<-", "operator", ""},
+			{":", "operator", ""}, // 1:10:2, numbers are elided
+			{":", "operator", ""},
+			{"echo", "function", ""},
+			{"i", "variable", ""},
+		}},
+	}
+	WithOptions(
+		Modes(Default),
+		Settings{"semanticTokens": true},
+	).Run(t, src, func(t *testing.T, env *Env) {
+		for _, test := range tests {
+			env.OpenFile(test.file)
+			p := &protocol.SemanticTokensParams{
+				TextDocument: protocol.TextDocumentIdentifier{
+					URI: env.Sandbox.Workdir.URI(test.file),
+				},
+			}
+			v, err := env.Editor.Server.SemanticTokensFull(env.Ctx, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			seen := interpret(v.Data, env.BufferText(test.file))
+			if x := cmp.Diff(test.want, seen); x != "" {
+				t.Errorf("%s: semantic tokens do not match (-want +got):\n%s", test.file, x)
+			}
+		}
+	})
+}
@@ -102,6 +102,94 @@ const (
 	})
 }
 
+func TestGopWorkspaceSymbols(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.17
+-- a/gop_autogen.go --
+package main
+-- a/a.gop --
+type Rect struct {
+	Width, Height int
+}
+
+func (r *Rect) Area() int {
+	return r.Width * r.Height
+}
+
+func Mul = (
+	func(a, b int) int {
+		return a * b
+	}
+	func(a, b float64) float64 {
+		return a * b
+	}
+)
+-- a/Counter.gox --
+var (
+	count int
+)
+
+func Incr() {
+	count++
+}
+`
+
+	var symbolMatcher = string(source.SymbolFastFuzzy)
+	WithOptions(
+		Settings{"symbolMatcher": symbolMatcher},
+	).Run(t, files, func(t *testing.T, env *Env) {
+		checkSymbols(env, "Rect", "Rect", "Rect.Area", "Rect.Width", "Rect.Height")
+		checkSymbols(env, "Mul", "Mul")
+		checkSymbols(env, "Counter", "Counter", "Counter.Incr", "Counter.count")
+	})
+}
+
+func TestGopWorkspaceSymbolsClassfile(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.17
+-- a/gop_autogen.go --
+package main
+-- a/main.gop --
+echo "main"
+-- a/Shape.gox --
+var (
+	Name string
+)
+
+func Draw() {
+	echo "draw", Name
+}
+
+func Scale = (
+	func(k int) {
+	}
+	func(k float64) {
+	}
+)
+
+Draw
+echo "main"
+`
+
+	var symbolMatcher = string(source.SymbolFastFuzzy)
+	WithOptions(
+		Settings{"symbolMatcher": symbolMatcher},
+	).Run(t, files, func(t *testing.T, env *Env) {
+		// Class methods are indexed as methods of the class, but not the
+		// shadow entry (the Main method) of the classfile.
+		checkSymbols(env, "Shape", "Shape", "Shape.Draw", "Shape.Name", "Shape.Scale")
+		checkSymbols(env, "Draw", "Shape.Draw")
+		checkSymbols(env, "MainEntry")
+		checkSymbols(env, "Shape.Main")
+	})
+}
+
 func checkSymbols(env *Env, query string, want ...string) {
 	env.T.Helper()
 	var got []string
@@ -0,0 +1,119 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modfile
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	. "golang.org/x/tools/gopls/internal/lsp/regtest"
+)
+
+const gopModFiles = `
+-- go.mod --
+module example.com
+
+go 1.18
+-- gop.mod --
+gop 1.2
+
+project .tdsl Game example.com/tdsl
+class .tspr Sprite
+import "fmt"
+
+project .bad App example.com/missing github.com/unknown/bad
+class .tdsl Dup
+-- tdsl/tdsl.go --
+package tdsl
+
+type Game struct{}
+`
+
+func TestGopModDiagnostics(t *testing.T) {
+	Run(t, gopModFiles, func(t *testing.T, env *Env) {
+		env.OpenFile("gop.mod")
+		env.AfterChange(
+			Diagnostics(env.AtRegexp("gop.mod", "example.com/missing"), WithMessage("cannot find package example.com/missing")),
+			Diagnostics(env.AtRegexp("gop.mod", "github.com/unknown/bad"), WithMessage("no required module provides package github.com/unknown/bad")),
+			Diagnostics(env.AtRegexp("gop.mod", `\.tdsl Dup`), WithMessage("classfile extension .tdsl is already registered")),
+		)
+		env.RegexpReplace("gop.mod", "gop 1.2", "gop 1.2\nregister example.com/tdsl")
+		env.AfterChange(
+			Diagnostics(env.AtRegexp("gop.mod", "register"), WithMessage("unknown directive: register")),
+		)
+	})
+}
+
+func TestGopModHover(t *testing.T) {
+	Run(t, gopModFiles, func(t *testing.T, env *Env) {
+		env.OpenFile("gop.mod")
+		content, _ := env.Hover(env.RegexpSearch("gop.mod", "Sprite"))
+		if content == nil {
+			t.Fatal("no hover over class statement")
+		}
+		for _, want := range []string{"classfile example.com/tdsl", "Project class `Game` for `*.tdsl` files", "`Sprite` for `*.tspr` files", "`fmt`"} {
+			if !strings.Contains(content.Value, want) {
+				t.Errorf("hover over class statement: got %q, want %q", content.Value, want)
+			}
+		}
+	})
+}
+
+func TestGopModCompletion(t *testing.T) {
+	const files = `
+-- go.mod --
+module example.com
+
+go 1.18
+-- gop.mod --
+gop 1.2
+
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("gop.mod")
+		env.EditBuffer("gop.mod", protocol.TextEdit{
+			Range:   protocol.Range{Start: protocol.Position{Line: 2}, End: protocol.Position{Line: 2}},
+			NewText: "p",
+		})
+		labels := func(list *protocol.CompletionList) []string {
+			var labels []string
+			for _, item := range list.Items {
+				labels = append(labels, item.Label)
+			}
+			return labels
+		}
+		got := labels(env.Completion(env.RegexpSearch("gop.mod", `()p$`)))
+		if len(got) != 1 || got[0] != "project" {
+			t.Errorf("completion of p: got %v, want [project]", got)
+		}
+
+		env.SetBufferContent("gop.mod", "gop 1.2\n\nproject .tdsl Game example.com/tdsl\n\n")
+		got = labels(env.Completion(env.RegexpSearch("gop.mod", `tdsl\n\n()`)))
+		if want := []string{"project", "class", "import"}; strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("completion in project: got %v, want %v", got, want)
+		}
+	})
+}
+
+func TestGopModFormat(t *testing.T) {
+	const files = `
+-- go.mod --
+module example.com
+
+go 1.18
+-- gop.mod --
+gop   1.2
+project    .tdsl Game   example.com/tdsl
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("gop.mod")
+		env.FormatBuffer("gop.mod")
+		const want = "gop 1.2\n\nproject .tdsl Game example.com/tdsl\n"
+		if got := env.BufferText("gop.mod"); got != want {
+			t.Errorf("formatted gop.mod: got %q, want %q", got, want)
+		}
+	})
+}
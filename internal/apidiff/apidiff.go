@@ -49,6 +49,11 @@ type differ struct {
 	// Messages.
 	incompatibles messageSet
 	compatibles   messageSet
+
+	// goxls: whether old or new is a Go+ package, and the objects that
+	// stand for overload sets in messages, by receiver type and name.
+	gop             bool
+	gopOverloadSets map[string]types.Object
 }
 
 func newDiffer(old, new *types.Package) *differ {
@@ -58,6 +63,9 @@ func newDiffer(old, new *types.Package) *differ {
 		correspondMap: map[*types.TypeName]types.Type{},
 		incompatibles: messageSet{},
 		compatibles:   messageSet{},
+
+		gop:             isGopPackage(old) || isGopPackage(new),
+		gopOverloadSets: map[string]types.Object{},
 	}
 }
 
@@ -74,10 +82,15 @@ func addMessage(ms messageSet, obj types.Object, part, format string, args []int
 }
 
 func (d *differ) checkPackage() {
+	// goxls: Go+ overload sets are compared as logical APIs.
+	var gopHandled map[string]bool
+	if d.gop {
+		gopHandled = d.checkGopPackage()
+	}
 	// Old changes.
 	for _, name := range d.old.Scope().Names() {
 		oldobj := d.old.Scope().Lookup(name)
-		if !oldobj.Exported() {
+		if !oldobj.Exported() || gopHandled[name] {
 			continue
 		}
 		newobj := d.new.Scope().Lookup(name)
@@ -90,7 +103,7 @@ func (d *differ) checkPackage() {
 	// New additions.
 	for _, name := range d.new.Scope().Names() {
 		newobj := d.new.Scope().Lookup(name)
-		if newobj.Exported() && d.old.Scope().Lookup(name) == nil {
+		if newobj.Exported() && d.old.Scope().Lookup(name) == nil && !gopHandled[name] {
 			d.compatible(newobj, "", "added")
 		}
 	}
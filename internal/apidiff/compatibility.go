@@ -286,6 +286,10 @@ func (d *differ) checkMethodSet(otn *types.TypeName, oldt, newt types.Type, addc
 	// TODO: find a way to use checkCompatibleObjectSets for this.
 	oldMethodSet := exportedMethods(oldt)
 	newMethodSet := exportedMethods(newt)
+	if d.gop {
+		// goxls: Go+ overload methods are compared as logical APIs.
+		d.checkGopMethodOverloads(otn, oldMethodSet, newMethodSet, addcompat)
+	}
 	msname := otn.Name()
 	if _, ok := oldt.(*types.Pointer); ok {
 		msname = "*" + msname
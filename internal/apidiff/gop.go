@@ -0,0 +1,303 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apidiff
+
+// This file defines the comparison of the overloads of Go+ packages.
+//
+// A Go+ package (one declaring the GopPackage constant) may declare
+// overloads of a function or method Foo, which Go+ code calls as Foo (or
+// foo), in two ways:
+//
+//	func Foo__0(x int) {}      // by name: Foo__0, Foo__1, ... Foo__z
+//	func Foo__1(x string) {}
+//
+//	const Gopo_Foo = "fooInt,,fooString" // by an overload declaration
+//
+// An overload declaration lists the members of the overload set in order;
+// an empty item denotes the function Foo__<index>. For a method M of type
+// T it is named Gopo_T_M (or Gopo__T__M) and lists method names in the
+// form .m or (T).m. Operator methods (Gop_Add, ...) are overloaded alike.
+//
+// Such an overload set is compared as a single logical API: what matters
+// to Go+ callers is which signatures the overloads of Foo accept, not
+// their names. So each overload is matched by signature. An old overload
+// left unmatched is then paired with an unmatched new one of the same
+// number of parameters, if any, and reported as changed; the remaining
+// ones are reported as removed or added. A function that turns into an
+// overload set (or back) is compared the same way.
+
+import (
+	"go/constant"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+const (
+	gopPackage = "GopPackage" // marks a Go+ package
+	gopoPrefix = "Gopo_"      // overload declaration
+)
+
+// isGopPackage reports whether pkg is a Go+ package.
+func isGopPackage(pkg *types.Package) bool {
+	_, ok := pkg.Scope().Lookup(gopPackage).(*types.Const)
+	return ok
+}
+
+// isOverload reports whether name is the name of an overload Foo__0,
+// Foo__1, ... of a function or method.
+func isOverload(name string) bool {
+	n := len(name)
+	if n <= 3 || name[n-3:n-1] != "__" {
+		return false
+	}
+	c := name[n-1]
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'z'
+}
+
+// gopOverloadSets groups the functions (or the methods of type recv, if
+// not nil) of pkg into the overload sets of exported logical names. objs
+// maps the names of the candidate members to them.
+func gopOverloadSets(pkg *types.Package, recv *types.TypeName, objs map[string]types.Object) map[string][]types.Object {
+	sets := make(map[string][]types.Object)
+	for name, obj := range objs {
+		if _, ok := obj.(*types.Func); ok && isOverload(name) {
+			key := name[:len(name)-3]
+			sets[key] = append(sets[key], obj)
+		}
+	}
+
+	// Overload declarations take precedence.
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if !strings.HasPrefix(name, gopoPrefix) {
+			continue
+		}
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || c.Val().Kind() != constant.String {
+			continue
+		}
+		tname, key := gopoTarget(scope, name[len(gopoPrefix):])
+		if recv == nil && tname != "" || recv != nil && tname != recv.Name() {
+			continue
+		}
+		var members []types.Object
+		for i, item := range strings.Split(constant.StringVal(c.Val()), ",") {
+			switch {
+			case item == "":
+				item = key + "__" + string("0123456789abcdefghijklmnopqrstuvwxyz"[i])
+			case item[0] == '.':
+				item = item[1:]
+			case item[0] == '(':
+				if pos := strings.Index(item, ")."); pos > 0 {
+					item = item[pos+2:]
+				}
+			}
+			if obj, ok := objs[item].(*types.Func); ok {
+				members = append(members, obj)
+			}
+		}
+		sets[key] = members
+	}
+
+	for key, members := range sets {
+		if !isExported(key) || len(members) == 0 {
+			delete(sets, key)
+			continue
+		}
+		// A function of the logical name belongs to its overload set.
+		if obj, ok := objs[key].(*types.Func); ok {
+			members = append(members, obj)
+		}
+		sort.Slice(members, func(i, j int) bool { return members[i].Name() < members[j].Name() })
+		sets[key] = members
+	}
+	return sets
+}
+
+// gopoTarget returns the type name (or "") and the name of the function
+// or method whose overloads are declared by the constant Gopo_<name> in
+// scope.
+func gopoTarget(scope *types.Scope, name string) (tname, key string) {
+	if strings.HasPrefix(name, "_") { // Gopo__T__M
+		if pos := strings.Index(name[1:], "__"); pos > 0 {
+			return name[1 : pos+1], name[pos+3:]
+		}
+		return "", name[1:]
+	}
+	if pos := strings.IndexByte(name, '_'); pos > 0 { // Gopo_T_M, if T is a type
+		if _, ok := scope.Lookup(name[:pos]).(*types.TypeName); ok {
+			return name[:pos], name[pos+1:]
+		}
+	}
+	return "", name
+}
+
+func isExported(name string) bool {
+	return name != "" && 'A' <= name[0] && name[0] <= 'Z'
+}
+
+// checkGopPackage compares the overload sets of the package-level
+// functions of the old and new packages, and returns the names of the
+// objects it has compared, which are not to be compared otherwise.
+func (d *differ) checkGopPackage() map[string]bool {
+	handled := make(map[string]bool)
+	for _, pkg := range []*types.Package{d.old, d.new} {
+		for _, name := range pkg.Scope().Names() {
+			if strings.HasPrefix(name, gopoPrefix) {
+				handled[name] = true // overload declarations are not APIs
+			}
+		}
+	}
+	objs := func(pkg *types.Package) map[string]types.Object {
+		m := make(map[string]types.Object)
+		for _, name := range pkg.Scope().Names() {
+			m[name] = pkg.Scope().Lookup(name)
+		}
+		return m
+	}
+	d.checkGopOverloads(nil, objs(d.old), objs(d.new), additionsCompatible, handled)
+	return handled
+}
+
+// checkGopMethodOverloads compares the overload sets of the methods in
+// the old and new method sets of the type otn, then deletes the methods
+// it has compared from them.
+func (d *differ) checkGopMethodOverloads(otn *types.TypeName, oldMethodSet, newMethodSet map[string]types.Object, addcompat bool) {
+	handled := make(map[string]bool)
+	d.checkGopOverloads(otn, oldMethodSet, newMethodSet, addcompat, handled)
+	for name := range handled {
+		delete(oldMethodSet, name)
+		delete(newMethodSet, name)
+	}
+}
+
+// checkGopOverloads compares the overload sets of the old and new objects
+// (the functions, or the methods of type recv if not nil), and adds the
+// names of the objects it has compared to handled.
+func (d *differ) checkGopOverloads(recv *types.TypeName, olds, news map[string]types.Object, addcompat bool, handled map[string]bool) {
+	var newRecv *types.TypeName
+	if recv != nil {
+		newRecv, _ = d.new.Scope().Lookup(recv.Name()).(*types.TypeName)
+	}
+	oldSets := gopOverloadSets(d.old, recv, olds)
+	newSets := gopOverloadSets(d.new, newRecv, news)
+	for key := range oldSets {
+		if _, ok := newSets[key]; !ok {
+			// The overloads of key may have become a single function or method.
+			if obj, ok := news[key].(*types.Func); ok {
+				newSets[key] = []types.Object{obj}
+			}
+		}
+	}
+	for key := range newSets {
+		if _, ok := oldSets[key]; !ok {
+			if obj, ok := olds[key].(*types.Func); ok {
+				oldSets[key] = []types.Object{obj}
+			}
+		}
+	}
+	for key, oldMembers := range oldSets {
+		for _, m := range oldMembers {
+			handled[m.Name()] = true
+		}
+		handled[key] = true
+		newMembers := newSets[key]
+		if len(newMembers) == 0 {
+			d.incompatible(d.overloadSet(oldMembers[0], key), "", "removed")
+			continue
+		}
+		d.checkOverloadSet(key, oldMembers, newMembers, addcompat)
+	}
+	for key, newMembers := range newSets {
+		for _, m := range newMembers {
+			handled[m.Name()] = true
+		}
+		handled[key] = true
+		if _, ok := oldSets[key]; !ok {
+			if addcompat {
+				d.compatible(d.overloadSet(newMembers[0], key), "", "added")
+			} else {
+				d.incompatible(d.overloadSet(newMembers[0], key), "", "added")
+			}
+		}
+	}
+}
+
+// checkOverloadSet compares the old and new members of the overload set
+// of the logical name key by their signatures, then by their numbers of
+// parameters.
+func (d *differ) checkOverloadSet(key string, oldMembers, newMembers []types.Object, addcompat bool) {
+	oldSet := d.overloadSet(oldMembers[0], key)
+	matched := make(map[types.Object]bool)
+	var unmatched []types.Object
+	for _, old := range oldMembers {
+		olds := d.overloadString(old, d.old)
+		var found types.Object
+		for _, new := range newMembers {
+			if !matched[new] && d.overloadString(new, d.new) == olds {
+				found = new
+				break
+			}
+		}
+		if found == nil {
+			unmatched = append(unmatched, old)
+			continue
+		}
+		matched[found] = true
+		d.checkCorrespondence(oldSet, ", overload "+olds, old.Type(), found.Type())
+	}
+	for _, old := range unmatched {
+		olds := d.overloadString(old, d.old)
+		n := old.Type().(*types.Signature).Params().Len()
+		var found types.Object
+		for _, new := range newMembers {
+			if !matched[new] && new.Type().(*types.Signature).Params().Len() == n {
+				found = new
+				break
+			}
+		}
+		if found == nil {
+			d.incompatible(oldSet, ", overload "+olds, "removed")
+			continue
+		}
+		matched[found] = true
+		d.typeChanged(oldSet, ", overload "+olds, old.Type(), found.Type())
+	}
+	for _, new := range newMembers {
+		if matched[new] {
+			continue
+		}
+		part := ", overload " + d.overloadString(new, d.new)
+		if addcompat {
+			d.compatible(oldSet, part, "added")
+		} else {
+			d.incompatible(oldSet, part, "added")
+		}
+	}
+}
+
+// overloadString returns the signature of the overload obj of package
+// pkg, without parameter names.
+func (d *differ) overloadString(obj types.Object, pkg *types.Package) string {
+	return types.TypeString(removeNamesFromSignature(obj.Type()), types.RelativeTo(pkg))
+}
+
+// overloadSet returns the object that stands for the overload set of
+// the logical name key, of which obj is a member, in messages.
+// The object is unique for each set, so messages about it are deduplicated.
+func (d *differ) overloadSet(obj types.Object, key string) types.Object {
+	sig := obj.Type().(*types.Signature)
+	id := key
+	if sig.Recv() != nil {
+		id = receiverNamedType(obj).Obj().Name() + "." + key
+	}
+	if set, ok := d.gopOverloadSets[id]; ok {
+		return set
+	}
+	set := types.NewFunc(obj.Pos(), obj.Pkg(), key, sig)
+	d.gopOverloadSets[id] = set
+	return set
+}
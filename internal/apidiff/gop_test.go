@@ -0,0 +1,133 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package apidiff
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+)
+
+func TestGopChanges(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		old, new string
+		wanti    []string // incompatible changes
+		wantc    []string // compatible changes
+	}{
+		{
+			name: "overload added and removed",
+			old: `const GopPackage = true
+func Add__0(a, b int) int { return a + b }
+func Add__1(a, b string) string { return a + b }`,
+			new: `const GopPackage = true
+func Add__0(a, b string) string { return a + b }
+func Add__1(a, b, c float64) float64 { return a + b + c }`,
+			wanti: []string{"Add, overload func(int, int) int: removed"},
+			wantc: []string{"Add, overload func(float64, float64, float64) float64: added"},
+		},
+		{
+			name: "overload changed",
+			old: `const GopPackage = true
+func Add__0(a, b int) int { return a + b }
+func Add__1(a, b string) string { return a + b }`,
+			new: `const GopPackage = true
+func Add__0(a, b string) string { return a + b }
+func Add__1(a, b float64) float64 { return a + b }
+func Add__2(a float64) float64 { return a }`,
+			wanti: []string{"Add, overload func(int, int) int: changed from func(int, int) int to func(float64, float64) float64"},
+			wantc: []string{"Add, overload func(float64) float64: added"},
+		},
+		{
+			name: "function becomes overloaded",
+			old: `const GopPackage = true
+func Max(a, b int) int { return a }`,
+			new: `const GopPackage = true
+func Max__0(a, b int) int { return a }
+func Max__1(a, b float64) float64 { return a }`,
+			wantc: []string{"Max, overload func(float64, float64) float64: added"},
+		},
+		{
+			name: "overload set removed and added",
+			old: `const GopPackage = true
+func Old__0(int) {}
+func Old__1(string) {}`,
+			new: `const GopPackage = true
+func New__0(int) {}
+func New__1(string) {}`,
+			wanti: []string{"Old: removed"},
+			wantc: []string{"New: added"},
+		},
+		{
+			name: "overload declaration",
+			old: `const GopPackage = true
+const Gopo_Mul = "mulInt,mulFloat"
+func mulInt(a, b int) int { return a * b }
+func mulFloat(a, b float64) float64 { return a * b }`,
+			new: `const GopPackage = true
+const Gopo_Mul = "mulInt,,mulString"
+func mulInt(a, b int) int { return a * b }
+func Mul__1(a, b float64) float64 { return a * b }
+func mulString(a string, n int) string { return a }`,
+			wantc: []string{"Mul, overload func(string, int) string: added"},
+		},
+		{
+			name: "overload declaration added",
+			old: `const GopPackage = true
+func mulInt(a, b int) int { return a * b }`,
+			new: `const GopPackage = true
+const Gopo_Mul = "mulInt"
+func mulInt(a, b int) int { return a * b }`,
+			wantc: []string{"Mul: added"},
+		},
+		{
+			name: "overload methods",
+			old: `const GopPackage = true
+type T int
+func (T) Gop_Add__0(T) T { return 0 }
+func (T) Gop_Add__1(int) T { return 0 }`,
+			new: `const GopPackage = true
+type T int
+func (T) Gop_Add__0(int) T { return 0 }`,
+			wanti: []string{"T.Gop_Add, overload func(T) T: removed"},
+		},
+		{
+			name:  "not a Go+ package",
+			old:   `func Add__0(a, b int) int { return a + b }`,
+			new:   `func Add__1(a, b int) int { return a + b }`,
+			wanti: []string{"Add__0: removed"},
+			wantc: []string{"Add__1: added"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			report := Changes(checkGop(t, "old", test.old), checkGop(t, "new", test.new))
+			if got := report.messages(false); !reflect.DeepEqual(got, test.wanti) {
+				t.Errorf("incompatibles: got %q, want %q", got, test.wanti)
+			}
+			if got := report.messages(true); !reflect.DeepEqual(got, test.wantc) {
+				t.Errorf("compatibles: got %q, want %q", got, test.wantc)
+			}
+		})
+	}
+}
+
+// checkGop type-checks the Go source src of a package p.
+func checkGop(t *testing.T, filename, src string) *types.Package {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename+".go", "package p\n"+src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
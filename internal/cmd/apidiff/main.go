@@ -0,0 +1,202 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The apidiff command reports the API changes between two versions of a
+// Go or Go+ module on disk.
+//
+// Usage: apidiff [flags] olddir newdir
+//
+// Each directory is the root of a version of the module. The packages of
+// both versions are loaded and matched by their import paths relative to
+// the module path, so the two versions may have different module paths
+// (e.g. example.com/m and example.com/m/v2). For each package, the
+// incompatible and compatible changes are reported as by
+// golang.org/x/tools/internal/apidiff. The overloads of Go+ packages are
+// compared as logical Go+ APIs.
+//
+// The Go code of the Go+ files (gop_autogen.go) of each version is
+// generated anew by an installed gop command before loading, in a copy of
+// the version's directory tree, so that read-only trees such as those of
+// the module cache may be compared; the -gop flag causes it to be
+// generated by the gop library instead.
+//
+// THIS TOOL IS EXPERIMENTAL and its interface may change.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/gop/langserver"
+	goppackages "golang.org/x/tools/gop/packages"
+	"golang.org/x/tools/internal/apidiff"
+)
+
+var (
+	incompatibleFlag = flag.Bool("incompatible", false, "display only incompatible changes")
+	gopFlag          = flag.Bool("gop", false, "generate the Go code of Go+ packages in-process, without an installed gop command")
+)
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: apidiff [flags] olddir newdir\n\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	log.SetPrefix("apidiff: ")
+	log.SetFlags(0) // no time prefix
+
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 2 {
+		usage()
+		os.Exit(2)
+	}
+	if *gopFlag {
+		goppackages.SetGenGoMode(langserver.ModeInProcess)
+	}
+	if err := run(os.Stdout, flag.Arg(0), flag.Arg(1), *incompatibleFlag); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run reports the API changes between the versions of a module in
+// directories oldDir and newDir to w.
+func run(w io.Writer, oldDir, newDir string, incompatibleOnly bool) error {
+	olds, err := loadModule(oldDir)
+	if err != nil {
+		return err
+	}
+	news, err := loadModule(newDir)
+	if err != nil {
+		return err
+	}
+
+	paths := make(map[string]bool)
+	for path := range olds {
+		paths[path] = true
+	}
+	for path := range news {
+		paths[path] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	for _, path := range sorted {
+		old, new := olds[path], news[path]
+		var report apidiff.Report
+		switch {
+		case new == nil:
+			report.Changes = []apidiff.Change{{Message: "package removed", Compatible: false}}
+		case old == nil:
+			report.Changes = []apidiff.Change{{Message: "package added", Compatible: true}}
+		default:
+			report = apidiff.Changes(old.Types, new.Types)
+		}
+		if len(report.Changes) == 0 {
+			continue
+		}
+		name := new
+		if name == nil {
+			name = old
+		}
+		var err error
+		if incompatibleOnly {
+			var sb strings.Builder
+			if err = report.TextIncompatible(&sb, false); err == nil && sb.Len() > 0 {
+				_, err = fmt.Fprintf(w, "## %s\n%s", name.PkgPath, sb.String())
+			}
+		} else {
+			if _, err = fmt.Fprintf(w, "## %s\n", name.PkgPath); err == nil {
+				err = report.Text(w)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadModule loads the packages of the module in directory dir, and
+// returns them by import path relative to the module path.
+func loadModule(dir string) (map[string]*packages.Package, error) {
+	// The API of a Go+ package is that of its Go code: Go+ code sees the
+	// overloads through their Go names (Foo__0, Gopo_Foo, ...).
+	// Generating it writes to the tree, so a copy of it is loaded.
+	tmpdir, err := os.MkdirTemp("", "apidiff")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpdir)
+	if err := copyTree(tmpdir, dir); err != nil {
+		return nil, err
+	}
+	dir = tmpdir
+	if _, err := goppackages.GenGo(dir + "/..."); err != nil {
+		return nil, err
+	}
+	cfg := &packages.Config{
+		// Type-check from source: the overloads listed by Gopo_ constants
+		// may be unexported, and so be missing from export data.
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedModule,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(cfg, dir+"/...")
+	if err != nil {
+		return nil, err
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, fmt.Errorf("packages in %s contain errors", dir)
+	}
+	m := make(map[string]*packages.Package)
+	for _, pkg := range pkgs {
+		path := pkg.PkgPath
+		if pkg.Module != nil {
+			path = strings.TrimPrefix(strings.TrimPrefix(path, pkg.Module.Path), "/")
+		}
+		m[path] = pkg
+	}
+	return m, nil
+}
+
+// copyTree copies the regular files of the directory tree src to the
+// directory dst, with writable permissions.
+func copyTree(dst, src string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case d.IsDir():
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0777)
+		case d.Type().IsRegular():
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(target, data, 0666)
+		}
+		return nil // e.g. a symbolic link
+	})
+}
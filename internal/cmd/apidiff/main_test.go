@@ -0,0 +1,148 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/testenv"
+	"golang.org/x/tools/txtar"
+)
+
+// Test runs the apidiff command on each scenario described by a
+// testdata/*.txtar file, whose old/ and new/ directories hold the two
+// versions of a module.
+func Test(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+
+	exe := buildApidiff(t)
+
+	matches, err := filepath.Glob("testdata/*.txtar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, filename := range matches {
+		filename := filename
+		t.Run(filename, func(t *testing.T) {
+			t.Parallel()
+
+			ar, err := txtar.ParseFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Parse archive comment as directives of these forms:
+			//
+			//    apidiff args...		command-line arguments
+			//  [!]want "quoted"		expected/unwanted string in output
+			//
+			var args []string
+			want := make(map[string]bool) // string -> sense
+			for _, line := range strings.Split(string(ar.Comment), "\n") {
+				line = strings.TrimSpace(line)
+				if line == "" || line[0] == '#' {
+					continue // skip blanks and comments
+				}
+
+				fields := strings.Fields(line)
+				switch kind := fields[0]; kind {
+				case "apidiff":
+					args = fields[1:] // lossy wrt spaces
+				case "want", "!want":
+					rest := line[len(kind):]
+					str, err := strconv.Unquote(strings.TrimSpace(rest))
+					if err != nil {
+						t.Fatalf("bad %s directive <<%s>>", kind, line)
+					}
+					want[str] = kind[0] != '!'
+				default:
+					t.Fatalf("%s: invalid directive %q", filename, kind)
+				}
+			}
+
+			// Go+ code is generated by the gop library in GOPROOT.
+			for _, arg := range args {
+				if arg == "-gop" {
+					testenv.NeedsGOPROOT(t)
+				}
+			}
+
+			// Write the archive files to the temp directory.
+			tmpdir := t.TempDir()
+			for _, f := range ar.Files {
+				filename := filepath.Join(tmpdir, f.Name)
+				if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filename, f.Data, 0666); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Run the command.
+			cmd := exec.Command(exe, args...)
+			cmd.Stdout = new(bytes.Buffer)
+			cmd.Stderr = new(bytes.Buffer)
+			cmd.Dir = tmpdir
+			cmd.Env = append(os.Environ(), "GOPROXY=off", "GO111MODULE=on")
+			if err := cmd.Run(); err != nil {
+				t.Fatalf("apidiff failed: %v (stderr=%s)", err, cmd.Stderr)
+			}
+
+			// The module trees must not be written to.
+			files := make(map[string]bool)
+			for _, f := range ar.Files {
+				files[filepath.FromSlash(f.Name)] = true
+			}
+			err = filepath.WalkDir(tmpdir, func(path string, d fs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					rel, _ := filepath.Rel(tmpdir, path)
+					if !files[rel] {
+						t.Errorf("apidiff wrote %s to a module tree", rel)
+					}
+				}
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Check each want directive.
+			got := fmt.Sprint(cmd.Stdout)
+			for str, sense := range want {
+				if strings.Contains(got, str) != sense {
+					if sense {
+						t.Errorf("missing %q", str)
+					} else {
+						t.Errorf("unwanted %q", str)
+					}
+					t.Errorf("got: <<%s>>", got)
+				}
+			}
+		})
+	}
+}
+
+// buildApidiff builds the apidiff executable and returns its path.
+func buildApidiff(t *testing.T) string {
+	bin := filepath.Join(t.TempDir(), "apidiff")
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	cmd := exec.Command("go", "build", "-o", bin)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Building apidiff: %v\n%s", err, out)
+	}
+	return bin
+}
@@ -34,6 +34,7 @@ var (
 
 	filterFlag = flag.String("filter", "<module>", "report only packages matching this regular expression (default: module of first package)")
 	lineFlag   = flag.Bool("line", false, "show output in a line-oriented format")
+	gopFlag    = flag.Bool("gop", false, "load Go+ packages and report functions at their Go+ source positions")
 	cpuProfile = flag.String("cpuprofile", "", "write CPU profile to this file")
 	memProfile = flag.String("memprofile", "", "write memory profile to this file")
 )
@@ -93,20 +94,33 @@ func main() {
 		Mode:       packages.LoadAllSyntax | packages.NeedModule,
 		Tests:      *testFlag,
 	}
-	initial, err := packages.Load(cfg, flag.Args()...)
-	if err != nil {
-		log.Fatalf("Load: %v", err)
-	}
-	if len(initial) == 0 {
-		log.Fatalf("no packages")
-	}
-	if packages.PrintErrors(initial) > 0 {
-		log.Fatalf("packages contain errors")
+	var (
+		prog   *ssa.Program
+		pkgs   []*ssa.Package
+		module *packages.Module
+	)
+	if *gopFlag { // goxls: load Go+ packages
+		prog, pkgs, module = loadGop(cfg, flag.Args())
+	} else {
+		initial, err := packages.Load(cfg, flag.Args()...)
+		if err != nil {
+			log.Fatalf("Load: %v", err)
+		}
+		if len(initial) == 0 {
+			log.Fatalf("no packages")
+		}
+		if packages.PrintErrors(initial) > 0 {
+			log.Fatalf("packages contain errors")
+		}
+		module = initial[0].Module
+
+		// Create SSA-form program representation.
+		prog, pkgs = ssautil.AllPackages(initial, ssa.InstantiateGenerics)
 	}
 
 	// If -filter is unset, use first module (if available).
 	if *filterFlag == "<module>" {
-		if mod := initial[0].Module; mod != nil && mod.Path != "" {
+		if mod := module; mod != nil && mod.Path != "" {
 			*filterFlag = "^" + regexp.QuoteMeta(mod.Path) + "\\b"
 		} else {
 			*filterFlag = "" // match any
@@ -117,9 +131,8 @@ func main() {
 		log.Fatalf("-filter: %v", err)
 	}
 
-	// Create SSA-form program representation
+	// Build the SSA-form program representation
 	// and find main packages.
-	prog, pkgs := ssautil.AllPackages(initial, ssa.InstantiateGenerics)
 	prog.Build()
 
 	mains := ssautil.MainPackages(pkgs)
@@ -146,10 +159,18 @@ func main() {
 	// if any one of them is live, we consider all of them live.
 	// (We use Position not Pos to avoid assuming that files common
 	// to packages "p" and "p [p.test]" were parsed only once.)
-	reachablePosn := make(map[token.Position]bool)
+	//
+	// goxls: the functions generated from an overloaded Go+ function,
+	// such as add__0 and add__1, share the position of its declaration,
+	// so we de-duplicate them by name too (see gopOverloadName).
+	type posnName struct {
+		posn token.Position
+		name string
+	}
+	reachablePosn := make(map[posnName]bool)
 	for fn := range res.Reachable {
 		if fn.Pos().IsValid() {
-			reachablePosn[prog.Fset.Position(fn.Pos())] = true
+			reachablePosn[posnName{prog.Fset.Position(fn.Pos()), gopOverloadName(fn)}] = true
 		}
 	}
 
@@ -175,9 +196,9 @@ func main() {
 			continue
 		}
 
-		posn := prog.Fset.Position(fn.Pos())
-		if !reachablePosn[posn] {
-			reachablePosn[posn] = true // suppress dups with same pos
+		key := posnName{prog.Fset.Position(fn.Pos()), gopOverloadName(fn)}
+		if !reachablePosn[key] {
+			reachablePosn[key] = true // suppress dups with same pos
 
 			pkgpath := fn.Pkg.Pkg.Path()
 			m, ok := byPkgPath[pkgpath]
@@ -217,7 +238,10 @@ func main() {
 			if xposn.Filename != yposn.Filename {
 				return xposn.Filename < yposn.Filename
 			}
-			return xposn.Line < yposn.Line
+			if xposn.Line != yposn.Line {
+				return xposn.Line < yposn.Line
+			}
+			return fns[i].Name() < fns[j].Name() // goxls: overloads share a line
 		})
 
 		// TODO(adonovan): add an option to skip (or indicate)
@@ -72,6 +72,13 @@ func Test(t *testing.T) {
 				}
 			}
 
+			// goxls: Go+ code is generated by the gop library in GOPROOT.
+			for _, arg := range args {
+				if arg == "-gop" {
+					testenv.NeedsGOPROOT(t)
+				}
+			}
+
 			// Write the archive files to the temp directory.
 			tmpdir := t.TempDir()
 			for _, f := range ar.Files {
@@ -28,6 +28,14 @@ The -filter flag restricts results to packages that match the provided
 regular expression; its default value is the module name of the first
 package. Use -filter= to display all results.
 
+The -gop flag causes it to load Go+ packages too, generating the Go
+code of their Go+ files (gop_autogen.go) with the gop library. Functions
+and classfile methods declared in Go+ source are then ordered by their
+Go+ declarations rather than by the generated code. Each function
+generated from an overloaded Go+ function (add__0, add__1, ...) is
+reported separately. Note that Go+ packages are found only by patterns
+that denote directories, such as ./... or /abs/dir.
+
 Example: show all dead code within the gopls module:
 
 	$ deadcode -test golang.org/x/tools/gopls/...
@@ -0,0 +1,58 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"log"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/gop/langserver"
+	goppackages "golang.org/x/tools/gop/packages"
+	gopssautil "golang.org/x/tools/gop/ssa/ssautil"
+)
+
+// loadGop loads the Go/Go+ packages named by args, generating the Go code
+// of Go+ packages in-process, and creates their SSA-form representation.
+// The positions of functions declared in Go+ source are those of their
+// Go+ declarations, not of the generated gop_autogen.go files.
+// It also returns the module of the first package, if any.
+func loadGop(cfg *packages.Config, args []string) (*ssa.Program, []*ssa.Package, *packages.Module) {
+	goppackages.SetGenGoMode(langserver.ModeInProcess)
+	initial, err := goppackages.Load(cfg, args...)
+	if err != nil {
+		log.Fatalf("Load: %v", err)
+	}
+	if len(initial) == 0 {
+		log.Fatalf("no packages")
+	}
+	if goppackages.PrintErrors(initial) > 0 {
+		log.Fatalf("packages contain errors")
+	}
+	prog, pkgs := gopssautil.AllPackages(initial, ssa.InstantiateGenerics)
+	return prog, pkgs, initial[0].Module
+}
+
+// gopOverloadName returns the name of fn if it is one of the functions
+// generated from an overloaded Go+ function, such as add__0 or
+// (*T).Mul__1, and "" otherwise. Instances of generic functions are
+// named after their origin, so that they are not told apart from it.
+func gopOverloadName(fn *ssa.Function) string {
+	if orig := fn.Origin(); orig != nil {
+		fn = orig
+	}
+	name := fn.Name()
+	i := strings.LastIndex(name, "__")
+	if i < 0 || i+2 == len(name) {
+		return ""
+	}
+	for _, c := range name[i+2:] {
+		if c < '0' || c > '9' {
+			return ""
+		}
+	}
+	return name
+}
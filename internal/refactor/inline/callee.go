@@ -48,6 +48,7 @@ type gobCallee struct {
 	TrivialReturns   int          // number of return statements with trivial result conversions
 	Labels           []string     // names of all control labels
 	Falcon           falconResult // falcon constraint system
+	Gop              bool         // goxls: callee is declared in a Go+ file
 }
 
 // A freeRef records a reference to a free object.  Gob-serializable.
@@ -111,6 +112,39 @@ func AnalyzeCallee(logf func(string, ...any), fset *token.FileSet, pkg *types.Pa
 		return nil, fmt.Errorf("cannot inline generic function %s: type parameters are not yet supported", name)
 	}
 
+	// goxls: the analysis of the declaration is shared with GopAnalyzeCallee.
+	callee, err := analyzeCallee(logf, fset, pkg, info, decl, fn, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compact content to just the FuncDecl.
+	//
+	// As a space optimization, we don't retain the complete
+	// callee file content; all we need is "package _; func f() { ... }".
+	// This reduces the size of analysis facts.
+	//
+	// Offsets in the callee information are "relocatable"
+	// since they are all relative to the FuncDecl.
+
+	content = append([]byte("package _\n"),
+		content[offsetOf(fset, decl.Pos()):offsetOf(fset, decl.End())]...)
+	// Sanity check: re-parse the compacted content.
+	if _, _, err := parseCompact(content); err != nil {
+		return nil, err
+	}
+
+	callee.Content = content
+	return &Callee{*callee}, nil
+}
+
+// analyzeCallee analyzes the declaration of the function fn, whose
+// user-friendly name is name. The result lacks the callee content.
+//
+// goxls: factored out of AnalyzeCallee, for GopAnalyzeCallee.
+func analyzeCallee(logf func(string, ...any), fset *token.FileSet, pkg *types.Package, info *types.Info, decl *ast.FuncDecl, fn *types.Func, name string) (*gobCallee, error) {
+	sig := fn.Type().(*types.Signature)
+
 	// Record the location of all free references in the FuncDecl.
 	// (Parameters are not free by this definition.)
 	var (
@@ -316,25 +350,8 @@ func AnalyzeCallee(logf func(string, ...any), fset *token.FileSet, pkg *types.Pa
 		}
 	}
 
-	// Compact content to just the FuncDecl.
-	//
-	// As a space optimization, we don't retain the complete
-	// callee file content; all we need is "package _; func f() { ... }".
-	// This reduces the size of analysis facts.
-	//
-	// Offsets in the callee information are "relocatable"
-	// since they are all relative to the FuncDecl.
-
-	content = append([]byte("package _\n"),
-		content[offsetOf(fset, decl.Pos()):offsetOf(fset, decl.End())]...)
-	// Sanity check: re-parse the compacted content.
-	if _, _, err := parseCompact(content); err != nil {
-		return nil, err
-	}
-
 	params, results, effects, falcon := analyzeParams(logf, fset, info, decl)
-	return &Callee{gobCallee{
-		Content:          content,
+	return &gobCallee{
 		PkgPath:          pkg.Path(),
 		Name:             name,
 		Unexported:       unexported,
@@ -351,7 +368,7 @@ func AnalyzeCallee(logf func(string, ...any), fset *token.FileSet, pkg *types.Pa
 		TrivialReturns:   trivialReturns,
 		Labels:           labels,
 		Falcon:           falcon,
-	}}, nil
+	}, nil
 }
 
 // parseCompact parses a Go source file of the form "package _\n func f() { ... }"
@@ -376,6 +393,8 @@ type paramInfo struct {
 	Refs       []int           // FuncDecl-relative byte offset of parameter ref within body
 	Shadow     map[string]bool // names shadowed at one of the above refs
 	FalconType string          // name of this parameter's type (if basic) in the falcon system
+
+	ImplicitRefs []int // goxls: FuncDecl-relative byte offsets of implicit refs to members of a Go+ class
 }
 
 // analyzeParams computes information about parameters of function fn,
@@ -0,0 +1,179 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inline
+
+// This file defines the analysis of a callee function declared in a Go+ file.
+
+import (
+	"fmt"
+	"go/types"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/parser"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+)
+
+// GopAnalyzeCallee is like AnalyzeCallee, but for a function declared
+// in a Go+ file. The resulting Callee can only be inlined into Go+
+// files, by GopInline.
+//
+// In a Go+ classfile, references to the fields and methods of the class
+// may omit the receiver "this". These implicit references are recorded
+// so that GopInline can make them explicit.
+func GopAnalyzeCallee(logf func(string, ...any), fset *token.FileSet, pkg *types.Package, info *typesutil.Info, decl *ast.FuncDecl, content []byte) (*Callee, error) {
+	// The client is expected to have determined that the callee
+	// is a function with a declaration (not a built-in or var).
+	fn, ok := info.Defs[decl.Name].(*types.Func)
+	if !ok {
+		return nil, fmt.Errorf("cannot inline %s: not a function", decl.Name.Name)
+	}
+	sig := fn.Type().(*types.Signature)
+
+	logf("analyzeCallee %v @ %v", fn, fset.PositionFor(decl.Pos(), false))
+
+	// Create user-friendly name ("pkg.Func" or "(pkg.T).Method")
+	var name string
+	if sig.Recv() == nil {
+		name = fmt.Sprintf("%s.%s", fn.Pkg().Name(), fn.Name())
+	} else {
+		name = fmt.Sprintf("(%s).%s", types.TypeString(sig.Recv().Type(), (*types.Package).Name), fn.Name())
+	}
+
+	if decl.Shadow {
+		return nil, fmt.Errorf("cannot inline the main entry of a Go+ file")
+	}
+	if decl.Body == nil {
+		return nil, fmt.Errorf("cannot inline function %s as it has no body", name)
+	}
+	if decl.Type.TypeParams != nil {
+		return nil, fmt.Errorf("cannot inline generic function %s: type parameters are not yet supported", name)
+	}
+
+	// Analyze the Go form of the declaration.
+	//
+	// The receiver of a classfile method is implicit.
+	var recv *types.Var
+	if decl.IsClass {
+		recv = sig.Recv()
+	}
+	conv := newGopConverter(pkg, info, recv)
+	callee, err := analyzeCallee(logf, fset, pkg, conv.info, conv.funcDecl(decl), fn, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// The Go form represents the implicit references to the members of
+	// the class as references to the receiver: make them implicit again.
+	implicit := make(map[int]bool)
+	for _, id := range conv.implicit {
+		implicit[int(id.Pos()-decl.Pos())] = true
+		if obj := info.Uses[id]; !obj.Exported() {
+			callee.Unexported = append(callee.Unexported, obj.Name())
+		}
+	}
+	// The implicit receiver has no position, so its references
+	// were also recorded as free references: they are not.
+	notFree := make(map[int]bool)
+	for ref := range implicit {
+		notFree[ref] = true
+	}
+	if recv != nil {
+		this := callee.Params[0]
+		refs := this.Refs
+		this.Refs = nil
+		for _, ref := range refs {
+			notFree[ref] = true
+			if implicit[ref] {
+				this.ImplicitRefs = append(this.ImplicitRefs, ref)
+			} else {
+				this.Refs = append(this.Refs, ref)
+			}
+		}
+	}
+	var (
+		freeObjs   []object
+		freeRefs   []freeRef
+		freeObjIdx = make(map[int]int)
+	)
+	for _, ref := range callee.FreeRefs {
+		if notFree[ref.Offset] {
+			continue
+		}
+		idx, ok := freeObjIdx[ref.Object]
+		if !ok {
+			idx = len(freeObjs)
+			freeObjs = append(freeObjs, callee.FreeObjs[ref.Object])
+			freeObjIdx[ref.Object] = idx
+		}
+		freeRefs = append(freeRefs, freeRef{Offset: ref.Offset, Object: idx})
+	}
+	callee.FreeObjs, callee.FreeRefs = freeObjs, freeRefs
+
+	// Compact content to just the FuncDecl.
+	//
+	// Offsets in the callee information are "relocatable"
+	// since they are all relative to the FuncDecl.
+	content = append([]byte("package _\n"),
+		content[fset.PositionFor(decl.Pos(), false).Offset:fset.PositionFor(decl.End(), false).Offset]...)
+	// Sanity check: re-parse the compacted content.
+	if _, _, err := gopParseCompact(content); err != nil {
+		return nil, err
+	}
+
+	callee.Content = content
+	callee.Gop = true
+	return &Callee{*callee}, nil
+}
+
+// gopParseCompact parses a Go+ source file of the form "package _\n func f() { ... }"
+// and returns the sole function declaration.
+//
+// The compacted content of a Go callee is valid Go+ too.
+func gopParseCompact(content []byte) (*token.FileSet, *ast.FuncDecl, error) {
+	fset := token.NewFileSet()
+	const mode = parser.ParseComments | parser.AllErrors
+	f, err := parser.ParseFile(fset, "callee.gop", content, mode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("internal error: cannot compact file: %v", err)
+	}
+	for _, decl := range f.Decls {
+		if decl, ok := decl.(*ast.FuncDecl); ok && !decl.Shadow {
+			return fset, decl, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("internal error: cannot compact file: no function")
+}
+
+// -- callee helpers --
+
+// gopScopeFor is like scopeFor, for Go+ syntax.
+func gopScopeFor(info *typesutil.Info, n ast.Node) *types.Scope {
+	// The function body scope (containing not just params)
+	// is associated with the function's type, not body.
+	switch fn := n.(type) {
+	case *ast.FuncDecl:
+		n = fn.Type
+	case *ast.FuncLit:
+		n = fn.Type
+	}
+	return info.Scopes[n]
+}
+
+// gopWithin reports whether pos is within the half-open interval [n.Pos, n.End).
+func gopWithin(pos token.Pos, n ast.Node) bool {
+	return n.Pos() <= pos && pos < n.End()
+}
+
+// gopUnparen returns e with any enclosing parentheses stripped.
+func gopUnparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.X
+	}
+}
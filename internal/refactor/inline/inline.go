@@ -65,6 +65,10 @@ func Inline(logf func(string, ...any), caller *Caller, callee *Callee) ([]byte,
 		return nil, fmt.Errorf("cannot inline calls from files that import \"C\"")
 	}
 
+	if callee.impl.Gop { // goxls: Go+
+		return nil, fmt.Errorf("cannot inline Go+ function %s into a Go file", callee.impl.Name)
+	}
+
 	res, err := inline(logf, caller, &callee.impl)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,917 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inline
+
+// This file defines the inlining of calls in Go+ files.
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/parser"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/gop/ast/astutil"
+)
+
+// A GopCaller describes the function call and its enclosing context
+// in a Go+ file.
+//
+// The client is responsible for populating this struct and passing it to GopInline.
+type GopCaller struct {
+	Fset    *token.FileSet
+	Types   *types.Package
+	Info    *typesutil.Info
+	File    *ast.File
+	Call    ast.Expr // *ast.CallExpr, or the *ast.Ident or *ast.SelectorExpr of an auto-property
+	Content []byte   // source of file containing
+
+	path          []ast.Node    // path from call to root of file syntax tree
+	enclosingFunc *ast.FuncDecl // top-level function/method enclosing the call, if any
+}
+
+// GopInline is like Inline, but inlines a call in a Go+ file.
+//
+// The callee may be declared in a Go file (see AnalyzeCallee) or in a
+// Go+ file (see GopAnalyzeCallee). Besides ordinary calls, the call may
+// be a command-style call such as "echo x", or an auto-property such as
+// "x.len", which calls a method without arguments.
+//
+// GopInline tries the following strategies in turn: reducing the call
+// to the result expression of the callee; splicing the statements of
+// the callee in place of a call statement; and calling a function
+// literal with the body of the callee. Parameters are replaced by their
+// arguments where it is safe, and bound to local variables otherwise.
+//
+// The result is the updated content of the caller file. It is not
+// reformatted, except for the indentation of the inlined code.
+func GopInline(logf func(string, ...any), caller *GopCaller, callee *Callee) ([]byte, error) {
+	logf("inline %s @ %v", callee, caller.Fset.PositionFor(caller.Call.Pos(), false))
+
+	start := offsetOf(caller.Fset, caller.Call.Pos())
+	end := offsetOf(caller.Fset, gopEnd(caller.Call))
+	if !(0 <= start && start < end && end <= len(caller.Content)) {
+		return nil, fmt.Errorf("internal error: caller syntax positions are inconsistent with file content")
+	}
+
+	res, err := gopInline(logf, caller, &callee.impl)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace the call (or the statement that encloses it) by the new code.
+	start = offsetOf(caller.Fset, res.old.Pos())
+	end = offsetOf(caller.Fset, gopEnd(res.old))
+	var out []byte
+	out = append(out, caller.Content[:start]...)
+	out = append(out, res.new...)
+	out = append(out, caller.Content[end:]...)
+
+	// Sanity check: the result must still be syntactically valid.
+	mode := parser.ParseComments | parser.AllErrors
+	if caller.File.IsClass {
+		mode |= parser.ParseGoPlusClass
+	}
+	filename := caller.Fset.PositionFor(caller.File.Pos(), false).Filename
+	if _, err := parser.ParseFile(token.NewFileSet(), filename, out, mode); err != nil {
+		return nil, fmt.Errorf("internal error: inlining %s produced invalid syntax: %v", callee, err)
+	}
+	return out, nil
+}
+
+// A gopResult describes the replacement of the old node, which is
+// the call or the statement that encloses it, by new source text.
+type gopResult struct {
+	old ast.Node
+	new string
+}
+
+// A gopArgument describes an argument of a call, including the
+// receiver of a method call. Its analysis is that of the Go form of
+// the argument.
+type gopArgument struct {
+	argument
+	text    string // source text of the argument
+	primary bool   // text is a primary expression (no parens needed)
+}
+
+// A gopEdit replaces the callee text between FuncDecl-relative byte
+// offsets start and end.
+type gopEdit struct {
+	start, end int
+	new        string
+}
+
+func gopInline(logf func(string, ...any), caller *GopCaller, callee *gobCallee) (*gopResult, error) {
+	// Inlining of dynamic calls is not currently supported.
+	fun, callArgs, fn := gopStaticCallee(caller.Info, caller.Call)
+	if fn == nil {
+		return nil, fmt.Errorf("cannot inline: not a static function call")
+	}
+	sig := fn.Type().(*types.Signature)
+
+	// Reject cross-package inlining if callee has
+	// free references to unexported symbols.
+	samePkg := caller.Types.Path() == callee.PkgPath
+	if !samePkg && len(callee.Unexported) > 0 {
+		return nil, fmt.Errorf("cannot inline call to %s because body refers to non-exported %s",
+			callee.Name, callee.Unexported[0])
+	}
+
+	_, calleeDecl, err := gopParseCompact(callee.Content)
+	if err != nil {
+		return nil, err
+	}
+	declOffset := strings.Index(string(callee.Content), "\n") + 1 // skip "package _\n"
+	src := callee.Content[declOffset:]
+	rel := func(pos token.Pos) int { return int(pos - calleeDecl.Pos()) }
+
+	// Compute syntax path enclosing Call, innermost first (Path[0]=Call),
+	// and outermost enclosing function, if any.
+	caller.path, _ = astutil.PathEnclosingInterval(caller.File, caller.Call.Pos(), caller.Call.End())
+	for _, n := range caller.path {
+		if decl, ok := n.(*ast.FuncDecl); ok {
+			caller.enclosingFunc = decl
+			break
+		}
+	}
+
+	// The analyses of the caller apply to the Go form of its syntax.
+	var this *types.Var
+	if caller.File.IsClass {
+		this, _ = caller.lookup("this").(*types.Var)
+	}
+	conv := newGopConverter(caller.Types, caller.Info, this)
+
+	// If call is within a function, analyze all its
+	// local vars for the "single assignment" property.
+	// (Taking the address &v counts as a potential assignment.)
+	updatedLocals := make(map[*types.Var]bool)
+	if caller.enclosingFunc != nil {
+		escape(conv.info, conv.funcDecl(caller.enclosingFunc), func(v *types.Var, _ bool) {
+			updatedLocals[v] = true
+		})
+	}
+	assign1 := func(v *types.Var) bool { return !updatedLocals[v] }
+
+	// -- analyze callee's free references in caller context --
+
+	var edits []gopEdit
+	for _, ref := range callee.FreeRefs {
+		obj := callee.FreeObjs[ref.Object]
+		name, err := caller.freeObjName(callee, obj)
+		if err != nil {
+			return nil, err
+		}
+		if name != obj.Name {
+			edits = append(edits, gopEdit{ref.Offset, ref.Offset + len(obj.Name), name})
+		}
+	}
+
+	// -- analyze arguments --
+
+	args, err := gopArguments(caller, conv, fun, callArgs, fn, assign1)
+	if err != nil {
+		return nil, err
+	}
+	params := callee.Params
+	literalOnly := false // only the literalization strategy applies
+	if call, ok := caller.Call.(*ast.CallExpr); ok {
+		if sig.Variadic() && !call.Ellipsis.IsValid() {
+			logf("keeping all params: call to variadic function")
+			literalOnly = true
+		}
+		if len(callArgs) == 1 && is[*types.Tuple](caller.Info.TypeOf(callArgs[0])) {
+			logf("keeping all params: spread call")
+			literalOnly = true
+		}
+	}
+	if len(args) != len(params) {
+		literalOnly = true
+	}
+
+	// The type of each parameter, in the same order as params.
+	var paramTypes []types.Type
+	if sig.Recv() != nil {
+		paramTypes = append(paramTypes, sig.Recv().Type())
+	}
+	for i := 0; i < sig.Params().Len(); i++ {
+		paramTypes = append(paramTypes, sig.Params().At(i).Type())
+	}
+	if len(paramTypes) != len(params) {
+		return nil, fmt.Errorf("internal error: callee %s does not match the called function %s", callee.Name, fn.Name())
+	}
+
+	// The syntax of the type of each ordinary parameter, in callee
+	// coordinates, for conversions and bindings.
+	var paramTypeExprs []ast.Expr
+	if sig.Recv() != nil {
+		var recvType ast.Expr
+		if calleeDecl.Recv != nil && len(calleeDecl.Recv.List) > 0 {
+			recvType = calleeDecl.Recv.List[0].Type
+		}
+		paramTypeExprs = append(paramTypeExprs, recvType)
+	}
+	for _, field := range calleeDecl.Type.Params.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			paramTypeExprs = append(paramTypeExprs, field.Type)
+		}
+	}
+
+	// -- decide which parameters are substituted by their arguments --
+
+	if !literalOnly {
+		gopSubstitute(logf, caller, params, args, paramTypeExprs)
+		resolveEffects(logf, gopArgs(args), callee.Effects)
+	} else if sig.Recv() != nil && len(args) > 0 {
+		// Only the receiver may be substituted when the call
+		// is literalized.
+		gopSubstitute(logf, caller, params[:1], args[:1], paramTypeExprs[:1])
+	}
+
+	// recvText returns the text that replaces the implicit receiver of
+	// a member of a class, followed by a dot.
+	recvText := func(i int) string {
+		if args[i].substitutable {
+			return args[i].paren() + "."
+		}
+		return params[i].Name + "."
+	}
+
+	// substEdits returns the edits that replace the substituted
+	// parameters by their arguments, from params[:n].
+	substEdits := func(n int) ([]gopEdit, error) {
+		var edits []gopEdit
+		for i, param := range params[:n] {
+			arg := args[i]
+			for _, ref := range param.ImplicitRefs {
+				edits = append(edits, gopEdit{ref, ref, recvText(i)})
+			}
+			if !arg.substitutable {
+				continue
+			}
+			text := arg.paren()
+			if len(param.Refs) > 0 && !gopTrivialConversion(arg.typ, paramTypes[i]) {
+				// Wrap the argument in an explicit conversion if
+				// substitution might materially change its type.
+				if paramTypeExprs[i] == nil {
+					return nil, fmt.Errorf("cannot inline: receiver %s needs a conversion", arg.text)
+				}
+				typ := gopSpan(src, paramTypeExprs[i].Pos()-calleeDecl.Pos(), paramTypeExprs[i].End()-calleeDecl.Pos(), edits)
+				if !gopIsPrimaryType(paramTypeExprs[i]) {
+					typ = "(" + typ + ")"
+				}
+				text = typ + "(" + arg.text + ")"
+				if arg.typ == nil {
+					// e.g. a lambda or a slice literal, typed by its context
+					logf("param %q: adding explicit %s conversion around argument",
+						param.Name, paramTypes[i])
+				} else {
+					logf("param %q: adding explicit %s -> %s conversion around argument",
+						param.Name, arg.typ, paramTypes[i])
+				}
+			}
+			logf("replacing parameter %q by argument %q", param.Name, arg.text)
+			for _, ref := range param.Refs {
+				edits = append(edits, gopEdit{ref, ref + len(param.Name), text})
+			}
+		}
+		return edits, nil
+	}
+
+	// stmt is the statement of a call used as a statement, if any.
+	var stmt *ast.ExprStmt
+	if len(caller.path) > 1 {
+		if s, ok := caller.path[1].(*ast.ExprStmt); ok && s.X == caller.Call {
+			stmt = s
+		}
+	}
+	indent := gopIndent(caller.Content, offsetOf(caller.Fset, caller.path[0].Pos()))
+
+	if !literalOnly {
+		allSubstituted := true
+		for _, arg := range args {
+			if !arg.substitutable {
+				allSubstituted = false
+			}
+		}
+		body := calleeDecl.Body.List
+
+		// Strategy 1: reduce a call to a "return expr" function
+		// to the expression, with all parameters substituted.
+		if allSubstituted &&
+			len(body) == 1 &&
+			callee.NumResults == 1 &&
+			callee.TrivialReturns == callee.TotalReturns &&
+			(stmt == nil || callee.ValidForCallStmt) {
+			if ret, ok := body[0].(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+				logf("strategy: reduce call to result expression")
+				sedits, err := substEdits(len(params))
+				if err != nil {
+					return nil, err
+				}
+				expr := ret.Results[0]
+				text := gopSpan(src, rel(expr.Pos()), rel(gopEnd(expr)), append(sedits, edits...))
+				if !gopIsPrimary(expr) && gopNeedsParens(caller.path) {
+					text = "(" + text + ")"
+				}
+				return &gopResult{old: caller.Call, new: gopReindent(text, "", indent)}, nil
+			}
+		}
+
+		// Strategy 2: splice the statements of a function without
+		// results or returns in place of a call statement.
+		if stmt != nil &&
+			callee.NumResults == 0 &&
+			callee.TotalReturns == 0 &&
+			!callee.HasDefer &&
+			len(callee.Labels) == 0 &&
+			len(body) > 0 &&
+			gopIsStmtList(caller.path[2]) {
+			logf("strategy: splice body into call statement")
+			sedits, err := substEdits(len(params))
+			if err != nil {
+				return nil, err
+			}
+			edits := append(sedits, edits...)
+
+			// Bind the remaining parameters to local variables.
+			var bindings []string
+			for i, param := range params {
+				arg := args[i]
+				if arg.substitutable {
+					continue
+				}
+				switch {
+				case param.Name == "" || param.Name == "_" || len(param.Refs)+len(param.ImplicitRefs) == 0:
+					if !arg.pure || arg.effects || len(param.Refs) == 0 {
+						bindings = append(bindings, "_ = "+arg.text)
+					}
+				case gopTrivialConversion(arg.typ, paramTypes[i]):
+					bindings = append(bindings, param.Name+" := "+arg.text)
+				case paramTypeExprs[i] != nil:
+					typ := gopSpan(src, rel(paramTypeExprs[i].Pos()), rel(paramTypeExprs[i].End()), edits)
+					bindings = append(bindings, "var "+param.Name+" "+typ+" = "+arg.text)
+				default:
+					typ, err := caller.typeString(paramTypes[i])
+					if err != nil {
+						return nil, err
+					}
+					bindings = append(bindings, "var "+param.Name+" "+typ+" = "+arg.text)
+				}
+			}
+
+			text := gopSpan(src, rel(body[0].Pos()), rel(gopEnd(body[len(body)-1])), edits)
+			if len(bindings) == 0 && !gopDeclaresNames(body) {
+				return &gopResult{old: stmt, new: gopReindent(text, "\t", indent)}, nil
+			}
+			var b strings.Builder
+			b.WriteString("{\n")
+			for _, binding := range bindings {
+				b.WriteString(indent + "\t" + gopReindent(binding, "", indent+"\t") + "\n")
+			}
+			b.WriteString(indent + "\t" + gopReindent(text, "\t", indent+"\t") + "\n")
+			b.WriteString(indent + "}")
+			return &gopResult{old: stmt, new: b.String()}, nil
+		}
+	}
+
+	// Strategy 3: call a function literal with the body of the callee.
+	logf("strategy: literalization")
+	nrecv := 0
+	if sig.Recv() != nil && len(args) > 0 {
+		nrecv = 1
+	}
+	sedits, err := substEdits(nrecv)
+	if err != nil {
+		return nil, err
+	}
+	edits = append(sedits, edits...)
+
+	var lparams, largs []string
+	if nrecv > 0 && !args[0].substitutable {
+		// The receiver is an ordinary parameter of the literal.
+		recv := params[0]
+		name := recv.Name
+		if name == "" {
+			name = "_"
+		}
+		var typ string
+		if expr := paramTypeExprs[0]; expr != nil {
+			typ = gopSpan(src, rel(expr.Pos()), rel(expr.End()), edits)
+		} else if typ, err = caller.typeString(paramTypes[0]); err != nil {
+			return nil, err
+		}
+		lparams = append(lparams, name+" "+typ)
+		largs = append(largs, args[0].text)
+	}
+	ftype := calleeDecl.Type
+	if ps := gopSpan(src, rel(ftype.Params.Opening+1), rel(ftype.Params.Closing), edits); strings.TrimSpace(ps) != "" {
+		lparams = append(lparams, ps)
+	}
+	for _, arg := range callArgs {
+		largs = append(largs, string(caller.Content[offsetOf(caller.Fset, arg.Pos()):offsetOf(caller.Fset, arg.End())]))
+	}
+	var b strings.Builder
+	b.WriteString("func(" + strings.Join(lparams, ", ") + ")")
+	if ftype.Results != nil {
+		b.WriteString(" " + gopSpan(src, rel(ftype.Results.Pos()), rel(ftype.Results.End()), edits))
+	}
+	b.WriteString(" " + gopSpan(src, rel(calleeDecl.Body.Lbrace), rel(calleeDecl.Body.End()), edits))
+	b.WriteString("(" + strings.Join(largs, ", "))
+	if call, ok := caller.Call.(*ast.CallExpr); ok && call.Ellipsis.IsValid() {
+		b.WriteString("...")
+	}
+	b.WriteString(")")
+	return &gopResult{old: caller.Call, new: gopReindent(b.String(), "", indent)}, nil
+}
+
+// gopStaticCallee returns the function expression, the arguments and the
+// callee of a static call, or a nil callee if the call is dynamic.
+func gopStaticCallee(info *typesutil.Info, call ast.Expr) (fun ast.Expr, args []ast.Expr, fn *types.Func) {
+	if c, ok := call.(*ast.CallExpr); ok {
+		fun, args = gopUnparen(c.Fun), c.Args
+	} else {
+		fun = call // auto-property
+	}
+	var id *ast.Ident
+	switch f := fun.(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[f]; ok && sel.Kind() == types.FieldVal {
+			return nil, nil, nil // call of a field of func type
+		}
+		id = f.Sel
+	default:
+		return nil, nil, nil
+	}
+	// For overloaded functions, Uses records the selected overload.
+	fn, _ = info.Uses[id].(*types.Func)
+	if fn == nil {
+		return nil, nil, nil
+	}
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil && types.IsInterface(recv.Type()) {
+		return nil, nil, nil // interface method
+	}
+	return fun, args, fn
+}
+
+// gopArguments returns the arguments of the call, including the
+// receiver of a method call, as the first element.
+func gopArguments(caller *GopCaller, conv *gopConverter, fun ast.Expr, callArgs []ast.Expr, fn *types.Func, assign1 func(*types.Var) bool) ([]*gopArgument, error) {
+	info := caller.Info
+	newArgument := func(expr ast.Expr) *gopArgument {
+		n := len(conv.implicit)
+		e := conv.expr(expr)
+		arg := &gopArgument{
+			argument: argument{
+				expr:       e,
+				typ:        info.TypeOf(expr),
+				pure:       pure(conv.info, assign1, e),
+				effects:    effects(conv.info, e),
+				duplicable: duplicable(conv.info, e),
+				freevars:   freeVars(conv.info, e),
+			},
+			text:    caller.text(expr),
+			primary: gopIsPrimary(expr),
+		}
+		// The implicit references to the members of the class
+		// are free too.
+		for _, id := range conv.implicit[n:] {
+			arg.freevars[id.Name] = true
+		}
+		return arg
+	}
+
+	var args []*gopArgument
+	sig := fn.Type().(*types.Signature)
+	if recv := sig.Recv(); recv != nil {
+		var arg *gopArgument
+		switch fun := fun.(type) {
+		case *ast.Ident:
+			// Implicit receiver of a member of a class: this.f(args)
+			this, ok := caller.lookup("this").(*types.Var)
+			if !ok {
+				return nil, fmt.Errorf("cannot inline: no receiver for call to method %s", fn.Name())
+			}
+			arg = &gopArgument{
+				argument: argument{
+					typ:        this.Type(),
+					pure:       assign1(this),
+					duplicable: true,
+					freevars:   map[string]bool{"this": true},
+				},
+				text:    "this",
+				primary: true,
+			}
+
+		case *ast.SelectorExpr:
+			seln, ok := info.Selections[fun]
+			if !ok {
+				// Go+ records no selection for an auto-property x.f.
+				if !gopAutoProperty(info, fun) || !types.Identical(deref(info.TypeOf(fun.X)), deref(recv.Type())) {
+					return nil, fmt.Errorf("cannot inline: unsupported call to method %s", fn.Name())
+				}
+				arg = newArgument(fun.X)
+				break
+			}
+			switch seln.Kind() {
+			case types.MethodVal: // recv.f(callArgs)
+				arg = newArgument(fun.X)
+
+				// Make field selections explicit (recv.f -> recv.y.f).
+				indices := seln.Index()
+				for _, index := range indices[:len(indices)-1] {
+					t := deref(arg.typ)
+					fld := t.Underlying().(*types.Struct).Field(index)
+					if fld.Pkg() != caller.Types && !fld.Exported() {
+						return nil, fmt.Errorf("in %s, implicit reference to unexported field .%s cannot be made explicit",
+							caller.text(fun), fld.Name())
+					}
+					if is[*types.Pointer](arg.typ.Underlying()) {
+						arg.pure = false // implicit *ptr operation => impure
+					}
+					arg.text = arg.paren() + "." + fld.Name()
+					arg.primary = true
+					arg.typ = fld.Type()
+					arg.duplicable = false
+				}
+			case types.MethodExpr: // T.f(recv, callArgs)
+				if len(callArgs) == 0 {
+					return nil, fmt.Errorf("cannot inline: missing receiver in call to method %s", fn.Name())
+				}
+				arg = newArgument(callArgs[0])
+				callArgs = callArgs[1:]
+			}
+		}
+		if arg == nil {
+			return nil, fmt.Errorf("cannot inline: unsupported call to method %s", fn.Name())
+		}
+
+		// Make * or & explicit.
+		argIsPtr := arg.typ != deref(arg.typ)
+		paramIsPtr := is[*types.Pointer](recv.Type())
+		if !argIsPtr && paramIsPtr {
+			// &recv
+			arg.text = "&" + arg.paren()
+			arg.primary = false
+			arg.typ = types.NewPointer(arg.typ)
+		} else if argIsPtr && !paramIsPtr {
+			// *recv
+			arg.text = "(*" + arg.text + ")"
+			arg.primary = true
+			arg.typ = deref(arg.typ)
+			arg.duplicable = false
+			arg.pure = false
+		}
+		args = append(args, arg)
+	}
+	for _, expr := range callArgs {
+		args = append(args, newArgument(expr))
+	}
+	return args, nil
+}
+
+// gopSubstitute is like substitute: it marks the arguments whose
+// parameters may be replaced by them, if effects permit.
+func gopSubstitute(logf func(string, ...any), caller *GopCaller, params []*paramInfo, args []*gopArgument, paramTypeExprs []ast.Expr) {
+next:
+	for i, param := range params {
+		arg := args[i]
+		if param.Escapes {
+			logf("keeping param %q: escapes from callee", param.Name)
+			continue
+		}
+		if param.Assigned {
+			logf("keeping param %q: assigned by callee", param.Name)
+			continue // callee needs the parameter variable
+		}
+		if len(param.Refs)+len(param.ImplicitRefs) > 1 && !arg.duplicable {
+			logf("keeping param %q: argument is not duplicable", param.Name)
+			continue // incorrect or poor style to duplicate an expression
+		}
+		if len(param.Refs)+len(param.ImplicitRefs) == 0 {
+			if arg.effects {
+				logf("keeping param %q: though unreferenced, it has effects", param.Name)
+				continue
+			}
+
+			// If the caller is within a function body,
+			// eliminating an unreferenced parameter might
+			// remove the last reference to a caller local var.
+			if caller.enclosingFunc != nil {
+				for free := range arg.freevars {
+					if v, ok := caller.lookup(free).(*types.Var); ok && gopWithin(v.Pos(), caller.enclosingFunc.Body) {
+						logf("keeping param %q: arg contains perhaps the last reference to possible caller local %v", param.Name, v)
+						continue next
+					}
+				}
+			}
+		}
+
+		// Check for shadowing of the free names of the argument,
+		// and of the type of its conversion, if any.
+		for free := range arg.freevars {
+			if param.Shadow[free] {
+				logf("keeping param %q: cannot replace with argument as it has free ref to %s that is shadowed", param.Name, free)
+				continue next // shadowing conflict
+			}
+		}
+		if typ := paramTypeExprs[i]; typ != nil {
+			// The callee syntax has no type information.
+			free := make(map[string]bool)
+			freeishNames(free, newGopConverter(nil, new(typesutil.Info), nil).expr(typ))
+			if intersects(free, param.Shadow) {
+				logf("keeping param %q: cannot replace with argument as its type is shadowed", param.Name)
+				continue
+			}
+		}
+
+		arg.substitutable = true // may be substituted, if effects permit
+	}
+}
+
+// gopArgs returns the analyses of the arguments, for resolveEffects.
+func gopArgs(args []*gopArgument) []*argument {
+	res := make([]*argument, len(args))
+	for i, arg := range args {
+		res[i] = &arg.argument
+	}
+	return res
+}
+
+// freeObjName returns the name by which the free object obj of the
+// callee is referenced from the caller.
+func (caller *GopCaller) freeObjName(callee *gobCallee, obj object) (string, error) {
+	switch {
+	case obj.Kind == "builtin" || obj.PkgPath == "":
+		// Go+ builtins and universal objects must not be shadowed.
+		if found := caller.lookup(obj.Name); found != nil && found.Parent() != types.Universe {
+			return "", fmt.Errorf("cannot inline: %s is shadowed in caller", obj.Name)
+		}
+		return obj.Name, nil
+
+	case obj.Kind == "pkgname":
+		name := caller.importName(obj.PkgPath)
+		if name == "" {
+			return "", fmt.Errorf("cannot inline: package %q is not imported by the caller", obj.PkgPath)
+		}
+		if obj.Shadow[name] {
+			return "", fmt.Errorf("cannot inline: import %s is shadowed in callee", name)
+		}
+		return name, nil
+
+	case obj.PkgPath == caller.Types.Path():
+		// The object must not be shadowed at the call site.
+		if found := caller.lookup(obj.Name); found == nil || found.Parent() != caller.Types.Scope() {
+			return "", fmt.Errorf("cannot inline: %s is shadowed in caller", obj.Name)
+		}
+		return obj.Name, nil
+
+	default:
+		// A package-level object of the callee package must be
+		// qualified by the import of that package.
+		name := caller.importName(obj.PkgPath)
+		if name == "" {
+			return "", fmt.Errorf("cannot inline: package %q is not imported by the caller", obj.PkgPath)
+		}
+		if obj.Shadow[name] {
+			return "", fmt.Errorf("cannot inline: import %s is shadowed in callee", name)
+		}
+		return name + "." + obj.Name, nil
+	}
+}
+
+// importName returns the name of the import of the package path in the
+// caller file, or "" if it is not imported. Dot imports are ignored.
+func (caller *GopCaller) importName(path string) string {
+	for _, imp := range caller.File.Imports {
+		var obj types.Object
+		if imp.Name != nil {
+			obj = caller.Info.Defs[imp.Name]
+		} else {
+			obj = caller.Info.Implicits[imp]
+		}
+		if pkgname, ok := obj.(*types.PkgName); ok && pkgname.Imported().Path() == path {
+			if name := pkgname.Name(); name != "_" && name != "." {
+				// The import must not be shadowed at the call site.
+				if caller.lookup(name) == obj {
+					return name
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// typeString returns the syntax of the type t in the caller file.
+func (caller *GopCaller) typeString(t types.Type) (string, error) {
+	var err error
+	s := types.TypeString(t, func(pkg *types.Package) string {
+		if pkg == caller.Types {
+			return ""
+		}
+		name := caller.importName(pkg.Path())
+		if name == "" && err == nil {
+			err = fmt.Errorf("cannot inline: package %q is not imported by the caller", pkg.Path())
+		}
+		return name
+	})
+	return s, err
+}
+
+// lookup does a symbol lookup in the lexical environment of the caller.
+//
+// Go+ doesn't link the file scope into the scopes of the functions of
+// the file, and doesn't record where the scope of a local variable
+// starts for all declarations, such as those in the main entry of a Go+
+// file: a local object declared after the call is ignored.
+func (caller *GopCaller) lookup(name string) types.Object {
+	pos := caller.Call.Pos()
+	for _, n := range caller.path {
+		scope := gopScopeFor(caller.Info, n)
+		if scope == nil {
+			continue
+		}
+		for ; scope != nil; scope = scope.Parent() {
+			s, obj := scope.LookupParent(name, pos)
+			if obj == nil || s == caller.Types.Scope() || s == types.Universe {
+				if fileScope := caller.Info.Scopes[caller.File]; fileScope != nil {
+					if obj := fileScope.Lookup(name); obj != nil {
+						return obj
+					}
+				}
+				return obj
+			}
+			if obj.Pos() < pos {
+				return obj
+			}
+			scope = s // declared after the call: look in the enclosing scope
+		}
+		return nil
+	}
+	return nil
+}
+
+// text returns the source text of the node n of the caller.
+func (caller *GopCaller) text(n ast.Node) string {
+	return string(caller.Content[offsetOf(caller.Fset, n.Pos()):offsetOf(caller.Fset, gopEnd(n))])
+}
+
+// gopEnd returns the end of n. Unlike n.End(), for a command-style call
+// such as "echo x" (or a statement of one), it is the end of the last
+// argument, not the position of the token after the call, which may
+// follow spaces or a comment.
+func gopEnd(n ast.Node) token.Pos {
+	if stmt, ok := n.(*ast.ExprStmt); ok {
+		n = stmt.X
+	}
+	call, ok := n.(*ast.CallExpr)
+	if !ok || !call.IsCommand() {
+		return n.End()
+	}
+	if call.Ellipsis.IsValid() {
+		return call.Ellipsis + token.Pos(len("..."))
+	}
+	if len(call.Args) > 0 {
+		return gopEnd(call.Args[len(call.Args)-1])
+	}
+	return call.Fun.End()
+}
+
+// paren returns the text of the argument, parenthesized if needed as
+// the operand of another expression.
+func (arg *gopArgument) paren() string {
+	if arg.primary {
+		return arg.text
+	}
+	return "(" + arg.text + ")"
+}
+
+// gopSpan returns the callee text between the FuncDecl-relative byte
+// offsets start and end, with the edits within this range applied.
+func gopSpan[T ~int](src []byte, start, end T, edits []gopEdit) string {
+	var inRange []gopEdit
+	for _, edit := range edits {
+		if int(start) <= edit.start && edit.end <= int(end) {
+			inRange = append(inRange, edit)
+		}
+	}
+	sort.Slice(inRange, func(i, j int) bool { return inRange[i].start < inRange[j].start })
+	var b strings.Builder
+	offset := int(start)
+	for _, edit := range inRange {
+		b.Write(src[offset:edit.start])
+		b.WriteString(edit.new)
+		offset = edit.end
+	}
+	b.Write(src[offset:end])
+	return b.String()
+}
+
+// gopIndent returns the indentation of the line containing offset.
+func gopIndent(content []byte, offset int) string {
+	start := offset
+	for start > 0 && content[start-1] != '\n' {
+		start--
+	}
+	end := start
+	for end < len(content) && (content[end] == ' ' || content[end] == '\t') {
+		end++
+	}
+	return string(content[start:end])
+}
+
+// gopReindent replaces the leading callee indentation of all lines of
+// text but the first by the caller indentation.
+func gopReindent(text, calleeIndent, callerIndent string) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = callerIndent + strings.TrimPrefix(lines[i], calleeIndent)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// gopNeedsParens reports whether a non-primary expression replacing the
+// call path[0] needs parens.
+func gopNeedsParens(path []ast.Node) bool {
+	if len(path) < 2 {
+		return false
+	}
+	switch parent := path[1].(type) {
+	case *ast.BinaryExpr, *ast.UnaryExpr, *ast.StarExpr, *ast.SelectorExpr,
+		*ast.IndexExpr, *ast.SliceExpr, *ast.TypeAssertExpr:
+		return true
+	case *ast.CallExpr:
+		return parent.Fun == path[0]
+	}
+	return false
+}
+
+// gopIsStmtList reports whether n holds a list of statements.
+func gopIsStmtList(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+		return true
+	case *ast.FuncDecl:
+		return n.Shadow // statements of the main function of a Go+ file
+	}
+	return false
+}
+
+// gopDeclaresNames reports whether the statements declare names in
+// their block.
+func gopDeclaresNames(stmts []ast.Stmt) bool {
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.DeclStmt, *ast.LabeledStmt:
+			return true
+		case *ast.AssignStmt:
+			if stmt.Tok == token.DEFINE {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// gopIsPrimary reports whether e is a primary expression, which needs
+// no parens as the operand of another expression.
+func gopIsPrimary(e ast.Expr) bool {
+	switch e.(type) {
+	case *ast.Ident, *ast.BasicLit, *ast.CallExpr, *ast.SelectorExpr,
+		*ast.IndexExpr, *ast.IndexListExpr, *ast.SliceExpr, *ast.TypeAssertExpr,
+		*ast.ParenExpr, *ast.CompositeLit, *ast.SliceLit:
+		return true
+	}
+	return false
+}
+
+// gopIsPrimaryType reports whether the type syntax t may be used as
+// the operand of a conversion without parens.
+func gopIsPrimaryType(t ast.Expr) bool {
+	switch t.(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr,
+		*ast.ArrayType, *ast.MapType, *ast.StructType, *ast.InterfaceType:
+		return true
+	}
+	return false
+}
+
+// gopTrivialConversion reports whether it is safe to omit the implicit
+// conversion of a value of type val to the parameter type. See trivialConversion.
+func gopTrivialConversion(val, param types.Type) bool {
+	return val != nil && types.Identical(types.Default(val), param)
+}
@@ -0,0 +1,288 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inline_test
+
+import (
+	"bytes"
+	"fmt"
+	goast "go/ast"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/go/expect"
+	"golang.org/x/tools/gop/ast/astutil"
+	"golang.org/x/tools/gop/packages"
+	"golang.org/x/tools/internal/diff"
+	"golang.org/x/tools/internal/refactor/inline"
+	"golang.org/x/tools/internal/testenv"
+	"golang.org/x/tools/txtar"
+)
+
+// TestGopData is like TestData, for the Go+ scenarios specified by files
+// in testdata/gop/*.txtar: the @inline notes are in Go+ files.
+//
+// Each package with Go+ files needs a gop_autogen.go file, as the
+// packages are loaded without generating their Go code.
+func TestGopData(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+	testenv.NeedsGOPROOT(t)
+
+	files, err := filepath.Glob("testdata/gop/*.txtar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			t.Parallel()
+
+			// Extract archive to temporary tree.
+			ar, err := txtar.ParseFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			if err := extractTxtar(ar, dir); err != nil {
+				t.Fatal(err)
+			}
+
+			// Load packages.
+			cfg := &packages.Config{
+				Dir:  dir,
+				Mode: packages.LoadAllSyntax,
+				Env: append(os.Environ(),
+					"GO111MODULES=on",
+					"GOPATH=",
+					"GOWORK=off",
+					"GOPROXY=off"),
+			}
+			pkgs, err := packages.Load(cfg, "./...")
+			if err != nil {
+				t.Errorf("Load: %v", err)
+			}
+			// Report parse/type errors; they may be benign.
+			packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+				for _, err := range pkg.Errors {
+					t.Log(err)
+				}
+			})
+
+			// Process @inline notes in comments in Go+ files of initial packages.
+			for _, pkg := range pkgs {
+				for _, file := range pkg.GopSyntax {
+					filename := pkg.Fset.File(file.Pos()).Name()
+					content, err := os.ReadFile(filename)
+					if err != nil {
+						t.Error(err)
+						continue
+					}
+
+					notes, err := expect.ExtractGop(pkg.Fset, file)
+					if err != nil {
+						t.Errorf("parsing notes in %q: %v", filename, err)
+						continue
+					}
+					for _, note := range notes {
+						posn := pkg.Fset.PositionFor(note.Pos, false)
+						if note.Name != "inline" {
+							t.Errorf("%s: invalid marker @%s", posn, note.Name)
+							continue
+						}
+						if nargs := len(note.Args); nargs != 2 {
+							t.Errorf("@inline: want 2 args, got %d", nargs)
+							continue
+						}
+						pattern, ok := note.Args[0].(*regexp.Regexp)
+						if !ok {
+							t.Errorf("%s: @inline(rx, want): want regular expression rx", posn)
+							continue
+						}
+
+						// want is a []byte (success) or *Regexp (failure)
+						var want any
+						switch x := note.Args[1].(type) {
+						case string, expect.Identifier:
+							for _, file := range ar.Files {
+								if file.Name == fmt.Sprint(x) {
+									want = file.Data
+									break
+								}
+							}
+							if want == nil {
+								t.Errorf("%s: @inline(rx, want): archive entry %q not found", posn, x)
+								continue
+							}
+						case *regexp.Regexp:
+							want = x
+						default:
+							t.Errorf("%s: @inline(rx, want): want file name (to assert success) or error message regexp (to assert failure)", posn)
+							continue
+						}
+						if err := doGopInlineNote(t.Logf, pkg, file, content, pattern, posn, want); err != nil {
+							t.Errorf("%s: @inline(%v, %v): %v", posn, note.Args[0], note.Args[1], err)
+							continue
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+// doGopInlineNote is like doInlineNote, for a call in a Go+ file.
+// The call enclosing the pattern match may be a command-style call,
+// or an auto-property that the pattern matches exactly.
+// The callee must be declared in the package of the caller.
+func doGopInlineNote(logf func(string, ...any), pkg *packages.Package, file *ast.File, content []byte, pattern *regexp.Regexp, posn token.Position, want any) error {
+	// Find extent of pattern match within commented line.
+	var startPos, endPos token.Pos
+	{
+		tokFile := pkg.Fset.File(file.Pos())
+		lineStartOffset := int(tokFile.LineStart(posn.Line)) - tokFile.Base()
+		line := content[lineStartOffset:]
+		if i := bytes.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i]
+		}
+		matches := pattern.FindSubmatchIndex(line)
+		var start, end int // offsets
+		switch len(matches) {
+		case 2:
+			// no subgroups: return the range of the regexp expression
+			start, end = matches[0], matches[1]
+		case 4:
+			// one subgroup: return its range
+			start, end = matches[2], matches[3]
+		default:
+			return fmt.Errorf("invalid location regexp %q: expect either 0 or 1 subgroups, got %d",
+				pattern, len(matches)/2-1)
+		}
+		startPos = tokFile.Pos(lineStartOffset + start)
+		endPos = tokFile.Pos(lineStartOffset + end)
+	}
+
+	// Find innermost call enclosing the pattern match,
+	// or the auto-property it matches.
+	var caller *inline.GopCaller
+	var id *ast.Ident
+	{
+		path, _ := astutil.PathEnclosingInterval(file, startPos, endPos)
+		var call ast.Expr
+		switch n := path[0].(type) {
+		case *ast.Ident:
+			if _, ok := path[1].(*ast.CallExpr); !ok && n.Pos() == startPos && n.End() == endPos {
+				call, id = n, n
+			}
+		case *ast.SelectorExpr:
+			if _, ok := path[1].(*ast.CallExpr); !ok && n.Pos() == startPos && n.End() == endPos {
+				call, id = n, n.Sel
+			}
+		}
+		if call == nil {
+			for _, n := range path {
+				if n, ok := n.(*ast.CallExpr); ok {
+					call = n
+					switch fun := astutil.Unparen(n.Fun).(type) {
+					case *ast.Ident:
+						id = fun
+					case *ast.SelectorExpr:
+						id = fun.Sel
+					}
+					break
+				}
+			}
+		}
+		if call == nil {
+			return fmt.Errorf("no enclosing call")
+		}
+		caller = &inline.GopCaller{
+			Fset:    pkg.Fset,
+			Types:   pkg.Types,
+			Info:    pkg.GopTypesInfo,
+			File:    file,
+			Call:    call,
+			Content: content,
+		}
+	}
+
+	// Is it a static function call?
+	// For overloaded functions, Uses records the selected overload.
+	var fn *types.Func
+	if id != nil {
+		fn, _ = caller.Info.Uses[id].(*types.Func)
+	}
+	if fn == nil {
+		return fmt.Errorf("cannot inline: not a static call")
+	}
+	if fn.Pkg() != caller.Types {
+		return fmt.Errorf("callee %v is not in the package of the caller", fn)
+	}
+
+	// Do the inlining. For the purposes of the test,
+	// GopAnalyzeCallee (or AnalyzeCallee) and GopInline are a single operation.
+	got, err := func() ([]byte, error) {
+		callee, err := analyzeGopCallee(logf, pkg, pkg.Fset.PositionFor(fn.Pos(), false))
+		if err != nil {
+			return nil, err
+		}
+		if err := checkTranscode(callee); err != nil {
+			return nil, err
+		}
+		return inline.GopInline(logf, caller, callee)
+	}()
+	if err != nil {
+		if wantRE, ok := want.(*regexp.Regexp); ok {
+			if !wantRE.MatchString(err.Error()) {
+				return fmt.Errorf("Inline failed with wrong error: %v (want error matching %q)", err, want)
+			}
+			return nil // expected error
+		}
+		return fmt.Errorf("Inline failed: %v", err) // success was expected
+	}
+
+	// Inline succeeded.
+	if want, ok := want.([]byte); ok {
+		got = append(bytes.TrimSpace(got), '\n')
+		want = append(bytes.TrimSpace(want), '\n')
+		if diff := diff.Unified("want", "got", string(want), string(got)); diff != "" {
+			return fmt.Errorf("Inline returned wrong output:\n%s\nWant:\n%s\nDiff:\n%s",
+				got, want, diff)
+		}
+		return nil
+	}
+	return fmt.Errorf("Inline succeeded unexpectedly: want error matching %q, got <<%s>>", want, got)
+}
+
+// analyzeGopCallee analyzes the function declared at posn, in a Go+ or a
+// Go file of pkg.
+func analyzeGopCallee(logf func(string, ...any), pkg *packages.Package, posn token.Position) (*inline.Callee, error) {
+	content, err := os.ReadFile(posn.Filename)
+	if err != nil {
+		return nil, err
+	}
+	same := func(pos token.Pos) bool {
+		posn2 := pkg.Fset.PositionFor(pos, false)
+		return posn.Filename == posn2.Filename && posn.Line == posn2.Line
+	}
+	for _, file := range pkg.GopSyntax {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && same(decl.Name.Pos()) {
+				return inline.GopAnalyzeCallee(logf, pkg.Fset, pkg.Types, pkg.GopTypesInfo, decl, content)
+			}
+		}
+	}
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*goast.FuncDecl); ok && same(decl.Name.Pos()) {
+				return inline.AnalyzeCallee(logf, pkg.Fset, pkg.Types, pkg.TypesInfo, decl, content)
+			}
+		}
+	}
+	return nil, fmt.Errorf("can't find FuncDecl at %v in package %q", posn, pkg.PkgPath)
+}
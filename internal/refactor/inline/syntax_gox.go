@@ -0,0 +1,733 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package inline
+
+// This file defines the conversion of Go+ syntax to Go syntax, so that
+// the analyses of Go callees and callers (such as escape, calleefx and
+// pure) apply to Go+ code too.
+
+import (
+	goast "go/ast"
+	gotoken "go/token"
+	"go/types"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+)
+
+// A gopConverter converts Go+ syntax to Go syntax of the same
+// positions, and records its type information in info.
+//
+// Go+ syntax without a Go counterpart is converted to Go syntax of the
+// same references and effects:
+//
+//   - a lambda x => e is converted to a function literal;
+//   - a slice literal [a, b] is converted to a composite literal;
+//   - a comprehension [e for x <- s, cond] is converted to a call of a
+//     function literal that ranges over s;
+//   - a for x <- s statement is converted to a range statement;
+//   - an auto-property x.f, which calls the method f, and any other
+//     expression of unknown effects, such as a string interpolation or
+//     x?, is converted to a call of an unknown function with the
+//     operands as arguments, as is an overloaded operator;
+//   - an implicit reference f to a field of a class is converted to
+//     *this, one to a method, to (*this).f, and one to an auto-property,
+//     to a call as above, where this is an identifier of the same name
+//     and position as f;
+//   - a reference to a Go+ builtin, such as echo, is converted to a
+//     reference to a universal object of that name.
+type gopConverter struct {
+	pkg  *types.Package
+	in   *typesutil.Info
+	info *types.Info
+	recv *types.Var // receiver of the implicit references to class members, or nil
+
+	implicit []*ast.Ident              // implicit references to class members, in order
+	builtins map[string]types.Object   // universal objects for Go+ builtins, by name
+	locals   map[*types.Var]*types.Var // positioned variables for local variables without position
+}
+
+func newGopConverter(pkg *types.Package, info *typesutil.Info, recv *types.Var) *gopConverter {
+	return &gopConverter{
+		pkg:  pkg,
+		in:   info,
+		recv: recv,
+		info: &types.Info{
+			Types:      make(map[goast.Expr]types.TypeAndValue),
+			Defs:       make(map[*goast.Ident]types.Object),
+			Uses:       make(map[*goast.Ident]types.Object),
+			Implicits:  make(map[goast.Node]types.Object),
+			Selections: make(map[*goast.SelectorExpr]*types.Selection),
+			Scopes:     make(map[goast.Node]*types.Scope),
+		},
+	}
+}
+
+// funcDecl converts a function declaration.
+func (c *gopConverter) funcDecl(decl *ast.FuncDecl) *goast.FuncDecl {
+	return &goast.FuncDecl{
+		Recv: c.fields(decl.Recv),
+		Name: c.ident(decl.Name),
+		Type: c.funcType(decl.Type),
+		Body: c.block(decl.Body),
+	}
+}
+
+// record records the type of the Go+ expression e for its Go counterpart g.
+func (c *gopConverter) record(e ast.Expr, g goast.Expr) {
+	if tv, ok := c.in.Types[e]; ok {
+		c.info.Types[g] = tv
+	}
+}
+
+// node records the scope and implicit object of the Go+ node n for its
+// Go counterpart g.
+func (c *gopConverter) node(n ast.Node, g goast.Node) {
+	if scope := c.in.Scopes[n]; scope != nil {
+		c.info.Scopes[g] = scope
+	}
+	if obj := c.in.Implicits[n]; obj != nil {
+		c.info.Implicits[g] = obj
+	}
+}
+
+// ident converts an identifier that is not an implicit reference to a
+// member of a class.
+func (c *gopConverter) ident(id *ast.Ident) *goast.Ident {
+	if id == nil {
+		return nil
+	}
+	g := &goast.Ident{NamePos: id.NamePos, Name: id.Name}
+	if obj := c.in.Defs[id]; obj != nil {
+		c.info.Defs[g] = c.local(id, obj)
+	}
+	if obj := c.in.Uses[id]; obj != nil {
+		// Go+ builtins denote objects of other packages,
+		// possibly of another name.
+		if _, ok := obj.(*types.PkgName); !ok &&
+			(id.Name != obj.Name() || obj.Pkg() != nil && obj.Pkg() != c.pkg) {
+			obj = c.builtin(id.Name, obj)
+		}
+		c.info.Uses[g] = c.local(id, obj)
+	}
+	c.record(id, g)
+	return g
+}
+
+// local returns the object that stands for the object of id.
+//
+// Some local variables of Go+, such as those of a comprehension, have
+// no position, so the analyses of Go would consider them free; such a
+// variable is replaced by one at the position of its scope.
+func (c *gopConverter) local(id *ast.Ident, obj types.Object) types.Object {
+	v, ok := obj.(*types.Var)
+	if !ok || v.Pos().IsValid() || v == c.recv || v.Parent() == nil ||
+		v.Parent() == types.Universe || v.Parent().Parent() == types.Universe {
+		return obj
+	}
+	if l, ok := c.locals[v]; ok {
+		return l
+	}
+	pos := v.Parent().Pos()
+	if !pos.IsValid() {
+		pos = id.Pos()
+	}
+	l := types.NewVar(pos, v.Pkg(), v.Name(), v.Type())
+	if c.locals == nil {
+		c.locals = make(map[*types.Var]*types.Var)
+	}
+	c.locals[v] = l
+	return l
+}
+
+// builtin returns the universal object that stands for the Go+ builtin
+// name, which denotes obj.
+func (c *gopConverter) builtin(name string, obj types.Object) types.Object {
+	if b, ok := c.builtins[name]; ok {
+		return b
+	}
+	// A builtin of Go, such as append, stands for itself.
+	b := types.Universe.Lookup(name)
+	if b == nil {
+		switch obj := obj.(type) {
+		case *types.Func:
+			b = types.NewFunc(token.NoPos, nil, name, obj.Type().(*types.Signature))
+		case *types.TypeName:
+			b = types.NewTypeName(token.NoPos, nil, name, obj.Type())
+		case *types.Const:
+			b = types.NewConst(token.NoPos, nil, name, obj.Type(), obj.Val())
+		default:
+			b = types.NewVar(token.NoPos, nil, name, obj.Type())
+		}
+	}
+	if c.builtins == nil {
+		c.builtins = make(map[string]types.Object)
+	}
+	c.builtins[name] = b
+	return b
+}
+
+// sel converts the identifier of a selection, or of a struct field key.
+// Go+ allows the name of an exported member to start with a lowercase
+// letter, so the Go identifier has the name of the member.
+func (c *gopConverter) sel(id *ast.Ident) *goast.Ident {
+	g := &goast.Ident{NamePos: id.NamePos, Name: id.Name}
+	if obj := c.in.Uses[id]; obj != nil {
+		g.Name = obj.Name()
+		c.info.Uses[g] = obj
+	}
+	c.record(id, g)
+	return g
+}
+
+// member converts an implicit reference to a member obj of a class.
+func (c *gopConverter) member(id *ast.Ident, obj types.Object) goast.Expr {
+	c.implicit = append(c.implicit, id)
+	this := &goast.Ident{NamePos: id.NamePos, Name: id.Name}
+	if c.recv != nil {
+		c.info.Uses[this] = c.recv
+		c.info.Types[this] = types.TypeAndValue{Type: c.recv.Type()}
+	}
+	star := &goast.StarExpr{Star: id.NamePos, X: this}
+	if isMethod(obj) {
+		if !is[*types.Signature](c.in.TypeOf(id)) {
+			// An implicit auto-property calls the method,
+			// which may take the address of the receiver.
+			if is[*types.Pointer](obj.Type().(*types.Signature).Recv().Type()) {
+				return c.opaque(id, this)
+			}
+			return c.opaque(id, star)
+		}
+		// A method is represented as a selection so that its
+		// calls are static calls.
+		g := &goast.SelectorExpr{X: star, Sel: c.sel(id)}
+		c.record(id, g)
+		return g
+	}
+	c.record(id, star)
+	return star
+}
+
+// opaque returns a call of an unknown function, with the args as
+// arguments, that stands for the Go+ expression e.
+func (c *gopConverter) opaque(e ast.Expr, args ...goast.Expr) *goast.CallExpr {
+	call := &goast.CallExpr{
+		Fun:    &goast.Ident{NamePos: e.Pos(), Name: "_"},
+		Args:   args,
+		Rparen: e.End() - 1,
+	}
+	tv, ok := c.in.Types[e]
+	if !ok {
+		tv.Type = types.Typ[types.Invalid]
+	}
+	tv.Value = nil // not a constant in Go
+	c.info.Types[call] = tv
+	return call
+}
+
+// operands converts the non-nil expressions.
+func (c *gopConverter) operands(list ...ast.Expr) []goast.Expr {
+	var res []goast.Expr
+	for _, e := range list {
+		if e != nil {
+			res = append(res, c.expr(e))
+		}
+	}
+	return res
+}
+
+func (c *gopConverter) exprs(list []ast.Expr) []goast.Expr {
+	var res []goast.Expr
+	for _, e := range list {
+		res = append(res, c.expr(e))
+	}
+	return res
+}
+
+// expr converts an expression (or type).
+func (c *gopConverter) expr(e ast.Expr) goast.Expr {
+	if e == nil {
+		return nil
+	}
+	g := c.expr1(e)
+	if _, ok := c.info.Types[g]; !ok {
+		c.record(e, g)
+	}
+	return g
+}
+
+func (c *gopConverter) expr1(e ast.Expr) goast.Expr {
+	switch e := e.(type) {
+	case *ast.Ident:
+		if obj := c.in.Uses[e]; isField(obj) || isMethod(obj) {
+			return c.member(e, obj)
+		}
+		return c.ident(e)
+
+	case *ast.BasicLit:
+		if e.Extra != nil {
+			// A string interpolation "...${x}..." formats its
+			// operands, possibly calling their String methods.
+			var args []goast.Expr
+			for _, part := range e.Extra.Parts {
+				if x, ok := part.(ast.Expr); ok {
+					args = append(args, c.expr(x))
+				}
+			}
+			return c.opaque(e, args...)
+		}
+		switch e.Kind {
+		case token.INT, token.FLOAT, token.IMAG, token.CHAR, token.STRING:
+			return &goast.BasicLit{ValuePos: e.ValuePos, Kind: gotoken.Token(e.Kind), Value: e.Value}
+		}
+		return c.opaque(e) // e.g. 1r
+
+	case *ast.FuncLit:
+		return &goast.FuncLit{Type: c.funcType(e.Type), Body: c.block(e.Body)}
+
+	case *ast.LambdaExpr:
+		body := &goast.BlockStmt{
+			Lbrace: e.Rarrow,
+			List:   []goast.Stmt{&goast.ReturnStmt{Return: e.Rarrow, Results: c.exprs(e.Rhs)}},
+			Rbrace: e.End() - 1,
+		}
+		return c.lambda(e, e.Lhs, body)
+
+	case *ast.LambdaExpr2:
+		return c.lambda(e, e.Lhs, c.block(e.Body))
+
+	case *ast.CompositeLit:
+		g := &goast.CompositeLit{
+			Type:       c.expr(e.Type),
+			Lbrace:     e.Lbrace,
+			Rbrace:     e.Rbrace,
+			Incomplete: e.Incomplete,
+		}
+		isStruct := false
+		if t := c.in.TypeOf(e); t != nil {
+			_, isStruct = deref(t).Underlying().(*types.Struct)
+		}
+		for _, elt := range e.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok && isStruct {
+				if key, ok := kv.Key.(*ast.Ident); ok {
+					g.Elts = append(g.Elts, &goast.KeyValueExpr{Key: c.sel(key), Colon: kv.Colon, Value: c.expr(kv.Value)})
+					continue
+				}
+			}
+			g.Elts = append(g.Elts, c.expr(elt))
+		}
+		return g
+
+	case *ast.SliceLit:
+		return &goast.CompositeLit{
+			Lbrace:     e.Lbrack,
+			Elts:       c.exprs(e.Elts),
+			Rbrace:     e.Rbrack,
+			Incomplete: e.Incomplete,
+		}
+
+	case *ast.ComprehensionExpr:
+		// [elt for x <- s, cond] is like
+		// func() { for _, x := range s { if cond { _ = elt } } }().
+		blank := func() goast.Expr { return &goast.Ident{Name: "_"} }
+		var stmt goast.Stmt = &goast.EmptyStmt{Implicit: true}
+		switch elt := e.Elt.(type) {
+		case nil:
+		case *ast.KeyValueExpr:
+			stmt = &goast.AssignStmt{
+				Lhs: []goast.Expr{blank(), blank()},
+				Tok: gotoken.ASSIGN,
+				Rhs: []goast.Expr{c.expr(elt.Key), c.expr(elt.Value)},
+			}
+		default:
+			stmt = &goast.AssignStmt{
+				Lhs: []goast.Expr{blank()},
+				Tok: gotoken.ASSIGN,
+				Rhs: []goast.Expr{c.expr(elt)},
+			}
+		}
+		for i := len(e.Fors) - 1; i >= 0; i-- {
+			stmt = c.forPhrase(e.Fors[i], &goast.BlockStmt{List: []goast.Stmt{stmt}})
+		}
+		lit := &goast.FuncLit{
+			Type: &goast.FuncType{Func: e.Lpos, Params: &goast.FieldList{}},
+			Body: &goast.BlockStmt{Lbrace: e.Lpos, List: []goast.Stmt{stmt}, Rbrace: e.Rpos},
+		}
+		call := &goast.CallExpr{Fun: lit, Lparen: e.Rpos, Rparen: e.Rpos}
+		if t := c.comprehensionType(e); t != nil {
+			c.info.Types[call] = types.TypeAndValue{Type: t}
+		}
+		return call
+
+	case *ast.ErrWrapExpr:
+		// x! panics and x? returns on error.
+		return c.opaque(e, c.operands(e.X, e.Default)...)
+
+	case *ast.RangeExpr:
+		return c.opaque(e, c.operands(e.First, e.Last, e.Expr3)...)
+
+	case *ast.ParenExpr:
+		return &goast.ParenExpr{Lparen: e.Lparen, X: c.expr(e.X), Rparen: e.Rparen}
+
+	case *ast.SelectorExpr:
+		if gopAutoProperty(c.in, e) {
+			// An auto-property x.f calls the method f,
+			// which may take the address of x.
+			x := c.expr(e.X)
+			recv := c.in.Uses[e.Sel].Type().(*types.Signature).Recv()
+			if t := c.in.TypeOf(e.X); t != nil && deref(t) == t && is[*types.Pointer](recv.Type()) {
+				x = &goast.UnaryExpr{OpPos: e.X.Pos(), Op: gotoken.AND, X: x}
+			}
+			return c.opaque(e, x)
+		}
+		g := &goast.SelectorExpr{X: c.expr(e.X), Sel: c.sel(e.Sel)}
+		if sel, ok := c.in.Selections[e]; ok {
+			c.info.Selections[g] = sel
+		}
+		return g
+
+	case *ast.IndexExpr:
+		return &goast.IndexExpr{X: c.expr(e.X), Lbrack: e.Lbrack, Index: c.expr(e.Index), Rbrack: e.Rbrack}
+
+	case *ast.IndexListExpr:
+		return &goast.IndexListExpr{X: c.expr(e.X), Lbrack: e.Lbrack, Indices: c.exprs(e.Indices), Rbrack: e.Rbrack}
+
+	case *ast.SliceExpr:
+		return &goast.SliceExpr{
+			X:      c.expr(e.X),
+			Lbrack: e.Lbrack,
+			Low:    c.expr(e.Low),
+			High:   c.expr(e.High),
+			Max:    c.expr(e.Max),
+			Slice3: e.Slice3,
+			Rbrack: e.Rbrack,
+		}
+
+	case *ast.TypeAssertExpr:
+		return &goast.TypeAssertExpr{X: c.expr(e.X), Lparen: e.Lparen, Type: c.expr(e.Type), Rparen: e.Rparen}
+
+	case *ast.CallExpr:
+		return c.call(e)
+
+	case *ast.StarExpr:
+		return &goast.StarExpr{Star: e.Star, X: c.expr(e.X)}
+
+	case *ast.UnaryExpr:
+		switch e.Op {
+		case token.AND, token.ARROW:
+		default:
+			if c.overloaded(e.X) {
+				return c.opaque(e, c.expr(e.X))
+			}
+		}
+		return &goast.UnaryExpr{OpPos: e.OpPos, Op: gotoken.Token(e.Op), X: c.expr(e.X)}
+
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.EQL, token.NEQ, token.LAND, token.LOR:
+		default:
+			if c.overloaded(e.X) {
+				return c.opaque(e, c.expr(e.X), c.expr(e.Y))
+			}
+		}
+		return &goast.BinaryExpr{X: c.expr(e.X), OpPos: e.OpPos, Op: gotoken.Token(e.Op), Y: c.expr(e.Y)}
+
+	case *ast.KeyValueExpr:
+		return &goast.KeyValueExpr{Key: c.expr(e.Key), Colon: e.Colon, Value: c.expr(e.Value)}
+
+	case *ast.Ellipsis:
+		return &goast.Ellipsis{Ellipsis: e.Ellipsis, Elt: c.expr(e.Elt)}
+
+	case *ast.ArrayType:
+		return &goast.ArrayType{Lbrack: e.Lbrack, Len: c.expr(e.Len), Elt: c.expr(e.Elt)}
+
+	case *ast.StructType:
+		return &goast.StructType{Struct: e.Struct, Fields: c.fields(e.Fields), Incomplete: e.Incomplete}
+
+	case *ast.FuncType:
+		return c.funcType(e)
+
+	case *ast.InterfaceType:
+		return &goast.InterfaceType{Interface: e.Interface, Methods: c.fields(e.Methods), Incomplete: e.Incomplete}
+
+	case *ast.MapType:
+		return &goast.MapType{Map: e.Map, Key: c.expr(e.Key), Value: c.expr(e.Value)}
+
+	case *ast.ChanType:
+		return &goast.ChanType{Begin: e.Begin, Arrow: e.Arrow, Dir: goast.ChanDir(e.Dir), Value: c.expr(e.Value)}
+
+	case *ast.BadExpr:
+		return &goast.BadExpr{From: e.From, To: e.To}
+	}
+	return c.opaque(e)
+}
+
+// overloaded reports whether an operator applied to the operand x
+// comprehensionType returns the type of the comprehension e, which the
+// Go+ type checker does not record, or nil if unknown.
+func (c *gopConverter) comprehensionType(e *ast.ComprehensionExpr) types.Type {
+	typeOf := func(e ast.Expr) types.Type {
+		if t := c.in.TypeOf(e); t != nil {
+			return types.Default(t)
+		}
+		return nil
+	}
+	switch elt := e.Elt.(type) {
+	case nil:
+		// {for x <- s, cond} reports whether an element exists.
+		return types.Typ[types.Bool]
+	case *ast.KeyValueExpr:
+		if k, v := typeOf(elt.Key), typeOf(elt.Value); k != nil && v != nil {
+			return types.NewMap(k, v)
+		}
+	default:
+		if t := typeOf(elt); t != nil {
+			if e.Tok == token.LBRACK {
+				return types.NewSlice(t)
+			}
+			return t // {elt for x <- s, cond} selects an element
+		}
+	}
+	return nil
+}
+
+// denotes a call of an overloaded operator method.
+func (c *gopConverter) overloaded(x ast.Expr) bool {
+	t := c.in.TypeOf(x)
+	return t != nil && !is[*types.Basic](t.Underlying())
+}
+
+func (c *gopConverter) call(e *ast.CallExpr) *goast.CallExpr {
+	rparen := e.Rparen
+	if !rparen.IsValid() {
+		rparen = gopEnd(e) - 1 // command-style call
+	}
+	g := &goast.CallExpr{
+		Fun:      c.expr(e.Fun),
+		Lparen:   e.Lparen,
+		Args:     c.exprs(e.Args),
+		Ellipsis: e.Ellipsis,
+		Rparen:   rparen,
+	}
+	c.record(e, g)
+	return g
+}
+
+// lambda converts the lambda e with the params and the (converted) body
+// to a function literal.
+func (c *gopConverter) lambda(e ast.Expr, params []*ast.Ident, body *goast.BlockStmt) *goast.FuncLit {
+	ftype := &goast.FuncType{Func: e.Pos(), Params: &goast.FieldList{}}
+	for _, name := range params {
+		ftype.Params.List = append(ftype.Params.List, &goast.Field{Names: []*goast.Ident{c.ident(name)}})
+	}
+	// As for a function literal, the scope is that of the type.
+	if scope := c.in.Scopes[e]; scope != nil {
+		c.info.Scopes[ftype] = scope
+	}
+	return &goast.FuncLit{Type: ftype, Body: body}
+}
+
+// forPhrase converts the phrase "for k, v <- x, init; cond" that
+// encloses the (converted) body.
+func (c *gopConverter) forPhrase(f *ast.ForPhrase, body *goast.BlockStmt) *goast.RangeStmt {
+	if f.Cond != nil {
+		body = &goast.BlockStmt{List: []goast.Stmt{&goast.IfStmt{
+			If:   f.IfPos,
+			Init: c.stmt(f.Init),
+			Cond: c.expr(f.Cond),
+			Body: body,
+		}}}
+	}
+	g := &goast.RangeStmt{For: f.For, TokPos: f.TokPos, X: c.expr(f.X), Body: body}
+	if f.Key != nil {
+		g.Key = c.ident(f.Key)
+	}
+	if f.Value != nil {
+		g.Value = c.ident(f.Value)
+	}
+	if g.Key != nil || g.Value != nil {
+		g.Tok = gotoken.DEFINE
+	}
+	c.node(f, g)
+	return g
+}
+
+func (c *gopConverter) funcType(t *ast.FuncType) *goast.FuncType {
+	g := &goast.FuncType{
+		Func:       t.Func,
+		TypeParams: c.fields(t.TypeParams),
+		Params:     c.fields(t.Params),
+		Results:    c.fields(t.Results),
+	}
+	if g.Params == nil {
+		g.Params = &goast.FieldList{}
+	}
+	c.record(t, g)
+	c.node(t, g)
+	return g
+}
+
+func (c *gopConverter) fields(list *ast.FieldList) *goast.FieldList {
+	if list == nil {
+		return nil
+	}
+	g := &goast.FieldList{Opening: list.Opening, Closing: list.Closing}
+	for _, field := range list.List {
+		gfield := &goast.Field{Type: c.expr(field.Type)}
+		for _, name := range field.Names {
+			gfield.Names = append(gfield.Names, c.ident(name))
+		}
+		if tag := field.Tag; tag != nil {
+			gfield.Tag = &goast.BasicLit{ValuePos: tag.ValuePos, Kind: gotoken.STRING, Value: tag.Value}
+		}
+		g.List = append(g.List, gfield)
+	}
+	return g
+}
+
+func (c *gopConverter) block(b *ast.BlockStmt) *goast.BlockStmt {
+	if b == nil {
+		return nil
+	}
+	g := &goast.BlockStmt{Lbrace: b.Lbrace, List: c.stmts(b.List), Rbrace: b.Rbrace}
+	c.node(b, g)
+	return g
+}
+
+func (c *gopConverter) stmts(list []ast.Stmt) []goast.Stmt {
+	var res []goast.Stmt
+	for _, s := range list {
+		res = append(res, c.stmt(s))
+	}
+	return res
+}
+
+// stmt converts a statement.
+func (c *gopConverter) stmt(s ast.Stmt) goast.Stmt {
+	if s == nil {
+		return nil
+	}
+	g := c.stmt1(s)
+	c.node(s, g)
+	return g
+}
+
+func (c *gopConverter) stmt1(s ast.Stmt) goast.Stmt {
+	switch s := s.(type) {
+	case *ast.DeclStmt:
+		if decl, ok := s.Decl.(*ast.GenDecl); ok {
+			return &goast.DeclStmt{Decl: c.genDecl(decl)}
+		}
+
+	case *ast.EmptyStmt:
+		return &goast.EmptyStmt{Semicolon: s.Semicolon, Implicit: s.Implicit}
+
+	case *ast.LabeledStmt:
+		return &goast.LabeledStmt{Label: c.ident(s.Label), Colon: s.Colon, Stmt: c.stmt(s.Stmt)}
+
+	case *ast.ExprStmt:
+		return &goast.ExprStmt{X: c.expr(s.X)}
+
+	case *ast.SendStmt:
+		return &goast.SendStmt{Chan: c.expr(s.Chan), Arrow: s.Arrow, Value: c.expr(s.Value)}
+
+	case *ast.IncDecStmt:
+		return &goast.IncDecStmt{X: c.expr(s.X), TokPos: s.TokPos, Tok: gotoken.Token(s.Tok)}
+
+	case *ast.AssignStmt:
+		return &goast.AssignStmt{Lhs: c.exprs(s.Lhs), TokPos: s.TokPos, Tok: gotoken.Token(s.Tok), Rhs: c.exprs(s.Rhs)}
+
+	case *ast.GoStmt:
+		return &goast.GoStmt{Go: s.Go, Call: c.call(s.Call)}
+
+	case *ast.DeferStmt:
+		return &goast.DeferStmt{Defer: s.Defer, Call: c.call(s.Call)}
+
+	case *ast.ReturnStmt:
+		return &goast.ReturnStmt{Return: s.Return, Results: c.exprs(s.Results)}
+
+	case *ast.BranchStmt:
+		return &goast.BranchStmt{TokPos: s.TokPos, Tok: gotoken.Token(s.Tok), Label: c.ident(s.Label)}
+
+	case *ast.BlockStmt:
+		return c.block(s)
+
+	case *ast.IfStmt:
+		return &goast.IfStmt{If: s.If, Init: c.stmt(s.Init), Cond: c.expr(s.Cond), Body: c.block(s.Body), Else: c.stmt(s.Else)}
+
+	case *ast.CaseClause:
+		return &goast.CaseClause{Case: s.Case, List: c.exprs(s.List), Colon: s.Colon, Body: c.stmts(s.Body)}
+
+	case *ast.SwitchStmt:
+		return &goast.SwitchStmt{Switch: s.Switch, Init: c.stmt(s.Init), Tag: c.expr(s.Tag), Body: c.block(s.Body)}
+
+	case *ast.TypeSwitchStmt:
+		return &goast.TypeSwitchStmt{Switch: s.Switch, Init: c.stmt(s.Init), Assign: c.stmt(s.Assign), Body: c.block(s.Body)}
+
+	case *ast.CommClause:
+		return &goast.CommClause{Case: s.Case, Comm: c.stmt(s.Comm), Colon: s.Colon, Body: c.stmts(s.Body)}
+
+	case *ast.SelectStmt:
+		return &goast.SelectStmt{Select: s.Select, Body: c.block(s.Body)}
+
+	case *ast.ForStmt:
+		return &goast.ForStmt{For: s.For, Init: c.stmt(s.Init), Cond: c.expr(s.Cond), Post: c.stmt(s.Post), Body: c.block(s.Body)}
+
+	case *ast.RangeStmt:
+		return &goast.RangeStmt{
+			For:    s.For,
+			Key:    c.expr(s.Key),
+			Value:  c.expr(s.Value),
+			TokPos: s.TokPos,
+			Tok:    gotoken.Token(s.Tok),
+			X:      c.expr(s.X),
+			Body:   c.block(s.Body),
+		}
+
+	case *ast.ForPhraseStmt:
+		return c.forPhrase(s.ForPhrase, c.block(s.Body))
+
+	case *ast.BadStmt:
+		return &goast.BadStmt{From: s.From, To: s.To}
+	}
+	// A statement of unknown effects.
+	return &goast.ExprStmt{X: &goast.CallExpr{
+		Fun:    &goast.Ident{NamePos: s.Pos(), Name: "_"},
+		Rparen: s.End() - 1,
+	}}
+}
+
+func (c *gopConverter) genDecl(decl *ast.GenDecl) *goast.GenDecl {
+	g := &goast.GenDecl{TokPos: decl.TokPos, Tok: gotoken.Token(decl.Tok), Lparen: decl.Lparen, Rparen: decl.Rparen}
+	for _, spec := range decl.Specs {
+		switch spec := spec.(type) {
+		case *ast.ValueSpec:
+			gspec := &goast.ValueSpec{Type: c.expr(spec.Type), Values: c.exprs(spec.Values)}
+			for _, name := range spec.Names {
+				gspec.Names = append(gspec.Names, c.ident(name))
+			}
+			g.Specs = append(g.Specs, gspec)
+
+		case *ast.TypeSpec:
+			gspec := &goast.TypeSpec{
+				Name:       c.ident(spec.Name),
+				TypeParams: c.fields(spec.TypeParams),
+				Assign:     spec.Assign,
+				Type:       c.expr(spec.Type),
+			}
+			c.node(spec, gspec)
+			g.Specs = append(g.Specs, gspec)
+		}
+	}
+	return g
+}
+
+// gopAutoProperty reports whether the selector e is a Go+ auto-property
+// x.f, which calls the method f without parens.
+func gopAutoProperty(info *typesutil.Info, e *ast.SelectorExpr) bool {
+	fn, ok := info.Uses[e.Sel].(*types.Func)
+	return ok && fn.Type().(*types.Signature).Recv() != nil && !is[*types.Signature](info.TypeOf(e))
+}
@@ -0,0 +1,66 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package testenv
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var (
+	goprootOnce sync.Once
+	goprootErr  error
+)
+
+// findGOPROOT makes sure GOPROOT names the root of the Go+ toolchain,
+// which the gop library needs to generate Go code from Go+ source.
+// If GOPROOT is not set, it uses the directory of the github.com/goplus/gop
+// module required by the current module, as found in the module cache.
+func findGOPROOT() error {
+	goprootOnce.Do(func() {
+		if os.Getenv("GOPROOT") != "" {
+			return
+		}
+		cmd := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", "github.com/goplus/gop")
+		cmd.Env = append(os.Environ(), "GO111MODULE=on") // some tests run in GOPATH mode
+		out, err := cmd.Output()
+		if err != nil {
+			if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+				err = fmt.Errorf("%v\n%s", err, ee.Stderr)
+			}
+			goprootErr = fmt.Errorf("%v: %v", cmd, err)
+			return
+		}
+		dir := strings.TrimSpace(string(out))
+		if dir == "" {
+			goprootErr = fmt.Errorf("%v: module github.com/goplus/gop is not in the module cache", cmd)
+			return
+		}
+		if _, err := os.Stat(filepath.Join(dir, "cmd", "gop")); err != nil {
+			goprootErr = fmt.Errorf("%s is not a valid GOPROOT: %v", dir, err)
+			return
+		}
+		goprootErr = os.Setenv("GOPROOT", dir)
+	})
+	return goprootErr
+}
+
+// NeedsGOPROOT skips t if the root of the Go+ toolchain cannot be found.
+// Unless GOPROOT is already set, it sets GOPROOT for the rest of the
+// process (including its child processes) to the directory of the
+// github.com/goplus/gop module required by the current module.
+func NeedsGOPROOT(t testing.TB) {
+	t.Helper()
+
+	NeedsTool(t, "go")
+	if err := findGOPROOT(); err != nil {
+		t.Skipf("skipping because GOPROOT is not available: %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rename
+
+// This file defines the renaming of references in Go+ source files.
+
+import (
+	"fmt"
+	"go/build"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/go/types/objectpath"
+	"golang.org/x/tools/gop/goputil"
+	"golang.org/x/tools/gop/packages"
+)
+
+// Gop enables the renaming of references in the Go+ source files
+// (.gop, .gox, ...) of the affected packages too.
+//
+// The Go+ packages are loaded through golang.org/x/tools/gop/packages.
+// The Go code generated from their Go+ files (gop_autogen.go) is not
+// renamed but generated anew from the renamed Go+ source, as configured
+// by packages.SetGenGoMode.
+var Gop bool
+
+// isGopAutogen reports whether Gop is set and pos is in a Go file
+// generated from Go+ files.
+func (r *renamer) isGopAutogen(pos token.Pos) bool {
+	if !Gop {
+		return false
+	}
+	return strings.HasPrefix(filepath.Base(r.iprog.Fset.File(pos).Name()), "gop_autogen")
+}
+
+// A gopEdit replaces the identifier at offset in a Go+ file.
+type gopEdit struct {
+	offset int
+	old    string // current name
+	new    string // new name
+}
+
+// updateGop renames the references to the objects to update in the Go+
+// files of the packages to inspect, and reports the number of renamed
+// identifiers, updated files and updated packages not in updated, the
+// set of paths of packages whose Go files were updated.
+//
+// Go+ refers to a Go object by its name, by the lowercase form of an
+// exported name (Println as println), and to the overloads Foo__0,
+// Foo__1, ... of a function by the name Foo (or foo). Each reference is
+// renamed in the same form.
+func (r *renamer) updateGop(updated map[string]bool) (nidents, nfiles, npkgs int, err error) {
+	// The Go+ packages are type-checked anew, so the objects to update
+	// are identified by package path and object path.
+	type key struct {
+		pkg  string
+		path objectpath.Path
+	}
+	keys := make(map[key]bool)
+	for obj := range r.objsToUpdate {
+		if obj.Pkg() == nil {
+			continue
+		}
+		if path, err := objectpath.For(obj); err == nil {
+			keys[key{obj.Pkg().Path(), path}] = true
+		}
+	}
+	if len(keys) == 0 {
+		return 0, 0, 0, nil
+	}
+	shouldUpdate := func(obj types.Object) bool {
+		if obj == nil || obj.Pkg() == nil {
+			return false
+		}
+		path, err := objectpath.For(obj)
+		return err == nil && keys[key{obj.Pkg().Path(), path}]
+	}
+
+	// Load the Go+ view of the packages to inspect.
+	seen := make(map[string]bool)
+	var patterns []string
+	for pkg := range r.packages {
+		path := strings.TrimSuffix(pkg.Path(), "_test") // external tests
+		if !seen[path] {
+			seen[path] = true
+			patterns = append(patterns, path)
+		}
+	}
+	sort.Strings(patterns)
+	cfg := &packages.Config{
+		Mode:  packages.LoadSyntax,
+		Tests: true,
+		Env:   gopEnv(r.ctxt),
+	}
+	if len(r.ctxt.BuildTags) > 0 {
+		cfg.BuildFlags = []string{"-tags=" + strings.Join(r.ctxt.BuildTags, ",")}
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	// Find the Go+ identifiers that define or use a renamed object.
+	// A file may belong to several package variants, so edits are
+	// keyed by file name and offset.
+	edits := make(map[string]map[int]gopEdit)
+	pkgOf := make(map[string]string) // file name -> package path
+	for _, pkg := range pkgs {
+		info := pkg.GopTypesInfo
+		if info == nil {
+			continue
+		}
+		add := func(id *ast.Ident, obj types.Object) {
+			posn := pkg.Fset.Position(id.Pos())
+			name, ok := gopNewName(id.Name, obj.Name(), r.to)
+			if !ok {
+				reportError(posn, fmt.Sprintf("cannot rename Go+ reference %s to %s", id.Name, obj.Name()))
+				return
+			}
+			m := edits[posn.Filename]
+			if m == nil {
+				m = make(map[int]gopEdit)
+				edits[posn.Filename] = m
+				pkgOf[posn.Filename] = strings.TrimSuffix(pkg.PkgPath, "_test")
+			}
+			m[posn.Offset] = gopEdit{posn.Offset, id.Name, name}
+		}
+		for id, obj := range info.Defs {
+			if !shouldUpdate(obj) {
+				continue
+			}
+			add(id, obj)
+
+			// Perform the rename in doc comments too.
+			if tf, doc := gopDocComment(pkg, id); doc != nil {
+				for _, comment := range doc.List {
+					for _, pos := range goputil.NameRefs(tf, comment, r.from) {
+						posn := pkg.Fset.Position(pos)
+						if m := edits[posn.Filename]; m != nil {
+							m[posn.Offset] = gopEdit{posn.Offset, r.from, r.to}
+						}
+					}
+				}
+			}
+		}
+		for id, obj := range info.Uses {
+			if shouldUpdate(obj) {
+				add(id, obj)
+			}
+		}
+	}
+
+	// Write affected files.
+	var filenames []string
+	for filename := range edits {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	var nerrs int
+	dirs := make(map[string]bool)
+	for _, filename := range filenames {
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			log.Print(err)
+			nerrs++
+			continue
+		}
+		list := make([]gopEdit, 0, len(edits[filename]))
+		for _, edit := range edits[filename] {
+			list = append(list, edit)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].offset > list[j].offset })
+		for _, edit := range list {
+			end := edit.offset + len(edit.old)
+			if end > len(content) || string(content[edit.offset:end]) != edit.old {
+				log.Printf("%s: stale Go+ syntax at offset %d", filename, edit.offset)
+				nerrs++
+				continue
+			}
+			content = append(content[:edit.offset], append([]byte(edit.new), content[end:]...)...)
+		}
+		if Verbose {
+			log.Printf("Updating Go+ file %s", filename)
+		}
+		if err := writeFile(filename, content); err != nil {
+			log.Print(err)
+			nerrs++
+			continue
+		}
+		nidents += len(list)
+		nfiles++
+		dirs[filepath.Dir(filename)] = true
+		if path := pkgOf[filename]; !updated[path] {
+			updated[path] = true
+			npkgs++
+		}
+	}
+
+	// Generate the Go code of the updated Go+ packages anew.
+	if !Diff && len(dirs) > 0 {
+		var patterns []string
+		for dir := range dirs {
+			patterns = append(patterns, dir)
+		}
+		sort.Strings(patterns)
+		if _, err := packages.GenGo(patterns...); err != nil {
+			log.Print(err)
+			nerrs++
+		}
+	}
+	if nerrs > 0 {
+		return nidents, nfiles, npkgs, fmt.Errorf("failed to rewrite %d Go+ file%s", nerrs, plural(nerrs))
+	}
+	return nidents, nfiles, npkgs, nil
+}
+
+// gopNewName returns the new spelling of identifier id, a Go+ reference
+// to an object renamed from "from" to "to". It reports false if id is
+// not a known form of a reference to the object.
+func gopNewName(id, from, to string) (string, bool) {
+	if id == from {
+		return to, true
+	}
+	from, _ = goputil.OverloadName(from)
+	to, _ = goputil.OverloadName(to)
+	if id == from {
+		return to, true
+	}
+	if lower, ok := goputil.ToStartWithLowerCase(from); ok && id == lower {
+		to, _ = goputil.ToStartWithLowerCase(to)
+		return to, true
+	}
+	return "", false
+}
+
+// gopDocComment returns the doc for an identifier defined in a Go+ file
+// of package pkg, and the token file of the Go+ file.
+func gopDocComment(pkg *packages.Package, id *ast.Ident) (*token.File, *ast.CommentGroup) {
+	for _, f := range pkg.GopSyntax {
+		if f.Pos() <= id.Pos() && id.Pos() <= f.End() {
+			tf := pkg.Fset.File(f.Pos())
+			return tf, goputil.DocComment(tf, f, id)
+		}
+	}
+	return nil, nil
+}
+
+// gopEnv returns the environment of the go command that loads the Go+
+// packages of the workspace described by ctxt.
+func gopEnv(ctxt *build.Context) []string {
+	env := append(os.Environ(),
+		"GOPATH="+ctxt.GOPATH,
+		"GOOS="+ctxt.GOOS,
+		"GOARCH="+ctxt.GOARCH,
+	)
+	if ctxt.GOROOT != "" {
+		env = append(env, "GOROOT="+ctxt.GOROOT)
+	}
+	if !ctxt.CgoEnabled {
+		env = append(env, "CGO_ENABLED=0")
+	}
+	return env
+}
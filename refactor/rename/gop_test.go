@@ -0,0 +1,131 @@
+// Copyright 2024 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rename
+
+import (
+	"go/build"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/internal/testenv"
+)
+
+// TestGop checks that Gop renames the references in Go+ files, in the
+// form in which they appear.
+func TestGop(t *testing.T) {
+	testenv.NeedsGOPROOT(t)
+	t.Setenv("GO111MODULE", "off") // gorename requires GOPATH mode
+
+	defer func(savedWriteFile func(string, []byte) error, savedReportError func(token.Position, string)) {
+		writeFile = savedWriteFile
+		reportError = savedReportError
+		Gop = false
+	}(writeFile, reportError)
+	reportError = func(posn token.Position, message string) {
+		t.Errorf("%s: %s", posn, message)
+	}
+	Gop = true
+
+	const (
+		libGo = `package lib
+
+const GopPackage = true
+
+// Hello returns a greeting.
+func Hello(name string) string { return "hello " + name }
+
+func Add__0(a, b int) int { return a + b }
+
+func Add__1(a, b string) string { return a + b }
+`
+		appGop = `import "lib"
+
+println lib.hello("x"), lib.Hello("y")
+println lib.add(1, 2), lib.Add("a", "b")
+`
+		// The Go code generated from app.gop by gop.
+		appAutogen = `package main
+
+import (
+	"fmt"
+	"lib"
+)
+
+func main() {
+	fmt.Println(lib.Hello("x"), lib.Hello("y"))
+	fmt.Println(lib.Add__0(1, 2), lib.Add__1("a", "b"))
+}
+`
+	)
+	gopath := t.TempDir()
+	for name, content := range map[string]string{
+		"src/lib/lib.go":         libGo,
+		"src/app/app.gop":        appGop,
+		"src/app/gop_autogen.go": appAutogen,
+	} {
+		filename := filepath.Join(gopath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctxt := build.Default
+	ctxt.GOPATH = gopath
+	appFile := filepath.Join(gopath, "src", "app", "app.gop")
+
+	for _, test := range []struct {
+		from, to string
+		want     string // the renamed app.gop
+	}{
+		// Exported name and its lowercase form.
+		{
+			from: `"lib".Hello`, to: "Greet",
+			want: `import "lib"
+
+println lib.greet("x"), lib.Greet("y")
+println lib.add(1, 2), lib.Add("a", "b")
+`,
+		},
+		// Overloads, referred to by the name of the overloaded function.
+		{
+			from: `"lib".Add__0`, to: "Sum__0",
+			want: `import "lib"
+
+println lib.hello("x"), lib.Hello("y")
+println lib.sum(1, 2), lib.Add("a", "b")
+`,
+		},
+		{
+			from: `"lib".Add__1`, to: "Concat",
+			want: `import "lib"
+
+println lib.hello("x"), lib.Hello("y")
+println lib.add(1, 2), lib.Concat("a", "b")
+`,
+		},
+	} {
+		got := make(map[string]string)
+		writeFile = func(filename string, content []byte) error {
+			got[filename] = string(content)
+			return nil
+		}
+		if err := Main(&ctxt, "", test.from, test.to); err != nil {
+			t.Errorf("%s -> %s: %v", test.from, test.to, err)
+			continue
+		}
+		if got[appFile] != test.want {
+			t.Errorf("%s -> %s: app.gop = <<%s>>, want <<%s>>", test.from, test.to, got[appFile], test.want)
+		}
+		for filename := range got {
+			if filepath.Base(filename) == "gop_autogen.go" {
+				t.Errorf("%s -> %s: generated file %s was renamed", test.from, test.to, filename)
+			}
+		}
+	}
+}
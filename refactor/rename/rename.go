@@ -82,6 +82,12 @@ Flags:
 
 -d         display diffs instead of rewriting files
 
+-gop       renames references in Go+ source files (.gop, .gox, ...) too,
+           including the lowercase form of exported names (println for
+           Println) and the name Foo of the overloads Foo__0, Foo__1, ...
+           The Go code generated from Go+ files (gop_autogen.go) is
+           generated anew rather than renamed.
+
 -v         enables verbose logging.
 
 gorename automatically computes the set of packages that might be
@@ -167,6 +173,7 @@ type renamer struct {
 	packages           map[*types.Package]*loader.PackageInfo // subset of iprog.AllPackages to inspect
 	msets              typeutil.MethodSetCache
 	changeMethods      bool
+	ctxt               *build.Context // goxls: for loading Go+ packages (see Gop); or nil
 }
 
 var reportError = func(posn token.Position, message string) {
@@ -316,6 +323,7 @@ func Main(ctxt *build.Context, offsetFlag, fromFlag, to string) error {
 		from:         spec.fromName,
 		to:           to,
 		packages:     make(map[*types.Package]*loader.PackageInfo),
+		ctxt:         ctxt,
 	}
 
 	// A renaming initiated at an interface method indicates the
@@ -463,6 +471,9 @@ func (r *renamer) update() error {
 		// Mutate the ASTs and note the filenames.
 		for id, obj := range info.Defs {
 			if r.objsToUpdate[obj] {
+				if r.isGopAutogen(id.Pos()) {
+					continue // goxls: generated anew from Go+ source
+				}
 				nidents++
 				id.Name = r.to
 				filesToUpdate[r.iprog.Fset.File(id.Pos())] = true
@@ -477,6 +488,9 @@ func (r *renamer) update() error {
 
 		for id, obj := range info.Uses {
 			if r.objsToUpdate[obj] {
+				if r.isGopAutogen(id.Pos()) {
+					continue // goxls: generated anew from Go+ source
+				}
 				nidents++
 				id.Name = r.to
 				filesToUpdate[r.iprog.Fset.File(id.Pos())] = true
@@ -500,6 +514,7 @@ func (r *renamer) update() error {
 
 	// Write affected files.
 	var nerrs, npkgs int
+	updated := make(map[string]bool) // goxls: paths of updated packages
 	for _, info := range r.packages {
 		first := true
 		for _, f := range info.Files {
@@ -507,6 +522,7 @@ func (r *renamer) update() error {
 			if filesToUpdate[tokenFile] {
 				if first {
 					npkgs++
+					updated[strings.TrimSuffix(info.Pkg.Path(), "_test")] = true // goxls
 					first = false
 					if Verbose {
 						log.Printf("Updating package %s", info.Pkg.Path())
@@ -527,10 +543,21 @@ func (r *renamer) update() error {
 			}
 		}
 	}
+	nfiles := len(filesToUpdate)
+	if Gop && r.ctxt != nil { // goxls: rename references in Go+ source
+		gopIdents, gopFiles, gopPkgs, err := r.updateGop(updated)
+		if err != nil {
+			log.Print(err)
+			nerrs++
+		}
+		nidents += gopIdents
+		nfiles += gopFiles
+		npkgs += gopPkgs
+	}
 	if !Diff {
 		fmt.Printf("Renamed %d occurrence%s in %d file%s in %d package%s.\n",
 			nidents, plural(nidents),
-			len(filesToUpdate), plural(len(filesToUpdate)),
+			nfiles, plural(nfiles),
 			npkgs, plural(npkgs))
 	}
 	if nerrs > 0 {